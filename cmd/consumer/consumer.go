@@ -22,3 +22,43 @@ func RunExpiredPaymentConsumer(ctx context.Context) {
 	con.Init()
 	con.Start(con.ExpiredPaymentConsumer)
 }
+
+func RunScheduledTransferConsumer(ctx context.Context) {
+	b := bootstrap.NewBootstrap(ctx).BuildConsumerScheduledTransferBootstrap()
+
+	con := consumer.NewConsumer(b)
+	con.Init()
+	con.Start(con.ScheduledTransferConsumer)
+}
+
+func RunGuestCartCleanupConsumer(ctx context.Context) {
+	b := bootstrap.NewBootstrap(ctx).BuildConsumerGuestCartCleanupBootstrap()
+
+	con := consumer.NewConsumer(b)
+	con.Init()
+	con.Start(con.GuestCartCleanupConsumer)
+}
+
+func RunCartWriteBehindConsumer(ctx context.Context) {
+	b := bootstrap.NewBootstrap(ctx).BuildConsumerCartWriteBehindBootstrap()
+
+	con := consumer.NewConsumer(b)
+	con.Init()
+	con.Start(con.CartWriteBehindConsumer)
+}
+
+func RunCartReminderConsumer(ctx context.Context) {
+	b := bootstrap.NewBootstrap(ctx).BuildConsumerCartReminderBootstrap()
+
+	con := consumer.NewConsumer(b)
+	con.Init()
+	con.Start(con.CartReminderConsumer)
+}
+
+func RunProductImageDerivativesConsumer(ctx context.Context) {
+	b := bootstrap.NewBootstrap(ctx).BuildConsumerProductImageDerivativesBootstrap()
+
+	con := consumer.NewConsumer(b)
+	con.Init()
+	con.Start(con.ProductImageDerivativesConsumer)
+}
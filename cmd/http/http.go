@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/handler/http"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/router"
 	"github.com/aldotp/ecommerce-go-api/internal/core/service"
@@ -17,14 +18,22 @@ func RunHTTPServer(ctx context.Context) {
 
 	// Services
 	userService := service.NewUserService(f.UserRepo, f.Cache, f.Token, f.Log, f.BalanceRepo)
-	authService := service.NewAuthService(f.UserRepo, f.Token, f.Log)
-	productService := service.NewProductService(f.ProductRepo, f.Cache)
+	productService := service.NewProductService(f.ProductRepo, f.Cache, f.WishlistRepo, f.ImageRepo, f.Storage, f.RabbitMQ, f.Log)
 	categoryService := service.NewCategoryService(f.CategoryRepo, f.Cache)
-	cartService := service.NewCartService(f.CartItemRepo, f.CartRepo, f.OrderRepo, f.OrderItemRepo, f.ProductRepo)
-	checkoutService := service.NewCheckoutService(f.ProductRepo, f.OrderRepo, f.OrderItemRepo, f.CartRepo, f.CartItemRepo, f.PaymentRepo)
-	balanceService := service.NewBalanceService(f.BalanceRepo, f.Cache)
+	cartService := service.NewCartService(f.CartItemRepo, f.CartRepo, f.OrderRepo, f.OrderItemRepo, f.ProductRepo, f.VariantRepo, f.Config.Business, config.CartTokenSecret())
+	authService := service.NewAuthService(f.UserRepo, f.Token, cartService, f.Log)
+	balanceService := service.NewBalanceService(f.BalanceRepo, f.BalanceTransactionRepo, f.UserRepo, f.TransferReviewRepo, f.OrderRepo, f.PaymentRepo, f.TransferInquiryRepo, f.Locker, f.Email, f.Config.Business)
+	checkoutService := service.NewCheckoutService(f.ProductRepo, f.VariantRepo, f.OrderRepo, f.OrderItemRepo, f.CartRepo, f.CartItemRepo, f.PaymentRepo, balanceService, f.Config.Business)
 	paymentService := service.NewPaymentService(f.PaymentRepo, f.OrderRepo, f.RabbitMQ, f.BalanceRepo, balanceService)
 	orderService := service.NewOrderService(f.PaymentRepo, f.OrderRepo)
+	withdrawalService := service.NewWithdrawalService(f.WithdrawalRepo, f.PayoutDestinationRepo, f.PayoutProvider, f.Locker, f.Config.Business)
+	scheduledTransferService := service.NewScheduledTransferService(f.ScheduledTransferRepo, f.UserRepo, balanceService, f.Email, f.Config.Business, f.Log)
+	moneyRequestService := service.NewMoneyRequestService(f.MoneyRequestRepo, f.UserRepo, balanceService, f.Config.Business, f.Log)
+	walletAdjustmentService := service.NewWalletAdjustmentService(f.WalletAdjustmentRepo, f.UserRepo, f.Locker, f.Config.Business)
+	wishlistService := service.NewWishlistService(f.WishlistRepo, f.ProductRepo, cartService)
+	productVariantService := service.NewProductVariantService(f.VariantRepo, f.ProductRepo)
+	productImageService := service.NewProductImageService(f.ImageRepo, f.ProductRepo, f.Storage, f.RabbitMQ, config.ProductImageMaxSize(), config.ProductImageUploadURLTTL(), f.Log)
+	cartReminderService := service.NewCartReminderService(f.CartReminderRepo, f.CartItemRepo, f.VariantRepo, f.Email, f.Config.Business, config.CartTokenSecret(), config.PublicURL(), f.Log)
 
 	// Handlers
 	userHandler := http.NewUserHandler(userService, f.Log)
@@ -36,6 +45,20 @@ func RunHTTPServer(ctx context.Context) {
 	paymentHandler := http.NewPaymentHandler(paymentService, f.Log)
 	orderHandler := http.NewOrderHandler(orderService, f.Log)
 	balanceHandler := http.NewBalanceHandler(balanceService, f.Log)
+	settingHandler := http.NewSettingHandler(f.Config.Business, f.Log)
+	withdrawalHandler := http.NewWithdrawalHandler(withdrawalService, f.Log)
+	scheduledTransferHandler := http.NewScheduledTransferHandler(scheduledTransferService, f.Log)
+	moneyRequestHandler := http.NewMoneyRequestHandler(moneyRequestService, f.Log)
+	walletAdjustmentHandler := http.NewWalletAdjustmentHandler(walletAdjustmentService, f.Log)
+	cartReminderHandler := http.NewCartReminderHandler(cartReminderService, f.Log)
+	wishlistHandler := http.NewWishlistHandler(wishlistService, f.Log)
+	productVariantHandler := http.NewProductVariantHandler(productVariantService, f.Log)
+	productImageHandler := http.NewProductImageHandler(productImageService, config.ProductImageMaxSize(), f.Log)
+
+	var fileHandler *http.FileHandler
+	if f.LocalStorage != nil {
+		fileHandler = http.NewFileHandler(f.LocalStorage, config.ProductImageMaxSize(), f.Log)
+	}
 
 	// HTTP server
 	routes, err := router.NewRouter(
@@ -49,6 +72,16 @@ func RunHTTPServer(ctx context.Context) {
 		paymentHandler,
 		orderHandler,
 		balanceHandler,
+		settingHandler,
+		withdrawalHandler,
+		scheduledTransferHandler,
+		moneyRequestHandler,
+		walletAdjustmentHandler,
+		cartReminderHandler,
+		wishlistHandler,
+		productVariantHandler,
+		productImageHandler,
+		fileHandler,
 	)
 	if err != nil {
 		slog.Error("Error creating router", "error", err)
@@ -0,0 +1,21 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
+	"go.uber.org/zap"
+)
+
+// RunRebuildBalance recomputes the cached wallet balances from the journal lines
+func RunRebuildBalance(ctx context.Context) {
+	b := bootstrap.NewBootstrap(ctx).BuildLedgerBootstrap()
+	defer b.PostgresDB.Close()
+
+	corrected, err := b.BalanceRepo.RebuildBalances(ctx)
+	if err != nil {
+		b.Log.Fatal("failed to rebuild balances", zap.Error(err))
+	}
+
+	b.Log.Info("balances rebuilt from journal", zap.Int64("corrected", corrected))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/aldotp/ecommerce-go-api/cmd/consumer"
 	"github.com/aldotp/ecommerce-go-api/cmd/http"
+	"github.com/aldotp/ecommerce-go-api/cmd/ledger"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
 	"github.com/spf13/cobra"
 
@@ -53,14 +54,78 @@ func main() {
 		},
 	}
 
+	consumerScheduledTransferCmd := cobra.Command{
+		Use:   "scheduled_transfer",
+		Short: "Consumer is a command to start the scheduled transfer worker",
+		Run: func(cmd *cobra.Command, args []string) {
+			consumer.RunScheduledTransferConsumer(ctx)
+		},
+	}
+
+	consumerGuestCartCleanupCmd := cobra.Command{
+		Use:   "guest_cart_cleanup",
+		Short: "Consumer is a command to start the expired guest cart cleanup worker",
+		Run: func(cmd *cobra.Command, args []string) {
+			consumer.RunGuestCartCleanupConsumer(ctx)
+		},
+	}
+
+	consumerCartWriteBehindCmd := cobra.Command{
+		Use:   "cart_write_behind",
+		Short: "Consumer is a command to start copying Redis carts to Postgres",
+		Run: func(cmd *cobra.Command, args []string) {
+			consumer.RunCartWriteBehindConsumer(ctx)
+		},
+	}
+
+	consumerCartReminderCmd := cobra.Command{
+		Use:   "cart_reminder",
+		Short: "Consumer is a command to start the abandoned cart reminder worker",
+		Run: func(cmd *cobra.Command, args []string) {
+			consumer.RunCartReminderConsumer(ctx)
+		},
+	}
+
+	consumerProductImageDerivativesCmd := cobra.Command{
+		Use:   "product_image_derivatives",
+		Short: "Consumer is a command to start the product image resizing worker",
+		Run: func(cmd *cobra.Command, args []string) {
+			consumer.RunProductImageDerivativesConsumer(ctx)
+		},
+	}
+
+	// define ledger command
+	ledgerCmd := cobra.Command{
+		Use:   "ledger",
+		Short: "Ledger is a command to run wallet ledger maintenance",
+	}
+
+	ledgerRebuildBalanceCmd := cobra.Command{
+		Use:   "rebuild_balance",
+		Short: "Rebuild wallet balances from the journal",
+		Run: func(cmd *cobra.Command, args []string) {
+			ledger.RunRebuildBalance(ctx)
+		},
+	}
+
 	rootCmd.AddCommand(
 		&restCmd,
 		&consumerCmd,
+		&ledgerCmd,
 	)
 
 	consumerCmd.AddCommand(
 		&consumerExpiredPaymentCmd,
 		&consumerUpdateStockCmd,
+		&consumerScheduledTransferCmd,
+		&consumerGuestCartCleanupCmd,
+		&consumerCartWriteBehindCmd,
+		&consumerCartReminderCmd,
+		&consumerProductImageDerivativesCmd,
+	)
+
+	ledgerCmd.AddCommand(
+		&ledgerRebuildBalanceCmd,
 	)
 
 	if err := rootCmd.Execute(); err != nil {
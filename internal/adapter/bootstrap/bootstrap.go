@@ -6,7 +6,10 @@ import (
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/rabbitmq"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/redis"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+	"github.com/aldotp/ecommerce-go-api/pkg/localstorage"
 
 	"go.uber.org/zap"
 )
@@ -21,6 +24,8 @@ type Bootstrap struct {
 	UserRepo      port.UserRepository
 	OrderRepo     port.OrderRepository
 	ProductRepo   port.ProductRepository
+	VariantRepo   port.ProductVariantRepository
+	ImageRepo     port.ProductImageRepository
 	PaymentRepo   port.PaymentRepository
 	OrderItemRepo port.OrderItemRepository
 	CartItemRepo  port.CartItemRepository
@@ -28,8 +33,31 @@ type Bootstrap struct {
 	CategoryRepo  port.CategoryRepository
 	BalanceRepo   port.BalanceRepository
 
-	Token port.TokenInterface
-	Cache port.CacheInterface
+	BalanceTransactionRepo port.BalanceTransactionRepository
+	PayoutDestinationRepo  port.PayoutDestinationRepository
+	WithdrawalRepo         port.WithdrawalRepository
+	TransferReviewRepo     port.TransferReviewRepository
+	TransferInquiryRepo    port.TransferInquiryRepository
+	ScheduledTransferRepo  port.ScheduledTransferRepository
+	MoneyRequestRepo       port.MoneyRequestRepository
+	WalletAdjustmentRepo   port.WalletAdjustmentRepository
+	CartReminderRepo       port.CartReminderRepository
+	WishlistRepo           port.WishlistRepository
+
+	PayoutProvider port.PayoutProvider
+
+	// CartWriteBehind is only set with the Redis cart backend
+	CartWriteBehind *redis.CartWriteBehind
+
+	Token  port.TokenInterface
+	Cache  port.CacheInterface
+	Locker port.Locker
+	Email  port.EmailSender
+
+	// Storage is nil while no bucket is configured
+	Storage gcs.StorageInterface
+	// LocalStorage is set with STORAGE_BACKEND=local, the API serves its files
+	LocalStorage *localstorage.LocalStorage
 }
 
 func NewBootstrap(ctx context.Context) *Bootstrap {
@@ -5,10 +5,15 @@ func (b *Bootstrap) BuildRestBootstrap() *Bootstrap {
 	b.setConfig()
 	b.setPostgresDB()
 	b.setRestApiRepository()
+	b.setCartRepository()
 	b.setLogger()
 	b.setJWTToken()
 	b.setCache()
+	b.setLocker()
 	b.setRabbitMQ()
+	b.setPayoutProvider()
+	b.setEmail()
+	b.setStorage()
 
 	return b
 }
@@ -25,6 +30,16 @@ func (b *Bootstrap) BuildConsumerUpdateOrderStatusBootstrap() *Bootstrap {
 	return b
 }
 
+func (b *Bootstrap) BuildLedgerBootstrap() *Bootstrap {
+	// set dependencies
+	b.setConfig()
+	b.setPostgresDB()
+	b.SetLedgerRepository()
+	b.setLogger()
+
+	return b
+}
+
 func (b *Bootstrap) BuildConsumerExpiredPaymentBootstrap() *Bootstrap {
 	// set dependencies
 	b.setConfig()
@@ -36,3 +51,66 @@ func (b *Bootstrap) BuildConsumerExpiredPaymentBootstrap() *Bootstrap {
 
 	return b
 }
+
+func (b *Bootstrap) BuildConsumerScheduledTransferBootstrap() *Bootstrap {
+	// set dependencies
+	b.setConfig()
+	b.setCache()
+	b.setPostgresDB()
+	b.SetScheduledTransferConsumerRepository()
+	b.setLogger()
+	b.setLocker()
+	b.setRabbitMQ()
+	b.setEmail()
+
+	return b
+}
+
+func (b *Bootstrap) BuildConsumerGuestCartCleanupBootstrap() *Bootstrap {
+	// set dependencies
+	b.setConfig()
+	b.setPostgresDB()
+	b.SetGuestCartConsumerRepository()
+	b.setCartRepository()
+	b.setLogger()
+	b.setRabbitMQ()
+
+	return b
+}
+
+func (b *Bootstrap) BuildConsumerCartWriteBehindBootstrap() *Bootstrap {
+	// set dependencies
+	b.setConfig()
+	b.setPostgresDB()
+	b.SetCartWriteBehindConsumerRepository()
+	b.setCartRepository()
+	b.setLogger()
+	b.setRabbitMQ()
+
+	return b
+}
+
+func (b *Bootstrap) BuildConsumerCartReminderBootstrap() *Bootstrap {
+	// set dependencies
+	b.setConfig()
+	b.setPostgresDB()
+	b.SetCartReminderConsumerRepository()
+	b.setCartRepository()
+	b.setLogger()
+	b.setRabbitMQ()
+	b.setEmail()
+
+	return b
+}
+
+func (b *Bootstrap) BuildConsumerProductImageDerivativesBootstrap() *Bootstrap {
+	// set dependencies
+	b.setConfig()
+	b.setPostgresDB()
+	b.SetProductImageDerivativesConsumerRepository()
+	b.setLogger()
+	b.setStorage()
+	b.setRabbitMQ()
+
+	return b
+}
@@ -1,16 +1,24 @@
 package bootstrap
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/auth/jwt"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/locker"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/payout"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/rabbitmq"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
 	postgresRepo "github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres/repository"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/redis"
+	"github.com/aldotp/ecommerce-go-api/pkg/email"
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+	"github.com/aldotp/ecommerce-go-api/pkg/localstorage"
 	"github.com/aldotp/ecommerce-go-api/pkg/logger"
+	"github.com/aldotp/ecommerce-go-api/pkg/s3"
+	"go.uber.org/zap"
 )
 
 func (b *Bootstrap) setConfig() {
@@ -58,44 +66,228 @@ func (b *Bootstrap) setJWTToken() {
 	b.Token = token
 }
 
+// setCache connects the Redis cache. Redis being down doesn't stop the boot,
+// the wallet runs on Postgres and the cached reads fail until Redis is back
 func (b *Bootstrap) setCache() {
-	cache, err := redis.New(b.ctx, &config.Redis{
+	redisConfig := &config.Redis{
 		Addr:     config.RedisAddr(),
 		Password: config.RedisPassword(),
-	})
+	}
+
+	cache, err := redis.New(b.ctx, redisConfig)
 	if err != nil {
-		panic(err)
+		slog.Warn("Redis cache unavailable, starting without it", "error", err)
+		cache = redis.NewUnchecked(redisConfig)
 	}
 
 	b.Cache = cache
 }
 
+// setLocker picks the wallet lock backend from LOCKER_BACKEND, the Postgres
+// locker needs setPostgresDB and the fallback logs through setLogger
+func (b *Bootstrap) setLocker() {
+	backend := config.LockerBackend()
+
+	if backend == config.LockerPostgres {
+		pgLocker, err := postgres.NewLocker(b.ctx, b.PostgresDB, config.LockerPostgresMaxConns(), config.LockerPostgresAcquireTimeout())
+		if err != nil {
+			panic(err)
+		}
+		b.Locker = pgLocker
+		return
+	}
+
+	redisConfig := &config.Redis{
+		Addr:     config.RedisAddr(),
+		Password: config.RedisPassword(),
+	}
+
+	switch backend {
+	case config.LockerRedis:
+		redisLocker, err := redis.NewLocker(b.ctx, redisConfig)
+		if err != nil {
+			panic(err)
+		}
+		b.Locker = redisLocker
+	case config.LockerRedisPostgres:
+		pgLocker, err := postgres.NewLocker(b.ctx, b.PostgresDB, config.LockerPostgresMaxConns(), config.LockerPostgresAcquireTimeout())
+		if err != nil {
+			panic(err)
+		}
+
+		// Redis being down at boot is the outage the fallback is there for,
+		// the first lock finds out and switches to Postgres
+		redisLocker, err := redis.NewLocker(b.ctx, redisConfig)
+		if err != nil {
+			b.Log.Warn("Redis locker unavailable, starting on the Postgres fallback", zap.Error(err))
+			redisLocker = redis.NewUncheckedLocker(redisConfig)
+		}
+		b.Locker = locker.NewFallbackLocker(redisLocker, pgLocker, b.Log)
+	default:
+		panic(fmt.Sprintf("unknown LOCKER_BACKEND %q", backend))
+	}
+}
+
+// setCartRepository picks the cart storage from CART_BACKEND, the Redis carts
+// join their items with products so it runs after the repositories are set
+func (b *Bootstrap) setCartRepository() {
+	switch backend := config.CartBackend(); backend {
+	case config.CartBackendPostgres:
+		b.CartRepo = postgresRepo.NewCartRepository(b.PostgresDB)
+		b.CartItemRepo = postgresRepo.NewCartItemRepository(b.PostgresDB)
+	case config.CartBackendRedis:
+		carts, cartItems, err := redis.NewCartRepositories(b.ctx, &config.Redis{
+			Addr:     config.RedisAddr(),
+			Password: config.RedisPassword(),
+		}, b.ProductRepo, redis.CartOptions{
+			TTL:         config.CartRedisTTL(),
+			WriteBehind: config.CartWriteBehind(),
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		b.CartRepo = carts
+		b.CartItemRepo = cartItems
+		b.CartWriteBehind = redis.NewCartWriteBehind(carts, postgresRepo.NewCartSnapshotRepository(b.PostgresDB))
+	default:
+		panic(fmt.Sprintf("unknown CART_BACKEND %q", backend))
+	}
+}
+
+func (b *Bootstrap) setEmail() {
+	smtp := &config.SMTP{
+		Host:     config.SMTPHost(),
+		Port:     config.SMTPPort(),
+		Username: config.SMTPUsername(),
+		Password: config.SMTPPassword(),
+		From:     config.SMTPFrom(),
+	}
+
+	b.Email = email.NewEmailSender(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From)
+}
+
+// setStorage picks the file storage from STORAGE_BACKEND. Without
+// GCS_BUCKET_NAME the GCS backend leaves Storage nil and file uploads are
+// refused
+func (b *Bootstrap) setStorage() {
+	switch backend := config.StorageBackend(); backend {
+	case config.StorageBackendGCS:
+		bucket := config.GCSBucketName()
+		if bucket == "" {
+			b.Log.Warn("GCS_BUCKET_NAME is not set, file uploads are disabled")
+			return
+		}
+
+		storage, err := gcs.NewCGS(config.GCSCredential(), bucket)
+		if err != nil {
+			panic(err)
+		}
+
+		b.Storage = storage
+	case config.StorageBackendLocal:
+		storage, err := localstorage.NewLocalStorage(config.LocalStorageDir(), config.LocalStorageURL(), config.LocalStorageSecret())
+		if err != nil {
+			panic(err)
+		}
+
+		b.Storage = storage
+		b.LocalStorage = storage
+	case config.StorageBackendS3:
+		storage, err := s3.NewS3(config.S3Endpoint(), config.S3Region(), config.S3Bucket(), config.S3AccessKey(), config.S3SecretKey())
+		if err != nil {
+			panic(err)
+		}
+
+		b.Storage = storage
+	default:
+		panic(fmt.Sprintf("unknown STORAGE_BACKEND %q", backend))
+	}
+}
+
 func (b *Bootstrap) setRabbitMQ() {
 	mqConn, mqCh := rabbitmq.CreateConnection()
 	b.RabbitMQ = rabbitmq.New(mqConn, mqCh, b.Log)
 }
 
+func (b *Bootstrap) setPayoutProvider() {
+	b.PayoutProvider = payout.NewFakeProvider(b.Log)
+}
+
 func (b *Bootstrap) setRestApiRepository() {
 	b.UserRepo = postgresRepo.NewUserRepository(b.PostgresDB)
 	b.OrderRepo = postgresRepo.NewOrderRepository(b.PostgresDB)
 	b.ProductRepo = postgresRepo.NewProductRepository(b.PostgresDB)
+	b.VariantRepo = postgresRepo.NewProductVariantRepository(b.PostgresDB)
+	b.ImageRepo = postgresRepo.NewProductImageRepository(b.PostgresDB)
 	b.PaymentRepo = postgresRepo.NewPaymentRepository(b.PostgresDB)
 	b.OrderItemRepo = postgresRepo.NewOrderItemRepository(b.PostgresDB)
-	b.CartItemRepo = postgresRepo.NewCartItemRepository(b.PostgresDB)
-	b.CartRepo = postgresRepo.NewCartRepository(b.PostgresDB)
 	b.CategoryRepo = postgresRepo.NewCategoryRepository(b.PostgresDB)
 	b.BalanceRepo = postgresRepo.NewBalanceRepository(b.PostgresDB)
+	b.BalanceTransactionRepo = postgresRepo.NewBalanceTransactionRepository(b.PostgresDB)
+	b.PayoutDestinationRepo = postgresRepo.NewPayoutDestinationRepository(b.PostgresDB)
+	b.WithdrawalRepo = postgresRepo.NewWithdrawalRepository(b.PostgresDB)
+	b.TransferReviewRepo = postgresRepo.NewTransferReviewRepository(b.PostgresDB)
+	b.TransferInquiryRepo = postgresRepo.NewTransferInquiryRepository(b.PostgresDB)
+	b.ScheduledTransferRepo = postgresRepo.NewScheduledTransferRepository(b.PostgresDB)
+	b.MoneyRequestRepo = postgresRepo.NewMoneyRequestRepository(b.PostgresDB)
+	b.WalletAdjustmentRepo = postgresRepo.NewWalletAdjustmentRepository(b.PostgresDB)
+	b.CartReminderRepo = postgresRepo.NewCartReminderRepository(b.PostgresDB, config.CartBackend() == config.CartBackendRedis)
+	b.WishlistRepo = postgresRepo.NewWishlistRepository(b.PostgresDB)
 }
 
 func (b *Bootstrap) SetUpdateStatusConsumerRepository() {
 	b.OrderRepo = postgresRepo.NewOrderRepository(b.PostgresDB)
 	b.PaymentRepo = postgresRepo.NewPaymentRepository(b.PostgresDB)
 	b.ProductRepo = postgresRepo.NewProductRepository(b.PostgresDB)
+	b.ImageRepo = postgresRepo.NewProductImageRepository(b.PostgresDB)
+	b.WishlistRepo = postgresRepo.NewWishlistRepository(b.PostgresDB)
 }
 
 func (b *Bootstrap) SetExpiredPaymentConsumerRepository() {
+	b.BalanceRepo = postgresRepo.NewBalanceRepository(b.PostgresDB)
 	b.OrderRepo = postgresRepo.NewOrderRepository(b.PostgresDB)
 	b.PaymentRepo = postgresRepo.NewPaymentRepository(b.PostgresDB)
 	b.OrderItemRepo = postgresRepo.NewOrderItemRepository(b.PostgresDB)
 	b.ProductRepo = postgresRepo.NewProductRepository(b.PostgresDB)
+	b.VariantRepo = postgresRepo.NewProductVariantRepository(b.PostgresDB)
+}
+
+func (b *Bootstrap) SetScheduledTransferConsumerRepository() {
+	b.UserRepo = postgresRepo.NewUserRepository(b.PostgresDB)
+	b.BalanceRepo = postgresRepo.NewBalanceRepository(b.PostgresDB)
+	b.BalanceTransactionRepo = postgresRepo.NewBalanceTransactionRepository(b.PostgresDB)
+	b.TransferReviewRepo = postgresRepo.NewTransferReviewRepository(b.PostgresDB)
+	b.TransferInquiryRepo = postgresRepo.NewTransferInquiryRepository(b.PostgresDB)
+	b.ScheduledTransferRepo = postgresRepo.NewScheduledTransferRepository(b.PostgresDB)
+}
+
+func (b *Bootstrap) SetGuestCartConsumerRepository() {
+	b.ProductRepo = postgresRepo.NewProductRepository(b.PostgresDB)
+	b.VariantRepo = postgresRepo.NewProductVariantRepository(b.PostgresDB)
+}
+
+func (b *Bootstrap) SetCartWriteBehindConsumerRepository() {
+	b.ProductRepo = postgresRepo.NewProductRepository(b.PostgresDB)
+}
+
+// SetCartReminderConsumerRepository refuses Redis carts without the write
+// behind, the reminders would read a cart_snapshots table nothing fills
+func (b *Bootstrap) SetCartReminderConsumerRepository() {
+	if config.CartBackend() == config.CartBackendRedis && !config.CartWriteBehind() {
+		panic("cart reminders need CART_WRITE_BEHIND=true when CART_BACKEND is redis")
+	}
+
+	b.ProductRepo = postgresRepo.NewProductRepository(b.PostgresDB)
+	b.VariantRepo = postgresRepo.NewProductVariantRepository(b.PostgresDB)
+	b.CartReminderRepo = postgresRepo.NewCartReminderRepository(b.PostgresDB, config.CartBackend() == config.CartBackendRedis)
+}
+
+func (b *Bootstrap) SetProductImageDerivativesConsumerRepository() {
+	b.ProductRepo = postgresRepo.NewProductRepository(b.PostgresDB)
+	b.ImageRepo = postgresRepo.NewProductImageRepository(b.PostgresDB)
+}
+
+func (b *Bootstrap) SetLedgerRepository() {
+	b.BalanceRepo = postgresRepo.NewBalanceRepository(b.PostgresDB)
 }
@@ -22,3 +22,12 @@ func SecretKey() string {
 func RefreshKey() string {
 	return viper.GetString("REFRESH_KEY")
 }
+
+// CartTokenSecret signs the guest cart tokens, it falls back to SECRET_KEY
+func CartTokenSecret() string {
+	if secret := viper.GetString("CART_TOKEN_SECRET"); secret != "" {
+		return secret
+	}
+
+	return SecretKey()
+}
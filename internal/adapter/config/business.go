@@ -0,0 +1,306 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Business rules related configuration
+func PaymentExpiry() time.Duration {
+	return viper.GetDuration("PAYMENT_EXPIRY")
+}
+
+func PaymentExpiryOverrides() string {
+	return viper.GetString("PAYMENT_EXPIRY_OVERRIDES")
+}
+
+func CartMaxItems() int {
+	return viper.GetInt("CART_MAX_ITEMS")
+}
+
+func CartMaxQuantity() int {
+	return viper.GetInt("CART_MAX_QUANTITY")
+}
+
+func GuestCartTTL() time.Duration {
+	return viper.GetDuration("GUEST_CART_TTL")
+}
+
+func OrderMaxTotal() float64 {
+	return viper.GetFloat64("ORDER_MAX_TOTAL")
+}
+
+func OrderMaxTotalOverrides() string {
+	return viper.GetString("ORDER_MAX_TOTAL_OVERRIDES")
+}
+
+func BalanceLockTTL() time.Duration {
+	return viper.GetDuration("BALANCE_LOCK_TTL")
+}
+
+func BalanceLockRetries() int {
+	return viper.GetInt("BALANCE_LOCK_RETRIES")
+}
+
+func BalanceLockRetryBackoff() time.Duration {
+	return viper.GetDuration("BALANCE_LOCK_RETRY_BACKOFF")
+}
+
+func BalanceLockMaxBackoff() time.Duration {
+	return viper.GetDuration("BALANCE_LOCK_MAX_BACKOFF")
+}
+
+func TransferMaxAmount() float64 {
+	return viper.GetFloat64("TRANSFER_MAX_AMOUNT")
+}
+
+func TransferMaxAmountOverrides() string {
+	return viper.GetString("TRANSFER_MAX_AMOUNT_OVERRIDES")
+}
+
+func TransferDailyLimit() float64 {
+	return viper.GetFloat64("TRANSFER_DAILY_LIMIT")
+}
+
+func TransferDailyLimitOverrides() string {
+	return viper.GetString("TRANSFER_DAILY_LIMIT_OVERRIDES")
+}
+
+func TransferMonthlyLimit() float64 {
+	return viper.GetFloat64("TRANSFER_MONTHLY_LIMIT")
+}
+
+func TransferMonthlyLimitOverrides() string {
+	return viper.GetString("TRANSFER_MONTHLY_LIMIT_OVERRIDES")
+}
+
+func TransferOTPThreshold() float64 {
+	return viper.GetFloat64("TRANSFER_OTP_THRESHOLD")
+}
+
+func TransferReviewThreshold() float64 {
+	return viper.GetFloat64("TRANSFER_REVIEW_THRESHOLD")
+}
+
+func TransferInquiryTTL() time.Duration {
+	return viper.GetDuration("TRANSFER_INQUIRY_TTL")
+}
+
+func ScheduledTransferMaxRetries() int {
+	return viper.GetInt("SCHEDULED_TRANSFER_MAX_RETRIES")
+}
+
+func ScheduledTransferRetryDelay() time.Duration {
+	return viper.GetDuration("SCHEDULED_TRANSFER_RETRY_DELAY")
+}
+
+func ScheduledTransferBatchSize() int {
+	return viper.GetInt("SCHEDULED_TRANSFER_BATCH_SIZE")
+}
+
+func MoneyRequestTTL() time.Duration {
+	return viper.GetDuration("MONEY_REQUEST_TTL")
+}
+
+func AdjustmentApprovalThreshold() float64 {
+	return viper.GetFloat64("ADJUSTMENT_APPROVAL_THRESHOLD")
+}
+
+func CartReminderAfter() time.Duration {
+	return viper.GetDuration("CART_REMINDER_AFTER")
+}
+
+func CartReminderInterval() time.Duration {
+	return viper.GetDuration("CART_REMINDER_INTERVAL")
+}
+
+func CartReminderMax() int {
+	return viper.GetInt("CART_REMINDER_MAX")
+}
+
+func CartReminderWindow() time.Duration {
+	return viper.GetDuration("CART_REMINDER_WINDOW")
+}
+
+func CartReminderBatchSize() int {
+	return viper.GetInt("CART_REMINDER_BATCH_SIZE")
+}
+
+// NewBusiness builds the business rules from the environment, applying the
+// per payment method overrides on top of the global values
+func NewBusiness() (*Business, error) {
+	business := &Business{
+		PaymentExpiry:      PaymentExpiry(),
+		MaxCartItems:       CartMaxItems(),
+		MaxQuantityPerItem: CartMaxQuantity(),
+		GuestCartTTL:       GuestCartTTL(),
+		MaxOrderTotal:      OrderMaxTotal(),
+		BalanceLockTTL:     BalanceLockTTL(),
+		PaymentMethods:     make(map[string]PaymentMethod),
+
+		BalanceLockRetries:      BalanceLockRetries(),
+		BalanceLockRetryBackoff: BalanceLockRetryBackoff(),
+		BalanceLockMaxBackoff:   BalanceLockMaxBackoff(),
+
+		TransferLimit: TransferLimit{
+			PerTransaction: TransferMaxAmount(),
+			Daily:          TransferDailyLimit(),
+			Monthly:        TransferMonthlyLimit(),
+		},
+		RoleTransferLimits:      make(map[string]TransferLimit),
+		TransferOTPThreshold:    TransferOTPThreshold(),
+		TransferReviewThreshold: TransferReviewThreshold(),
+		TransferInquiryTTL:      TransferInquiryTTL(),
+
+		ScheduledTransferMaxRetries: ScheduledTransferMaxRetries(),
+		ScheduledTransferRetryDelay: ScheduledTransferRetryDelay(),
+		ScheduledTransferBatchSize:  ScheduledTransferBatchSize(),
+
+		MoneyRequestTTL: MoneyRequestTTL(),
+
+		AdjustmentApprovalThreshold: AdjustmentApprovalThreshold(),
+
+		CartReminderAfter:     CartReminderAfter(),
+		CartReminderInterval:  CartReminderInterval(),
+		CartReminderMax:       CartReminderMax(),
+		CartReminderWindow:    CartReminderWindow(),
+		CartReminderBatchSize: CartReminderBatchSize(),
+	}
+
+	expiries, err := parseOverrides(PaymentExpiryOverrides())
+	if err != nil {
+		return nil, fmt.Errorf("PAYMENT_EXPIRY_OVERRIDES: %w", err)
+	}
+
+	for method, value := range expiries {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("PAYMENT_EXPIRY_OVERRIDES: %w", err)
+		}
+
+		rule := business.PaymentMethods[method]
+		rule.PaymentExpiry = duration
+		business.PaymentMethods[method] = rule
+	}
+
+	totals, err := parseOverrides(OrderMaxTotalOverrides())
+	if err != nil {
+		return nil, fmt.Errorf("ORDER_MAX_TOTAL_OVERRIDES: %w", err)
+	}
+
+	for method, value := range totals {
+		total, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ORDER_MAX_TOTAL_OVERRIDES: %w", err)
+		}
+
+		rule := business.PaymentMethods[method]
+		rule.MaxOrderTotal = total
+		business.PaymentMethods[method] = rule
+	}
+
+	transferOverrides := []struct {
+		env string
+		raw string
+		set func(limit *TransferLimit, value float64)
+	}{
+		{"TRANSFER_MAX_AMOUNT_OVERRIDES", TransferMaxAmountOverrides(), func(limit *TransferLimit, value float64) { limit.PerTransaction = value }},
+		{"TRANSFER_DAILY_LIMIT_OVERRIDES", TransferDailyLimitOverrides(), func(limit *TransferLimit, value float64) { limit.Daily = value }},
+		{"TRANSFER_MONTHLY_LIMIT_OVERRIDES", TransferMonthlyLimitOverrides(), func(limit *TransferLimit, value float64) { limit.Monthly = value }},
+	}
+
+	for _, override := range transferOverrides {
+		limits, err := parseOverrides(override.raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", override.env, err)
+		}
+
+		for role, value := range limits {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", override.env, err)
+			}
+
+			limit := business.RoleTransferLimits[role]
+			override.set(&limit, amount)
+			business.RoleTransferLimits[role] = limit
+		}
+	}
+
+	return business, nil
+}
+
+// PaymentExpiryFor returns the payment window of the given payment method
+func (b *Business) PaymentExpiryFor(method string) time.Duration {
+	if rule, ok := b.PaymentMethods[method]; ok && rule.PaymentExpiry > 0 {
+		return rule.PaymentExpiry
+	}
+
+	return b.PaymentExpiry
+}
+
+// MaxOrderTotalFor returns the maximum order total of the given payment method, zero means unlimited
+func (b *Business) MaxOrderTotalFor(method string) float64 {
+	if rule, ok := b.PaymentMethods[method]; ok && rule.MaxOrderTotal > 0 {
+		return rule.MaxOrderTotal
+	}
+
+	return b.MaxOrderTotal
+}
+
+// TransferLimitFor returns the transfer caps of the given role, each cap falls
+// back to the global value when the role does not override it
+func (b *Business) TransferLimitFor(role string) TransferLimit {
+	limit := b.TransferLimit
+
+	if rule, ok := b.RoleTransferLimits[role]; ok {
+		if rule.PerTransaction > 0 {
+			limit.PerTransaction = rule.PerTransaction
+		}
+		if rule.Daily > 0 {
+			limit.Daily = rule.Daily
+		}
+		if rule.Monthly > 0 {
+			limit.Monthly = rule.Monthly
+		}
+	}
+
+	return limit
+}
+
+// TransferVerificationFor returns the extra check a transfer of the given amount needs
+func (b *Business) TransferVerificationFor(amount float64) string {
+	if b.TransferReviewThreshold > 0 && amount >= b.TransferReviewThreshold {
+		return "review"
+	}
+	if b.TransferOTPThreshold > 0 && amount >= b.TransferOTPThreshold {
+		return "otp"
+	}
+
+	return "none"
+}
+
+// parseOverrides parses a "key=value,key=value" list into a map
+func parseOverrides(raw string) (map[string]string, error) {
+	overrides := make(map[string]string)
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		method, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(method) == "" {
+			return nil, fmt.Errorf("invalid override %q", pair)
+		}
+
+		overrides[strings.TrimSpace(method)] = strings.TrimSpace(value)
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Cart storage backends selectable with CART_BACKEND
+const (
+	CartBackendPostgres = "postgres"
+	CartBackendRedis    = "redis"
+)
+
+// Cart storage related configuration
+func CartBackend() string {
+	return viper.GetString("CART_BACKEND")
+}
+
+// CartRedisTTL is how long a user cart stays in Redis after its last change,
+// 0 keeps it until checkout
+func CartRedisTTL() time.Duration {
+	return viper.GetDuration("CART_REDIS_TTL")
+}
+
+func CartWriteBehind() bool {
+	return viper.GetBool("CART_WRITE_BEHIND")
+}
+
+func CartWriteBehindInterval() time.Duration {
+	return viper.GetDuration("CART_WRITE_BEHIND_INTERVAL")
+}
+
+func CartWriteBehindBatch() int {
+	return viper.GetInt("CART_WRITE_BEHIND_BATCH")
+}
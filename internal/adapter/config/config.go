@@ -3,16 +3,18 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type (
 	Config struct {
-		App   *App
-		Token *Token
-		DB    *DB
-		HTTP  *HTTP
+		App      *App
+		Token    *Token
+		DB       *DB
+		HTTP     *HTTP
+		Business *Business
 	}
 
 	App struct {
@@ -29,6 +31,14 @@ type (
 		Password string
 	}
 
+	SMTP struct {
+		Host     string
+		Port     string
+		Username string
+		Password string
+		From     string
+	}
+
 	DB struct {
 		Connection string
 		Host       string
@@ -48,6 +58,58 @@ type (
 		MaxHeaderBytes string
 	}
 
+	Business struct {
+		PaymentExpiry      time.Duration
+		MaxCartItems       int
+		MaxQuantityPerItem int
+		GuestCartTTL       time.Duration
+		MaxOrderTotal      float64
+		BalanceLockTTL     time.Duration
+		PaymentMethods     map[string]PaymentMethod
+
+		// a busy wallet lock is retried with a doubling backoff
+		BalanceLockRetries      int
+		BalanceLockRetryBackoff time.Duration
+		BalanceLockMaxBackoff   time.Duration
+
+		TransferLimit           TransferLimit
+		RoleTransferLimits      map[string]TransferLimit
+		TransferOTPThreshold    float64
+		TransferReviewThreshold float64
+		TransferInquiryTTL      time.Duration
+
+		// scheduled transfers retry this many times when the wallet is locked
+		ScheduledTransferMaxRetries int
+		ScheduledTransferRetryDelay time.Duration
+		ScheduledTransferBatchSize  int
+
+		// default lifetime of a money request when the requester sets no expiry
+		MoneyRequestTTL time.Duration
+
+		// wallet adjustments at or above this amount need a second admin, 0 disables
+		AdjustmentApprovalThreshold float64
+
+		// a user's cart idle for CartReminderAfter gets up to CartReminderMax
+		// reminders CartReminderInterval apart, 0 turns reminders off. An order
+		// within CartReminderWindow of a reminder counts as a conversion
+		CartReminderAfter     time.Duration
+		CartReminderInterval  time.Duration
+		CartReminderMax       int
+		CartReminderWindow    time.Duration
+		CartReminderBatchSize int
+	}
+
+	PaymentMethod struct {
+		PaymentExpiry time.Duration
+		MaxOrderTotal float64
+	}
+
+	TransferLimit struct {
+		PerTransaction float64
+		Daily          float64
+		Monthly        float64
+	}
+
 	GCS struct {
 		Credential string
 		BucketName string
@@ -93,16 +155,23 @@ func New() (*Config, error) {
 		AllowedOrigins: HTTPAllowedOrigins(),
 	}
 
+	business, err := NewBusiness()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		app,
 		token,
 		db,
 		http,
+		business,
 	}, nil
 }
 
 func LoadConfig() {
 	viper.AutomaticEnv()
+	setDefaults()
 
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
@@ -117,3 +186,46 @@ func LoadConfig() {
 		log.Println("Using .env file:", viper.ConfigFileUsed())
 	}
 }
+
+// setDefaults registers fallback values for settings that are optional in .env
+func setDefaults() {
+	viper.SetDefault("PAYMENT_EXPIRY", "10m")
+	viper.SetDefault("CART_MAX_ITEMS", 50)
+	viper.SetDefault("CART_MAX_QUANTITY", 100)
+	viper.SetDefault("GUEST_CART_TTL", "168h")
+	viper.SetDefault("CART_BACKEND", CartBackendPostgres)
+	viper.SetDefault("CART_REDIS_TTL", "720h")
+	viper.SetDefault("CART_WRITE_BEHIND", false)
+	viper.SetDefault("CART_WRITE_BEHIND_INTERVAL", "30s")
+	viper.SetDefault("CART_WRITE_BEHIND_BATCH", 100)
+	viper.SetDefault("ORDER_MAX_TOTAL", 0)
+	viper.SetDefault("BALANCE_LOCK_TTL", "5s")
+	viper.SetDefault("LOCKER_BACKEND", LockerRedisPostgres)
+	viper.SetDefault("LOCKER_POSTGRES_MAX_CONNS", 20)
+	viper.SetDefault("LOCKER_POSTGRES_ACQUIRE_TIMEOUT", "1s")
+	viper.SetDefault("BALANCE_LOCK_RETRIES", 5)
+	viper.SetDefault("BALANCE_LOCK_RETRY_BACKOFF", "50ms")
+	viper.SetDefault("BALANCE_LOCK_MAX_BACKOFF", "1s")
+	viper.SetDefault("TRANSFER_MAX_AMOUNT", 0)
+	viper.SetDefault("TRANSFER_DAILY_LIMIT", 0)
+	viper.SetDefault("TRANSFER_MONTHLY_LIMIT", 0)
+	viper.SetDefault("TRANSFER_OTP_THRESHOLD", 0)
+	viper.SetDefault("TRANSFER_REVIEW_THRESHOLD", 0)
+	viper.SetDefault("TRANSFER_INQUIRY_TTL", "5m")
+	viper.SetDefault("SCHEDULED_TRANSFER_MAX_RETRIES", 3)
+	viper.SetDefault("SCHEDULED_TRANSFER_RETRY_DELAY", "500ms")
+	viper.SetDefault("SCHEDULED_TRANSFER_BATCH_SIZE", 100)
+	viper.SetDefault("MONEY_REQUEST_TTL", "168h")
+	viper.SetDefault("ADJUSTMENT_APPROVAL_THRESHOLD", 0)
+	viper.SetDefault("CART_REMINDER_AFTER", "24h")
+	viper.SetDefault("CART_REMINDER_INTERVAL", "48h")
+	viper.SetDefault("CART_REMINDER_MAX", 2)
+	viper.SetDefault("CART_REMINDER_WINDOW", "72h")
+	viper.SetDefault("CART_REMINDER_BATCH_SIZE", 100)
+	viper.SetDefault("PUBLIC_URL", "http://localhost:8080")
+	viper.SetDefault("STORAGE_BACKEND", StorageBackendGCS)
+	viper.SetDefault("LOCAL_STORAGE_DIR", "./uploads")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("PRODUCT_IMAGE_MAX_SIZE", 5<<20)
+	viper.SetDefault("PRODUCT_IMAGE_UPLOAD_URL_TTL", "15m")
+}
@@ -0,0 +1,24 @@
+package config
+
+import "github.com/spf13/viper"
+
+// SMTP related configuration
+func SMTPHost() string {
+	return viper.GetString("SMTP_HOST")
+}
+
+func SMTPPort() string {
+	return viper.GetString("SMTP_PORT")
+}
+
+func SMTPUsername() string {
+	return viper.GetString("SMTP_USERNAME")
+}
+
+func SMTPPassword() string {
+	return viper.GetString("SMTP_PASSWORD")
+}
+
+func SMTPFrom() string {
+	return viper.GetString("SMTP_FROM")
+}
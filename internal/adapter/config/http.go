@@ -14,3 +14,8 @@ func HTTPURL() string {
 func HTTPAllowedOrigins() string {
 	return viper.GetString("HTTP_ALLOWED_ORIGINS")
 }
+
+// PublicURL is the address users reach the API on, used for links in emails
+func PublicURL() string {
+	return viper.GetString("PUBLIC_URL")
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Lock backends selectable with LOCKER_BACKEND
+const (
+	LockerRedis         = "redis"
+	LockerRedisPostgres = "redis_postgres"
+	LockerPostgres      = "postgres"
+)
+
+// Distributed lock related configuration
+func LockerBackend() string {
+	return viper.GetString("LOCKER_BACKEND")
+}
+
+// LockerPostgresMaxConns caps the connections held by Postgres advisory locks,
+// every held lock pins one of them
+func LockerPostgresMaxConns() int32 {
+	return viper.GetInt32("LOCKER_POSTGRES_MAX_CONNS")
+}
+
+// LockerPostgresAcquireTimeout bounds the wait for a free lock connection, a
+// timed out wait counts as the lock being held
+func LockerPostgresAcquireTimeout() time.Duration {
+	return viper.GetDuration("LOCKER_POSTGRES_ACQUIRE_TIMEOUT")
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// File storage backends selectable with STORAGE_BACKEND
+const (
+	StorageBackendGCS   = "gcs"
+	StorageBackendLocal = "local"
+	StorageBackendS3    = "s3"
+)
+
+// File storage related configuration
+func StorageBackend() string {
+	return viper.GetString("STORAGE_BACKEND")
+}
+
+func GCSCredential() string {
+	return viper.GetString("GCS_CREDENTIAL")
+}
+
+func GCSBucketName() string {
+	return viper.GetString("GCS_BUCKET_NAME")
+}
+
+// LocalStorageDir is the directory the local storage keeps its files in
+func LocalStorageDir() string {
+	return viper.GetString("LOCAL_STORAGE_DIR")
+}
+
+// LocalStorageURL is the address the files of the local storage are served
+// on, the /files routes of the API under PUBLIC_URL by default
+func LocalStorageURL() string {
+	if url := viper.GetString("LOCAL_STORAGE_URL"); url != "" {
+		return url
+	}
+
+	return strings.TrimRight(PublicURL(), "/") + "/files"
+}
+
+// LocalStorageSecret signs the upload URLs of the local storage
+func LocalStorageSecret() string {
+	return viper.GetString("LOCAL_STORAGE_SECRET")
+}
+
+func S3Endpoint() string {
+	return viper.GetString("S3_ENDPOINT")
+}
+
+func S3Region() string {
+	return viper.GetString("S3_REGION")
+}
+
+func S3Bucket() string {
+	return viper.GetString("S3_BUCKET")
+}
+
+func S3AccessKey() string {
+	return viper.GetString("S3_ACCESS_KEY")
+}
+
+func S3SecretKey() string {
+	return viper.GetString("S3_SECRET_KEY")
+}
+
+// ProductImageMaxSize is the largest product image accepted, in bytes
+func ProductImageMaxSize() int64 {
+	return viper.GetInt64("PRODUCT_IMAGE_MAX_SIZE")
+}
+
+// ProductImageUploadURLTTL is how long a presigned image upload URL stays
+// valid
+func ProductImageUploadURLTTL() time.Duration {
+	return viper.GetDuration("PRODUCT_IMAGE_UPLOAD_URL_TTL")
+}
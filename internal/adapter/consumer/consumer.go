@@ -28,6 +28,11 @@ type Consumer interface {
 
 	UpdateStatusOrderConsumer()
 	ExpiredPaymentConsumer()
+	ScheduledTransferConsumer()
+	GuestCartCleanupConsumer()
+	CartWriteBehindConsumer()
+	CartReminderConsumer()
+	ProductImageDerivativesConsumer()
 }
 
 func NewConsumer(b *bootstrap.Bootstrap) Consumer {
@@ -44,6 +49,14 @@ func (c *consumer) Init() {
 			IsBindingExchange: false,
 			QueueName:         consts.QueueUpdateStock,
 		},
+		{
+			IsBindingExchange: false,
+			QueueName:         consts.QueuePriceDrop,
+		},
+		{
+			IsBindingExchange: false,
+			QueueName:         consts.QueueProductImageDerivatives,
+		},
 		{
 			Exchange: rabbitmq.RabbitMQExchange{
 				Name: consts.ExchangeUpdateStock,
@@ -107,3 +120,33 @@ func (c *consumer) ExpiredPaymentConsumer() {
 
 	worker.NewPaymentWorker(c.bootstrap).Run()
 }
+
+func (c *consumer) ScheduledTransferConsumer() {
+	c.log.Info("Consumer registered...", zap.String("job_name", "scheduled_transfer"))
+
+	go worker.NewScheduledTransferWorker(c.bootstrap).Run()
+}
+
+func (c *consumer) GuestCartCleanupConsumer() {
+	c.log.Info("Consumer registered...", zap.String("job_name", "guest_cart_cleanup"))
+
+	go worker.NewGuestCartWorker(c.bootstrap).Run()
+}
+
+func (c *consumer) CartWriteBehindConsumer() {
+	c.log.Info("Consumer registered...", zap.String("job_name", "cart_write_behind"))
+
+	go worker.NewCartWriteBehindWorker(c.bootstrap).Run()
+}
+
+func (c *consumer) CartReminderConsumer() {
+	c.log.Info("Consumer registered...", zap.String("job_name", "cart_reminder"))
+
+	go worker.NewCartReminderWorker(c.bootstrap).Run()
+}
+
+func (c *consumer) ProductImageDerivativesConsumer() {
+	c.log.Info("Consumer registered...", zap.String("job_name", "product_image_derivatives"))
+
+	go worker.NewProductImageWorker(c.bootstrap).Run()
+}
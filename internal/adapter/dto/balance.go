@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
 type DepositRequest struct {
 	Amount float64 `json:"amount" binding:"required,gt=0"`
 }
@@ -8,9 +14,43 @@ type DepositResponse struct {
 	Balance float64 `json:"balance"`
 }
 
+type TransferInquiryRequest struct {
+	RecipientID    uint64  `json:"recipient_id" binding:"required_without_all=RecipientEmail RecipientPhone"`
+	RecipientEmail string  `json:"recipient_email" binding:"omitempty,email" example:"test@example.com"`
+	RecipientPhone string  `json:"recipient_phone" binding:"omitempty,e164" example:"+6281234567890"`
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+}
+
+type TransferInquiryResponse struct {
+	InquiryID    string                      `json:"inquiry_id"`
+	Recipient    TransferRecipientResponse   `json:"recipient"`
+	Amount       float64                     `json:"amount"`
+	Verification domain.TransferVerification `json:"verification" example:"none"`
+	ExpiresAt    time.Time                   `json:"expires_at"`
+}
+
+type TransferRecipientResponse struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name" example:"J*** D**"`
+}
+
 type TransferRequest struct {
-	RecipientID uint64  `json:"recipient_id" binding:"required,gt=0"`
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	InquiryID string `json:"inquiry_id" binding:"required,uuid"`
+	OTP       string `json:"otp" binding:"omitempty,len=6,numeric"`
+}
+
+type TransferConfirmationResponse struct {
+	Status   string                 `json:"status" example:"completed"`
+	Transfer *TransferResponse      `json:"transfer,omitempty"`
+	Review   *domain.TransferReview `json:"review,omitempty"`
+}
+
+type ListTransferReviewRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected failed"`
+}
+
+type RejectTransferReviewRequest struct {
+	Note string `json:"note" binding:"required,max=500"`
 }
 
 type TransferResponse struct {
@@ -24,12 +64,71 @@ type TransferResponse struct {
 	} `json:"to"`
 }
 
-type WithdrawRequest struct {
-	Amount float64 `json:"amount" binding:"required,gt=0"`
-	// Bank    string  `json:"bank" binding:"required"`
-	// Account string  `json:"account" binding:"required"`
+type RefundRequest struct {
+	UserID  uint64  `json:"user_id" binding:"required,gt=0"`
+	OrderID int     `json:"order_id" binding:"required,gt=0"`
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
 }
 
 type BalanceResponse struct {
-	Balance float64 `json:"balance"`
+	Balance     float64 `json:"balance"`
+	HeldBalance float64 `json:"held_balance"`
+}
+
+type ListBalanceTransactionRequest struct {
+	Type           string  `form:"type" binding:"omitempty,oneof=opening_balance deposit withdrawal withdrawal_reversal transfer payment refund hold hold_release adjustment"`
+	From           string  `form:"from" binding:"omitempty,datetime=2006-01-02" example:"2025-01-01"`
+	To             string  `form:"to" binding:"omitempty,datetime=2006-01-02" example:"2025-01-31"`
+	MinAmount      float64 `form:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount      float64 `form:"max_amount" binding:"omitempty,gte=0"`
+	CounterpartyID uint64  `form:"counterparty_id"`
+	Cursor         string  `form:"cursor"`
+	Limit          uint64  `form:"limit" binding:"omitempty,max=100"`
+}
+
+type ExportBalanceTransactionRequest struct {
+	ListBalanceTransactionRequest
+	Format string `form:"format" binding:"required,oneof=csv"`
+}
+
+type BalanceTransactionResponse struct {
+	ID              uint64    `json:"id"`
+	TransactionType string    `json:"transaction_type" example:"transfer"`
+	Amount          float64   `json:"amount" example:"-15000"`
+	BalanceBefore   float64   `json:"balance_before" example:"50000"`
+	BalanceAfter    float64   `json:"balance_after" example:"35000"`
+	ReferenceID     uint64    `json:"reference_id,omitempty"`
+	CounterpartyID  uint64    `json:"counterparty_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ListBalanceTransactionResponse struct {
+	Items      []BalanceTransactionResponse `json:"items"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+}
+
+func NewTransferInquiryResponse(inquiry domain.TransferInquiry) TransferInquiryResponse {
+	return TransferInquiryResponse{
+		InquiryID: inquiry.ID,
+		Recipient: TransferRecipientResponse{
+			ID:   inquiry.RecipientID,
+			Name: inquiry.RecipientName,
+		},
+		Amount:       inquiry.Amount,
+		Verification: inquiry.Verification,
+		ExpiresAt:    inquiry.ExpiresAt,
+	}
+}
+
+func NewBalanceTransactionResponse(transaction domain.BalanceTransaction) BalanceTransactionResponse {
+	return BalanceTransactionResponse{
+		ID:              transaction.ID,
+		TransactionType: string(transaction.TransactionType),
+		Amount:          transaction.Amount,
+		BalanceBefore:   transaction.BalanceBefore,
+		BalanceAfter:    transaction.BalanceAfter,
+		ReferenceID:     transaction.ReferenceID,
+		CounterpartyID:  transaction.CounterpartyID,
+		CreatedAt:       transaction.CreatedAt,
+	}
 }
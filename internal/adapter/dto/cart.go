@@ -1,8 +1,11 @@
 package dto
 
+// AddCartRequest adds a product, VariantID is required for a product with
+// variants
 type AddCartRequest struct {
 	UserID    int `json:"user_id"`
 	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id"`
 	Quantity  int `json:"quantity"`
 }
 
@@ -11,13 +14,47 @@ type CartResponse struct {
 	TotalProducts int                `json:"total_products"`
 	TotalPrice    float64            `json:"total_price"`
 	Items         []CartItemResponse `json:"items"`
+	// Version changes whenever a quantity, price or availability in the cart
+	// does, checkout needs the version the user last saw
+	Version    string `json:"version" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	HasChanges bool   `json:"has_changes"`
 }
 
 type RemoveCartRequest struct {
 	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id"`
+}
+
+// cart item operations accepted by PATCH /carts/items
+const (
+	CartOperationUpsert = "upsert"
+	CartOperationRemove = "remove"
+)
+
+// CartItemOperation is one line of a bulk cart change. Upsert sets the
+// quantity of the product or its variant, adding it when it is not in the
+// cart yet
+type CartItemOperation struct {
+	Op        string `json:"op" example:"upsert"`
+	ProductID int    `json:"product_id" example:"12"`
+	VariantID int    `json:"variant_id" example:"0"`
+	Quantity  int    `json:"quantity" example:"2"`
+}
+
+type BulkCartRequest struct {
+	Operations []CartItemOperation `json:"operations" binding:"required,min=1"`
+}
+
+// CartLineError explains why the operation at Index was rejected
+type CartLineError struct {
+	Index     int    `json:"index"`
+	ProductID int    `json:"product_id"`
+	VariantID int    `json:"variant_id,omitempty"`
+	Message   string `json:"message"`
 }
 
 type UpdateCartRequest struct {
 	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id"`
 	Quantity  int `json:"quantity"`
 }
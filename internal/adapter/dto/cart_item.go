@@ -1,8 +1,13 @@
 package dto
 
+import "github.com/aldotp/ecommerce-go-api/internal/core/domain"
+
 type CartItemResponse struct {
-	Name      string  `json:"name"`
-	ProductID int     `json:"product_id"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
+	Name       string                `json:"name"`
+	ProductID  int                   `json:"product_id"`
+	VariantID  int                   `json:"variant_id,omitempty"`
+	Price      float64               `json:"price"`
+	AddedPrice float64               `json:"added_price"`
+	Quantity   int                   `json:"quantity"`
+	Status     domain.CartItemStatus `json:"status" example:"available" enums:"available,price_changed,out_of_stock,unavailable"`
 }
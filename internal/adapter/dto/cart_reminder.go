@@ -0,0 +1,13 @@
+package dto
+
+type CartReminderSettingsRequest struct {
+	Enabled *bool `json:"enabled" binding:"required" example:"false"`
+}
+
+type CartReminderStatsRequest struct {
+	Since string `form:"since" binding:"omitempty,datetime=2006-01-02" example:"2025-01-01"`
+}
+
+type UnsubscribeCartRemindersRequest struct {
+	Token string `form:"token" binding:"required"`
+}
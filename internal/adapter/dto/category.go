@@ -12,9 +12,9 @@ type GetCategoryRequest struct {
 	ID int `uri:"id" binding:"required"`
 }
 
+// ListCategoryRequest sorts on name or id
 type ListCategoryRequest struct {
-	Page     int    `form:"page"`
-	PageSize uint64 `form:"pageSize"`
+	PageRequest
 }
 
 func NewCategoryResponse(category *domain.Category) CategoryResponse {
@@ -2,6 +2,8 @@ package dto
 
 type CheckoutRequest struct {
 	PaymentMethod string `json:"payment_method"`
+	// version of the cart the user reviewed, the If-Match header works too
+	CartVersion string `json:"cart_version" example:"9f86d081884c7d659a2feaa0c55ad015"`
 }
 
 type CheckoutResponse struct {
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// MoneyRequestRequest asks other users for money, either with an explicit
+// amount per payer or by splitting SplitAmount evenly between SplitWith and,
+// when IncludeSelf is set, the requester
+type MoneyRequestRequest struct {
+	Note        string                   `json:"note" binding:"max=255" example:"Dinner on Friday"`
+	Payers      []MoneyRequestPayerInput `json:"payers" binding:"required_without=SplitWith,omitempty,max=20,dive"`
+	SplitAmount float64                  `json:"split_amount" binding:"required_with=SplitWith,omitempty,gt=0" example:"300000"`
+	SplitWith   []uint64                 `json:"split_with" binding:"omitempty,max=20,dive,gt=0"`
+	IncludeSelf bool                     `json:"include_self"`
+	ExpiresAt   *time.Time               `json:"expires_at" example:"2026-11-01T00:00:00Z"`
+}
+
+type MoneyRequestPayerInput struct {
+	UserID uint64  `json:"user_id" binding:"required,gt=0"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// PayMoneyRequestRequest confirms paying a share that needs a verification
+// code with the inquiry_id and otp of a transfer inquiry to the requester for
+// the share amount, smaller shares send no body
+type PayMoneyRequestRequest struct {
+	InquiryID string `json:"inquiry_id" binding:"omitempty,uuid"`
+	OTP       string `json:"otp" binding:"omitempty,len=6,numeric"`
+}
+
+type ListMoneyRequestRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending completed cancelled expired"`
+}
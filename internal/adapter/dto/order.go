@@ -3,3 +3,9 @@ package dto
 type OrderRequest struct {
 	ID int `uri:"id" binding:"required"`
 }
+
+// ListOrderRequest sorts on created_at, total_price, status or id
+type ListOrderRequest struct {
+	PageRequest
+	Status string `form:"status" binding:"omitempty,oneof=pending paid shipped delivered cancelled"`
+}
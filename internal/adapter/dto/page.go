@@ -0,0 +1,21 @@
+package dto
+
+// PageRequest holds the paging query parameters shared by the listings. Page
+// counts from 1 and is ignored when a cursor is sent. Sort is a field name,
+// prefixed with "-" for descending order
+type PageRequest struct {
+	Page     uint64 `form:"page" binding:"omitempty,min=1" example:"1"`
+	PageSize uint64 `form:"page_size" binding:"omitempty,min=1,max=100" example:"20"`
+	Cursor   string `form:"cursor"`
+	Sort     string `form:"sort" example:"-created_at"`
+}
+
+// PageResponse is the envelope of a listing. NextCursor is empty on the last
+// page, passing it as cursor continues right after this page
+type PageResponse[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       uint64 `json:"page,omitempty"`
+	PageSize   uint64 `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
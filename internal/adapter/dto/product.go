@@ -12,46 +12,160 @@ type ProductRequest struct {
 	Price       float64 `json:"price" binding:"required"`
 	Stock       int     `json:"stock" binding:"required"`
 	CategoryID  int     `json:"category_id" binding:"required"`
+	// Attributes are free-form filters such as {"color": "red"}
+	Attributes map[string]string `json:"attributes"`
 }
 
 type GetProductRequest struct {
 	ID int `uri:"id" binding:"required"`
 }
 
+// ListProductRequest sorts on name, price, stock, created_at or id
 type ListProductRequest struct {
-	Page     int    `form:"page"`
-	PageSize uint64 `form:"pageSize"`
-	Search   string `form:"search"`
-}
-
-func NewProductResponse(user *domain.Product) ProductResponse {
-	return ProductResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Price:     user.Price,
-		Stock:     user.Stock,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	PageRequest
+	Search    string `form:"search"`
+	ImageSize string `form:"image_size" binding:"omitempty,oneof=thumbnail medium large original" example:"thumbnail"`
+}
+
+// ImageSizeRequest picks the size of the image URLs in product responses,
+// thumbnail, medium, large or the original upload by default
+type ImageSizeRequest struct {
+	ImageSize string `form:"image_size" binding:"omitempty,oneof=thumbnail medium large original" example:"thumbnail"`
+}
+
+// NewProductResponse sets the image URLs to the given size where it has been
+// generated, the original image otherwise
+func NewProductResponse(user *domain.Product, size domain.ImageSize) ProductResponse {
+	response := ProductResponse{
+		ID:          user.ID,
+		Name:        user.Name,
+		Description: user.Description,
+		Price:       user.Price,
+		Stock:       user.Stock,
+		CategoryID:  user.CategoryID,
+		Attributes:  user.Attributes,
+		Images:      make([]ProductImageResponse, 0, len(user.Images)),
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	}
+
+	for _, image := range user.Images {
+		if image.IsPrimary {
+			response.ImageURL = image.SizedURL(size)
+		}
+		response.Images = append(response.Images, NewProductImageResponse(image, size))
+	}
+
+	return response
 }
 
-func NewProductsResponse(products []domain.Product) []ProductResponse {
+func NewProductsResponse(products []domain.Product, size domain.ImageSize) []ProductResponse {
 	var res []ProductResponse
 	for _, product := range products {
-		res = append(res, NewProductResponse(&product))
+		res = append(res, NewProductResponse(&product, size))
 	}
 	return res
 }
 
+// ProductResponse is a product with its gallery in order, ImageURL is the
+// primary image
 type ProductResponse struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Price     float64   `json:"price"`
-	Stock     int       `json:"stock"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int                    `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Price       float64                `json:"price"`
+	Stock       int                    `json:"stock"`
+	CategoryID  int                    `json:"category_id"`
+	Attributes  map[string]string      `json:"attributes,omitempty"`
+	ImageURL    string                 `json:"image_url,omitempty"`
+	Images      []ProductImageResponse `json:"images"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
 }
 
 type ParamProductRequest struct {
 	ID int `uri:"id" binding:"required"`
 }
+
+// SearchProductRequest searches products by q. Sort is relevance, name,
+// price, stock, created_at or id, prefixed with "-" for descending order.
+// Attributes come from attr[key]=value query parameters
+type SearchProductRequest struct {
+	Query      string            `form:"q" example:"running shoes"`
+	CategoryID int               `form:"category_id" binding:"omitempty,min=1"`
+	MinPrice   *float64          `form:"min_price" binding:"omitempty,min=0"`
+	MaxPrice   *float64          `form:"max_price" binding:"omitempty,min=0"`
+	InStock    bool              `form:"in_stock"`
+	Sort       string            `form:"sort" example:"-price"`
+	Page       uint64            `form:"page" binding:"omitempty,min=1" example:"1"`
+	PageSize   uint64            `form:"page_size" binding:"omitempty,min=1,max=100" example:"20"`
+	Attributes map[string]string `form:"-"`
+	ImageSize  string            `form:"image_size" binding:"omitempty,oneof=thumbnail medium large original" example:"thumbnail"`
+}
+
+// SearchProductResponse is one page of search results. Fuzzy is set when
+// nothing matched exactly and the results are similar names instead
+type SearchProductResponse struct {
+	Items    []ProductResponse    `json:"items"`
+	Total    int64                `json:"total"`
+	Page     uint64               `json:"page"`
+	PageSize uint64               `json:"page_size"`
+	Fuzzy    bool                 `json:"fuzzy"`
+	Facets   SearchFacetsResponse `json:"facets"`
+}
+
+type SearchFacetsResponse struct {
+	Categories []CategoryFacetResponse `json:"categories"`
+	Prices     []PriceFacetResponse    `json:"prices"`
+}
+
+type CategoryFacetResponse struct {
+	CategoryID int    `json:"category_id"`
+	Name       string `json:"name"`
+	Count      int64  `json:"count"`
+}
+
+// PriceFacetResponse counts the products priced from Min up to but not
+// including Max, the last bucket has no Max
+type PriceFacetResponse struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+func NewSearchProductResponse(result *domain.ProductSearchResult, page, pageSize uint64, size domain.ImageSize) *SearchProductResponse {
+	response := &SearchProductResponse{
+		Items:    make([]ProductResponse, 0, len(result.Items)),
+		Total:    result.Total,
+		Page:     page,
+		PageSize: pageSize,
+		Fuzzy:    result.Fuzzy,
+		Facets: SearchFacetsResponse{
+			Categories: make([]CategoryFacetResponse, 0, len(result.Categories)),
+			Prices:     make([]PriceFacetResponse, 0, len(result.Prices)),
+		},
+	}
+
+	for _, product := range result.Items {
+		response.Items = append(response.Items, NewProductResponse(&product, size))
+	}
+
+	for _, facet := range result.Categories {
+		response.Facets.Categories = append(response.Facets.Categories, CategoryFacetResponse{
+			CategoryID: facet.CategoryID,
+			Name:       facet.Name,
+			Count:      facet.Count,
+		})
+	}
+
+	for _, facet := range result.Prices {
+		price := PriceFacetResponse{Min: facet.Min, Count: facet.Count}
+		if facet.Max > 0 {
+			max := facet.Max
+			price.Max = &max
+		}
+		response.Facets.Prices = append(response.Facets.Prices, price)
+	}
+
+	return response
+}
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type ProductImageParam struct {
+	ID      int `uri:"id" binding:"required,min=1"`
+	ImageID int `uri:"image_id" binding:"required,min=1"`
+}
+
+// ProductImageUploadURLRequest asks for a URL to upload an image straight to
+// the bucket, the type and size are checked again once it is confirmed
+type ProductImageUploadURLRequest struct {
+	ContentType string `json:"content_type" binding:"required" example:"image/jpeg"`
+	Size        int64  `json:"size" binding:"required,min=1" example:"204800"`
+}
+
+// ProductImageUploadURLResponse is where the client PUTs the image before
+// confirming ObjectKey
+type ProductImageUploadURLResponse struct {
+	ObjectKey string    `json:"object_key"`
+	UploadURL string    `json:"upload_url"`
+	Method    string    `json:"method"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ConfirmProductImageRequest struct {
+	ObjectKey string `json:"object_key" binding:"required" example:"products/12/0b6f5b9e-8a1c-4c53-9d43-1f0a3e8e2c11.jpg"`
+}
+
+// ReorderProductImagesRequest lists every image of the product in the new
+// gallery order
+type ReorderProductImagesRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required,min=1,dive,min=1" example:"3,1,2"`
+}
+
+// ProductImageResponse is an image in the requested size, Derivatives holds
+// the URL of every size generated so far. The sized copies are always JPEG,
+// whatever the format of the original
+type ProductImageResponse struct {
+	ID          int                         `json:"id"`
+	URL         string                      `json:"url"`
+	Position    int                         `json:"position"`
+	IsPrimary   bool                        `json:"is_primary"`
+	Derivatives map[domain.ImageSize]string `json:"derivatives,omitempty"` // JPEG copies by size
+}
+
+func NewProductImageResponse(image domain.ProductImage, size domain.ImageSize) ProductImageResponse {
+	response := ProductImageResponse{
+		ID:        image.ID,
+		URL:       image.SizedURL(size),
+		Position:  image.Position,
+		IsPrimary: image.IsPrimary,
+	}
+
+	for name, derivative := range image.Derivatives {
+		if response.Derivatives == nil {
+			response.Derivatives = make(map[domain.ImageSize]string, len(image.Derivatives))
+		}
+		response.Derivatives[name] = derivative.URL
+	}
+
+	return response
+}
+
+// ProductImageUploadedEvent is published once an image joins a gallery, the
+// product_image_derivatives consumer then makes its resized copies
+type ProductImageUploadedEvent struct {
+	ImageID    int       `json:"image_id"`
+	ProductID  int       `json:"product_id"`
+	ObjectKey  string    `json:"object_key"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
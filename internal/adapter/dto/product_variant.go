@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type ProductOptionRequest struct {
+	Name     string   `json:"name" binding:"required,max=50" example:"size"`
+	Values   []string `json:"values" binding:"omitempty,dive,required" example:"S,M,L"`
+	Position int      `json:"position" binding:"omitempty,min=0" example:"1"`
+}
+
+type ProductOptionParam struct {
+	ID       int `uri:"id" binding:"required,min=1"`
+	OptionID int `uri:"option_id" binding:"required,min=1"`
+}
+
+// ProductVariantRequest creates or replaces a variant. Options needs one
+// value for every option of the product, a missing price sells the variant
+// at the product price
+type ProductVariantRequest struct {
+	SKU     string            `json:"sku" binding:"required,max=64" example:"TSHIRT-RED-M"`
+	Price   *float64          `json:"price" binding:"omitempty,gt=0" example:"129000"`
+	Stock   int               `json:"stock" binding:"min=0" example:"10"`
+	Barcode string            `json:"barcode" binding:"omitempty,max=64" example:"8991234567890"`
+	Options map[string]string `json:"options"`
+}
+
+type ProductVariantParam struct {
+	ID        int `uri:"id" binding:"required,min=1"`
+	VariantID int `uri:"variant_id" binding:"required,min=1"`
+}
+
+// ProductVariantResponse shows the price the variant sells at, Overrides
+// tells whether it differs from the product price on purpose
+type ProductVariantResponse struct {
+	ID        int               `json:"id"`
+	ProductID int               `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Title     string            `json:"title"`
+	Price     float64           `json:"price"`
+	Overrides bool              `json:"overrides_price"`
+	Stock     int               `json:"stock"`
+	Barcode   string            `json:"barcode,omitempty"`
+	Options   map[string]string `json:"options"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func NewProductVariantResponse(variant *domain.ProductVariant, product *domain.Product) ProductVariantResponse {
+	return ProductVariantResponse{
+		ID:        variant.ID,
+		ProductID: variant.ProductID,
+		SKU:       variant.SKU,
+		Title:     variant.Title(),
+		Price:     variant.Apply(product).Price,
+		Overrides: variant.Price != nil,
+		Stock:     variant.Stock,
+		Barcode:   variant.Barcode,
+		Options:   variant.Options,
+		CreatedAt: variant.CreatedAt,
+		UpdatedAt: variant.UpdatedAt,
+	}
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+// ScheduledTransferRequest schedules a transfer, amounts that need a
+// verification code also carry the inquiry_id and otp of a transfer inquiry
+// for the same recipient and amount
+type ScheduledTransferRequest struct {
+	RecipientID uint64     `json:"recipient_id" binding:"required,gt=0"`
+	Amount      float64    `json:"amount" binding:"required,gt=0"`
+	Frequency   string     `json:"frequency" binding:"required,oneof=once weekly monthly" example:"monthly"`
+	StartAt     time.Time  `json:"start_at" binding:"required" example:"2026-11-01T09:00:00Z"`
+	EndAt       *time.Time `json:"end_at" example:"2027-11-01T09:00:00Z"`
+	InquiryID   string     `json:"inquiry_id" binding:"omitempty,uuid"`
+	OTP         string     `json:"otp" binding:"omitempty,len=6,numeric"`
+}
+
+// UpdateScheduledTransferRequest changes a schedule that has not closed yet,
+// a new start date restarts the cadence from that date. A new amount that
+// needs a verification code is confirmed like a new schedule
+type UpdateScheduledTransferRequest struct {
+	Amount    float64    `json:"amount" binding:"omitempty,gt=0"`
+	Frequency string     `json:"frequency" binding:"omitempty,oneof=once weekly monthly"`
+	StartAt   *time.Time `json:"start_at"`
+	EndAt     *time.Time `json:"end_at"`
+	Status    string     `json:"status" binding:"omitempty,oneof=active paused"`
+	InquiryID string     `json:"inquiry_id" binding:"omitempty,uuid"`
+	OTP       string     `json:"otp" binding:"omitempty,len=6,numeric"`
+}
+
+type ListScheduledTransferRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=active paused completed cancelled failed"`
+}
+
+type ScheduledTransferDetailResponse struct {
+	domain.ScheduledTransfer
+	Runs []domain.ScheduledTransferRun `json:"runs"`
+}
@@ -0,0 +1,66 @@
+package dto
+
+import "github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+
+type BusinessRulesResponse struct {
+	PaymentExpiry      string                               `json:"payment_expiry" example:"10m0s"`
+	MaxCartItems       int                                  `json:"max_cart_items" example:"50"`
+	MaxQuantityPerItem int                                  `json:"max_quantity_per_item" example:"100"`
+	MaxOrderTotal      float64                              `json:"max_order_total" example:"0"`
+	BalanceLockTTL     string                               `json:"balance_lock_ttl" example:"5s"`
+	PaymentMethods     map[string]PaymentMethodRuleResponse `json:"payment_methods"`
+
+	TransferLimits          map[string]TransferLimitResponse `json:"transfer_limits"`
+	TransferOTPThreshold    float64                          `json:"transfer_otp_threshold" example:"1000000"`
+	TransferReviewThreshold float64                          `json:"transfer_review_threshold" example:"10000000"`
+
+	AdjustmentApprovalThreshold float64 `json:"adjustment_approval_threshold" example:"1000000"`
+}
+
+type TransferLimitResponse struct {
+	PerTransaction float64 `json:"per_transaction" example:"5000000"`
+	Daily          float64 `json:"daily" example:"10000000"`
+	Monthly        float64 `json:"monthly" example:"50000000"`
+}
+
+type PaymentMethodRuleResponse struct {
+	PaymentExpiry string  `json:"payment_expiry" example:"15m0s"`
+	MaxOrderTotal float64 `json:"max_order_total" example:"5000000"`
+}
+
+// NewBusinessRulesResponse returns the effective business rules, resolving each payment method override
+func NewBusinessRulesResponse(rules *config.Business) BusinessRulesResponse {
+	methods := make(map[string]PaymentMethodRuleResponse, len(rules.PaymentMethods))
+	for method := range rules.PaymentMethods {
+		methods[method] = PaymentMethodRuleResponse{
+			PaymentExpiry: rules.PaymentExpiryFor(method).String(),
+			MaxOrderTotal: rules.MaxOrderTotalFor(method),
+		}
+	}
+
+	// transfer limits are resolved per role, zero means unlimited
+	limits := make(map[string]TransferLimitResponse)
+	for _, role := range []string{"customer", "admin"} {
+		limit := rules.TransferLimitFor(role)
+		limits[role] = TransferLimitResponse{
+			PerTransaction: limit.PerTransaction,
+			Daily:          limit.Daily,
+			Monthly:        limit.Monthly,
+		}
+	}
+
+	return BusinessRulesResponse{
+		PaymentExpiry:      rules.PaymentExpiry.String(),
+		MaxCartItems:       rules.MaxCartItems,
+		MaxQuantityPerItem: rules.MaxQuantityPerItem,
+		MaxOrderTotal:      rules.MaxOrderTotal,
+		BalanceLockTTL:     rules.BalanceLockTTL.String(),
+		PaymentMethods:     methods,
+
+		TransferLimits:          limits,
+		TransferOTPThreshold:    rules.TransferOTPThreshold,
+		TransferReviewThreshold: rules.TransferReviewThreshold,
+
+		AdjustmentApprovalThreshold: rules.AdjustmentApprovalThreshold,
+	}
+}
@@ -10,6 +10,7 @@ type UserResponse struct {
 	ID        uint64    `json:"id" example:"1"`
 	Name      string    `json:"name" example:"John Doe"`
 	Email     string    `json:"email" example:"test@example.com"`
+	Phone     string    `json:"phone,omitempty" example:"+6281234567890"`
 	CreatedAt time.Time `json:"created_at" example:"1970-01-01T00:00:00Z"`
 	UpdatedAt time.Time `json:"updated_at" example:"1970-01-01T00:00:00Z"`
 }
@@ -19,6 +20,7 @@ func NewUserResponse(user *domain.User) UserResponse {
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
+		Phone:     user.Phone,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
@@ -27,17 +29,22 @@ func NewUserResponse(user *domain.User) UserResponse {
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"test@example.com"`
 	Password string `json:"password" binding:"required,min=8" example:"12345678" minLength:"8"`
+	// guest cart to merge into the user's cart, the X-Cart-Token header or cart_token cookie work too
+	CartToken string `json:"cart_token,omitempty"`
 }
 
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required" example:"John Doe"`
 	Email    string `json:"email" binding:"required,email" example:"test@example.com"`
+	Phone    string `json:"phone" binding:"omitempty,e164" example:"+6281234567890"`
 	Password string `json:"password" binding:"required,min=8" example:"12345678"`
 }
 
+// ListUserRequest sorts on name, email, created_at or id. Limit is the former
+// name of page_size
 type ListUserRequest struct {
-	Page  uint64 `form:"page"`
-	Limit uint64 `form:"limit"`
+	PageRequest
+	Limit uint64 `form:"limit" binding:"omitempty,min=1,max=100"`
 }
 
 type GetUserRequest struct {
@@ -47,6 +54,7 @@ type GetUserRequest struct {
 type UpdateUserRequest struct {
 	Name     string          `json:"name" binding:"omitempty,required" example:"John Doe"`
 	Email    string          `json:"email" binding:"omitempty,required,email" example:"test@example.com"`
+	Phone    string          `json:"phone" binding:"omitempty,e164" example:"+6281234567890"`
 	Password string          `json:"password" binding:"omitempty,required,min=8" example:"12345678"`
 	Role     domain.UserRole `json:"role" binding:"omitempty,required,user_role" example:"admin"`
 }
@@ -63,6 +71,7 @@ type GetProfile struct {
 	ID        uint64    `json:"id" example:"1"`
 	Name      string    `json:"name" example:"John Doe"`
 	Email     string    `json:"email" example:"test@example.com"`
+	Phone     string    `json:"phone,omitempty" example:"+6281234567890"`
 	Role      string    `json:"role" example:"admin"`
 	CreatedAt time.Time `json:"created_at" example:"1970-01-01T00:00:00Z"`
 	UpdatedAt time.Time `json:"updated_at" example:"1970-01-01T00:00:00Z"`
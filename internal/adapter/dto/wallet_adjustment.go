@@ -0,0 +1,23 @@
+package dto
+
+type WalletAdjustmentRequest struct {
+	UserID     uint64  `json:"user_id" binding:"required,gt=0"`
+	Direction  string  `json:"direction" binding:"required,oneof=credit debit" example:"credit"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	ReasonCode string  `json:"reason_code" binding:"required,oneof=goodwill correction compensation chargeback other" example:"goodwill"`
+	Note       string  `json:"note" binding:"required,max=1000" example:"Late delivery on order #1024"`
+}
+
+type ListWalletAdjustmentRequest struct {
+	UserID      uint64 `form:"user_id"`
+	RequestedBy uint64 `form:"requested_by"`
+	Direction   string `form:"direction" binding:"omitempty,oneof=credit debit"`
+	ReasonCode  string `form:"reason_code" binding:"omitempty,oneof=goodwill correction compensation chargeback other"`
+	Status      string `form:"status" binding:"omitempty,oneof=pending applied rejected"`
+	From        string `form:"from" binding:"omitempty,datetime=2006-01-02" example:"2025-01-01"`
+	To          string `form:"to" binding:"omitempty,datetime=2006-01-02" example:"2025-01-31"`
+}
+
+type RejectWalletAdjustmentRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
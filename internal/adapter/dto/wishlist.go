@@ -0,0 +1,73 @@
+package dto
+
+import "time"
+
+type WishlistRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"Birthday ideas"`
+}
+
+type WishlistItemRequest struct {
+	ProductID int `json:"product_id" binding:"required" example:"12"`
+}
+
+type WishlistParam struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type WishlistItemParam struct {
+	ID        int `uri:"id" binding:"required"`
+	ProductID int `uri:"product_id" binding:"required"`
+}
+
+type SharedWishlistParam struct {
+	Token string `uri:"token" binding:"required"`
+}
+
+// MoveToCartRequest moves a saved product into the cart, one unit when the
+// quantity is left out. VariantID picks the variant of a product with variants
+type MoveToCartRequest struct {
+	VariantID int `json:"variant_id" example:"0"`
+	Quantity  int `json:"quantity" binding:"omitempty,min=1" example:"1"`
+}
+
+// SaveForLaterRequest moves a cart line into a list, the default list when
+// WishlistID is left out. VariantID names the line of a product with variants
+type SaveForLaterRequest struct {
+	ProductID  int `json:"product_id" binding:"required" example:"12"`
+	VariantID  int `json:"variant_id" example:"0"`
+	WishlistID int `json:"wishlist_id" example:"3"`
+}
+
+// WishlistResponse is a list with its products at their current price. A
+// shared list leaves out its ID and share token
+type WishlistResponse struct {
+	ID         int                    `json:"id,omitempty"`
+	Name       string                 `json:"name"`
+	ShareToken string                 `json:"share_token,omitempty"`
+	Items      []WishlistItemResponse `json:"items"`
+	TotalItems int                    `json:"total_items"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+// WishlistItemResponse compares a saved product with its price when it was
+// saved
+type WishlistItemResponse struct {
+	ProductID    int       `json:"product_id"`
+	Name         string    `json:"name"`
+	Price        float64   `json:"price"`
+	SavedPrice   float64   `json:"saved_price"`
+	PriceDropped bool      `json:"price_dropped"`
+	InStock      bool      `json:"in_stock"`
+	SavedAt      time.Time `json:"saved_at"`
+}
+
+// PriceDropEvent is published when the price of a product saved in a
+// wishlist goes down, UserIDs are the users who saved it
+type PriceDropEvent struct {
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	OldPrice    float64   `json:"old_price"`
+	NewPrice    float64   `json:"new_price"`
+	UserIDs     []int     `json:"user_ids"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
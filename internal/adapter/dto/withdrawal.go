@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+)
+
+type PayoutDestinationRequest struct {
+	BankName      string `json:"bank_name" binding:"required,max=100" example:"BCA"`
+	AccountNumber string `json:"account_number" binding:"required,numeric,max=50" example:"1234567890"`
+	AccountName   string `json:"account_name" binding:"required,max=150" example:"John Doe"`
+}
+
+type PayoutDestinationResponse struct {
+	ID            uint64    `json:"id"`
+	BankName      string    `json:"bank_name"`
+	AccountNumber string    `json:"account_number" example:"******7890"`
+	AccountName   string    `json:"account_name"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type WithdrawRequest struct {
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	DestinationID uint64  `json:"destination_id" binding:"required,gt=0"`
+}
+
+type ListWithdrawalRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=requested approved processing paid rejected"`
+}
+
+type RejectWithdrawalRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type WithdrawalResponse struct {
+	ID                uint64                  `json:"id"`
+	UserID            uint64                  `json:"user_id"`
+	DestinationID     uint64                  `json:"destination_id,omitempty"`
+	BankName          string                  `json:"bank_name"`
+	AccountNumber     string                  `json:"account_number" example:"******7890"`
+	AccountName       string                  `json:"account_name"`
+	Amount            float64                 `json:"amount"`
+	Status            domain.WithdrawalStatus `json:"status"`
+	ProviderReference string                  `json:"provider_reference,omitempty"`
+	RejectionReason   string                  `json:"rejection_reason,omitempty"`
+	CreatedAt         time.Time               `json:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+}
+
+func NewPayoutDestinationResponse(destination domain.PayoutDestination) PayoutDestinationResponse {
+	return PayoutDestinationResponse{
+		ID:            destination.ID,
+		BankName:      destination.BankName,
+		AccountNumber: util.MaskString(destination.AccountNumber, 4),
+		AccountName:   destination.AccountName,
+		CreatedAt:     destination.CreatedAt,
+	}
+}
+
+func NewWithdrawalResponse(withdrawal domain.Withdrawal) WithdrawalResponse {
+	return WithdrawalResponse{
+		ID:                withdrawal.ID,
+		UserID:            withdrawal.UserID,
+		DestinationID:     withdrawal.DestinationID,
+		BankName:          withdrawal.BankName,
+		AccountNumber:     util.MaskString(withdrawal.AccountNumber, 4),
+		AccountName:       withdrawal.AccountName,
+		Amount:            withdrawal.Amount,
+		Status:            withdrawal.Status,
+		ProviderReference: withdrawal.ProviderReference,
+		RejectionReason:   withdrawal.RejectionReason,
+		CreatedAt:         withdrawal.CreatedAt,
+		UpdatedAt:         withdrawal.UpdatedAt,
+	}
+}
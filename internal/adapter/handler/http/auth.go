@@ -28,10 +28,11 @@ func NewAuthHandler(svc port.AuthService, logger *zap.Logger) *AuthHandler {
 // Login godoc
 //
 //	@Summary		User Login
-//	@Description	Authenticate user and return an access token
+//	@Description	Authenticate user and return an access token, a guest cart token in the body, X-Cart-Token header or cart_token cookie is merged into the user's cart
 //	@Tags			Auth
 //	@Accept			json
 //	@Produce		json
+//	@Param			X-Cart-Token	header	string	false	"Guest cart token to merge"
 //	@Param			request	body		dto.LoginRequest	true	"Login request body"
 //	@Success		200		{object}	dto.AuthResponse	"Successfully logged in"
 //	@Failure		400		{object}	util.ErrorResponse	"Bad request (validation error)"
@@ -53,7 +54,11 @@ func (ah *AuthHandler) Login(c *gin.Context) {
 	// Log email yang dicoba login
 	ah.logger.Info("User attempting login", zap.String("email", request.Email))
 
-	data, err := ah.svc.Login(c.Request.Context(), request.Email, request.Password)
+	if request.CartToken == "" {
+		request.CartToken = cartToken(c)
+	}
+
+	data, err := ah.svc.Login(c.Request.Context(), request.Email, request.Password, request.CartToken)
 	if err != nil {
 		ah.logger.Error("Login failed", zap.String("email", request.Email), zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
@@ -61,6 +66,10 @@ func (ah *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if request.CartToken != "" {
+		clearCartToken(c)
+	}
+
 	ah.logger.Info("Login successful", zap.String("email", request.Email))
 	c.JSON(http.StatusOK, util.APIResponse("Successfully logged in", http.StatusOK, "success", data))
 }
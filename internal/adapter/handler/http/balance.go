@@ -1,10 +1,15 @@
 package http
 
 import (
+	"encoding/csv"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
 	"github.com/aldotp/ecommerce-go-api/pkg/consts"
 	"github.com/aldotp/ecommerce-go-api/pkg/util"
@@ -67,19 +72,56 @@ func (bh *BalanceHandler) Deposit(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// InquireTransfer godoc
+//
+//	@Summary		Prepare a transfer
+//	@Description	Look up the recipient by ID, email or phone and return a transfer inquiry with the recipient's masked name to confirm
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.TransferInquiryRequest	true	"Transfer inquiry request"
+//	@Success		200		{object}	util.Response				"Transfer inquiry created"
+//	@Failure		400		{object}	util.ErrorResponse			"Validation error"
+//	@Failure		404		{object}	util.ErrorResponse			"Recipient not found"
+//	@Failure		500		{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/balance/transfer/inquiry [post]
+func (bh *BalanceHandler) InquireTransfer(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.TransferInquiryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bh.logger.Error("Failed to bind transfer inquiry request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	inquiry, err := bh.svc.InquireTransfer(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		bh.logger.Error("Transfer inquiry failed", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Transfer inquiry created", http.StatusOK, "success", inquiry)
+	c.JSON(http.StatusOK, response)
+}
+
 // Transfer godoc
 //
-// @Summary	Transfer money to another user
-// @Description	Transfer funds from one user to another
-// @Tags		Balance
-// @Accept	json
-// @Produce	json
-// @Security	BearerAuth
-// @Param	request	body	dto.TransferRequest	true	"Transfer request payload"
-// @Success	200	{object}	util.Response	"Transfer successful"
-// @Failure	400	{object}	util.ErrorResponse	"Validation error"
-// @Failure	500	{object}	util.ErrorResponse	"Internal server error"
-// @Router	/api/v1/balance/transfer [post]
+//	@Summary		Confirm a transfer
+//	@Description	Confirm a transfer inquiry, with the emailed code when one was required. Transfers above the review threshold wait for an admin
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.TransferRequest	true	"Transfer request payload"
+//	@Success		200		{object}	util.Response		"Transfer successful"
+//	@Failure		400		{object}	util.ErrorResponse	"Validation error"
+//	@Failure		404		{object}	util.ErrorResponse	"Transfer inquiry not found"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/transfer [post]
 func (bh *BalanceHandler) Transfer(c *gin.Context) {
 	userSess := util.GetAuthPayload(c, consts.AuthorizationKey) // Ambil user ID dari context
 
@@ -96,7 +138,7 @@ func (bh *BalanceHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	transaction, err := bh.svc.Transfer(c.Request.Context(), uint64(userSess.UserID), request.RecipientID, request.Amount)
+	result, err := bh.svc.ConfirmTransfer(c.Request.Context(), uint64(userSess.UserID), request)
 	if err != nil {
 		bh.logger.Error("Transfer failed", zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
@@ -104,8 +146,121 @@ func (bh *BalanceHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	bh.logger.Info("Transfer successful", zap.Uint64("from_user_id", uint64(userSess.UserID)), zap.Uint64("to_user_id", request.RecipientID))
-	response := util.APIResponse("Transfer successful", http.StatusOK, "success", transaction)
+	bh.logger.Info("Transfer confirmed", zap.Uint64("from_user_id", uint64(userSess.UserID)), zap.String("status", result.Status))
+	response := util.APIResponse("Transfer successful", http.StatusOK, "success", result)
+	c.JSON(http.StatusOK, response)
+}
+
+// ListTransferReviews godoc
+//
+//	@Summary		List transfer reviews
+//	@Description	List transfers above the review threshold, optionally filtered by status
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string				false	"Review status"
+//	@Success		200		{object}	util.Response		"Transfer reviews retrieved"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		403		{object}	util.ErrorResponse	"Forbidden error"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/transfer-reviews [get]
+func (bh *BalanceHandler) ListTransferReviews(c *gin.Context) {
+	var request dto.ListTransferReviewRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		bh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	reviews, err := bh.svc.ListTransferReviews(c.Request.Context(), request)
+	if err != nil {
+		bh.logger.Error("Failed to list transfer reviews", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Transfer reviews retrieved successfully", http.StatusOK, "success", reviews)
+	c.JSON(http.StatusOK, response)
+}
+
+// ApproveTransferReview godoc
+//
+//	@Summary		Approve transfer review
+//	@Description	Approve a pending transfer and execute it
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Transfer review ID"
+//	@Success		200	{object}	util.Response		"Transfer successful"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Transfer review not found"
+//	@Failure		409	{object}	util.ErrorResponse	"Transfer review already closed"
+//	@Router			/api/v1/balance/transfer-reviews/{id}/approve [post]
+func (bh *BalanceHandler) ApproveTransferReview(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		bh.logger.Error("Invalid transfer review ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	transfer, err := bh.svc.ApproveTransferReview(c.Request.Context(), uint64(userSess.UserID), id)
+	if err != nil {
+		bh.logger.Error("Failed to approve transfer review", zap.Uint64("review_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	bh.logger.Info("Transfer review approved", zap.Uint64("review_id", id), zap.Int("admin_id", userSess.UserID))
+	response := util.APIResponse("Transfer successful", http.StatusOK, "success", transfer)
+	c.JSON(http.StatusOK, response)
+}
+
+// RejectTransferReview godoc
+//
+//	@Summary		Reject transfer review
+//	@Description	Reject a pending transfer, no funds are moved
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int								true	"Transfer review ID"
+//	@Param			request	body		dto.RejectTransferReviewRequest	true	"Rejection reason"
+//	@Success		200		{object}	util.Response					"Transfer review rejected"
+//	@Failure		400		{object}	util.ErrorResponse				"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse				"Transfer review not found"
+//	@Failure		409		{object}	util.ErrorResponse				"Transfer review already closed"
+//	@Router			/api/v1/balance/transfer-reviews/{id}/reject [post]
+func (bh *BalanceHandler) RejectTransferReview(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		bh.logger.Error("Invalid transfer review ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.RejectTransferReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		bh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := bh.svc.RejectTransferReview(c.Request.Context(), uint64(userSess.UserID), id, request); err != nil {
+		bh.logger.Error("Failed to reject transfer review", zap.Uint64("review_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	bh.logger.Info("Transfer review rejected", zap.Uint64("review_id", id), zap.Int("admin_id", userSess.UserID))
+	response := util.APIResponse("Transfer review rejected successfully", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -144,45 +299,175 @@ func (bh *BalanceHandler) CheckBalance(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// Withdraw godoc
+// Reconcile godoc
 //
-//	@Summary		Withdraw balance
-//	@Description	Withdraw balance from user account
-//	@Tags			Transactions
+//	@Summary		Reconcile balances
+//	@Description	List the balances that do not match the sum of their ledger movements
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200		{object}	util.Response		"Reconciliation finished"
+//	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		403		{object}	util.ErrorResponse	"Forbidden error"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/reconciliation [get]
+func (bh *BalanceHandler) Reconcile(c *gin.Context) {
+	mismatches, err := bh.svc.Reconcile(c.Request.Context())
+	if err != nil {
+		bh.logger.Error("Failed to reconcile balances", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	if len(mismatches) > 0 {
+		bh.logger.Warn("Balance mismatches found", zap.Int("count", len(mismatches)))
+	}
+
+	response := util.APIResponse("Reconciliation finished", http.StatusOK, "success", mismatches)
+	c.JSON(http.StatusOK, response)
+}
+
+// Refund godoc
+//
+//	@Summary		Refund an order to the wallet
+//	@Description	Credit an order amount back to a user's wallet out of platform revenue. The order must belong to the user and be paid from the wallet, refunds of an order cannot exceed what it paid
+//	@Tags			Balance
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			request	body		dto.WithdrawRequest	true	"Withdraw request"
-//	@Success		200		{object}	util.Response		"Withdraw successful"
+//	@Param			request	body		dto.RefundRequest	true	"Refund request"
+//	@Success		200		{object}	util.Response		"Refund successful"
 //	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
 //	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
-//	@Failure		403		{object}	util.ErrorResponse	"Insufficient balance"
+//	@Failure		403		{object}	util.ErrorResponse	"Forbidden error"
+//	@Failure		404		{object}	util.ErrorResponse	"Order not found"
+//	@Failure		409		{object}	util.ErrorResponse	"Refund exceeds the amount paid"
 //	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
-//	@Router			/api/v1/withdraw [post]
-func (bh *BalanceHandler) Withdraw(c *gin.Context) {
-	var request dto.WithdrawRequest
+//	@Router			/api/v1/balance/refund [post]
+func (bh *BalanceHandler) Refund(c *gin.Context) {
+	var request dto.RefundRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		bh.logger.Error("Failed to bind request", zap.Error(err))
 		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
 		return
 	}
 
+	err := bh.svc.Refund(c.Request.Context(), request.UserID, request.OrderID, request.Amount)
+	if err != nil {
+		bh.logger.Error("Failed to refund", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	bh.logger.Info("Refund successful", zap.Uint64("user_id", request.UserID), zap.Int("order_id", request.OrderID))
+	response := util.APIResponse("Refund successful", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// ListTransactions godoc
+//
+//	@Summary		List balance transactions
+//	@Description	List the wallet ledger of the authenticated user, newest first, with cursor pagination
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			type			query		string	false	"Transaction type"
+//	@Param			from			query		string	false	"Start date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"End date, inclusive (YYYY-MM-DD)"
+//	@Param			min_amount		query		number	false	"Minimum absolute amount"
+//	@Param			max_amount		query		number	false	"Maximum absolute amount"
+//	@Param			counterparty_id	query		int		false	"Counterparty user ID"
+//	@Param			cursor			query		string	false	"Cursor returned by the previous page"
+//	@Param			limit			query		int		false	"Page size, max 100"
+//	@Success		200				{object}	util.Response		"Transactions retrieved"
+//	@Failure		400				{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		401				{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		500				{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/transactions [get]
+func (bh *BalanceHandler) ListTransactions(c *gin.Context) {
 	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
-	if userSess.UserID == 0 {
-		bh.logger.Error("Unauthorized request")
-		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+
+	var request dto.ListBalanceTransactionRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		bh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
 		return
 	}
 
-	err := bh.svc.Withdraw(c.Request.Context(), uint64(userSess.UserID), request.Amount)
+	transactions, err := bh.svc.ListTransactions(c.Request.Context(), uint64(userSess.UserID), request)
 	if err != nil {
-		bh.logger.Error("Failed to withdraw", zap.Error(err))
+		bh.logger.Error("Failed to list balance transactions", zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
 		c.JSON(statusCode, response)
 		return
 	}
 
-	bh.logger.Info("Withdraw successful", zap.Uint64("user_id", uint64(userSess.UserID)), zap.Float64("amount", request.Amount))
-	response := util.APIResponse("Withdraw successful", http.StatusOK, "success", nil)
+	response := util.APIResponse("Transactions retrieved successfully", http.StatusOK, "success", transactions)
 	c.JSON(http.StatusOK, response)
 }
+
+// ExportTransactions godoc
+//
+//	@Summary		Export balance transactions
+//	@Description	Stream the wallet statement of the authenticated user as CSV, accepts the same filters as the list endpoint
+//	@Tags			Balance
+//	@Produce		text/csv
+//	@Security		BearerAuth
+//	@Param			format	query		string	true	"Export format"	Enums(csv)
+//	@Success		200		{file}		file				"Statement"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
+//	@Router			/api/v1/balance/transactions/export [get]
+func (bh *BalanceHandler) ExportTransactions(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.ExportBalanceTransactionRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		bh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	fileName := fmt.Sprintf("statement-%d-%s.csv", userSess.UserID, time.Now().Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write([]string{"id", "created_at", "transaction_type", "amount", "balance_before", "balance_after", "reference_id", "counterparty_id"})
+
+	err := bh.svc.ExportTransactions(c.Request.Context(), uint64(userSess.UserID), request.ListBalanceTransactionRequest, func(transaction domain.BalanceTransaction) error {
+		return writer.Write([]string{
+			strconv.FormatUint(transaction.ID, 10),
+			transaction.CreatedAt.Format(time.RFC3339),
+			string(transaction.TransactionType),
+			strconv.FormatFloat(transaction.Amount, 'f', 2, 64),
+			strconv.FormatFloat(transaction.BalanceBefore, 'f', 2, 64),
+			strconv.FormatFloat(transaction.BalanceAfter, 'f', 2, 64),
+			formatOptionalID(transaction.ReferenceID),
+			formatOptionalID(transaction.CounterpartyID),
+		})
+	})
+
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		// the status line is already sent, so the statement is cut short
+		bh.logger.Error("Failed to export balance transactions", zap.Int("user_id", userSess.UserID), zap.Error(err))
+		return
+	}
+
+	bh.logger.Info("Balance transactions exported", zap.Int("user_id", userSess.UserID))
+}
+
+func formatOptionalID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(id, 10)
+}
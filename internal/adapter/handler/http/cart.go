@@ -2,10 +2,14 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/service"
 	"github.com/aldotp/ecommerce-go-api/pkg/consts"
 	"github.com/aldotp/ecommerce-go-api/pkg/util"
@@ -30,11 +34,12 @@ func NewCartHandler(cartService *service.CartService, logger *zap.Logger) *CartH
 // AddToCart godoc
 //
 //	@Summary		Add product to cart
-//	@Description	Add a product to the user's shopping cart
+//	@Description	Add a product to the user's shopping cart, or to a guest cart without a bearer token. A new guest cart returns its token in the X-Cart-Token header and cart_token cookie
 //	@Tags			Cart
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Param			X-Cart-Token	header	string	false	"Guest cart token, used when no bearer token is sent"
 //	@Param			request	body		dto.AddCartRequest	true	"Add to cart request body"
 //	@Success		200		{object}	util.Response	"Product added to cart successfully"
 //	@Failure		400		{object}	util.ErrorResponse	"Bad request (validation error)"
@@ -42,7 +47,7 @@ func NewCartHandler(cartService *service.CartService, logger *zap.Logger) *CartH
 //	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
 //	@Router			/api/v1/carts [post]
 func (h *CartHandler) AddToCart(c *gin.Context) {
-	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+	owner := cartOwner(c)
 
 	var request dto.AddCartRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -52,14 +57,16 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 	}
 
 	h.logger.Info("Adding product to cart",
-		zap.Int("user_id", userSess.UserID),
+		zap.Int("user_id", owner.UserID),
 		zap.Int("product_id", request.ProductID),
+		zap.Int("variant_id", request.VariantID),
 		zap.Int("quantity", request.Quantity),
 	)
 
-	if err := h.CartService.AddToCart(c.Request.Context(), userSess.UserID, request.ProductID, request.Quantity); err != nil {
+	token, err := h.CartService.AddToCart(c.Request.Context(), owner, request.ProductID, request.VariantID, request.Quantity)
+	if err != nil {
 		h.logger.Error("Failed to add product to cart",
-			zap.Int("user_id", userSess.UserID),
+			zap.Int("user_id", owner.UserID),
 			zap.Int("product_id", request.ProductID),
 			zap.Int("quantity", request.Quantity),
 			zap.Error(err),
@@ -69,7 +76,11 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Product added to cart successfully", zap.Int("user_id", userSess.UserID))
+	if token != "" {
+		setCartToken(c, token)
+	}
+
+	h.logger.Info("Product added to cart successfully", zap.Int("user_id", owner.UserID))
 	response := util.APIResponse("Product added to cart successfully", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
@@ -77,35 +88,54 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 // ViewCart godoc
 //
 //	@Summary		View user's cart
-//	@Description	Get the list of products in the user's shopping cart
+//	@Description	Get the list of products in the user's or the guest's shopping cart at current prices. Lines whose price changed since they were added, or whose product is out of stock or deleted, are flagged in status. The cart version is also sent as the ETag header
 //	@Tags			Cart
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Param			X-Cart-Token	header	string	false	"Guest cart token, used when no bearer token is sent"
 //	@Success		200		{object}	util.Response	"Cart retrieved successfully"
 //	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
 //	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
 //	@Router			/api/v1/carts [get]
 func (h *CartHandler) ViewCart(c *gin.Context) {
-	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+	owner := cartOwner(c)
 
-	h.logger.Info("Retrieving cart", zap.Int("user_id", userSess.UserID))
+	h.logger.Info("Retrieving cart", zap.Int("user_id", owner.UserID))
 
-	cart, err := h.CartService.GetCart(context.Background(), userSess.UserID)
+	cart, err := h.CartService.GetCart(context.Background(), owner)
 	if err != nil {
-		h.logger.Error("Failed to retrieve cart", zap.Int("user_id", userSess.UserID), zap.Error(err))
+		h.logger.Error("Failed to retrieve cart", zap.Int("user_id", owner.UserID), zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
 		c.JSON(statusCode, response)
 		return
 	}
 
-	h.logger.Info("Cart retrieved successfully", zap.Int("user_id", userSess.UserID))
+	if cart.Version != "" {
+		c.Header("ETag", `"`+cart.Version+`"`)
+	}
+
+	h.logger.Info("Cart retrieved successfully", zap.Int("user_id", owner.UserID))
 	response := util.APIResponse("Get Cart successfully", http.StatusOK, "success", cart)
 	c.JSON(http.StatusOK, response)
 }
 
+// RemoveFromCart godoc
+//
+//	@Summary		Remove a product from the cart
+//	@Description	Remove one product, or one variant of it, from the cart
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Cart-Token	header	string	false	"Guest cart token, used when no bearer token is sent"
+//	@Param			request	body		dto.RemoveCartRequest	true	"Product to remove"
+//	@Success		200		{object}	util.Response	"Product removed from cart successfully"
+//	@Failure		400		{object}	util.ErrorResponse	"Bad request"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/carts/items [delete]
 func (h *CartHandler) RemoveFromCart(c *gin.Context) {
-	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+	owner := cartOwner(c)
 
 	var request dto.RemoveCartRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -115,13 +145,14 @@ func (h *CartHandler) RemoveFromCart(c *gin.Context) {
 	}
 
 	h.logger.Info("Removing product from cart",
-		zap.Int("user_id", userSess.UserID),
+		zap.Int("user_id", owner.UserID),
 		zap.Int("product_id", request.ProductID),
+		zap.Int("variant_id", request.VariantID),
 	)
 
-	if err := h.CartService.RemoveFromCart(c.Request.Context(), userSess.UserID, request.ProductID); err != nil {
+	if err := h.CartService.RemoveFromCart(c.Request.Context(), owner, request.ProductID, request.VariantID); err != nil {
 		h.logger.Error("Failed to remove product from cart",
-			zap.Int("user_id", userSess.UserID),
+			zap.Int("user_id", owner.UserID),
 			zap.Int("product_id", request.ProductID),
 			zap.Error(err),
 		)
@@ -130,13 +161,113 @@ func (h *CartHandler) RemoveFromCart(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Product removed from cart successfully", zap.Int("user_id", userSess.UserID))
+	h.logger.Info("Product removed from cart successfully", zap.Int("user_id", owner.UserID))
 	response := util.APIResponse("Product removed from cart successfully", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
 
+// BulkUpdateCart godoc
+//
+//	@Summary		Change several cart lines at once
+//	@Description	Apply a list of upsert and remove operations to the cart all at once. Upsert sets the quantity of a product and adds it when missing. When any operation is invalid the cart is left unchanged and the errors list the rejected operations by index
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Cart-Token	header	string	false	"Guest cart token, used when no bearer token is sent"
+//	@Param			request	body		dto.BulkCartRequest	true	"Cart operations"
+//	@Success		200		{object}	util.Response	"Cart updated successfully"
+//	@Failure		400		{object}	util.ErrorResponse	"Bad request (validation error)"
+//	@Failure		422		{object}	util.ErrorResponse	"Some operations are invalid, nothing was changed"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/carts/items [patch]
+func (h *CartHandler) BulkUpdateCart(c *gin.Context) {
+	owner := cartOwner(c)
+
+	var request dto.BulkCartRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Updating cart lines",
+		zap.Int("user_id", owner.UserID),
+		zap.Int("operations", len(request.Operations)),
+	)
+
+	token, lineErrors, err := h.CartService.BulkUpdateCart(c.Request.Context(), owner, request.Operations)
+	if err != nil {
+		h.logger.Error("Failed to update cart lines",
+			zap.Int("user_id", owner.UserID),
+			zap.Int("operations", len(request.Operations)),
+			zap.Error(err),
+		)
+		statusCode, response := helper.ErrorResponse(err)
+		if len(lineErrors) > 0 {
+			details := make([]util.ErrorResponse, 0, len(lineErrors))
+			for _, line := range lineErrors {
+				details = append(details, util.ErrorResponse{
+					Key:     fmt.Sprintf("operations[%d]", line.Index),
+					Message: line.Message,
+				})
+			}
+			response = response.WithError(details...)
+		}
+		c.JSON(statusCode, response)
+		return
+	}
+
+	if token != "" {
+		setCartToken(c, token)
+	}
+
+	h.logger.Info("Cart lines updated successfully", zap.Int("user_id", owner.UserID))
+	response := util.APIResponse("Cart updated successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// ClearCart godoc
+//
+//	@Summary		Empty the cart
+//	@Description	Remove the cart and every product in it. A single product is removed with DELETE /api/v1/carts/items, a request with a body is refused so a client still sending a product_id here doesn't lose its cart
+//	@Tags			Cart
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Cart-Token	header	string	false	"Guest cart token, used when no bearer token is sent"
+//	@Success		200		{object}	util.Response	"Cart cleared successfully"
+//	@Failure		400		{object}	util.ErrorResponse	"Bad request or a body was sent"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/carts [delete]
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	owner := cartOwner(c)
+
+	// this endpoint used to remove the product named in its body, chunked
+	// bodies have no length so the body itself is checked
+	if _, err := io.ReadFull(c.Request.Body, make([]byte, 1)); err == nil {
+		h.logger.Warn("Refusing to clear cart sent with a body", zap.Int("user_id", owner.UserID))
+		statusCode, response := helper.ErrorResponse(consts.ErrClearCartWithBody)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	h.logger.Info("Clearing cart", zap.Int("user_id", owner.UserID))
+
+	if err := h.CartService.ClearCart(c.Request.Context(), owner); err != nil {
+		h.logger.Error("Failed to clear cart", zap.Int("user_id", owner.UserID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	h.logger.Info("Cart cleared successfully", zap.Int("user_id", owner.UserID))
+	response := util.APIResponse("Cart cleared successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *CartHandler) UpdateCart(c *gin.Context) {
-	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+	owner := cartOwner(c)
 
 	var request dto.UpdateCartRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -146,14 +277,14 @@ func (h *CartHandler) UpdateCart(c *gin.Context) {
 	}
 
 	h.logger.Info("Updating cart",
-		zap.Int("user_id", userSess.UserID),
+		zap.Int("user_id", owner.UserID),
 		zap.Int("product_id", request.ProductID),
 		zap.Int("quantity", request.Quantity),
 	)
 
-	if err := h.CartService.UpdateCart(c.Request.Context(), userSess.UserID, request); err != nil {
+	if err := h.CartService.UpdateCart(c.Request.Context(), owner, request); err != nil {
 		h.logger.Error("Failed to update cart",
-			zap.Int("user_id", userSess.UserID),
+			zap.Int("user_id", owner.UserID),
 			zap.Int("product_id", request.ProductID),
 			zap.Int("quantity", request.Quantity),
 			zap.Error(err),
@@ -163,7 +294,40 @@ func (h *CartHandler) UpdateCart(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Cart updated successfully", zap.Int("user_id", userSess.UserID))
+	h.logger.Info("Cart updated successfully", zap.Int("user_id", owner.UserID))
 	response := util.APIResponse("Cart updated successfully", http.StatusOK, "success", nil)
 	c.JSON(http.StatusOK, response)
 }
+
+// cartOwner picks the signed in user's cart, or the guest cart of the token in
+// the X-Cart-Token header or cart_token cookie for an anonymous request
+func cartOwner(c *gin.Context) domain.CartOwner {
+	if payload, ok := c.Get(consts.AuthorizationKey); ok {
+		return domain.CartOwner{UserID: payload.(*domain.TokenPayload).UserID}
+	}
+
+	return domain.CartOwner{GuestToken: cartToken(c)}
+}
+
+func cartToken(c *gin.Context) string {
+	if token := c.GetHeader(consts.CartTokenHeader); token != "" {
+		return token
+	}
+
+	token, _ := c.Cookie(consts.CartTokenCookie)
+	return token
+}
+
+// setCartToken hands a guest their cart token both as a cookie for browsers
+// and as a header for API clients
+func setCartToken(c *gin.Context, token string) {
+	c.Header(consts.CartTokenHeader, token)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(consts.CartTokenCookie, token, int(config.GuestCartTTL().Seconds()), "/", "", config.AppEnv() == "production", true)
+}
+
+// clearCartToken drops the cart_token cookie once the guest cart is merged
+func clearCartToken(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(consts.CartTokenCookie, "", -1, "/", "", config.AppEnv() == "production", true)
+}
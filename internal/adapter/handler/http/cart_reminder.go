@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// cartReminderStatsPeriod is how far back the stats go without a since date
+const cartReminderStatsPeriod = 30 * 24 * time.Hour
+
+// CartReminderHandler represents the HTTP handler for abandoned cart reminders
+type CartReminderHandler struct {
+	svc    port.CartReminderService
+	logger *zap.Logger
+}
+
+// NewCartReminderHandler creates a new CartReminderHandler instance
+func NewCartReminderHandler(svc port.CartReminderService, logger *zap.Logger) *CartReminderHandler {
+	return &CartReminderHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// UpdateSettings godoc
+//
+//	@Summary		Turn cart reminders on or off
+//	@Description	Choose whether to get reminder emails about an idle cart
+//	@Tags			Profile
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.CartReminderSettingsRequest	true	"Reminder settings"
+//	@Success		200		{object}	util.Response					"Settings updated"
+//	@Failure		400		{object}	util.ErrorResponse				"Invalid request parameters"
+//	@Failure		500		{object}	util.ErrorResponse				"Internal server error"
+//	@Router			/api/v1/profile/cart-reminders [put]
+func (rh *CartReminderHandler) UpdateSettings(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.CartReminderSettingsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		rh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := rh.svc.SetReminders(c.Request.Context(), userSess.UserID, *request.Enabled); err != nil {
+		rh.logger.Error("Failed to update cart reminder settings", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Cart reminder settings updated successfully", http.StatusOK, "success", request)
+	c.JSON(http.StatusOK, response)
+}
+
+// Unsubscribe godoc
+//
+//	@Summary		Unsubscribe from cart reminders
+//	@Description	Turn cart reminders off with the link sent in a reminder email
+//	@Tags			Carts
+//	@Produce		json
+//	@Param			token	query		string				true	"Unsubscribe token"
+//	@Success		200		{object}	util.Response		"Unsubscribed"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid unsubscribe link"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/carts/reminders/unsubscribe [get]
+func (rh *CartReminderHandler) Unsubscribe(c *gin.Context) {
+	var request dto.UnsubscribeCartRemindersRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		rh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := rh.svc.Unsubscribe(c.Request.Context(), request.Token); err != nil {
+		rh.logger.Error("Failed to unsubscribe from cart reminders", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("You will no longer get cart reminders", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// Stats godoc
+//
+//	@Summary		Cart reminder stats
+//	@Description	Reminders sent since a date and how many of the reminded carts turned into an order
+//	@Tags			Carts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			since	query		string				false	"Start date (YYYY-MM-DD), defaults to 30 days ago"
+//	@Success		200		{object}	util.Response		"Stats retrieved"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/carts/reminders/stats [get]
+func (rh *CartReminderHandler) Stats(c *gin.Context) {
+	var request dto.CartReminderStatsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		rh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	since := time.Now().Add(-cartReminderStatsPeriod)
+	if request.Since != "" {
+		// already validated by the binding
+		since, _ = time.Parse(time.DateOnly, request.Since)
+	}
+
+	stats, err := rh.svc.Stats(c.Request.Context(), since)
+	if err != nil {
+		rh.logger.Error("Failed to get cart reminder stats", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Cart reminder stats retrieved successfully", http.StatusOK, "success", stats)
+	c.JSON(http.StatusOK, response)
+}
@@ -102,13 +102,25 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{object}	util.Response	"List of categories successfully retrieved"
-//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Param			page		query		int					false	"Page number, from 1"
+//	@Param			page_size	query		int					false	"Page size, up to 100"
+//	@Param			cursor		query		string				false	"Next cursor of the previous page"
+//	@Param			sort		query		string				false	"name or id, prefixed with - for descending"
+//	@Success		200			{object}	util.Response		"List of categories successfully retrieved"
+//	@Failure		400			{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
 //	@Router			/api/v1/categories [get]
 func (h *CategoryHandler) ListCategory(c *gin.Context) {
 	h.logger.Info("Fetching category list")
 
-	categories, err := h.svc.Finds(c.Request.Context(), dto.ListCategoryRequest{})
+	var request dto.ListCategoryRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		h.logger.Warn("Invalid request parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	categories, err := h.svc.Finds(c.Request.Context(), request)
 	if err != nil {
 		h.logger.Error("Failed to fetch category list", zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
@@ -116,8 +128,8 @@ func (h *CategoryHandler) ListCategory(c *gin.Context) {
 		return
 	}
 
-	h.logger.Info("Category list retrieved successfully", zap.Int("count", len(categories)))
-	c.JSON(http.StatusOK, util.APIResponse("List Category successfully", http.StatusOK, "success", dto.NewCategoryResponses(categories)))
+	h.logger.Info("Category list retrieved successfully", zap.Int("count", len(categories.Items)))
+	c.JSON(http.StatusOK, util.APIResponse("List Category successfully", http.StatusOK, "success", categories))
 }
 
 // UpdateCategory godoc
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
@@ -28,16 +29,20 @@ func NewCheckoutHandler(checkoutService *service.CheckoutService, logger *zap.Lo
 // Checkout godoc
 //
 //	@Summary		Checkout a cart
-//	@Description	Completes the checkout process for the user’s cart
+//	@Description	Completes the checkout process for the user’s cart. The cart version from the cart view must be sent in cart_version or If-Match, a cart that changed since returns 412
 //	@Tags			Checkout
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Param			If-Match	header	string	false	"Cart version, used when cart_version is empty"
 //	@Param			request	body		dto.CheckoutRequest	true	"Checkout request"
 //	@Success		200		{object}	util.Response	"Checkout successful"
 //	@Failure		400		{object}	util.ErrorResponse	"Invalid request payload"
 //	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
 //	@Failure		404		{object}	util.ErrorResponse	"Cart not found"
+//	@Failure		409		{object}	util.ErrorResponse	"Cart has unavailable products"
+//	@Failure		412		{object}	util.ErrorResponse	"Cart changed since it was reviewed"
+//	@Failure		428		{object}	util.ErrorResponse	"Cart version is missing"
 //	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
 //	@Router			/api/v1/checkout [post]
 //	@Security		BearerAuth
@@ -64,7 +69,11 @@ func (h *CheckoutHandler) Checkout(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.CheckoutService.Checkout(c.Request.Context(), userSess.UserID, request.PaymentMethod)
+	if request.CartVersion == "" {
+		request.CartVersion = parseETag(c.GetHeader("If-Match"))
+	}
+
+	resp, err := h.CheckoutService.Checkout(c.Request.Context(), userSess.UserID, request.PaymentMethod, request.CartVersion)
 	if err != nil {
 		h.Logger.Error("Checkout failed",
 			zap.Int("userID", userSess.UserID),
@@ -84,3 +93,9 @@ func (h *CheckoutHandler) Checkout(c *gin.Context) {
 	response := util.APIResponse("Checkout successful", http.StatusOK, "success", resp)
 	c.JSON(http.StatusOK, response)
 }
+
+// parseETag strips the weak prefix and quotes of an If-Match value
+func parseETag(value string) string {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+	return strings.Trim(value, `"`)
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/localstorage"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FileHandler serves the files of the local storage and takes the uploads of
+// its presigned URLs, standing in for a bucket
+type FileHandler struct {
+	storage *localstorage.LocalStorage
+	maxSize int64
+	logger  *zap.Logger
+}
+
+// NewFileHandler creates a new FileHandler instance, maxSize bounds the body
+// of a presigned upload
+func NewFileHandler(storage *localstorage.LocalStorage, maxSize int64, logger *zap.Logger) *FileHandler {
+	return &FileHandler{
+		storage: storage,
+		maxSize: maxSize,
+		logger:  logger,
+	}
+}
+
+// ServeFile godoc
+//
+//	@Summary		Get a stored file
+//	@Description	Download a file of the local storage, the public URL of product images when STORAGE_BACKEND is local
+//	@Tags			Files
+//	@Produce		octet-stream
+//	@Param			key	path		string				true	"File name"
+//	@Success		200	{file}		file				"File content"
+//	@Failure		404	{object}	util.ErrorResponse	"File not found"
+//	@Router			/files/{key} [get]
+func (h *FileHandler) ServeFile(c *gin.Context) {
+	path, err := h.storage.Path(fileKey(c))
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(consts.ErrDataNotFound)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		statusCode, response := helper.ErrorResponse(consts.ErrDataNotFound)
+		c.JSON(statusCode, response)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		statusCode, response := helper.ErrorResponse(consts.ErrDataNotFound)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), file)
+}
+
+// UploadFile godoc
+//
+//	@Summary		Upload a file to a presigned URL
+//	@Description	Store the request body under the file name of a presigned URL handed out by the local storage
+//	@Tags			Files
+//	@Accept			octet-stream
+//	@Produce		json
+//	@Param			key			path		string				true	"File name"
+//	@Param			expires		query		int					true	"Expiry of the URL, in Unix seconds"
+//	@Param			signature	query		string				true	"Signature of the URL"
+//	@Success		200			{object}	util.Response		"File stored"
+//	@Failure		403			{object}	util.ErrorResponse	"Upload URL is invalid or has expired"
+//	@Failure		413			{object}	util.ErrorResponse	"File too large"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/files/{key} [put]
+func (h *FileHandler) UploadFile(c *gin.Context) {
+	key := fileKey(c)
+	if err := h.storage.Verify(http.MethodPut, key, c.Request.URL.Query()); err != nil {
+		statusCode, response := helper.ErrorResponse(consts.ErrInvalidUploadURL)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize)
+	if err := h.storage.Write(key, body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			statusCode, response := helper.ErrorResponse(consts.ErrImageTooLarge)
+			c.JSON(statusCode, response)
+			return
+		}
+
+		h.logger.Error("Failed to store uploaded file", zap.String("key", key), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("File uploaded successfully", http.StatusOK, "success", nil))
+}
+
+// fileKey is the file name of a /files/*key route
+func fileKey(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("key"), "/")
+}
@@ -0,0 +1,284 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MoneyRequestHandler represents the HTTP handler for money requests between users
+type MoneyRequestHandler struct {
+	svc    port.MoneyRequestService
+	logger *zap.Logger
+}
+
+// NewMoneyRequestHandler creates a new MoneyRequestHandler instance
+func NewMoneyRequestHandler(svc port.MoneyRequestService, logger *zap.Logger) *MoneyRequestHandler {
+	return &MoneyRequestHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Request money
+//	@Description	Ask other users for money with an amount per payer, or split a bill evenly between them
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.MoneyRequestRequest	true	"Money request"
+//	@Success		201		{object}	util.Response			"Money request created"
+//	@Failure		400		{object}	util.ErrorResponse		"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse		"Payer not found"
+//	@Failure		500		{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/balance/requests [post]
+func (mh *MoneyRequestHandler) Create(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.MoneyRequestRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		mh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	moneyRequest, err := mh.svc.Create(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		mh.logger.Error("Failed to create money request", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	mh.logger.Info("Money request created", zap.Uint64("money_request_id", moneyRequest.ID), zap.Int("user_id", userSess.UserID))
+	response := util.APIResponse("Money request created successfully", http.StatusCreated, "success", moneyRequest)
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListSent godoc
+//
+//	@Summary		List sent money requests
+//	@Description	List the money requests the authenticated user sent
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string				false	"Request status"
+//	@Success		200		{object}	util.Response		"Money requests retrieved"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/requests/sent [get]
+func (mh *MoneyRequestHandler) ListSent(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.ListMoneyRequestRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		mh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	requests, err := mh.svc.ListSent(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		mh.logger.Error("Failed to list sent money requests", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Money requests retrieved successfully", http.StatusOK, "success", requests)
+	c.JSON(http.StatusOK, response)
+}
+
+// ListReceived godoc
+//
+//	@Summary		List received money requests
+//	@Description	List the money requests the authenticated user was asked to pay
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string				false	"Request status"
+//	@Success		200		{object}	util.Response		"Money requests retrieved"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/requests/received [get]
+func (mh *MoneyRequestHandler) ListReceived(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.ListMoneyRequestRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		mh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	requests, err := mh.svc.ListReceived(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		mh.logger.Error("Failed to list received money requests", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Money requests retrieved successfully", http.StatusOK, "success", requests)
+	c.JSON(http.StatusOK, response)
+}
+
+// Get godoc
+//
+//	@Summary		Get money request
+//	@Description	Get a money request the authenticated user sent or was asked to pay
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Money request ID"
+//	@Success		200	{object}	util.Response		"Money request retrieved"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Money request not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/requests/{id} [get]
+func (mh *MoneyRequestHandler) Get(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		mh.logger.Error("Invalid money request ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	moneyRequest, err := mh.svc.Get(c.Request.Context(), uint64(userSess.UserID), id)
+	if err != nil {
+		mh.logger.Error("Failed to get money request", zap.Uint64("money_request_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Money request retrieved successfully", http.StatusOK, "success", moneyRequest)
+	c.JSON(http.StatusOK, response)
+}
+
+// Pay godoc
+//
+//	@Summary		Pay money request
+//	@Description	Pay the authenticated user's share of a money request from their wallet. A share at or above the OTP threshold needs the inquiry_id and otp of a transfer inquiry to the requester for the share amount
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int							true	"Money request ID"
+//	@Param			request	body		dto.PayMoneyRequestRequest	false	"Transfer confirmation"
+//	@Success		200		{object}	util.Response				"Money request paid"
+//	@Failure		400		{object}	util.ErrorResponse			"Insufficient balance, limit exceeded or verification code needed"
+//	@Failure		404		{object}	util.ErrorResponse			"Money request not found"
+//	@Failure		409		{object}	util.ErrorResponse			"Money request no longer open"
+//	@Router			/api/v1/balance/requests/{id}/pay [post]
+func (mh *MoneyRequestHandler) Pay(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		mh.logger.Error("Invalid money request ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	// the body is optional, shares below the OTP threshold send none
+	var request dto.PayMoneyRequestRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		mh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	transfer, err := mh.svc.Pay(c.Request.Context(), uint64(userSess.UserID), id, request)
+	if err != nil {
+		mh.logger.Error("Failed to pay money request", zap.Uint64("money_request_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	mh.logger.Info("Money request paid", zap.Uint64("money_request_id", id), zap.Int("user_id", userSess.UserID))
+	response := util.APIResponse("Money request paid successfully", http.StatusOK, "success", transfer)
+	c.JSON(http.StatusOK, response)
+}
+
+// Decline godoc
+//
+//	@Summary		Decline money request
+//	@Description	Decline the authenticated user's share of a money request
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Money request ID"
+//	@Success		200	{object}	util.Response		"Money request declined"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Money request not found"
+//	@Failure		409	{object}	util.ErrorResponse	"Money request no longer open"
+//	@Router			/api/v1/balance/requests/{id}/decline [post]
+func (mh *MoneyRequestHandler) Decline(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		mh.logger.Error("Invalid money request ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := mh.svc.Decline(c.Request.Context(), uint64(userSess.UserID), id); err != nil {
+		mh.logger.Error("Failed to decline money request", zap.Uint64("money_request_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Money request declined successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// Cancel godoc
+//
+//	@Summary		Cancel money request
+//	@Description	Cancel a money request the authenticated user sent, shares already paid stay paid
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Money request ID"
+//	@Success		200	{object}	util.Response		"Money request cancelled"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Money request not found"
+//	@Failure		409	{object}	util.ErrorResponse	"Money request no longer open"
+//	@Router			/api/v1/balance/requests/{id}/cancel [post]
+func (mh *MoneyRequestHandler) Cancel(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		mh.logger.Error("Invalid money request ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := mh.svc.Cancel(c.Request.Context(), uint64(userSess.UserID), id); err != nil {
+		mh.logger.Error("Failed to cancel money request", zap.Uint64("money_request_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	mh.logger.Info("Money request cancelled", zap.Uint64("money_request_id", id), zap.Int("user_id", userSess.UserID))
+	response := util.APIResponse("Money request cancelled successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
@@ -34,6 +34,11 @@ func NewOrderHandler(orderSvc port.OrderService, logger *zap.Logger) *OrderHandl
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
+//	@Param			page		query		int		false	"Page number, from 1"
+//	@Param			page_size	query		int		false	"Page size, up to 100"
+//	@Param			cursor		query		string	false	"Next cursor of the previous page"
+//	@Param			sort		query		string	false	"created_at, total_price, status or id, prefixed with - for descending"
+//	@Param			status		query		string	false	"Order status"
 //	@Success		200	{object}	util.Response	"Orders retrieved successfully"
 //	@Failure		401	{object}	util.ErrorResponse	"Unauthorized"
 //	@Failure		500	{object}	util.ErrorResponse	"Internal Server Error"
@@ -44,7 +49,14 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 
 	h.logger.Info("Fetching orders", zap.String("user_id", fmt.Sprintf("%v", userSess.UserID)))
 
-	resp, err := h.svc.ListOrders(c.Request.Context(), userSess.UserID)
+	var request dto.ListOrderRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		h.logger.Warn("Invalid request parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	resp, err := h.svc.ListOrders(c.Request.Context(), userSess.UserID, request)
 	if err != nil {
 		h.logger.Error("Failed to fetch orders", zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
 	"github.com/aldotp/ecommerce-go-api/pkg/util"
 	"github.com/gin-gonic/gin"
@@ -58,6 +59,48 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, util.APIResponse("Product created successfully", http.StatusOK, "success", nil))
 }
 
+// SearchProducts godoc
+//
+//	@Summary		Search products
+//	@Description	Full-text search on the product name and description with filters and facet counts per category and price bucket. Words match as prefixes, a query without matches falls back to similar names
+//	@Tags			Products
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			q			query		string				false	"Search words"
+//	@Param			category_id	query		int					false	"Category ID"
+//	@Param			min_price	query		number				false	"Lowest price"
+//	@Param			max_price	query		number				false	"Highest price"
+//	@Param			in_stock	query		bool				false	"Only products in stock"
+//	@Param			attr[color]	query		string				false	"Attribute filter, any attr[key]=value"
+//	@Param			sort		query		string				false	"relevance, name, price, stock, created_at or id, prefixed with - for descending"
+//	@Param			page		query		int					false	"Page number, from 1"
+//	@Param			page_size	query		int					false	"Page size, up to 100"
+//	@Param			image_size	query		string				false	"Image size: thumbnail, medium or large for a JPEG copy, original for the uploaded file"
+//	@Success		200			{object}	util.Response		"Search results"
+//	@Failure		400			{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/search [get]
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	var request dto.SearchProductRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		h.logger.Warn("Invalid request parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+	request.Attributes = c.QueryMap("attr")
+
+	result, err := h.svc.Search(c.Request.Context(), request)
+	if err != nil {
+		h.logger.Error("Failed to search products", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Search Product successfully", http.StatusOK, "success", result))
+}
+
 // GetProduct godoc
 //
 //	@Summary		Get a product by ID
@@ -65,7 +108,8 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 //	@Tags			Products
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			id	path		int	true	"Product ID"
+//	@Param			id			path		int		true	"Product ID"
+//	@Param			image_size	query		string	false	"Image size: thumbnail, medium or large for a JPEG copy, original for the uploaded file"
 //	@Success		200	{object}	util.Response	"Product found"
 //	@Failure		400	{object}	util.ErrorResponse	"Bad request"
 //	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
@@ -79,6 +123,12 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	var size dto.ImageSizeRequest
+	if err := c.ShouldBindQuery(&size); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
 	product, err := h.svc.FindOne(c.Request.Context(), request.ID)
 	if err != nil {
 		h.logger.Error("Failed to retrieve product", zap.Int("id", request.ID), zap.Error(err))
@@ -87,7 +137,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	}
 
 	h.logger.Info("Product found", zap.Int("id", request.ID))
-	c.JSON(http.StatusOK, util.APIResponse("Product found successfully", http.StatusOK, "success", dto.NewProductResponse(product)))
+	c.JSON(http.StatusOK, util.APIResponse("Product found successfully", http.StatusOK, "success", dto.NewProductResponse(product, domain.ImageSize(size.ImageSize))))
 }
 
 // ListProducts godoc
@@ -97,8 +147,15 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 //	@Tags			Products
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{object}	util.Response	"List of products"
-//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Param			page		query		int					false	"Page number, from 1"
+//	@Param			page_size	query		int					false	"Page size, up to 100"
+//	@Param			cursor		query		string				false	"Next cursor of the previous page"
+//	@Param			sort		query		string				false	"name, price, stock, created_at or id, prefixed with - for descending"
+//	@Param			search		query		string				false	"Part of the product name"
+//	@Param			image_size	query		string				false	"Image size: thumbnail, medium or large for a JPEG copy, original for the uploaded file"
+//	@Success		200			{object}	util.Response		"List of products"
+//	@Failure		400			{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
 //	@Router			/api/v1/products [get]
 func (h *ProductHandler) ListProducts(c *gin.Context) {
 	var request dto.ListProductRequest
@@ -118,7 +175,7 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 	}
 
 	h.logger.Info("Retrieved product list successfully")
-	c.JSON(http.StatusOK, util.APIResponse("List Product successfully", http.StatusOK, "success", dto.NewProductsResponse(products)))
+	c.JSON(http.StatusOK, util.APIResponse("List Product successfully", http.StatusOK, "success", products))
 }
 
 // UpdateProduct godoc
@@ -0,0 +1,303 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// multipartOverhead is the room left in an upload request for the multipart
+// headers around the image
+const multipartOverhead = 1 << 20
+
+// ProductImageHandler represents the HTTP handler for product image galleries
+type ProductImageHandler struct {
+	svc     port.ProductImageService
+	maxSize int64
+	logger  *zap.Logger
+}
+
+// NewProductImageHandler creates a new ProductImageHandler instance, maxSize
+// bounds the body of an upload request
+func NewProductImageHandler(svc port.ProductImageService, maxSize int64, logger *zap.Logger) *ProductImageHandler {
+	return &ProductImageHandler{
+		svc:     svc,
+		maxSize: maxSize,
+		logger:  logger,
+	}
+}
+
+// ListImages godoc
+//
+//	@Summary		List product images
+//	@Description	List the image gallery of a product in order
+//	@Tags			Product Images
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Product ID"
+//	@Success		200	{object}	util.Response		"Product images"
+//	@Failure		404	{object}	util.ErrorResponse	"Product not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/images [get]
+func (h *ProductImageHandler) ListImages(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	images, err := h.svc.ListImages(c.Request.Context(), param.ID)
+	if err != nil {
+		h.logger.Error("Failed to list product images", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("List Product Image successfully", http.StatusOK, "success", images))
+}
+
+// UploadImage godoc
+//
+//	@Summary		Upload a product image
+//	@Description	Upload a JPEG, PNG or WebP image to the end of the product gallery. The first image of a product becomes its primary image
+//	@Tags			Product Images
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int					true	"Product ID"
+//	@Param			image	formData	file				true	"Image file"
+//	@Success		201		{object}	util.Response		"Image uploaded"
+//	@Failure		400		{object}	util.ErrorResponse	"Missing image"
+//	@Failure		404		{object}	util.ErrorResponse	"Product not found"
+//	@Failure		413		{object}	util.ErrorResponse	"Image too large"
+//	@Failure		415		{object}	util.ErrorResponse	"Unsupported image type"
+//	@Failure		503		{object}	util.ErrorResponse	"File storage is not configured"
+//	@Router			/api/v1/products/{id}/images [post]
+func (h *ProductImageHandler) UploadImage(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			statusCode, response := helper.ErrorResponse(consts.ErrImageTooLarge)
+			c.JSON(statusCode, response)
+			return
+		}
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.logger.Error("Failed to open uploaded image", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, util.APIResponse(err.Error(), http.StatusInternalServerError, "error", nil))
+		return
+	}
+
+	image, err := h.svc.UploadImage(c.Request.Context(), param.ID, file, header.Size)
+	if err != nil {
+		h.logger.Error("Failed to upload product image", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	h.logger.Info("Product image uploaded", zap.Int("product_id", param.ID), zap.String("object_key", image.ObjectKey))
+	c.JSON(http.StatusCreated, util.APIResponse("Product image uploaded successfully", http.StatusCreated, "success", image))
+}
+
+// CreateUploadURL godoc
+//
+//	@Summary		Create a product image upload URL
+//	@Description	Get a presigned URL to PUT an image straight into the bucket. The image joins the gallery once the returned object key is confirmed
+//	@Tags			Product Images
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int									true	"Product ID"
+//	@Param			request	body		dto.ProductImageUploadURLRequest	true	"Image type and size"
+//	@Success		200		{object}	util.Response						"Upload URL"
+//	@Failure		400		{object}	util.ErrorResponse					"Validation error"
+//	@Failure		404		{object}	util.ErrorResponse					"Product not found"
+//	@Failure		413		{object}	util.ErrorResponse					"Image too large"
+//	@Failure		415		{object}	util.ErrorResponse					"Unsupported image type"
+//	@Failure		503		{object}	util.ErrorResponse					"File storage is not configured"
+//	@Router			/api/v1/products/{id}/images/upload-url [post]
+func (h *ProductImageHandler) CreateUploadURL(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.ProductImageUploadURLRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	upload, err := h.svc.CreateUploadURL(c.Request.Context(), param.ID, request)
+	if err != nil {
+		h.logger.Error("Failed to create product image upload URL", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Upload URL created successfully", http.StatusOK, "success", upload))
+}
+
+// ConfirmUpload godoc
+//
+//	@Summary		Confirm a product image upload
+//	@Description	Add an image uploaded through an upload URL to the end of the gallery. An image of the wrong type or size is removed from the bucket
+//	@Tags			Product Images
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int								true	"Product ID"
+//	@Param			request	body		dto.ConfirmProductImageRequest	true	"Uploaded object key"
+//	@Success		201		{object}	util.Response					"Image added"
+//	@Failure		400		{object}	util.ErrorResponse				"The image has not been uploaded"
+//	@Failure		404		{object}	util.ErrorResponse				"Product not found"
+//	@Failure		409		{object}	util.ErrorResponse				"The image is already in the gallery"
+//	@Failure		413		{object}	util.ErrorResponse				"Image too large"
+//	@Failure		415		{object}	util.ErrorResponse				"Unsupported image type"
+//	@Failure		503		{object}	util.ErrorResponse				"File storage is not configured"
+//	@Router			/api/v1/products/{id}/images/confirm [post]
+func (h *ProductImageHandler) ConfirmUpload(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.ConfirmProductImageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	image, err := h.svc.ConfirmUpload(c.Request.Context(), param.ID, request)
+	if err != nil {
+		h.logger.Error("Failed to confirm product image upload", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusCreated, util.APIResponse("Product image added successfully", http.StatusCreated, "success", image))
+}
+
+// ReorderImages godoc
+//
+//	@Summary		Reorder product images
+//	@Description	Put the gallery in the given order, listing every image of the product once
+//	@Tags			Product Images
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int									true	"Product ID"
+//	@Param			request	body		dto.ReorderProductImagesRequest		true	"Image IDs in gallery order"
+//	@Success		200		{object}	util.Response						"Images reordered"
+//	@Failure		400		{object}	util.ErrorResponse					"The order does not list every image once"
+//	@Failure		404		{object}	util.ErrorResponse					"Product not found"
+//	@Failure		500		{object}	util.ErrorResponse					"Internal server error"
+//	@Router			/api/v1/products/{id}/images/order [put]
+func (h *ProductImageHandler) ReorderImages(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.ReorderProductImagesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := h.svc.ReorderImages(c.Request.Context(), param.ID, request); err != nil {
+		h.logger.Error("Failed to reorder product images", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Product images reordered successfully", http.StatusOK, "success", nil))
+}
+
+// SetPrimaryImage godoc
+//
+//	@Summary		Set the primary product image
+//	@Description	Make an image the primary image of its product, shown as image_url in product responses
+//	@Tags			Product Images
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int					true	"Product ID"
+//	@Param			image_id	path		int					true	"Image ID"
+//	@Success		200			{object}	util.Response		"Primary image set"
+//	@Failure		404			{object}	util.ErrorResponse	"Image not found"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/images/{image_id}/primary [put]
+func (h *ProductImageHandler) SetPrimaryImage(c *gin.Context) {
+	var param dto.ProductImageParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := h.svc.SetPrimary(c.Request.Context(), param.ID, param.ImageID); err != nil {
+		h.logger.Error("Failed to set primary product image", zap.Int("image_id", param.ImageID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Primary image set successfully", http.StatusOK, "success", nil))
+}
+
+// DeleteImage godoc
+//
+//	@Summary		Delete a product image
+//	@Description	Remove an image from the gallery and the bucket. When it was the primary image the first remaining image takes its place
+//	@Tags			Product Images
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int					true	"Product ID"
+//	@Param			image_id	path		int					true	"Image ID"
+//	@Success		200			{object}	util.Response		"Image deleted"
+//	@Failure		404			{object}	util.ErrorResponse	"Image not found"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/images/{image_id} [delete]
+func (h *ProductImageHandler) DeleteImage(c *gin.Context) {
+	var param dto.ProductImageParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := h.svc.DeleteImage(c.Request.Context(), param.ID, param.ImageID); err != nil {
+		h.logger.Error("Failed to delete product image", zap.Int("image_id", param.ImageID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Product image deleted successfully", http.StatusOK, "success", nil))
+}
@@ -0,0 +1,301 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ProductVariantHandler represents the HTTP handler for product options and
+// variants
+type ProductVariantHandler struct {
+	svc    port.ProductVariantService
+	logger *zap.Logger
+}
+
+// NewProductVariantHandler creates a new ProductVariantHandler instance
+func NewProductVariantHandler(svc port.ProductVariantService, logger *zap.Logger) *ProductVariantHandler {
+	return &ProductVariantHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// ListOptions godoc
+//
+//	@Summary		List product options
+//	@Description	List the option types of a product, such as size or color, with their allowed values
+//	@Tags			Product Variants
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Product ID"
+//	@Success		200	{object}	util.Response		"Product options"
+//	@Failure		404	{object}	util.ErrorResponse	"Product not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/options [get]
+func (h *ProductVariantHandler) ListOptions(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	options, err := h.svc.ListOptions(c.Request.Context(), param.ID)
+	if err != nil {
+		h.logger.Error("Failed to list product options", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("List Product Option successfully", http.StatusOK, "success", options))
+}
+
+// CreateOption godoc
+//
+//	@Summary		Create a product option
+//	@Description	Add an option type to a product. Without values a variant may pick any value
+//	@Tags			Product Variants
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int							true	"Product ID"
+//	@Param			request	body		dto.ProductOptionRequest	true	"Option"
+//	@Success		201		{object}	util.Response				"Option created"
+//	@Failure		400		{object}	util.ErrorResponse			"Validation error"
+//	@Failure		404		{object}	util.ErrorResponse			"Product not found"
+//	@Failure		409		{object}	util.ErrorResponse			"The product already has an option with this name"
+//	@Failure		500		{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/products/{id}/options [post]
+func (h *ProductVariantHandler) CreateOption(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.ProductOptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	option, err := h.svc.CreateOption(c.Request.Context(), param.ID, request)
+	if err != nil {
+		h.logger.Error("Failed to create product option", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	h.logger.Info("Product option created", zap.Int("product_id", param.ID), zap.Int("option_id", option.ID))
+	c.JSON(http.StatusCreated, util.APIResponse("Product option created successfully", http.StatusCreated, "success", option))
+}
+
+// DeleteOption godoc
+//
+//	@Summary		Delete a product option
+//	@Description	Remove an option type that no variant of the product uses
+//	@Tags			Product Variants
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int					true	"Product ID"
+//	@Param			option_id	path		int					true	"Option ID"
+//	@Success		200			{object}	util.Response		"Option deleted"
+//	@Failure		404			{object}	util.ErrorResponse	"Option not found"
+//	@Failure		409			{object}	util.ErrorResponse	"The option is used by a variant"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/options/{option_id} [delete]
+func (h *ProductVariantHandler) DeleteOption(c *gin.Context) {
+	var param dto.ProductOptionParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := h.svc.DeleteOption(c.Request.Context(), param.ID, param.OptionID); err != nil {
+		h.logger.Error("Failed to delete product option", zap.Int("option_id", param.OptionID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Product option deleted successfully", http.StatusOK, "success", nil))
+}
+
+// ListVariants godoc
+//
+//	@Summary		List product variants
+//	@Description	List the variants of a product with the price and stock they sell at
+//	@Tags			Product Variants
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Product ID"
+//	@Success		200	{object}	util.Response		"Product variants"
+//	@Failure		404	{object}	util.ErrorResponse	"Product not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/variants [get]
+func (h *ProductVariantHandler) ListVariants(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	variants, err := h.svc.ListVariants(c.Request.Context(), param.ID)
+	if err != nil {
+		h.logger.Error("Failed to list product variants", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("List Product Variant successfully", http.StatusOK, "success", variants))
+}
+
+// GetVariant godoc
+//
+//	@Summary		Get a product variant
+//	@Description	Retrieve a variant of a product
+//	@Tags			Product Variants
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int					true	"Product ID"
+//	@Param			variant_id	path		int					true	"Variant ID"
+//	@Success		200			{object}	util.Response		"Product variant"
+//	@Failure		404			{object}	util.ErrorResponse	"Variant not found"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/variants/{variant_id} [get]
+func (h *ProductVariantHandler) GetVariant(c *gin.Context) {
+	var param dto.ProductVariantParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	variant, err := h.svc.GetVariant(c.Request.Context(), param.ID, param.VariantID)
+	if err != nil {
+		h.logger.Error("Failed to retrieve product variant", zap.Int("variant_id", param.VariantID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Product variant found successfully", http.StatusOK, "success", variant))
+}
+
+// CreateVariant godoc
+//
+//	@Summary		Create a product variant
+//	@Description	Add a variant with its own SKU, stock and barcode and one value for every option of the product. Without a price it sells at the product price
+//	@Tags			Product Variants
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int							true	"Product ID"
+//	@Param			request	body		dto.ProductVariantRequest	true	"Variant"
+//	@Success		201		{object}	util.Response				"Variant created"
+//	@Failure		400		{object}	util.ErrorResponse			"Validation error or options not matching the product"
+//	@Failure		404		{object}	util.ErrorResponse			"Product not found"
+//	@Failure		409		{object}	util.ErrorResponse			"SKU, barcode or options already in use"
+//	@Failure		500		{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/products/{id}/variants [post]
+func (h *ProductVariantHandler) CreateVariant(c *gin.Context) {
+	var param dto.GetProductRequest
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.ProductVariantRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	variant, err := h.svc.CreateVariant(c.Request.Context(), param.ID, request)
+	if err != nil {
+		h.logger.Error("Failed to create product variant", zap.Int("product_id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	h.logger.Info("Product variant created", zap.Int("product_id", param.ID), zap.String("sku", variant.SKU))
+	c.JSON(http.StatusCreated, util.APIResponse("Product variant created successfully", http.StatusCreated, "success", variant))
+}
+
+// UpdateVariant godoc
+//
+//	@Summary		Update a product variant
+//	@Description	Replace the SKU, price, stock, barcode and options of a variant
+//	@Tags			Product Variants
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int							true	"Product ID"
+//	@Param			variant_id	path		int							true	"Variant ID"
+//	@Param			request		body		dto.ProductVariantRequest	true	"Variant"
+//	@Success		200			{object}	util.Response				"Variant updated"
+//	@Failure		400			{object}	util.ErrorResponse			"Validation error or options not matching the product"
+//	@Failure		404			{object}	util.ErrorResponse			"Variant not found"
+//	@Failure		409			{object}	util.ErrorResponse			"SKU, barcode or options already in use"
+//	@Failure		500			{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/products/{id}/variants/{variant_id} [put]
+func (h *ProductVariantHandler) UpdateVariant(c *gin.Context) {
+	var param dto.ProductVariantParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.ProductVariantRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := h.svc.UpdateVariant(c.Request.Context(), param.ID, param.VariantID, request); err != nil {
+		h.logger.Error("Failed to update product variant", zap.Int("variant_id", param.VariantID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Product variant updated successfully", http.StatusOK, "success", nil))
+}
+
+// DeleteVariant godoc
+//
+//	@Summary		Delete a product variant
+//	@Description	Remove a variant, cart lines holding it are removed with it
+//	@Tags			Product Variants
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int					true	"Product ID"
+//	@Param			variant_id	path		int					true	"Variant ID"
+//	@Success		200			{object}	util.Response		"Variant deleted"
+//	@Failure		404			{object}	util.ErrorResponse	"Variant not found"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/products/{id}/variants/{variant_id} [delete]
+func (h *ProductVariantHandler) DeleteVariant(c *gin.Context) {
+	var param dto.ProductVariantParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := h.svc.DeleteVariant(c.Request.Context(), param.ID, param.VariantID); err != nil {
+		h.logger.Error("Failed to delete product variant", zap.Int("variant_id", param.VariantID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, util.APIResponse("Product variant deleted successfully", http.StatusOK, "success", nil))
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ScheduledTransferHandler represents the HTTP handler for scheduled wallet transfers
+type ScheduledTransferHandler struct {
+	svc    port.ScheduledTransferService
+	logger *zap.Logger
+}
+
+// NewScheduledTransferHandler creates a new ScheduledTransferHandler instance
+func NewScheduledTransferHandler(svc port.ScheduledTransferService, logger *zap.Logger) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Schedule a transfer
+//	@Description	Schedule a transfer once at a future date, or weekly or monthly from that date. Amounts at or above the OTP threshold need the inquiry_id and otp of a transfer inquiry for the same recipient and amount
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.ScheduledTransferRequest	true	"Scheduled transfer"
+//	@Success		201		{object}	util.Response					"Scheduled transfer created"
+//	@Failure		400		{object}	util.ErrorResponse				"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse				"Recipient not found"
+//	@Failure		500		{object}	util.ErrorResponse				"Internal server error"
+//	@Router			/api/v1/balance/scheduled [post]
+func (sh *ScheduledTransferHandler) Create(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.ScheduledTransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		sh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	schedule, err := sh.svc.Create(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		sh.logger.Error("Failed to schedule transfer", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	sh.logger.Info("Transfer scheduled", zap.Uint64("scheduled_transfer_id", schedule.ID), zap.Int("user_id", userSess.UserID))
+	response := util.APIResponse("Scheduled transfer created successfully", http.StatusCreated, "success", schedule)
+	c.JSON(http.StatusCreated, response)
+}
+
+// List godoc
+//
+//	@Summary		List scheduled transfers
+//	@Description	List the scheduled transfers of the authenticated user
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string				false	"Schedule status"
+//	@Success		200		{object}	util.Response		"Scheduled transfers retrieved"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/scheduled [get]
+func (sh *ScheduledTransferHandler) List(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.ListScheduledTransferRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		sh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	schedules, err := sh.svc.List(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		sh.logger.Error("Failed to list scheduled transfers", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Scheduled transfers retrieved successfully", http.StatusOK, "success", schedules)
+	c.JSON(http.StatusOK, response)
+}
+
+// Get godoc
+//
+//	@Summary		Get scheduled transfer
+//	@Description	Get a scheduled transfer of the authenticated user with the outcome of each run
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Scheduled transfer ID"
+//	@Success		200	{object}	util.Response		"Scheduled transfer retrieved"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Scheduled transfer not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/scheduled/{id} [get]
+func (sh *ScheduledTransferHandler) Get(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		sh.logger.Error("Invalid scheduled transfer ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	schedule, err := sh.svc.Get(c.Request.Context(), uint64(userSess.UserID), id)
+	if err != nil {
+		sh.logger.Error("Failed to get scheduled transfer", zap.Uint64("scheduled_transfer_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Scheduled transfer retrieved successfully", http.StatusOK, "success", schedule)
+	c.JSON(http.StatusOK, response)
+}
+
+// Update godoc
+//
+//	@Summary		Update scheduled transfer
+//	@Description	Change the amount, cadence or dates of a schedule, or pause and resume it. A new amount at or above the OTP threshold needs the inquiry_id and otp of a transfer inquiry
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int									true	"Scheduled transfer ID"
+//	@Param			request	body		dto.UpdateScheduledTransferRequest	true	"Scheduled transfer changes"
+//	@Success		200		{object}	util.Response						"Scheduled transfer updated"
+//	@Failure		400		{object}	util.ErrorResponse					"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse					"Scheduled transfer not found"
+//	@Failure		409		{object}	util.ErrorResponse					"Scheduled transfer already closed"
+//	@Router			/api/v1/balance/scheduled/{id} [put]
+func (sh *ScheduledTransferHandler) Update(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		sh.logger.Error("Invalid scheduled transfer ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.UpdateScheduledTransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		sh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	schedule, err := sh.svc.Update(c.Request.Context(), uint64(userSess.UserID), id, request)
+	if err != nil {
+		sh.logger.Error("Failed to update scheduled transfer", zap.Uint64("scheduled_transfer_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Scheduled transfer updated successfully", http.StatusOK, "success", schedule)
+	c.JSON(http.StatusOK, response)
+}
+
+// Cancel godoc
+//
+//	@Summary		Cancel scheduled transfer
+//	@Description	Cancel a schedule so it never runs again
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Scheduled transfer ID"
+//	@Success		200	{object}	util.Response		"Scheduled transfer cancelled"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Scheduled transfer not found"
+//	@Failure		409	{object}	util.ErrorResponse	"Scheduled transfer already closed"
+//	@Router			/api/v1/balance/scheduled/{id} [delete]
+func (sh *ScheduledTransferHandler) Cancel(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		sh.logger.Error("Invalid scheduled transfer ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := sh.svc.Cancel(c.Request.Context(), uint64(userSess.UserID), id); err != nil {
+		sh.logger.Error("Failed to cancel scheduled transfer", zap.Uint64("scheduled_transfer_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	sh.logger.Info("Scheduled transfer cancelled", zap.Uint64("scheduled_transfer_id", id), zap.Int("user_id", userSess.UserID))
+	response := util.APIResponse("Scheduled transfer cancelled successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SettingHandler represents the HTTP handler for reading runtime settings
+type SettingHandler struct {
+	rules  *config.Business
+	logger *zap.Logger
+}
+
+// NewSettingHandler creates a new SettingHandler instance
+func NewSettingHandler(rules *config.Business, logger *zap.Logger) *SettingHandler {
+	return &SettingHandler{
+		rules:  rules,
+		logger: logger,
+	}
+}
+
+// GetBusinessRules godoc
+//
+//	@Summary		Get business rules
+//	@Description	Retrieve the effective business rules, including per payment method overrides
+//	@Tags			Settings
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	util.Response	"Business rules retrieved"
+//	@Failure		401	{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		403	{object}	util.ErrorResponse	"Forbidden error"
+//	@Router			/api/v1/settings/business-rules [get]
+func (h *SettingHandler) GetBusinessRules(c *gin.Context) {
+	h.logger.Info("Business rules retrieved")
+	response := util.APIResponse("Business rules retrieved successfully", http.StatusOK, "success", dto.NewBusinessRulesResponse(h.rules))
+	c.JSON(http.StatusOK, response)
+}
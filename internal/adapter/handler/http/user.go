@@ -51,6 +51,7 @@ func (uh *UserHandler) Register(c *gin.Context) {
 	user := domain.User{
 		Name:     request.Name,
 		Email:    request.Email,
+		Phone:    request.Phone,
 		Password: request.Password,
 	}
 
@@ -75,8 +76,12 @@ func (uh *UserHandler) Register(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200		{object}	util.Response		"Users displayed"
-//	@Failure		400		{object}	util.ErrorResponse	"Validation error"
+//	@Param			page		query		int					false	"Page number, from 1"
+//	@Param			page_size	query		int					false	"Page size, up to 100"
+//	@Param			cursor		query		string				false	"Next cursor of the previous page"
+//	@Param			sort		query		string				false	"name, email, created_at or id, prefixed with - for descending"
+//	@Success		200			{object}	util.Response		"Users displayed"
+//	@Failure		400			{object}	util.ErrorResponse	"Validation error"
 //	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
 //	@Router			/api/v1/users [get]
 func (uh *UserHandler) ListUsers(c *gin.Context) {
@@ -87,7 +92,7 @@ func (uh *UserHandler) ListUsers(c *gin.Context) {
 		return
 	}
 
-	users, err := uh.svc.ListUsers(c.Request.Context(), request.Page, request.Limit)
+	users, err := uh.svc.ListUsers(c.Request.Context(), request)
 	if err != nil {
 		uh.logger.Error("Failed to list users", zap.Error(err))
 		statusCode, response := helper.ErrorResponse(err)
@@ -213,6 +218,7 @@ func (uh *UserHandler) UpdateUser(c *gin.Context) {
 		ID:       id,
 		Name:     request.Name,
 		Email:    request.Email,
+		Phone:    request.Phone,
 		Password: request.Password,
 		Role:     request.Role,
 	}
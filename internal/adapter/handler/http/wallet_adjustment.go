@@ -0,0 +1,223 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WalletAdjustmentHandler represents the HTTP handler for admin wallet adjustments
+type WalletAdjustmentHandler struct {
+	svc    port.WalletAdjustmentService
+	logger *zap.Logger
+}
+
+// NewWalletAdjustmentHandler creates a new WalletAdjustmentHandler instance
+func NewWalletAdjustmentHandler(svc port.WalletAdjustmentService, logger *zap.Logger) *WalletAdjustmentHandler {
+	return &WalletAdjustmentHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Adjust a wallet
+//	@Description	Credit or debit a user's wallet with a reason code and note, large adjustments wait for a second admin
+//	@Tags			Transactions
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.WalletAdjustmentRequest	true	"Wallet adjustment"
+//	@Success		201		{object}	util.Response				"Wallet adjustment created"
+//	@Failure		400		{object}	util.ErrorResponse			"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse			"User not found"
+//	@Failure		409		{object}	util.ErrorResponse			"Wallet is locked by another transaction"
+//	@Failure		500		{object}	util.ErrorResponse			"Internal server error"
+//	@Router			/api/v1/balance/adjustments [post]
+func (ah *WalletAdjustmentHandler) Create(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.WalletAdjustmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	adjustment, err := ah.svc.Create(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		ah.logger.Error("Failed to adjust wallet", zap.Uint64("user_id", request.UserID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	ah.logger.Info("Wallet adjustment created",
+		zap.Uint64("wallet_adjustment_id", adjustment.ID),
+		zap.Uint64("user_id", adjustment.UserID),
+		zap.String("status", string(adjustment.Status)),
+		zap.Int("admin_id", userSess.UserID),
+	)
+	response := util.APIResponse("Wallet adjustment created successfully", http.StatusCreated, "success", adjustment)
+	c.JSON(http.StatusCreated, response)
+}
+
+// List godoc
+//
+//	@Summary		List wallet adjustments
+//	@Description	List wallet adjustments filtered by user, admin, direction, reason, status and date
+//	@Tags			Transactions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			user_id			query		int					false	"Adjusted user ID"
+//	@Param			requested_by	query		int					false	"Requesting admin ID"
+//	@Param			direction		query		string				false	"credit or debit"
+//	@Param			reason_code		query		string				false	"Reason code"
+//	@Param			status			query		string				false	"Adjustment status"
+//	@Param			from			query		string				false	"Start date (YYYY-MM-DD)"
+//	@Param			to				query		string				false	"End date (YYYY-MM-DD)"
+//	@Success		200				{object}	util.Response		"Wallet adjustments retrieved"
+//	@Failure		400				{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		500				{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/adjustments [get]
+func (ah *WalletAdjustmentHandler) List(c *gin.Context) {
+	var request dto.ListWalletAdjustmentRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		ah.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	adjustments, err := ah.svc.List(c.Request.Context(), request)
+	if err != nil {
+		ah.logger.Error("Failed to list wallet adjustments", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wallet adjustments retrieved successfully", http.StatusOK, "success", adjustments)
+	c.JSON(http.StatusOK, response)
+}
+
+// Get godoc
+//
+//	@Summary		Get wallet adjustment
+//	@Description	Get a wallet adjustment with the admins who requested and reviewed it
+//	@Tags			Transactions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Wallet adjustment ID"
+//	@Success		200	{object}	util.Response		"Wallet adjustment retrieved"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Wallet adjustment not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/adjustments/{id} [get]
+func (ah *WalletAdjustmentHandler) Get(c *gin.Context) {
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		ah.logger.Error("Invalid wallet adjustment ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	adjustment, err := ah.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		ah.logger.Error("Failed to get wallet adjustment", zap.Uint64("wallet_adjustment_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wallet adjustment retrieved successfully", http.StatusOK, "success", adjustment)
+	c.JSON(http.StatusOK, response)
+}
+
+// Approve godoc
+//
+//	@Summary		Approve wallet adjustment
+//	@Description	Apply a pending wallet adjustment, it must be approved by an admin other than its requester
+//	@Tags			Transactions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Wallet adjustment ID"
+//	@Success		200	{object}	util.Response		"Wallet adjustment applied"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		403	{object}	util.ErrorResponse	"Requester can't approve their own adjustment"
+//	@Failure		404	{object}	util.ErrorResponse	"Wallet adjustment not found"
+//	@Failure		409	{object}	util.ErrorResponse	"Wallet adjustment already closed"
+//	@Router			/api/v1/balance/adjustments/{id}/approve [post]
+func (ah *WalletAdjustmentHandler) Approve(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		ah.logger.Error("Invalid wallet adjustment ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	adjustment, err := ah.svc.Approve(c.Request.Context(), uint64(userSess.UserID), id)
+	if err != nil {
+		ah.logger.Error("Failed to approve wallet adjustment", zap.Uint64("wallet_adjustment_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	ah.logger.Info("Wallet adjustment applied", zap.Uint64("wallet_adjustment_id", id), zap.Int("admin_id", userSess.UserID))
+	response := util.APIResponse("Wallet adjustment applied successfully", http.StatusOK, "success", adjustment)
+	c.JSON(http.StatusOK, response)
+}
+
+// Reject godoc
+//
+//	@Summary		Reject wallet adjustment
+//	@Description	Reject a pending wallet adjustment, the wallet is left untouched
+//	@Tags			Transactions
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int									true	"Wallet adjustment ID"
+//	@Param			request	body		dto.RejectWalletAdjustmentRequest	true	"Rejection reason"
+//	@Success		200		{object}	util.Response						"Wallet adjustment rejected"
+//	@Failure		400		{object}	util.ErrorResponse					"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse					"Wallet adjustment not found"
+//	@Failure		409		{object}	util.ErrorResponse					"Wallet adjustment already closed"
+//	@Router			/api/v1/balance/adjustments/{id}/reject [post]
+func (ah *WalletAdjustmentHandler) Reject(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		ah.logger.Error("Invalid wallet adjustment ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.RejectWalletAdjustmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		ah.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	adjustment, err := ah.svc.Reject(c.Request.Context(), uint64(userSess.UserID), id, request)
+	if err != nil {
+		ah.logger.Error("Failed to reject wallet adjustment", zap.Uint64("wallet_adjustment_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	ah.logger.Info("Wallet adjustment rejected", zap.Uint64("wallet_adjustment_id", id), zap.Int("admin_id", userSess.UserID))
+	response := util.APIResponse("Wallet adjustment rejected successfully", http.StatusOK, "success", adjustment)
+	c.JSON(http.StatusOK, response)
+}
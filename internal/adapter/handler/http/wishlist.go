@@ -0,0 +1,467 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WishlistHandler represents the HTTP handler for wishlist-related requests
+type WishlistHandler struct {
+	svc    port.WishlistService
+	logger *zap.Logger
+}
+
+// NewWishlistHandler creates a new WishlistHandler instance
+func NewWishlistHandler(svc port.WishlistService, logger *zap.Logger) *WishlistHandler {
+	return &WishlistHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// ListWishlists godoc
+//
+//	@Summary		List wishlists
+//	@Description	List the wishlists of the authenticated user with their number of items
+//	@Tags			Wishlists
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	util.Response		"Wishlists retrieved"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists [get]
+func (wh *WishlistHandler) ListWishlists(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	wishlists, err := wh.svc.List(c.Request.Context(), userSess.UserID)
+	if err != nil {
+		wh.logger.Error("Failed to list wishlists", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlists retrieved successfully", http.StatusOK, "success", wishlists)
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateWishlist godoc
+//
+//	@Summary		Create a wishlist
+//	@Description	Create a named wishlist, names are unique per user
+//	@Tags			Wishlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.WishlistRequest	true	"Wishlist"
+//	@Success		201		{object}	util.Response		"Wishlist created"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		409		{object}	util.ErrorResponse	"Name already used"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists [post]
+func (wh *WishlistHandler) CreateWishlist(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.WishlistRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		wh.logger.Warn("Invalid wishlist payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	wishlist, err := wh.svc.Create(c.Request.Context(), userSess.UserID, request)
+	if err != nil {
+		wh.logger.Error("Failed to create wishlist", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlist created successfully", http.StatusCreated, "success", wishlist)
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetWishlist godoc
+//
+//	@Summary		Get a wishlist
+//	@Description	Get a wishlist of the authenticated user with its products at their current price
+//	@Tags			Wishlists
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Wishlist ID"
+//	@Success		200	{object}	util.Response		"Wishlist retrieved"
+//	@Failure		404	{object}	util.ErrorResponse	"Wishlist not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists/{id} [get]
+func (wh *WishlistHandler) GetWishlist(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var param dto.WishlistParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist ID", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	wishlist, err := wh.svc.Get(c.Request.Context(), userSess.UserID, param.ID)
+	if err != nil {
+		wh.logger.Error("Failed to get wishlist", zap.Int("id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlist retrieved successfully", http.StatusOK, "success", wishlist)
+	c.JSON(http.StatusOK, response)
+}
+
+// RenameWishlist godoc
+//
+//	@Summary		Rename a wishlist
+//	@Tags			Wishlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int					true	"Wishlist ID"
+//	@Param			request	body		dto.WishlistRequest	true	"Wishlist"
+//	@Success		200		{object}	util.Response		"Wishlist renamed"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse	"Wishlist not found"
+//	@Failure		409		{object}	util.ErrorResponse	"Name already used"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists/{id} [put]
+func (wh *WishlistHandler) RenameWishlist(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var (
+		param   dto.WishlistParam
+		request dto.WishlistRequest
+	)
+
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist ID", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		wh.logger.Warn("Invalid wishlist payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := wh.svc.Rename(c.Request.Context(), userSess.UserID, param.ID, request); err != nil {
+		wh.logger.Error("Failed to rename wishlist", zap.Int("id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlist renamed successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteWishlist godoc
+//
+//	@Summary		Delete a wishlist
+//	@Description	Delete a wishlist together with its items
+//	@Tags			Wishlists
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Wishlist ID"
+//	@Success		200	{object}	util.Response		"Wishlist deleted"
+//	@Failure		404	{object}	util.ErrorResponse	"Wishlist not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists/{id} [delete]
+func (wh *WishlistHandler) DeleteWishlist(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var param dto.WishlistParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist ID", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := wh.svc.Delete(c.Request.Context(), userSess.UserID, param.ID); err != nil {
+		wh.logger.Error("Failed to delete wishlist", zap.Int("id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlist deleted successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// AddItem godoc
+//
+//	@Summary		Save a product in a wishlist
+//	@Description	Save a product at its current price, saving it again keeps the first price
+//	@Tags			Wishlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"Wishlist ID"
+//	@Param			request	body		dto.WishlistItemRequest	true	"Product"
+//	@Success		200		{object}	util.Response			"Product saved"
+//	@Failure		400		{object}	util.ErrorResponse		"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse		"Wishlist or product not found"
+//	@Failure		500		{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/wishlists/{id}/items [post]
+func (wh *WishlistHandler) AddItem(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var (
+		param   dto.WishlistParam
+		request dto.WishlistItemRequest
+	)
+
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist ID", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		wh.logger.Warn("Invalid wishlist item payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := wh.svc.AddItem(c.Request.Context(), userSess.UserID, param.ID, request.ProductID); err != nil {
+		wh.logger.Error("Failed to save product in wishlist", zap.Int("id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Product saved successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveItem godoc
+//
+//	@Summary		Remove a product from a wishlist
+//	@Tags			Wishlists
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int					true	"Wishlist ID"
+//	@Param			product_id	path		int					true	"Product ID"
+//	@Success		200			{object}	util.Response		"Product removed"
+//	@Failure		404			{object}	util.ErrorResponse	"Wishlist or product not found"
+//	@Failure		500			{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists/{id}/items/{product_id} [delete]
+func (wh *WishlistHandler) RemoveItem(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var param dto.WishlistItemParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist item", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := wh.svc.RemoveItem(c.Request.Context(), userSess.UserID, param.ID, param.ProductID); err != nil {
+		wh.logger.Error("Failed to remove product from wishlist", zap.Int("id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Product removed successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// MoveToCart godoc
+//
+//	@Summary		Move a saved product to the cart
+//	@Description	Add a saved product to the cart, one unit unless a quantity is sent, and take it out of the wishlist
+//	@Tags			Wishlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int						true	"Wishlist ID"
+//	@Param			product_id	path		int						true	"Product ID"
+//	@Param			request		body		dto.MoveToCartRequest	false	"Quantity"
+//	@Success		200			{object}	util.Response			"Product moved to the cart"
+//	@Failure		400			{object}	util.ErrorResponse		"Invalid request or not enough stock"
+//	@Failure		404			{object}	util.ErrorResponse		"Wishlist or product not found"
+//	@Failure		500			{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/wishlists/{id}/items/{product_id}/move-to-cart [post]
+func (wh *WishlistHandler) MoveToCart(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var (
+		param   dto.WishlistItemParam
+		request dto.MoveToCartRequest
+	)
+
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist item", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	// the body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			wh.logger.Warn("Invalid move to cart payload", zap.Error(err))
+			c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+			return
+		}
+	}
+
+	if err := wh.svc.MoveToCart(c.Request.Context(), userSess.UserID, param.ID, param.ProductID, request.VariantID, request.Quantity); err != nil {
+		wh.logger.Error("Failed to move product to cart", zap.Int("id", param.ID), zap.Int("product_id", param.ProductID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Product moved to the cart successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// SaveForLater godoc
+//
+//	@Summary		Save a cart line for later
+//	@Description	Move a product from the cart to a wishlist, the default "Saved for later" list unless a wishlist is given
+//	@Tags			Wishlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.SaveForLaterRequest	true	"Cart line"
+//	@Success		200		{object}	util.Response			"Product saved for later"
+//	@Failure		400		{object}	util.ErrorResponse		"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse		"Product not in the cart or wishlist not found"
+//	@Failure		500		{object}	util.ErrorResponse		"Internal server error"
+//	@Router			/api/v1/wishlists/save-for-later [post]
+func (wh *WishlistHandler) SaveForLater(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.SaveForLaterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		wh.logger.Warn("Invalid save for later payload", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	wishlist, err := wh.svc.SaveForLater(c.Request.Context(), userSess.UserID, request)
+	if err != nil {
+		wh.logger.Error("Failed to save cart line for later", zap.Int("product_id", request.ProductID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Product saved for later successfully", http.StatusOK, "success", wishlist)
+	c.JSON(http.StatusOK, response)
+}
+
+// ShareWishlist godoc
+//
+//	@Summary		Share a wishlist
+//	@Description	Create a read-only share link for a wishlist, a shared wishlist keeps its link
+//	@Tags			Wishlists
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Wishlist ID"
+//	@Success		200	{object}	util.Response		"Share token"
+//	@Failure		404	{object}	util.ErrorResponse	"Wishlist not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists/{id}/share [post]
+func (wh *WishlistHandler) ShareWishlist(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var param dto.WishlistParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist ID", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	token, err := wh.svc.Share(c.Request.Context(), userSess.UserID, param.ID)
+	if err != nil {
+		wh.logger.Error("Failed to share wishlist", zap.Int("id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlist shared successfully", http.StatusOK, "success", gin.H{
+		"share_token": token,
+		"share_path":  "/api/v1/wishlists/shared/" + token,
+	})
+	c.JSON(http.StatusOK, response)
+}
+
+// UnshareWishlist godoc
+//
+//	@Summary		Stop sharing a wishlist
+//	@Description	Revoke the share link of a wishlist
+//	@Tags			Wishlists
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Wishlist ID"
+//	@Success		200	{object}	util.Response		"Sharing stopped"
+//	@Failure		404	{object}	util.ErrorResponse	"Wishlist not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists/{id}/share [delete]
+func (wh *WishlistHandler) UnshareWishlist(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var param dto.WishlistParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid wishlist ID", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := wh.svc.Unshare(c.Request.Context(), userSess.UserID, param.ID); err != nil {
+		wh.logger.Error("Failed to stop sharing wishlist", zap.Int("id", param.ID), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlist is no longer shared", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSharedWishlist godoc
+//
+//	@Summary		View a shared wishlist
+//	@Description	Read-only view of a wishlist through its share link, no login needed
+//	@Tags			Wishlists
+//	@Produce		json
+//	@Param			token	path		string				true	"Share token"
+//	@Success		200		{object}	util.Response		"Wishlist retrieved"
+//	@Failure		404		{object}	util.ErrorResponse	"Wishlist not found or no longer shared"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/wishlists/shared/{token} [get]
+func (wh *WishlistHandler) GetSharedWishlist(c *gin.Context) {
+	var param dto.SharedWishlistParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		wh.logger.Warn("Invalid share token", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	wishlist, err := wh.svc.GetShared(c.Request.Context(), param.Token)
+	if err != nil {
+		wh.logger.Error("Failed to get shared wishlist", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Wishlist retrieved successfully", http.StatusOK, "success", wishlist)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,316 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/helper"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithdrawalHandler represents the HTTP handler for payout destinations and withdrawals
+type WithdrawalHandler struct {
+	svc    port.WithdrawalService
+	logger *zap.Logger
+}
+
+// NewWithdrawalHandler creates a new WithdrawalHandler instance
+func NewWithdrawalHandler(svc port.WithdrawalService, logger *zap.Logger) *WithdrawalHandler {
+	return &WithdrawalHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// AddDestination godoc
+//
+//	@Summary		Add payout destination
+//	@Description	Save a bank account the authenticated user can withdraw to
+//	@Tags			Balance
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.PayoutDestinationRequest	true	"Payout destination"
+//	@Success		201		{object}	util.Response					"Payout destination created"
+//	@Failure		400		{object}	util.ErrorResponse				"Invalid request parameters"
+//	@Failure		401		{object}	util.ErrorResponse				"Unauthorized error"
+//	@Failure		500		{object}	util.ErrorResponse				"Internal server error"
+//	@Router			/api/v1/balance/destinations [post]
+func (wh *WithdrawalHandler) AddDestination(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.PayoutDestinationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		wh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	destination, err := wh.svc.AddDestination(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		wh.logger.Error("Failed to add payout destination", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Payout destination created successfully", http.StatusCreated, "success", destination)
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListDestinations godoc
+//
+//	@Summary		List payout destinations
+//	@Description	List the saved bank accounts of the authenticated user
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	util.Response		"Payout destinations retrieved"
+//	@Failure		401	{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/destinations [get]
+func (wh *WithdrawalHandler) ListDestinations(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	destinations, err := wh.svc.ListDestinations(c.Request.Context(), uint64(userSess.UserID))
+	if err != nil {
+		wh.logger.Error("Failed to list payout destinations", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Payout destinations retrieved successfully", http.StatusOK, "success", destinations)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteDestination godoc
+//
+//	@Summary		Delete payout destination
+//	@Description	Delete a saved bank account of the authenticated user
+//	@Tags			Balance
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Payout destination ID"
+//	@Success		200	{object}	util.Response		"Payout destination deleted"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Payout destination not found"
+//	@Failure		500	{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/destinations/{id} [delete]
+func (wh *WithdrawalHandler) DeleteDestination(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		wh.logger.Error("Invalid payout destination ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	if err := wh.svc.DeleteDestination(c.Request.Context(), uint64(userSess.UserID), id); err != nil {
+		wh.logger.Error("Failed to delete payout destination", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Payout destination deleted successfully", http.StatusOK, "success", nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// Withdraw godoc
+//
+//	@Summary		Withdraw balance
+//	@Description	Request a withdrawal to a saved payout destination, the amount is held until an admin reviews it
+//	@Tags			Transactions
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.WithdrawRequest	true	"Withdraw request"
+//	@Success		201		{object}	util.Response		"Withdrawal requested"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		404		{object}	util.ErrorResponse	"Payout destination not found"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/withdraw [post]
+func (wh *WithdrawalHandler) Withdraw(c *gin.Context) {
+	var request dto.WithdrawRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		wh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+	if userSess.UserID == 0 {
+		wh.logger.Error("Unauthorized request")
+		c.JSON(http.StatusUnauthorized, util.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil))
+		return
+	}
+
+	withdrawal, err := wh.svc.Withdraw(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		wh.logger.Error("Failed to withdraw", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	wh.logger.Info("Withdrawal requested", zap.Uint64("user_id", uint64(userSess.UserID)), zap.Uint64("withdrawal_id", withdrawal.ID), zap.Float64("amount", request.Amount))
+	response := util.APIResponse("Withdrawal requested successfully", http.StatusCreated, "success", withdrawal)
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListWithdrawals godoc
+//
+//	@Summary		List withdrawals
+//	@Description	List the withdrawal requests of the authenticated user
+//	@Tags			Transactions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string				false	"Withdrawal status"	Enums(requested, approved, processing, paid, rejected)
+//	@Success		200		{object}	util.Response		"Withdrawals retrieved"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		401		{object}	util.ErrorResponse	"Unauthorized error"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/withdrawals [get]
+func (wh *WithdrawalHandler) ListWithdrawals(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	var request dto.ListWithdrawalRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		wh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	withdrawals, err := wh.svc.ListWithdrawals(c.Request.Context(), uint64(userSess.UserID), request)
+	if err != nil {
+		wh.logger.Error("Failed to list withdrawals", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Withdrawals retrieved successfully", http.StatusOK, "success", withdrawals)
+	c.JSON(http.StatusOK, response)
+}
+
+// ListAllWithdrawals godoc
+//
+//	@Summary		List all withdrawals
+//	@Description	List the withdrawal requests of every user for review
+//	@Tags			Transactions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string				false	"Withdrawal status"	Enums(requested, approved, processing, paid, rejected)
+//	@Success		200		{object}	util.Response		"Withdrawals retrieved"
+//	@Failure		400		{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		403		{object}	util.ErrorResponse	"Forbidden error"
+//	@Failure		500		{object}	util.ErrorResponse	"Internal server error"
+//	@Router			/api/v1/balance/withdrawals/all [get]
+func (wh *WithdrawalHandler) ListAllWithdrawals(c *gin.Context) {
+	var request dto.ListWithdrawalRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		wh.logger.Warn("Invalid query parameters", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	withdrawals, err := wh.svc.ListAllWithdrawals(c.Request.Context(), request)
+	if err != nil {
+		wh.logger.Error("Failed to list withdrawals", zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	response := util.APIResponse("Withdrawals retrieved successfully", http.StatusOK, "success", withdrawals)
+	c.JSON(http.StatusOK, response)
+}
+
+// Approve godoc
+//
+//	@Summary		Approve withdrawal
+//	@Description	Approve a withdrawal request and pay it out, approving an approved withdrawal retries a failed payout. A payout in progress cannot be approved again
+//	@Tags			Transactions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Withdrawal ID"
+//	@Success		200	{object}	util.Response		"Withdrawal paid"
+//	@Failure		400	{object}	util.ErrorResponse	"Invalid request parameters"
+//	@Failure		404	{object}	util.ErrorResponse	"Withdrawal not found"
+//	@Failure		409	{object}	util.ErrorResponse	"Withdrawal already closed"
+//	@Failure		502	{object}	util.ErrorResponse	"Payout failed"
+//	@Router			/api/v1/balance/withdrawals/{id}/approve [post]
+func (wh *WithdrawalHandler) Approve(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		wh.logger.Error("Invalid withdrawal ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	withdrawal, err := wh.svc.Approve(c.Request.Context(), uint64(userSess.UserID), id)
+	if err != nil {
+		wh.logger.Error("Failed to approve withdrawal", zap.Uint64("withdrawal_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	wh.logger.Info("Withdrawal paid", zap.Uint64("withdrawal_id", id), zap.Int("admin_id", userSess.UserID))
+	response := util.APIResponse("Withdrawal paid successfully", http.StatusOK, "success", withdrawal)
+	c.JSON(http.StatusOK, response)
+}
+
+// Reject godoc
+//
+//	@Summary		Reject withdrawal
+//	@Description	Reject a requested withdrawal, or an approved one whose payout failed, and release the held funds
+//	@Tags			Transactions
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int								true	"Withdrawal ID"
+//	@Param			request	body		dto.RejectWithdrawalRequest	true	"Rejection reason"
+//	@Success		200		{object}	util.Response					"Withdrawal rejected"
+//	@Failure		400		{object}	util.ErrorResponse				"Invalid request parameters"
+//	@Failure		404		{object}	util.ErrorResponse				"Withdrawal not found"
+//	@Failure		409		{object}	util.ErrorResponse				"Withdrawal already closed"
+//	@Router			/api/v1/balance/withdrawals/{id}/reject [post]
+func (wh *WithdrawalHandler) Reject(c *gin.Context) {
+	userSess := util.GetAuthPayload(c, consts.AuthorizationKey)
+
+	id, err := util.StringToUint64(c.Param("id"))
+	if err != nil {
+		wh.logger.Error("Invalid withdrawal ID in request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	var request dto.RejectWithdrawalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		wh.logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, util.APIResponse(err.Error(), http.StatusBadRequest, "error", nil))
+		return
+	}
+
+	withdrawal, err := wh.svc.Reject(c.Request.Context(), uint64(userSess.UserID), id, request)
+	if err != nil {
+		wh.logger.Error("Failed to reject withdrawal", zap.Uint64("withdrawal_id", id), zap.Error(err))
+		statusCode, response := helper.ErrorResponse(err)
+		c.JSON(statusCode, response)
+		return
+	}
+
+	wh.logger.Info("Withdrawal rejected", zap.Uint64("withdrawal_id", id), zap.Int("admin_id", userSess.UserID))
+	response := util.APIResponse("Withdrawal rejected successfully", http.StatusOK, "success", withdrawal)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/internal/core/service"
+	"go.uber.org/zap"
+)
+
+type CartReminderWorker struct {
+	log *zap.Logger
+	svc port.CartReminderService
+}
+
+func NewCartReminderWorker(b *bootstrap.Bootstrap) *CartReminderWorker {
+	return &CartReminderWorker{
+		log: b.Log,
+		svc: service.NewCartReminderService(b.CartReminderRepo, b.CartItemRepo, b.VariantRepo, b.Email, b.Config.Business, config.CartTokenSecret(), config.PublicURL(), b.Log),
+	}
+}
+
+func (w *CartReminderWorker) Run() {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.sendReminders()
+		}
+	}
+}
+
+func (w *CartReminderWorker) sendReminders() {
+	ctx := context.Background()
+
+	sent, err := w.svc.SendReminders(ctx, time.Now())
+	if err != nil {
+		w.log.Error("Error sending cart reminders", zap.Error(err))
+		return
+	}
+
+	if sent > 0 {
+		w.log.Info("Cart reminders sent", zap.Int("count", sent))
+	}
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/redis"
+	"go.uber.org/zap"
+)
+
+type CartWriteBehindWorker struct {
+	log         *zap.Logger
+	writeBehind *redis.CartWriteBehind
+	interval    time.Duration
+	batch       int
+}
+
+func NewCartWriteBehindWorker(b *bootstrap.Bootstrap) *CartWriteBehindWorker {
+	return &CartWriteBehindWorker{
+		log:         b.Log,
+		writeBehind: b.CartWriteBehind,
+		interval:    config.CartWriteBehindInterval(),
+		batch:       config.CartWriteBehindBatch(),
+	}
+}
+
+func (w *CartWriteBehindWorker) Run() {
+	if w.writeBehind == nil {
+		w.log.Warn("Cart write-behind needs CART_BACKEND=redis, nothing to copy")
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flushCarts()
+		}
+	}
+}
+
+// flushCarts copies batches until the queue of changed carts is empty
+func (w *CartWriteBehindWorker) flushCarts() {
+	ctx := context.Background()
+
+	total := 0
+	for {
+		flushed, err := w.writeBehind.Flush(ctx, w.batch)
+		total += flushed
+		if err != nil {
+			w.log.Error("Error copying carts to Postgres", zap.Error(err))
+			break
+		}
+
+		if flushed < w.batch {
+			break
+		}
+	}
+
+	if total > 0 {
+		w.log.Info("Carts copied to Postgres", zap.Int("count", total))
+	}
+}
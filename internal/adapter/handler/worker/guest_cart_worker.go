@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/internal/core/service"
+	"go.uber.org/zap"
+)
+
+type GuestCartWorker struct {
+	log *zap.Logger
+	svc port.CartService
+}
+
+func NewGuestCartWorker(b *bootstrap.Bootstrap) *GuestCartWorker {
+	return &GuestCartWorker{
+		log: b.Log,
+		svc: service.NewCartService(b.CartItemRepo, b.CartRepo, b.OrderRepo, b.OrderItemRepo, b.ProductRepo, b.VariantRepo, b.Config.Business, config.CartTokenSecret()),
+	}
+}
+
+func (w *GuestCartWorker) Run() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.cleanupExpiredCarts()
+		}
+	}
+}
+
+func (w *GuestCartWorker) cleanupExpiredCarts() {
+	ctx := context.Background()
+
+	removed, err := w.svc.CleanupGuestCarts(ctx, time.Now())
+	if err != nil {
+		w.log.Error("Error removing expired guest carts", zap.Error(err))
+		return
+	}
+
+	w.log.Info("Expired guest carts removed", zap.Int64("count", removed))
+}
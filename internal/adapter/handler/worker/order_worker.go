@@ -28,7 +28,7 @@ func NewOrderWorker(b *bootstrap.Bootstrap) *worker {
 		log:             b.Log,
 		rabbitMqService: b.RabbitMQ,
 		orderSvc:        service.NewOrderService(b.PaymentRepo, b.OrderRepo),
-		productSvc:      service.NewProductService(b.ProductRepo, b.Cache),
+		productSvc:      service.NewProductService(b.ProductRepo, b.Cache, b.WishlistRepo, b.ImageRepo, b.Storage, b.RabbitMQ, b.Log),
 	}
 }
 
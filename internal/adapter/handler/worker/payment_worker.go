@@ -8,6 +8,7 @@ import (
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/internal/core/service"
 )
 
 type PaymentWorker struct {
@@ -15,6 +16,8 @@ type PaymentWorker struct {
 	OrderRepo     port.OrderRepository
 	OrderItemRepo port.OrderItemRepository
 	ProductRepo   port.ProductRepository
+	VariantRepo   port.ProductVariantRepository
+	BalanceRepo   port.BalanceRepository
 }
 
 func NewPaymentWorker(b *bootstrap.Bootstrap) *PaymentWorker {
@@ -23,6 +26,8 @@ func NewPaymentWorker(b *bootstrap.Bootstrap) *PaymentWorker {
 		OrderRepo:     b.OrderRepo,
 		OrderItemRepo: b.OrderItemRepo,
 		ProductRepo:   b.ProductRepo,
+		VariantRepo:   b.VariantRepo,
+		BalanceRepo:   b.BalanceRepo,
 	}
 }
 
@@ -71,17 +76,15 @@ func (w *PaymentWorker) ProcessExpiredPayments(ctx context.Context, payment *dom
 	}
 
 	for _, item := range items {
-		product, err := w.ProductRepo.FindOne(ctx, item.ProductID)
-		if err != nil {
-			log.Println("Error finding product:", err)
-
+		if err := service.RestockOrderItem(ctx, w.ProductRepo, w.VariantRepo, item); err != nil {
+			log.Println("Error updating stock:", err)
 		}
+	}
 
-		newStock := product.Stock + item.Quantity
-		err = w.ProductRepo.UpdateStock(ctx, item.ProductID, newStock)
+	if payment.PaymentMethod == "balance" {
+		err = w.BalanceRepo.ReleaseHold(ctx, payment.OrderID)
 		if err != nil {
-			log.Println("Error updating stock:", err)
-
+			log.Println("Error releasing held balance:", err)
 		}
 	}
 
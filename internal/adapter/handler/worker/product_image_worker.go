@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/rabbitmq"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/internal/core/service"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+type ProductImageWorker struct {
+	log             *zap.Logger
+	rabbitMqService rabbitmq.RabbitMqInterface
+	svc             port.ProductImageService
+}
+
+func NewProductImageWorker(b *bootstrap.Bootstrap) *ProductImageWorker {
+	return &ProductImageWorker{
+		log:             b.Log,
+		rabbitMqService: b.RabbitMQ,
+		svc:             service.NewProductImageService(b.ImageRepo, b.ProductRepo, b.Storage, b.RabbitMQ, config.ProductImageMaxSize(), config.ProductImageUploadURLTTL(), b.Log),
+	}
+}
+
+// Run resizes every uploaded image into its derivatives. Images that cannot be
+// decoded are acknowledged, retrying them would fail the same way
+func (w *ProductImageWorker) Run() {
+	ctx := context.Background()
+
+	request := rabbitmq.RabbitMqConsumeRequest{
+		QueueName:    consts.QueueProductImageDerivatives,
+		ConsumerName: fmt.Sprintf("go-%s", consts.QueueProductImageDerivatives),
+	}
+
+	chClosedCh := make(chan *amqp.Error)
+
+	msgs, err := w.rabbitMqService.Consume(request, chClosedCh)
+	if err != nil {
+		w.log.Error("failed to consume messages", zap.Error(err), zap.String("queue_name", request.QueueName), zap.String("from", "worker.product_image"))
+		return
+	}
+
+	for {
+		select {
+		case amqErr := <-chClosedCh:
+			w.log.Warn("channel closed by abnormal shutdown", zap.String("queue_name", request.QueueName), zap.Any("error", amqErr))
+			time.Sleep(1 * time.Second)
+
+			chClosedCh = make(chan *amqp.Error)
+			msgs, err = w.rabbitMqService.Consume(request, chClosedCh)
+			if err != nil {
+				w.log.Error("failed to reconnect to RabbitMQ", zap.Error(err), zap.String("queue_name", request.QueueName))
+				continue
+			}
+
+			w.log.Info("RabbitMQ channel reconnected", zap.String("queue_name", request.QueueName))
+
+		case m := <-msgs:
+			if m.Body == nil {
+				_ = m.Ack(false)
+				continue
+			}
+
+			var data dto.ProductImageUploadedEvent
+			if err := json.Unmarshal(m.Body, &data); err != nil {
+				w.log.Error("failed to unmarshal message body", zap.Error(err), zap.String("queue_name", request.QueueName))
+				_ = m.Nack(false, false)
+				continue
+			}
+
+			if w.rabbitMqService.IsClosed() {
+				w.log.Warn("RabbitMQ channel closed, message will be requeued", zap.String("queue_name", request.QueueName))
+				_ = m.Nack(false, true)
+				continue
+			}
+
+			err := w.svc.GenerateDerivatives(ctx, data.ImageID)
+			if errors.Is(err, consts.ErrInvalidImage) {
+				w.log.Warn("product image cannot be resized", zap.Int("image_id", data.ImageID), zap.Error(err), zap.String("queue_name", request.QueueName))
+				_ = m.Ack(false)
+				continue
+			}
+
+			if err != nil {
+				w.log.Error("failed to resize product image", zap.Int("image_id", data.ImageID), zap.Error(err), zap.String("queue_name", request.QueueName))
+				_ = m.Nack(false, true)
+				continue
+			}
+
+			w.log.Info("product image resized successfully", zap.Int("image_id", data.ImageID), zap.String("queue_name", request.QueueName))
+			_ = m.Ack(false)
+		}
+	}
+}
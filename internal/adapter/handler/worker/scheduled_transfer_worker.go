@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/bootstrap"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/internal/core/service"
+	"go.uber.org/zap"
+)
+
+type ScheduledTransferWorker struct {
+	log *zap.Logger
+	svc port.ScheduledTransferService
+}
+
+func NewScheduledTransferWorker(b *bootstrap.Bootstrap) *ScheduledTransferWorker {
+	balanceSvc := service.NewBalanceService(b.BalanceRepo, b.BalanceTransactionRepo, b.UserRepo, b.TransferReviewRepo, b.OrderRepo, b.PaymentRepo, b.TransferInquiryRepo, b.Locker, b.Email, b.Config.Business)
+
+	return &ScheduledTransferWorker{
+		log: b.Log,
+		svc: service.NewScheduledTransferService(b.ScheduledTransferRepo, b.UserRepo, balanceSvc, b.Email, b.Config.Business, b.Log),
+	}
+}
+
+func (w *ScheduledTransferWorker) Run() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.runDueTransfers()
+		}
+	}
+}
+
+func (w *ScheduledTransferWorker) runDueTransfers() {
+	ctx := context.Background()
+
+	if err := w.svc.RunDue(ctx, time.Now()); err != nil {
+		w.log.Error("Error running scheduled transfers", zap.Error(err))
+	}
+}
@@ -12,16 +12,28 @@ func ErrorResponse(err error) (int, util.Response) {
 	message := "Internal server error"
 
 	switch err {
-	case consts.ErrDataNotFound:
+	case consts.ErrDataNotFound, consts.ErrHoldNotFound, consts.ErrTransferInquiryNotFound, consts.ErrProductNotFound, consts.ErrProductNotInCart, consts.ErrVariantNotFound:
 		statusCode = http.StatusNotFound
 		message = err.Error()
 	case consts.ErrNoUpdatedData:
 		statusCode = http.StatusNotModified
 		message = err.Error()
-	case consts.ErrConflictingData, consts.ErrEmailAlreadyExist:
+	case consts.ErrConflictingData, consts.ErrEmailAlreadyExist, consts.ErrInvalidWithdrawalStatus, consts.ErrTransferReviewClosed, consts.ErrBalanceLocked, consts.ErrScheduledTransferClosed, consts.ErrMoneyRequestClosed, consts.ErrAdjustmentClosed, consts.ErrCartItemUnavailable, consts.ErrOptionInUse, consts.ErrHoldReleased, consts.ErrPaymentClosed, consts.ErrRefundExceedsPayment, consts.ErrAlreadyPosted:
 		statusCode = http.StatusConflict
 		message = err.Error()
-	case consts.ErrInsufficientStock, consts.ErrInsufficientPayment:
+	case consts.ErrInvalidCartOperations, consts.ErrInvalidImage:
+		statusCode = http.StatusUnprocessableEntity
+		message = err.Error()
+	case consts.ErrCartChanged:
+		statusCode = http.StatusPreconditionFailed
+		message = err.Error()
+	case consts.ErrCartVersionRequired:
+		statusCode = http.StatusPreconditionRequired
+		message = err.Error()
+	case consts.ErrInsufficientStock, consts.ErrInsufficientPayment, consts.ErrCartItemLimitExceeded, consts.ErrQuantityLimitExceeded, consts.ErrOrderTotalLimitExceeded, consts.ErrInvalidCursor, consts.ErrInvalidSort, consts.ErrInvalidCartToken, consts.ErrInvalidUnsubscribeToken, consts.ErrInvalidCartOperation, consts.ErrDuplicateCartOperation, consts.ErrClearCartWithBody, consts.ErrInvalidQuantity, consts.ErrVariantRequired, consts.ErrInvalidVariantOptions, consts.ErrImageNotUploaded, consts.ErrInvalidImageOrder:
+		statusCode = http.StatusBadRequest
+		message = err.Error()
+	case consts.ErrTransferAmountLimitExceeded, consts.ErrDailyTransferLimitExceeded, consts.ErrMonthlyTransferLimitExceeded, consts.ErrInvalidOTP, consts.ErrTransferNeedsReview, consts.ErrInvalidSchedule, consts.ErrInvalidMoneyRequest, consts.ErrTransferNeedsOTP, consts.ErrTransferInquiryMismatch:
 		statusCode = http.StatusBadRequest
 		message = err.Error()
 	case consts.ErrTokenDuration, consts.ErrTokenCreation, consts.ErrInvalidToken, consts.ErrExpiredToken:
@@ -30,7 +42,7 @@ func ErrorResponse(err error) (int, util.Response) {
 	case consts.ErrInvalidCredentials:
 		statusCode = http.StatusUnauthorized
 		message = err.Error()
-	case consts.ErrEmptyAuthorizationHeader, consts.ErrInvalidAuthorizationHeader, consts.ErrInvalidAuthorizationType, consts.ErrEmptyCart, consts.ErrInsufficientBalance, consts.ErrCannotSendBalanceSameAccount:
+	case consts.ErrEmptyAuthorizationHeader, consts.ErrInvalidAuthorizationHeader, consts.ErrInvalidAuthorizationType, consts.ErrEmptyCart, consts.ErrInsufficientBalance, consts.ErrCannotSendBalanceSameAccount, consts.ErrOrderNotPaidByWallet:
 		statusCode = http.StatusBadRequest
 		message = err.Error()
 	case consts.ErrUnauthorized:
@@ -39,12 +51,24 @@ func ErrorResponse(err error) (int, util.Response) {
 	case consts.ErrForbidden:
 		statusCode = http.StatusForbidden
 		message = err.Error()
-	case consts.ErrEmailNotVerified:
+	case consts.ErrEmailNotVerified, consts.ErrAdjustmentSelfApproval, consts.ErrInvalidUploadURL:
 		statusCode = http.StatusForbidden
 		message = err.Error()
+	case consts.ErrPayoutFailed:
+		statusCode = http.StatusBadGateway
+		message = err.Error()
 	case consts.ErrNotImplemented:
 		statusCode = http.StatusNotImplemented
 		message = err.Error()
+	case consts.ErrUnsupportedImageType:
+		statusCode = http.StatusUnsupportedMediaType
+		message = err.Error()
+	case consts.ErrImageTooLarge:
+		statusCode = http.StatusRequestEntityTooLarge
+		message = err.Error()
+	case consts.ErrStorageNotConfigured:
+		statusCode = http.StatusServiceUnavailable
+		message = err.Error()
 	}
 
 	return statusCode, util.APIResponse(message, statusCode, "error", nil)
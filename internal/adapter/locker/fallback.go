@@ -0,0 +1,71 @@
+package locker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"go.uber.org/zap"
+)
+
+// primaryRetryAfter is how long the fallback is used on its own after the
+// primary failed, so an outage doesn't cost every lock a connection timeout
+const primaryRetryAfter = 10 * time.Second
+
+// FallbackLocker obtains locks from the primary locker and switches to the
+// fallback while the primary is unreachable. A lock held by someone else is
+// not an outage, it is reported as is.
+//
+// Around the start and end of an outage one process may hold a primary lock
+// while another holds the fallback lock of the same key. The ledger still
+// locks the balance rows it updates, so the overlap can delay a wallet
+// operation but can't corrupt a balance
+type FallbackLocker struct {
+	primary  port.Locker
+	fallback port.Locker
+	log      *zap.Logger
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// NewFallbackLocker creates a locker that prefers primary and uses fallback
+// while primary fails
+func NewFallbackLocker(primary, fallback port.Locker, log *zap.Logger) *FallbackLocker {
+	return &FallbackLocker{
+		primary:  primary,
+		fallback: fallback,
+		log:      log,
+	}
+}
+
+func (l *FallbackLocker) Obtain(ctx context.Context, key string, ttl time.Duration, opts port.LockOptions) (port.Lock, error) {
+	if l.primaryUp() {
+		lock, err := l.primary.Obtain(ctx, key, ttl, opts)
+		if err == nil || errors.Is(err, consts.ErrLockNotObtained) || ctx.Err() != nil {
+			return lock, err
+		}
+
+		l.log.Warn("Primary locker unavailable, using fallback", zap.String("key", key), zap.Error(err))
+		l.markPrimaryDown()
+	}
+
+	return l.fallback.Obtain(ctx, key, ttl, opts)
+}
+
+func (l *FallbackLocker) primaryUp() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return time.Now().After(l.downUntil)
+}
+
+func (l *FallbackLocker) markPrimaryDown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.downUntil = time.Now().Add(primaryRetryAfter)
+}
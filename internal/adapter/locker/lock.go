@@ -0,0 +1,84 @@
+package locker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// NewToken returns the random token that identifies a held lock
+func NewToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Retry calls attempt until it takes the lock, waiting between attempts with
+// the exponential backoff of opts. It returns consts.ErrLockNotObtained once
+// every retry found the lock held
+func Retry(ctx context.Context, opts port.LockOptions, attempt func() (bool, error)) error {
+	backoff := opts.RetryBackoff
+	for i := 0; ; i++ {
+		acquired, err := attempt()
+		if err != nil {
+			return err
+		}
+
+		if acquired {
+			return nil
+		}
+
+		if i >= opts.RetryCount {
+			return consts.ErrLockNotObtained
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if opts.MaxBackoff > 0 && backoff > opts.MaxBackoff {
+			backoff = opts.MaxBackoff
+		}
+	}
+}
+
+// Renew calls refresh every third of ttl until stop is called or refresh
+// reports the lock lost with consts.ErrLockNotHeld. stop waits for a refresh
+// in flight to finish
+func Renew(ttl time.Duration, refresh func(ctx context.Context) error) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := refresh(ctx); err == consts.ErrLockNotHeld {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
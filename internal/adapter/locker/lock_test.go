@@ -0,0 +1,113 @@
+package locker_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/locker"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+func TestNewToken(t *testing.T) {
+	a, err := locker.NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	b, err := locker.NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+
+	if a == "" || a == b {
+		t.Fatalf("tokens %q and %q, want two different ones", a, b)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	opts := port.LockOptions{RetryCount: 3, RetryBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	t.Run("held", func(t *testing.T) {
+		attempts := 0
+		err := locker.Retry(ctx, opts, func() (bool, error) {
+			attempts++
+			return false, nil
+		})
+		if !errors.Is(err, consts.ErrLockNotObtained) || attempts != 4 {
+			t.Fatalf("Retry = %v after %d attempts, want ErrLockNotObtained after 4", err, attempts)
+		}
+	})
+
+	t.Run("acquired", func(t *testing.T) {
+		attempts := 0
+		err := locker.Retry(ctx, opts, func() (bool, error) {
+			attempts++
+			return attempts == 2, nil
+		})
+		if err != nil || attempts != 2 {
+			t.Fatalf("Retry = %v after %d attempts, want nil after 2", err, attempts)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		failure := errors.New("unreachable")
+		attempts := 0
+		err := locker.Retry(ctx, opts, func() (bool, error) {
+			attempts++
+			return false, failure
+		})
+		if !errors.Is(err, failure) || attempts != 1 {
+			t.Fatalf("Retry = %v after %d attempts, want the error after 1", err, attempts)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := locker.Retry(ctx, port.LockOptions{RetryCount: 3, RetryBackoff: time.Hour}, func() (bool, error) {
+			return false, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Retry = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestRenew(t *testing.T) {
+	var refreshes atomic.Int32
+	stop := locker.Renew(30*time.Millisecond, func(ctx context.Context) error {
+		refreshes.Add(1)
+		return nil
+	})
+
+	time.Sleep(100 * time.Millisecond)
+	stop()
+	count := refreshes.Load()
+	if count < 3 {
+		t.Fatalf("%d refreshes in 100ms with a 30ms TTL, want at least 3", count)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if refreshes.Load() != count {
+		t.Fatal("refreshed after stop")
+	}
+
+	t.Run("lost", func(t *testing.T) {
+		var refreshes atomic.Int32
+		stop := locker.Renew(30*time.Millisecond, func(ctx context.Context) error {
+			refreshes.Add(1)
+			return consts.ErrLockNotHeld
+		})
+		defer stop()
+
+		time.Sleep(100 * time.Millisecond)
+		if n := refreshes.Load(); n != 1 {
+			t.Fatalf("%d refreshes after the lock was lost, want 1", n)
+		}
+	})
+}
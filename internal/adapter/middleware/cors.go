@@ -13,7 +13,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Cart-Token, If-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Cart-Token, ETag")
 
 		if c.Request.Method == "OPTIONS" {
 			c.JSON(http.StatusOK, `{"method":"OPTIONS"}`)
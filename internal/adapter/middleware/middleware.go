@@ -59,6 +59,21 @@ func AuthMiddleware(token port.TokenInterface) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware authenticates the request like AuthMiddleware when it
+// carries an authorization header and lets it through anonymously otherwise
+func OptionalAuthMiddleware(token port.TokenInterface) gin.HandlerFunc {
+	auth := AuthMiddleware(token)
+
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader(authorizationHeaderKey) == "" {
+			ctx.Next()
+			return
+		}
+
+		auth(ctx)
+	}
+}
+
 func AdminMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		payload := util.GetAuthPayload(ctx, consts.AuthorizationKey)
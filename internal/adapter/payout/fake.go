@@ -0,0 +1,36 @@
+package payout
+
+import (
+	"context"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FakeProvider accepts every payout without moving any money, it stands in
+// for a real disbursement provider in development
+type FakeProvider struct {
+	logger *zap.Logger
+}
+
+func NewFakeProvider(logger *zap.Logger) *FakeProvider {
+	return &FakeProvider{
+		logger: logger,
+	}
+}
+
+func (p *FakeProvider) Payout(ctx context.Context, withdrawal domain.Withdrawal, idempotencyKey string) (string, error) {
+	// a repeated key returns the first payout like a real provider would
+	reference := "FAKE-" + uuid.NewSHA1(uuid.NameSpaceOID, []byte(idempotencyKey)).String()
+
+	p.logger.Info("Fake payout sent",
+		zap.Uint64("withdrawal_id", withdrawal.ID),
+		zap.String("idempotency_key", idempotencyKey),
+		zap.String("bank_name", withdrawal.BankName),
+		zap.Float64("amount", withdrawal.Amount),
+		zap.String("reference", reference),
+	)
+
+	return reference, nil
+}
@@ -30,6 +30,16 @@ func NewRouter(
 	paymentHandler *http.PaymentHandler,
 	orderHandler *http.OrderHandler,
 	balanceHandler *http.BalanceHandler,
+	settingHandler *http.SettingHandler,
+	withdrawalHandler *http.WithdrawalHandler,
+	scheduledTransferHandler *http.ScheduledTransferHandler,
+	moneyRequestHandler *http.MoneyRequestHandler,
+	walletAdjustmentHandler *http.WalletAdjustmentHandler,
+	cartReminderHandler *http.CartReminderHandler,
+	wishlistHandler *http.WishlistHandler,
+	productVariantHandler *http.ProductVariantHandler,
+	productImageHandler *http.ProductImageHandler,
+	fileHandler *http.FileHandler,
 ) (*Router, error) {
 
 	// Set Gin mode
@@ -57,6 +67,12 @@ func NewRouter(
 
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Files of the local storage, only served with STORAGE_BACKEND=local
+	if fileHandler != nil {
+		router.GET("/files/*key", fileHandler.ServeFile)
+		router.PUT("/files/*key", fileHandler.UploadFile)
+	}
+
 	// API Routes
 	api := router.Group("/api")
 	v1 := api.Group("/v1")
@@ -77,6 +93,7 @@ func NewRouter(
 			authUser := profile.Group("/").Use(middleware.AuthMiddleware(token))
 			{
 				authUser.GET("/", userHandler.GetProfile)
+				authUser.PUT("/cart-reminders", cartReminderHandler.UpdateSettings)
 			}
 		}
 
@@ -100,13 +117,29 @@ func NewRouter(
 			{
 
 				authUser.GET("/", productHandler.ListProducts)
+				authUser.GET("/search", productHandler.SearchProducts)
 				authUser.GET("/:id", productHandler.GetProduct)
+				authUser.GET("/:id/options", productVariantHandler.ListOptions)
+				authUser.GET("/:id/variants", productVariantHandler.ListVariants)
+				authUser.GET("/:id/variants/:variant_id", productVariantHandler.GetVariant)
+				authUser.GET("/:id/images", productImageHandler.ListImages)
 
 				admin := authUser.Use(middleware.AdminMiddleware())
 				{
 					admin.POST("/", productHandler.CreateProduct)
 					admin.DELETE("/:id", productHandler.DeleteProduct)
 					admin.PUT("/:id", productHandler.UpdateProduct)
+					admin.POST("/:id/options", productVariantHandler.CreateOption)
+					admin.DELETE("/:id/options/:option_id", productVariantHandler.DeleteOption)
+					admin.POST("/:id/variants", productVariantHandler.CreateVariant)
+					admin.PUT("/:id/variants/:variant_id", productVariantHandler.UpdateVariant)
+					admin.DELETE("/:id/variants/:variant_id", productVariantHandler.DeleteVariant)
+					admin.POST("/:id/images", productImageHandler.UploadImage)
+					admin.POST("/:id/images/upload-url", productImageHandler.CreateUploadURL)
+					admin.POST("/:id/images/confirm", productImageHandler.ConfirmUpload)
+					admin.PUT("/:id/images/order", productImageHandler.ReorderImages)
+					admin.PUT("/:id/images/:image_id/primary", productImageHandler.SetPrimaryImage)
+					admin.DELETE("/:id/images/:image_id", productImageHandler.DeleteImage)
 				}
 
 			}
@@ -132,12 +165,45 @@ func NewRouter(
 
 		cart := v1.Group("/carts")
 		{
+			// guests without a bearer token work on the cart of their cart token
+			anyUser := cart.Group("/").Use(middleware.OptionalAuthMiddleware(token))
+			{
+				anyUser.POST("/", cartHandler.AddToCart)
+				anyUser.GET("/", cartHandler.ViewCart)
+				anyUser.DELETE("/", cartHandler.ClearCart)
+				anyUser.PUT("/", cartHandler.UpdateCart)
+				anyUser.PATCH("/items", cartHandler.BulkUpdateCart)
+				anyUser.DELETE("/items", cartHandler.RemoveFromCart)
+			}
+
+			cart.GET("/reminders/unsubscribe", cartReminderHandler.Unsubscribe)
+
 			authUser := cart.Group("/").Use(middleware.AuthMiddleware(token))
 			{
-				authUser.POST("/", cartHandler.AddToCart)
-				authUser.GET("/", cartHandler.ViewCart)
-				authUser.DELETE("/", cartHandler.RemoveFromCart)
-				authUser.PUT("/", cartHandler.UpdateCart)
+				admin := authUser.Use(middleware.AdminMiddleware())
+				{
+					admin.GET("/reminders/stats", cartReminderHandler.Stats)
+				}
+			}
+		}
+
+		wishlist := v1.Group("/wishlists")
+		{
+			wishlist.GET("/shared/:token", wishlistHandler.GetSharedWishlist)
+
+			authUser := wishlist.Group("/").Use(middleware.AuthMiddleware(token))
+			{
+				authUser.GET("/", wishlistHandler.ListWishlists)
+				authUser.POST("/", wishlistHandler.CreateWishlist)
+				authUser.POST("/save-for-later", wishlistHandler.SaveForLater)
+				authUser.GET("/:id", wishlistHandler.GetWishlist)
+				authUser.PUT("/:id", wishlistHandler.RenameWishlist)
+				authUser.DELETE("/:id", wishlistHandler.DeleteWishlist)
+				authUser.POST("/:id/items", wishlistHandler.AddItem)
+				authUser.DELETE("/:id/items/:product_id", wishlistHandler.RemoveItem)
+				authUser.POST("/:id/items/:product_id/move-to-cart", wishlistHandler.MoveToCart)
+				authUser.POST("/:id/share", wishlistHandler.ShareWishlist)
+				authUser.DELETE("/:id/share", wishlistHandler.UnshareWishlist)
 			}
 		}
 
@@ -171,9 +237,56 @@ func NewRouter(
 			authUser := balance.Group("/").Use(middleware.AuthMiddleware(token))
 			{
 				authUser.POST("/deposit", balanceHandler.Deposit)
+				authUser.POST("/transfer/inquiry", balanceHandler.InquireTransfer)
 				authUser.POST("/transfer", balanceHandler.Transfer)
 				authUser.GET("", balanceHandler.CheckBalance)
-				authUser.POST("/withdraw", balanceHandler.Withdraw)
+				authUser.POST("/withdraw", withdrawalHandler.Withdraw)
+				authUser.GET("/withdrawals", withdrawalHandler.ListWithdrawals)
+				authUser.GET("/destinations", withdrawalHandler.ListDestinations)
+				authUser.POST("/destinations", withdrawalHandler.AddDestination)
+				authUser.DELETE("/destinations/:id", withdrawalHandler.DeleteDestination)
+				authUser.GET("/transactions", balanceHandler.ListTransactions)
+				authUser.GET("/transactions/export", balanceHandler.ExportTransactions)
+				authUser.GET("/scheduled", scheduledTransferHandler.List)
+				authUser.POST("/scheduled", scheduledTransferHandler.Create)
+				authUser.GET("/scheduled/:id", scheduledTransferHandler.Get)
+				authUser.PUT("/scheduled/:id", scheduledTransferHandler.Update)
+				authUser.DELETE("/scheduled/:id", scheduledTransferHandler.Cancel)
+				authUser.POST("/requests", moneyRequestHandler.Create)
+				authUser.GET("/requests/sent", moneyRequestHandler.ListSent)
+				authUser.GET("/requests/received", moneyRequestHandler.ListReceived)
+				authUser.GET("/requests/:id", moneyRequestHandler.Get)
+				authUser.POST("/requests/:id/pay", moneyRequestHandler.Pay)
+				authUser.POST("/requests/:id/decline", moneyRequestHandler.Decline)
+				authUser.POST("/requests/:id/cancel", moneyRequestHandler.Cancel)
+
+				admin := authUser.Use(middleware.AdminMiddleware())
+				{
+					admin.GET("/reconciliation", balanceHandler.Reconcile)
+					admin.POST("/refund", balanceHandler.Refund)
+					admin.GET("/transfer-reviews", balanceHandler.ListTransferReviews)
+					admin.POST("/transfer-reviews/:id/approve", balanceHandler.ApproveTransferReview)
+					admin.POST("/transfer-reviews/:id/reject", balanceHandler.RejectTransferReview)
+					admin.GET("/withdrawals/all", withdrawalHandler.ListAllWithdrawals)
+					admin.POST("/withdrawals/:id/approve", withdrawalHandler.Approve)
+					admin.POST("/withdrawals/:id/reject", withdrawalHandler.Reject)
+					admin.GET("/adjustments", walletAdjustmentHandler.List)
+					admin.POST("/adjustments", walletAdjustmentHandler.Create)
+					admin.GET("/adjustments/:id", walletAdjustmentHandler.Get)
+					admin.POST("/adjustments/:id/approve", walletAdjustmentHandler.Approve)
+					admin.POST("/adjustments/:id/reject", walletAdjustmentHandler.Reject)
+				}
+			}
+		}
+
+		setting := v1.Group("/settings")
+		{
+			authUser := setting.Group("/").Use(middleware.AuthMiddleware(token))
+			{
+				admin := authUser.Use(middleware.AdminMiddleware())
+				{
+					admin.GET("/business-rules", settingHandler.GetBusinessRules)
+				}
 			}
 		}
 	}
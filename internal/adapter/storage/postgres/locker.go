@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/locker"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Locker implements port.Locker with transaction level advisory locks. Each
+// lock keeps a transaction open on its own connection, so it is released when
+// the transaction ends, when the connection drops, or when its TTL runs out
+// without a refresh
+type Locker struct {
+	pool           *pgxpool.Pool
+	acquireTimeout time.Duration
+}
+
+// NewLocker creates a distributed locker on the advisory locks of db. The
+// locks hold their connections from a separate pool of maxConns, so waiting
+// for a lock can never starve the queries made while holding one. Waiting
+// for a free connection is bounded by acquireTimeout
+func NewLocker(ctx context.Context, db *DB, maxConns int32, acquireTimeout time.Duration) (*Locker, error) {
+	poolConfig, err := pgxpool.ParseConfig(db.url)
+	if err != nil {
+		return nil, err
+	}
+	if maxConns > 0 {
+		poolConfig.MaxConns = maxConns
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Locker{pool, acquireTimeout}, nil
+}
+
+// Obtain takes the advisory lock of key, retrying with an exponential backoff
+// while another session holds it
+func (l *Locker) Obtain(ctx context.Context, key string, ttl time.Duration, opts port.LockOptions) (port.Lock, error) {
+	token, err := locker.NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &advisoryLock{key: key, token: token}
+	err = locker.Retry(ctx, opts, func() (bool, error) {
+		conn, tx, acquired, err := l.tryLock(ctx, key)
+		lock.conn, lock.tx = conn, tx
+		return acquired, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lock.expireAfter(ttl)
+	if opts.AutoRenew {
+		// a cancelled query would break the transaction, so the refresh
+		// itself is not tied to the renewal context
+		lock.stopRenew = locker.Renew(ttl, func(context.Context) error {
+			return lock.Refresh(context.Background(), ttl)
+		})
+	}
+
+	return lock, nil
+}
+
+// tryLock opens the transaction that will hold the lock and takes it with
+// pg_try_advisory_xact_lock, the non blocking form of pg_advisory_xact_lock,
+// so waiting follows the retry options. The transaction is rolled back
+// straight away when another session holds the lock. When every lock
+// connection is busy past the acquire timeout the lock counts as held, so a
+// caller waiting on a second lock gives up and frees its first one instead of
+// waiting on the pool forever
+func (l *Locker) tryLock(ctx context.Context, key string) (*pgxpool.Conn, pgx.Tx, bool, error) {
+	acquireCtx, cancel := ctx, context.CancelFunc(func() {})
+	if l.acquireTimeout > 0 {
+		acquireCtx, cancel = context.WithTimeout(ctx, l.acquireTimeout)
+	}
+	conn, err := l.pool.Acquire(acquireCtx)
+	cancel()
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return nil, nil, false, nil
+		}
+		return nil, nil, false, err
+	}
+
+	// the transaction outlives the request that obtained the lock, only
+	// Release or the TTL end it
+	tx, err := conn.Begin(context.WithoutCancel(ctx))
+	if err != nil {
+		conn.Release()
+		return nil, nil, false, err
+	}
+
+	var acquired bool
+	err = tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock(hashtextextended($1, 0))", key).Scan(&acquired)
+	if err != nil || !acquired {
+		tx.Rollback(context.WithoutCancel(ctx))
+		conn.Release()
+		return nil, nil, false, err
+	}
+
+	return conn, tx, true, nil
+}
+
+type advisoryLock struct {
+	key   string
+	token string
+
+	mu        sync.Mutex
+	conn      *pgxpool.Conn
+	tx        pgx.Tx
+	expiresAt time.Time
+	expiry    *time.Timer
+	stopRenew func()
+}
+
+func (l *advisoryLock) Key() string {
+	return l.key
+}
+
+func (l *advisoryLock) Token() string {
+	return l.token
+}
+
+func (l *advisoryLock) TTL(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tx == nil {
+		return 0, nil
+	}
+
+	return time.Until(l.expiresAt), nil
+}
+
+// Refresh checks the holding connection is still alive and moves the expiry
+// to ttl from now
+func (l *advisoryLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tx == nil {
+		return consts.ErrLockNotHeld
+	}
+
+	if _, err := l.tx.Exec(ctx, "SELECT 1"); err != nil {
+		l.end()
+		return consts.ErrLockNotHeld
+	}
+
+	l.expiresAt = time.Now().Add(ttl)
+	l.expiry.Reset(ttl)
+
+	return nil
+}
+
+func (l *advisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	stopRenew := l.stopRenew
+	l.stopRenew = nil
+	l.mu.Unlock()
+
+	// the renewal refreshes under mu, so it is stopped before taking it
+	if stopRenew != nil {
+		stopRenew()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tx == nil {
+		return consts.ErrLockNotHeld
+	}
+
+	l.end()
+
+	return nil
+}
+
+// expireAfter ends the transaction once ttl passes without a refresh, like a
+// Redis key expiring
+func (l *advisoryLock) expireAfter(ttl time.Duration) {
+	l.expiresAt = time.Now().Add(ttl)
+	l.expiry = time.AfterFunc(ttl, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.tx != nil && !time.Now().Before(l.expiresAt) {
+			l.end()
+		}
+	})
+}
+
+// end rolls back the holding transaction, which releases the advisory lock,
+// and hands the connection back to the pool. The caller must hold mu
+func (l *advisoryLock) end() {
+	l.expiry.Stop()
+	l.tx.Rollback(context.Background())
+	l.conn.Release()
+	l.tx = nil
+	l.conn = nil
+}
@@ -0,0 +1,94 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/storagetest"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// newTestDB connects to the database in TEST_DB_*, the tests are skipped
+// without TEST_DB_HOST
+func newTestDB(t *testing.T) *postgres.DB {
+	t.Helper()
+
+	if os.Getenv("TEST_DB_HOST") == "" {
+		t.Skip("TEST_DB_HOST is not set")
+	}
+
+	db, err := postgres.New(context.Background(), &config.DB{
+		Connection: "postgres",
+		User:       os.Getenv("TEST_DB_USER"),
+		Password:   os.Getenv("TEST_DB_PASSWORD"),
+		Host:       os.Getenv("TEST_DB_HOST"),
+		Port:       os.Getenv("TEST_DB_PORT"),
+		Name:       os.Getenv("TEST_DB_NAME"),
+	})
+	if err != nil {
+		t.Fatalf("connecting to Postgres: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+func TestLockerContract(t *testing.T) {
+	db := newTestDB(t)
+
+	locker, err := postgres.NewLocker(context.Background(), db, 10, time.Second)
+	if err != nil {
+		t.Fatalf("NewLocker: %v", err)
+	}
+
+	storagetest.LockerContract(t, locker)
+}
+
+func TestLockerBusyPool(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	locker, err := postgres.NewLocker(ctx, db, 1, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewLocker: %v", err)
+	}
+
+	key := "locker-busy-pool:" + time.Now().Format(time.RFC3339Nano)
+	lock, err := locker.Obtain(ctx, key+":first", time.Minute, port.LockOptions{})
+	if err != nil {
+		t.Fatalf("Obtain: %v", err)
+	}
+
+	// the only connection is held, waiting for another one gives up instead of
+	// blocking until the first lock is released
+	done := make(chan error, 1)
+	go func() {
+		_, err := locker.Obtain(ctx, key+":second", time.Minute, port.LockOptions{RetryCount: 1, RetryBackoff: 10 * time.Millisecond})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, consts.ErrLockNotObtained) {
+			t.Fatalf("Obtain on a busy pool = %v, want ErrLockNotObtained", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Obtain on a busy pool did not give up")
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	second, err := locker.Obtain(ctx, key+":second", time.Minute, port.LockOptions{})
+	if err != nil {
+		t.Fatalf("Obtain after Release: %v", err)
+	}
+	second.Release(ctx)
+}
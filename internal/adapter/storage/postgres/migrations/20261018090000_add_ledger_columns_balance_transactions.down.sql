@@ -0,0 +1,6 @@
+DELETE FROM balance_transactions WHERE transaction_type IN ('opening_balance', 'payment');
+DROP INDEX IF EXISTS idx_balance_transactions_user_id_created_at;
+ALTER TABLE balance_transactions DROP COLUMN counterparty_id;
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('deposit', 'withdrawal', 'transfer'));
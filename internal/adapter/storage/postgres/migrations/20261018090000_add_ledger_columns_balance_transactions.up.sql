@@ -0,0 +1,11 @@
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'transfer', 'payment'));
+ALTER TABLE balance_transactions ADD COLUMN counterparty_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_balance_transactions_user_id_created_at ON balance_transactions (user_id, created_at, id);
+
+-- carry existing balances into the ledger so that it reconciles from day one
+INSERT INTO balance_transactions (user_id, amount, balance_before, balance_after, transaction_type, created_at)
+SELECT user_id, balance, 0, balance, 'opening_balance', NOW()
+FROM balances
+WHERE balance <> 0;
@@ -0,0 +1,11 @@
+DELETE FROM balance_transactions WHERE transaction_type = 'refund';
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'transfer', 'payment'));
+ALTER TABLE balance_transactions DROP COLUMN journal_entry_id;
+
+DROP TRIGGER IF EXISTS trg_journal_lines_balanced ON journal_lines;
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
+DROP TABLE journal_lines;
+DROP TABLE journal_entries;
+DROP TABLE accounts;
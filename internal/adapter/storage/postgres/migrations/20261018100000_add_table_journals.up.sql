@@ -0,0 +1,75 @@
+CREATE TABLE accounts (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(100) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('asset', 'liability', 'revenue', 'equity')),
+    user_id BIGINT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE journal_entries (
+    id SERIAL PRIMARY KEY,
+    entry_type VARCHAR(50) NOT NULL,
+    reference_id BIGINT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE journal_lines (
+    id SERIAL PRIMARY KEY,
+    journal_entry_id BIGINT NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    account_id BIGINT NOT NULL REFERENCES accounts(id),
+    amount DECIMAL(18,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_journal_lines_account_id ON journal_lines (account_id);
+CREATE INDEX idx_journal_lines_journal_entry_id ON journal_lines (journal_entry_id);
+
+-- every journal entry must sum to zero once its transaction commits
+CREATE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT COALESCE(SUM(amount), 0) FROM journal_lines WHERE journal_entry_id = NEW.journal_entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.journal_entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_journal_lines_balanced
+    AFTER INSERT OR UPDATE ON journal_lines
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+INSERT INTO accounts (code, name, account_type) VALUES
+    ('platform_revenue', 'Platform revenue', 'revenue'),
+    ('payment_clearing', 'Payment clearing', 'asset'),
+    ('refunds_payable', 'Refunds payable', 'liability'),
+    ('opening_equity', 'Opening balance equity', 'equity');
+
+INSERT INTO accounts (code, name, account_type, user_id)
+SELECT 'wallet:' || user_id, 'Wallet of user ' || user_id, 'liability', user_id
+FROM balances;
+
+-- open the journal with the balances that existed before it
+WITH opening AS (
+    INSERT INTO journal_entries (entry_type, reference_id, description)
+    SELECT 'opening_balance', user_id, 'Opening balance'
+    FROM balances
+    WHERE balance <> 0
+    RETURNING id, reference_id
+)
+INSERT INTO journal_lines (journal_entry_id, account_id, amount)
+SELECT o.id, a.id, b.balance
+FROM opening o
+JOIN balances b ON b.user_id = o.reference_id
+JOIN accounts a ON a.user_id = b.user_id
+UNION ALL
+SELECT o.id, (SELECT id FROM accounts WHERE code = 'opening_equity'), -b.balance
+FROM opening o
+JOIN balances b ON b.user_id = o.reference_id;
+
+ALTER TABLE balance_transactions ADD COLUMN journal_entry_id BIGINT NULL REFERENCES journal_entries(id) ON DELETE SET NULL;
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'transfer', 'payment', 'refund'));
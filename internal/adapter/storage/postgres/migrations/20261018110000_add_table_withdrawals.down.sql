@@ -0,0 +1,7 @@
+DELETE FROM balance_transactions WHERE transaction_type = 'withdrawal_reversal';
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'transfer', 'payment', 'refund'));
+
+DROP TABLE IF EXISTS withdrawals;
+DROP TABLE IF EXISTS payout_destinations;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS payout_destinations (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    bank_name VARCHAR(100) NOT NULL,
+    account_number VARCHAR(50) NOT NULL,
+    account_name VARCHAR(150) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (user_id, bank_name, account_number)
+);
+
+-- the destination is copied onto the withdrawal so the audit trail survives its deletion
+CREATE TABLE IF NOT EXISTS withdrawals (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    destination_id INT NULL REFERENCES payout_destinations(id) ON DELETE SET NULL,
+    bank_name VARCHAR(100) NOT NULL,
+    account_number VARCHAR(50) NOT NULL,
+    account_name VARCHAR(150) NOT NULL,
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'approved', 'paid', 'rejected')),
+    provider_reference VARCHAR(100) NULL,
+    rejection_reason TEXT NULL,
+    reviewed_by INT NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_user_id ON withdrawals (user_id, id);
+CREATE INDEX IF NOT EXISTS idx_withdrawals_status ON withdrawals (status, id);
+
+INSERT INTO accounts (code, name, account_type) VALUES
+    ('withdrawals_pending', 'Withdrawals pending payout', 'liability')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'withdrawal_reversal', 'transfer', 'payment', 'refund'));
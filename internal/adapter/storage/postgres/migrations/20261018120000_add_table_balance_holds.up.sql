@@ -0,0 +1,23 @@
+-- balance is what the user can spend, held_balance is reserved for pending orders
+ALTER TABLE balances ADD COLUMN held_balance DECIMAL(18,2) NOT NULL DEFAULT 0 CHECK (held_balance >= 0);
+
+CREATE TABLE IF NOT EXISTS balance_holds (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    order_id INT NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'held' CHECK (status IN ('held', 'captured', 'released')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_holds_user_id ON balance_holds (user_id, status);
+
+INSERT INTO accounts (code, name, account_type) VALUES
+    ('wallet_holds', 'Wallet funds held for orders', 'liability')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'withdrawal_reversal', 'transfer', 'payment', 'refund', 'hold', 'hold_release'));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS transfer_reviews;
+DROP INDEX IF EXISTS idx_balance_transactions_user_type;
+DROP INDEX IF EXISTS idx_users_phone;
+ALTER TABLE users DROP COLUMN phone;
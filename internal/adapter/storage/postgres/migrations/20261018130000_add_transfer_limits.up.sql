@@ -0,0 +1,19 @@
+ALTER TABLE users ADD COLUMN phone VARCHAR(20) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users (phone) WHERE phone <> '';
+
+-- daily and monthly caps sum the sender's outgoing transfers
+CREATE INDEX IF NOT EXISTS idx_balance_transactions_user_type ON balance_transactions (user_id, transaction_type, created_at);
+
+CREATE TABLE IF NOT EXISTS transfer_reviews (
+    id SERIAL PRIMARY KEY,
+    sender_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'failed')),
+    note TEXT NULL,
+    reviewed_by INT NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_reviews_status ON transfer_reviews (status, id);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    frequency VARCHAR(20) NOT NULL CHECK (frequency IN ('once', 'weekly', 'monthly')),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'completed', 'cancelled', 'failed')),
+    start_at TIMESTAMP NOT NULL,
+    end_at TIMESTAMP NULL,
+    next_run_at TIMESTAMP NOT NULL,
+    run_count INT NOT NULL DEFAULT 0,
+    failure_count INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    -- set while a worker is executing the schedule so concurrent workers skip it
+    locked_until TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers (next_run_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_user ON scheduled_transfers (user_id, id);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    id SERIAL PRIMARY KEY,
+    scheduled_transfer_id INT NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    amount DECIMAL(18,2) NOT NULL,
+    error TEXT NULL,
+    scheduled_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_schedule ON scheduled_transfer_runs (scheduled_transfer_id, id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS money_request_payers;
+DROP TABLE IF EXISTS money_requests;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS money_requests (
+    id SERIAL PRIMARY KEY,
+    requester_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    note VARCHAR(255) NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'cancelled')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_money_requests_requester ON money_requests (requester_id, id);
+
+-- one row per user asked to pay, paying executes a wallet transfer referencing the request
+CREATE TABLE IF NOT EXISTS money_request_payers (
+    id SERIAL PRIMARY KEY,
+    request_id INT NOT NULL REFERENCES money_requests(id) ON DELETE CASCADE,
+    payer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'declined', 'cancelled')),
+    paid_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (request_id, payer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_money_request_payers_payer ON money_request_payers (payer_id, request_id);
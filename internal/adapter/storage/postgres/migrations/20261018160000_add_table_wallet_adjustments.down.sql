@@ -0,0 +1,7 @@
+DELETE FROM balance_transactions WHERE transaction_type = 'adjustment';
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'withdrawal_reversal', 'transfer', 'payment', 'refund', 'hold', 'hold_release'));
+
+DROP TABLE IF EXISTS wallet_adjustments;
+ALTER TABLE journal_entries DROP COLUMN created_by;
@@ -0,0 +1,29 @@
+-- the admin who posted a manual journal entry, NULL for entries made by the system
+ALTER TABLE journal_entries ADD COLUMN created_by INT NULL REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS wallet_adjustments (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('credit', 'debit')),
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    reason_code VARCHAR(50) NOT NULL,
+    note TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'rejected')),
+    requested_by INT NOT NULL REFERENCES users(id),
+    reviewed_by INT NULL REFERENCES users(id),
+    rejection_reason TEXT NULL,
+    journal_entry_id BIGINT NULL REFERENCES journal_entries(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_adjustments_user_id ON wallet_adjustments (user_id, id);
+CREATE INDEX IF NOT EXISTS idx_wallet_adjustments_status ON wallet_adjustments (status, id);
+
+INSERT INTO accounts (code, name, account_type) VALUES
+    ('wallet_adjustments', 'Manual wallet adjustments', 'equity')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE balance_transactions DROP CONSTRAINT IF EXISTS balance_transactions_transaction_type_check;
+ALTER TABLE balance_transactions ADD CONSTRAINT balance_transactions_transaction_type_check
+    CHECK (transaction_type IN ('opening_balance', 'deposit', 'withdrawal', 'withdrawal_reversal', 'transfer', 'payment', 'refund', 'hold', 'hold_release', 'adjustment'));
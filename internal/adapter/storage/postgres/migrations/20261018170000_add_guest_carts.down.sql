@@ -0,0 +1,6 @@
+DELETE FROM carts WHERE guest_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_carts_guest_expires_at;
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS carts_owner_check;
+ALTER TABLE carts DROP COLUMN expires_at;
+ALTER TABLE carts DROP COLUMN guest_id;
@@ -0,0 +1,9 @@
+-- a cart belongs to a user or, until they sign in, to a guest holding a signed cart token
+DELETE FROM carts WHERE user_id IS NULL;
+
+ALTER TABLE carts ADD COLUMN guest_id VARCHAR(64) NULL UNIQUE;
+-- guest carts are removed once they pass expires_at, user carts never expire
+ALTER TABLE carts ADD COLUMN expires_at TIMESTAMP NULL;
+ALTER TABLE carts ADD CONSTRAINT carts_owner_check CHECK ((user_id IS NULL) <> (guest_id IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_carts_guest_expires_at ON carts (expires_at) WHERE guest_id IS NOT NULL;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_cart_items_product_id;
+
+DELETE FROM cart_items WHERE product_id NOT IN (SELECT id FROM products);
+ALTER TABLE cart_items ADD CONSTRAINT cart_items_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+
+ALTER TABLE cart_items DROP COLUMN product_name;
+ALTER TABLE cart_items DROP COLUMN price;
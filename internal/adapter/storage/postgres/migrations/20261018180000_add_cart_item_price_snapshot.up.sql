@@ -0,0 +1,15 @@
+-- the price and name of a product when it was put in the cart, so a later price
+-- change can be flagged instead of silently changing the cart total
+ALTER TABLE cart_items ADD COLUMN price DECIMAL(18,2) NULL;
+ALTER TABLE cart_items ADD COLUMN product_name VARCHAR(255) NULL;
+
+UPDATE cart_items ci SET price = p.price, product_name = p.name
+FROM products p
+WHERE p.id = ci.product_id;
+
+ALTER TABLE cart_items ALTER COLUMN price SET NOT NULL;
+ALTER TABLE cart_items ALTER COLUMN product_name SET NOT NULL;
+
+-- a deleted product stays in the cart flagged as unavailable until the user removes it
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_product_id_fkey;
+CREATE INDEX IF NOT EXISTS idx_cart_items_product_id ON cart_items (product_id);
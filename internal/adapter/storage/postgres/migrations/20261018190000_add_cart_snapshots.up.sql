@@ -0,0 +1,16 @@
+-- carts kept in Redis are copied here by the write-behind consumer for analytics,
+-- cart_id is the Redis cart ID and does not reference the carts table
+CREATE TABLE IF NOT EXISTS cart_snapshots (
+    cart_id BIGINT PRIMARY KEY,
+    user_id INT NULL,
+    guest_id VARCHAR(64) NULL,
+    items JSONB NOT NULL DEFAULT '[]',
+    total_quantity INT NOT NULL DEFAULT 0,
+    total_value DECIMAL(18,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_snapshots_user_id ON cart_snapshots (user_id);
+CREATE INDEX IF NOT EXISTS idx_cart_snapshots_updated_at ON cart_snapshots (updated_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_user_id_created_at;
+DROP INDEX IF EXISTS idx_carts_user_updated_at;
+DROP TABLE IF EXISTS cart_reminder_opt_outs;
+DROP TABLE IF EXISTS cart_reminders;
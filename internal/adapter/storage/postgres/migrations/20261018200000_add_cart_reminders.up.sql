@@ -0,0 +1,23 @@
+-- reminders sent for idle carts. cart_id is a carts row or, with the Redis cart
+-- backend, a cart snapshot, so it has no foreign key
+CREATE TABLE IF NOT EXISTS cart_reminders (
+    id SERIAL PRIMARY KEY,
+    cart_id BIGINT NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    cart_updated_at TIMESTAMP NOT NULL,
+    item_count INT NOT NULL,
+    cart_total DECIMAL(18,2) NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_reminders_cart_id ON cart_reminders (cart_id);
+CREATE INDEX IF NOT EXISTS idx_cart_reminders_sent_at ON cart_reminders (sent_at);
+
+-- users who turned cart reminders off
+CREATE TABLE IF NOT EXISTS cart_reminder_opt_outs (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_carts_user_updated_at ON carts (updated_at) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_orders_user_id_created_at ON orders (user_id, created_at);
@@ -0,0 +1,25 @@
+-- named product lists, a user has at most one default list used by save for later
+CREATE TABLE IF NOT EXISTS wishlists (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    share_token VARCHAR(64) NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlists_user_default ON wishlists (user_id) WHERE is_default;
+
+-- price is the product price when it was saved
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    id SERIAL PRIMARY KEY,
+    wishlist_id INT NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (wishlist_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_product_id ON wishlist_items (product_id);
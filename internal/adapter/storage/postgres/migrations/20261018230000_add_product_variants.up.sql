@@ -0,0 +1,33 @@
+-- option types of a product such as size or color, an empty values list
+-- accepts any value
+CREATE TABLE IF NOT EXISTS product_options (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    "values" TEXT[] NOT NULL DEFAULT '{}',
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, name)
+);
+
+-- a variant holds one value per option of its product, price overrides the
+-- product price when set
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    price DECIMAL(10,2) NULL,
+    stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    barcode VARCHAR(64) NULL UNIQUE,
+    options JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, options)
+);
+
+-- lines of products without variants keep a NULL variant
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS variant_id INT NULL REFERENCES product_variants(id) ON DELETE CASCADE;
+
+-- order history outlives the variant, so there is no foreign key
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id INT NULL;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NULL;
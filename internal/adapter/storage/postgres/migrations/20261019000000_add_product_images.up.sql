@@ -0,0 +1,15 @@
+-- the ordered image gallery of a product, object_key is the file in the
+-- storage bucket and at most one image per product is primary
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    object_key VARCHAR(255) NOT NULL UNIQUE,
+    content_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images (product_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_primary ON product_images (product_id) WHERE is_primary;
@@ -0,0 +1,3 @@
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS derivatives_status,
+    DROP COLUMN IF EXISTS derivatives;
@@ -0,0 +1,5 @@
+-- resized copies of a product image keyed by size name, written by the
+-- product_image_derivatives consumer once the image is uploaded
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS derivatives JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS derivatives_status VARCHAR(16) NOT NULL DEFAULT 'pending';
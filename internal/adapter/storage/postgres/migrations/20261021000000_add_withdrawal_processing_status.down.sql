@@ -0,0 +1,5 @@
+UPDATE withdrawals SET status = 'approved' WHERE status = 'processing';
+
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS withdrawals_status_check;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_status_check
+    CHECK (status IN ('requested', 'approved', 'paid', 'rejected'));
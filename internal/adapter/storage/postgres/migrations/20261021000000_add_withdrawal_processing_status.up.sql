@@ -0,0 +1,4 @@
+-- a withdrawal is processing while its payout is sent, only one approval can claim it
+ALTER TABLE withdrawals DROP CONSTRAINT IF EXISTS withdrawals_status_check;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_status_check
+    CHECK (status IN ('requested', 'approved', 'processing', 'paid', 'rejected'));
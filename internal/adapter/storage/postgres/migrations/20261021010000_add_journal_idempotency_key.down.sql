@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_journal_entries_idempotency_key;
+ALTER TABLE journal_entries DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,3 @@
+-- a business event that may be retried posts its journal entry under a key at most once
+ALTER TABLE journal_entries ADD COLUMN idempotency_key VARCHAR(100) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journal_entries_idempotency_key ON journal_entries (idempotency_key);
@@ -0,0 +1,16 @@
+-- transfer confirmations live in Postgres so a Redis outage doesn't stop transfers
+CREATE TABLE IF NOT EXISTS transfer_inquiries (
+    id VARCHAR(36) PRIMARY KEY,
+    sender_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_name VARCHAR(255) NOT NULL DEFAULT '',
+    amount DECIMAL(18,2) NOT NULL CHECK (amount > 0),
+    verification VARCHAR(20) NOT NULL CHECK (verification IN ('none', 'otp', 'review')),
+    otp_hash VARCHAR(64) NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_inquiries_expires_at ON transfer_inquiries (expires_at);
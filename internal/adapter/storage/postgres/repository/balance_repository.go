@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -14,60 +16,78 @@ import (
 )
 
 type BalanceRepository struct {
-	db        *postgres.DB
-	TableName string
+	db                    *postgres.DB
+	TableName             string
+	LedgerTableName       string
+	AccountTableName      string
+	JournalEntryTableName string
+	JournalLineTableName  string
+	HoldTableName         string
 }
 
 func NewBalanceRepository(db *postgres.DB) *BalanceRepository {
 	return &BalanceRepository{
-		db:        db,
-		TableName: "balances",
+		db:                    db,
+		TableName:             "balances",
+		LedgerTableName:       "balance_transactions",
+		AccountTableName:      "accounts",
+		JournalEntryTableName: "journal_entries",
+		JournalLineTableName:  "journal_lines",
+		HoldTableName:         "balance_holds",
 	}
 }
 
-func (br *BalanceRepository) Withdraw(ctx context.Context, userID uint64, amount float64) error {
+func (br *BalanceRepository) Deposit(ctx context.Context, userID uint64, amount float64) error {
+	return br.Post(ctx, &domain.JournalEntry{
+		EntryType:   domain.TransactionDeposit,
+		Description: "Wallet deposit",
+		Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountPaymentClearing, Amount: -amount},
+			{UserID: userID, Amount: amount},
+		},
+	})
+}
 
+// Pay debits the wallet for an order, the journal references the order ID.
+// Paying an order that is already paid is a no-op
+func (br *BalanceRepository) Pay(ctx context.Context, userID uint64, orderID int, amount float64) error {
 	tx, err := br.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	query := sq.Select("balance").
-		From(br.TableName).
-		Where(sq.Eq{"user_id": userID}).
-		Suffix("FOR UPDATE").PlaceholderFormat(sq.Dollar)
+	// the order is recorded as a captured hold, its unique order ID keeps a
+	// second payment of the same order from being charged
+	tNow := time.Now()
+	query := sq.Insert(br.HoldTableName).
+		Columns("user_id", "order_id", "amount", "status", "expires_at", "created_at", "updated_at").
+		Values(userID, orderID, amount, domain.BalanceHoldCaptured, tNow, tNow, tNow).
+		Suffix("ON CONFLICT (order_id) DO NOTHING").PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return err
 	}
 
-	var balance float64
-	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	tag, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return errors.New("balance record not found")
-		}
 		return err
 	}
 
-	if balance < amount {
-		return consts.ErrInsufficientBalance
+	if tag.RowsAffected() == 0 {
+		return br.settledHold(ctx, tx, orderID)
 	}
 
-	newBalance := balance - amount
-	updateQuery := sq.Update(br.TableName).
-		Set("balance", newBalance).
-		Set("updated_at", time.Now()).
-		Where(sq.Eq{"user_id": userID}).PlaceholderFormat(sq.Dollar)
-
-	sql, args, err = updateQuery.ToSql()
-	if err != nil {
-		return err
-	}
-
-	_, err = tx.Exec(ctx, sql, args...)
+	err = br.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionPayment,
+		ReferenceID: uint64(orderID),
+		Description: "Order payment",
+		Lines: []domain.JournalLine{
+			{UserID: userID, Amount: -amount},
+			{AccountCode: domain.AccountPlatformRevenue, Amount: amount},
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -75,48 +95,86 @@ func (br *BalanceRepository) Withdraw(ctx context.Context, userID uint64, amount
 	return tx.Commit(ctx)
 }
 
-func (br *BalanceRepository) Deposit(ctx context.Context, userID uint64, amount float64) error {
+// Refund credits the wallet back for an order out of platform revenue, the
+// refund is recognized as payable and settled to the wallet in the same entry.
+// Refunds are keyed on the order and together cannot exceed what it paid, so a
+// repeated refund returns ErrRefundExceedsPayment instead of paying again
+func (br *BalanceRepository) Refund(ctx context.Context, userID uint64, orderID int, amount float64) error {
 	tx, err := br.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	query := sq.Select("balance").
-		From(br.TableName).
-		Where(sq.Eq{"user_id": userID}).
-		Suffix("FOR UPDATE").PlaceholderFormat(sq.Dollar)
+	// the wallet row serializes refunds of the same order
+	if _, err := br.lockBalance(ctx, tx, userID); err != nil {
+		return err
+	}
 
-	sql, args, err := query.ToSql()
+	refundable, err := br.refundable(ctx, tx, orderID)
 	if err != nil {
 		return err
 	}
 
-	var balance float64
-	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	if math.Round(amount*100) > math.Round(refundable*100) {
+		return consts.ErrRefundExceedsPayment
+	}
+
+	err = br.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionRefund,
+		ReferenceID: uint64(orderID),
+		Description: "Order refund",
+		Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountPlatformRevenue, Amount: -amount},
+			{AccountCode: domain.AccountRefundsPayable, Amount: amount},
+			{AccountCode: domain.AccountRefundsPayable, Amount: -amount},
+			{UserID: userID, Amount: amount},
+		},
+	})
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return errors.New("balance record not found")
-		}
 		return err
 	}
 
-	// Update balance
-	newBalance := balance + amount
-	updateQuery := sq.Update(br.TableName).
-		Set("balance", newBalance).
-		Set("updated_at", time.Now()).
-		Where(sq.Eq{"user_id": userID}).PlaceholderFormat(sq.Dollar)
+	return tx.Commit(ctx)
+}
 
-	sql, args, err = updateQuery.ToSql()
+// refundable is what the order paid into platform revenue less what was
+// already refunded out of it
+func (br *BalanceRepository) refundable(ctx context.Context, tx pgx.Tx, orderID int) (float64, error) {
+	query := sq.Select("COALESCE(SUM(l.amount), 0)").
+		From(br.JournalLineTableName + " l").
+		Join(br.JournalEntryTableName + " e ON e.id = l.journal_entry_id").
+		Join(br.AccountTableName + " a ON a.id = l.account_id").
+		Where(sq.Eq{
+			"a.code":         domain.AccountPlatformRevenue,
+			"e.reference_id": orderID,
+			"e.entry_type":   []domain.TransactionType{domain.TransactionPayment, domain.TransactionRefund},
+		}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	var refundable float64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&refundable); err != nil {
+		return 0, err
+	}
+
+	return refundable, nil
+}
+
+// Post records a balanced journal entry and updates the wallet projections it touches
+func (br *BalanceRepository) Post(ctx context.Context, entry *domain.JournalEntry) error {
+	tx, err := br.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	if err := br.post(ctx, tx, entry); err != nil {
+		return err
+	}
 
 	return tx.Commit(ctx)
 }
@@ -139,105 +197,223 @@ func (br *BalanceRepository) GetBalance(ctx context.Context, userID uint64) (flo
 
 	return balance, nil
 }
-func (br *BalanceRepository) Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64) (*domain.Balance, *domain.Balance, error) {
-	// Ensure sender and receiver are different
-	if fromUserID == toUserID {
-		return nil, nil, errors.New("cannot transfer to the same account")
+
+// GetHeldBalance returns the funds reserved for the user's pending orders
+func (br *BalanceRepository) GetHeldBalance(ctx context.Context, userID uint64) (float64, error) {
+	var held float64
+	query := br.db.QueryBuilder.Select("held_balance").
+		From(br.TableName).
+		Where(sq.Eq{"user_id": userID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
 	}
 
-	// Start database transaction
+	err = br.db.QueryRow(ctx, sql, args...).Scan(&held)
+	if err != nil {
+		return 0, err
+	}
+
+	return held, nil
+}
+
+// Hold moves the amount from the available balance to the held balance for an order
+func (br *BalanceRepository) Hold(ctx context.Context, userID uint64, orderID int, amount float64, expiresAt time.Time) (*domain.BalanceHold, error) {
 	tx, err := br.db.Begin(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	// Fetch sender's balance with row lock
-	var senderBalance float64
-	senderQuery := sq.Select("balance").
-		From(br.TableName).
-		Where(sq.Eq{"user_id": fromUserID}).
-		Suffix("FOR UPDATE").PlaceholderFormat(sq.Dollar)
+	tNow := time.Now()
+	hold := &domain.BalanceHold{
+		UserID:    userID,
+		OrderID:   orderID,
+		Amount:    amount,
+		Status:    domain.BalanceHoldHeld,
+		ExpiresAt: expiresAt,
+		CreatedAt: tNow,
+		UpdatedAt: tNow,
+	}
 
-	sql, args, err := senderQuery.ToSql()
+	query := sq.Insert(br.HoldTableName).
+		Columns("user_id", "order_id", "amount", "status", "expires_at", "created_at", "updated_at").
+		Values(hold.UserID, hold.OrderID, hold.Amount, hold.Status, hold.ExpiresAt, hold.CreatedAt, hold.UpdatedAt).
+		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&hold.ID); err != nil {
+		return nil, err
 	}
 
-	err = tx.QueryRow(ctx, sql, args...).Scan(&senderBalance)
+	err = br.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionHold,
+		ReferenceID: uint64(orderID),
+		Description: "Funds held for order",
+		Lines: []domain.JournalLine{
+			{UserID: userID, Amount: -amount},
+			{AccountCode: domain.AccountWalletHolds, Amount: amount},
+		},
+	})
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, nil, errors.New("sender balance record not found")
-		}
-		return nil, nil, err
+		return nil, err
 	}
 
-	// Check if sender has enough balance
-	if senderBalance < amount {
-		return nil, nil, consts.ErrInsufficientBalance
+	if err := br.adjustHeld(ctx, tx, userID, amount); err != nil {
+		return nil, err
 	}
 
-	// Fetch receiver's balance with row lock
-	var receiverBalance float64
-	receiverQuery := sq.Select("balance").
-		From(br.TableName).
-		Where(sq.Eq{"user_id": toUserID}).
-		Suffix("FOR UPDATE").PlaceholderFormat(sq.Dollar)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
 
-	sql, args, err = receiverQuery.ToSql()
+// CaptureHold settles the order's held funds as a payment. Capturing an order
+// that is already paid is a no-op, an order whose hold was released or has
+// expired returns ErrHoldReleased and one that never had a hold
+// ErrHoldNotFound
+func (br *BalanceRepository) CaptureHold(ctx context.Context, orderID int) error {
+	tx, err := br.db.Begin(ctx)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	err = tx.QueryRow(ctx, sql, args...).Scan(&receiverBalance)
+	hold, err := br.closeHold(ctx, tx, orderID, domain.BalanceHoldCaptured)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, nil, errors.New("receiver balance record not found")
-		}
-		return nil, nil, err
+		return err
+	}
+	if hold == nil {
+		return br.settledHold(ctx, tx, orderID)
+	}
+	if !hold.ExpiresAt.After(time.Now()) {
+		return consts.ErrHoldReleased
 	}
 
-	// Update sender's balance
-	updateSenderQuery := sq.Update(br.TableName).
-		Set("balance", senderBalance-amount).
-		Set("updated_at", time.Now()).
-		Where(sq.Eq{"user_id": fromUserID}).PlaceholderFormat(sq.Dollar)
+	err = br.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionPayment,
+		ReferenceID: uint64(orderID),
+		Description: "Order payment from held funds",
+		Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountWalletHolds, Amount: -hold.Amount},
+			{AccountCode: domain.AccountPlatformRevenue, Amount: hold.Amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := br.adjustHeld(ctx, tx, hold.UserID, -hold.Amount); err != nil {
+		return err
+	}
 
-	sql, args, err = updateSenderQuery.ToSql()
+	return tx.Commit(ctx)
+}
+
+// ReleaseHold returns the order's held funds to the available balance,
+// releasing an order without an active hold is a no-op
+func (br *BalanceRepository) ReleaseHold(ctx context.Context, orderID int) error {
+	tx, err := br.db.Begin(ctx)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
-	_, err = tx.Exec(ctx, sql, args...)
+	defer tx.Rollback(ctx)
+
+	hold, err := br.closeHold(ctx, tx, orderID, domain.BalanceHoldReleased)
 	if err != nil {
-		return nil, nil, err
+		return err
+	}
+	if hold == nil {
+		return nil
 	}
 
-	// Update receiver's balance
-	updateReceiverQuery := sq.Update(br.TableName).
-		Set("balance", receiverBalance+amount).
-		Set("updated_at", time.Now()).
-		Where(sq.Eq{"user_id": toUserID}).PlaceholderFormat(sq.Dollar)
+	err = br.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionHoldRelease,
+		ReferenceID: uint64(orderID),
+		Description: "Held funds released",
+		Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountWalletHolds, Amount: -hold.Amount},
+			{UserID: hold.UserID, Amount: hold.Amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := br.adjustHeld(ctx, tx, hold.UserID, -hold.Amount); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Transfer moves the amount between two wallets, the sender's caps are checked
+// while its balance row is locked so concurrent transfers cannot exceed them.
+// A transfer whose idempotency key was already posted returns ErrAlreadyPosted
+func (br *BalanceRepository) Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64, referenceID uint64, idempotencyKey string, limit domain.TransferLimit) (*domain.Balance, *domain.Balance, error) {
+	// Ensure sender and receiver are different
+	if fromUserID == toUserID {
+		return nil, nil, errors.New("cannot transfer to the same account")
+	}
 
-	sql, args, err = updateReceiverQuery.ToSql()
+	// Start database transaction
+	tx, err := br.db.Begin(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	_, err = tx.Exec(ctx, sql, args...)
+	defer tx.Rollback(ctx)
+
+	// Lock both rows in a consistent order to prevent deadlocks
+	firstID, secondID := fromUserID, toUserID
+	if toUserID < fromUserID {
+		firstID, secondID = toUserID, fromUserID
+	}
+
+	for _, userID := range []uint64{firstID, secondID} {
+		if _, err := br.lockBalance(ctx, tx, userID); err != nil {
+			if err == pgx.ErrNoRows {
+				if userID == fromUserID {
+					return nil, nil, errors.New("sender balance record not found")
+				}
+				return nil, nil, errors.New("receiver balance record not found")
+			}
+			return nil, nil, err
+		}
+	}
+
+	if err := br.checkTransferLimit(ctx, tx, fromUserID, amount, limit); err != nil {
+		return nil, nil, err
+	}
+
+	// Debit the sender and credit the receiver in one journal entry
+	err = br.post(ctx, tx, &domain.JournalEntry{
+		EntryType:      domain.TransactionTransfer,
+		ReferenceID:    referenceID,
+		IdempotencyKey: idempotencyKey,
+		Description:    "Wallet transfer",
+		Lines: []domain.JournalLine{
+			{UserID: fromUserID, Amount: -amount},
+			{UserID: toUserID, Amount: amount},
+		},
+	})
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Fetch updated balances
-	var updatedSender, updatedReceiver domain.Balance
-
-	err = tx.QueryRow(ctx, "SELECT id, user_id, balance, created_at, updated_at FROM balances WHERE user_id = $1", fromUserID).
-		Scan(&updatedSender.ID, &updatedSender.UserID, &updatedSender.Balance, &updatedSender.CreatedAt, &updatedSender.UpdatedAt)
+	updatedSender, err := br.findByUserID(ctx, tx, fromUserID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	err = tx.QueryRow(ctx, "SELECT id, user_id, balance, created_at, updated_at FROM balances WHERE user_id = $1", toUserID).
-		Scan(&updatedReceiver.ID, &updatedReceiver.UserID, &updatedReceiver.Balance, &updatedReceiver.CreatedAt, &updatedReceiver.UpdatedAt)
+	updatedReceiver, err := br.findByUserID(ctx, tx, toUserID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -247,7 +423,7 @@ func (br *BalanceRepository) Transfer(ctx context.Context, fromUserID, toUserID
 		return nil, nil, err
 	}
 
-	return &updatedSender, &updatedReceiver, nil
+	return updatedSender, updatedReceiver, nil
 }
 
 func (br *BalanceRepository) Store(ctx context.Context, data *domain.Balance) error {
@@ -274,3 +450,426 @@ func (br *BalanceRepository) Store(ctx context.Context, data *domain.Balance) er
 
 	return nil
 }
+
+// Reconcile returns every user whose stored balance differs from the sum of their ledger rows
+func (br *BalanceRepository) Reconcile(ctx context.Context) ([]domain.BalanceReconciliation, error) {
+	ledger := br.db.QueryBuilder.Select("user_id", "SUM(amount) AS total").
+		From(br.LedgerTableName).
+		GroupBy("user_id")
+
+	ledgerSql, ledgerArgs, err := ledger.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query := br.db.QueryBuilder.Select("b.user_id", "b.balance", "COALESCE(l.total, 0)").
+		From(br.TableName+" b").
+		LeftJoin("("+ledgerSql+") l ON l.user_id = b.user_id", ledgerArgs...).
+		Where("b.balance <> COALESCE(l.total, 0)").
+		OrderBy("b.user_id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := br.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []domain.BalanceReconciliation
+	for rows.Next() {
+		var data domain.BalanceReconciliation
+		if err := rows.Scan(&data.UserID, &data.Balance, &data.LedgerSum); err != nil {
+			return nil, err
+		}
+
+		data.Difference = data.Balance - data.LedgerSum
+		mismatches = append(mismatches, data)
+	}
+
+	return mismatches, rows.Err()
+}
+
+// RebuildBalances recomputes every wallet balance from the journal, and the held
+// balance from the active holds, and returns how many were corrected
+func (br *BalanceRepository) RebuildBalances(ctx context.Context) (int64, error) {
+	heldTotal := "(SELECT COALESCE(SUM(h.amount), 0) FROM " + br.HoldTableName + " h WHERE h.user_id = b.user_id AND h.status = '" + string(domain.BalanceHoldHeld) + "') AS held"
+	totals := br.db.QueryBuilder.Select("b.user_id", "COALESCE(SUM(l.amount), 0) AS total", heldTotal).
+		From(br.TableName + " b").
+		LeftJoin(br.AccountTableName + " a ON a.user_id = b.user_id").
+		LeftJoin(br.JournalLineTableName + " l ON l.account_id = a.id").
+		GroupBy("b.user_id")
+
+	totalsSql, totalsArgs, err := totals.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	query := br.db.QueryBuilder.Update(br.TableName+" b").
+		Set("balance", sq.Expr("j.total")).
+		Set("held_balance", sq.Expr("j.held")).
+		Set("updated_at", time.Now()).
+		Suffix("FROM ("+totalsSql+") j WHERE j.user_id = b.user_id AND (b.balance <> j.total OR b.held_balance <> j.held)", totalsArgs...)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := br.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// post inserts the journal entry and its lines inside the given transaction,
+// wallet lines also move the balance projection and append a statement row
+func (br *BalanceRepository) post(ctx context.Context, tx pgx.Tx, entry *domain.JournalEntry) error {
+	if !entry.IsBalanced() {
+		return consts.ErrUnbalancedJournal
+	}
+
+	entry.CreatedAt = time.Now()
+	entryQuery := sq.Insert(br.JournalEntryTableName).
+		Columns("entry_type", "reference_id", "idempotency_key", "description", "created_by", "created_at").
+		Values(entry.EntryType, nullUint64(entry.ReferenceID), nullString(entry.IdempotencyKey), entry.Description, nullUint64(entry.CreatedBy), entry.CreatedAt).
+		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := entryQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&entry.ID); err != nil {
+		if entry.IdempotencyKey != "" && uniqueViolation(err) == consts.ErrConflictingData {
+			return consts.ErrAlreadyPosted
+		}
+		return err
+	}
+
+	// a journal between exactly two wallets is a transfer, each side sees the other as counterparty
+	var wallets []uint64
+	for _, line := range entry.Lines {
+		if line.UserID != 0 {
+			wallets = append(wallets, line.UserID)
+		}
+	}
+
+	for i := range entry.Lines {
+		line := &entry.Lines[i]
+		line.JournalEntryID = entry.ID
+
+		if line.UserID != 0 {
+			line.AccountCode = domain.WalletAccountCode(line.UserID)
+		}
+
+		line.AccountID, err = br.findAccountID(ctx, tx, line.AccountCode, line.UserID)
+		if err != nil {
+			return err
+		}
+
+		lineQuery := sq.Insert(br.JournalLineTableName).
+			Columns("journal_entry_id", "account_id", "amount", "created_at").
+			Values(line.JournalEntryID, line.AccountID, line.Amount, entry.CreatedAt).
+			Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+		sql, args, err := lineQuery.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&line.ID); err != nil {
+			return err
+		}
+
+		if line.UserID == 0 {
+			continue
+		}
+
+		var counterpartyID uint64
+		if len(wallets) == 2 {
+			counterpartyID = wallets[0]
+			if counterpartyID == line.UserID {
+				counterpartyID = wallets[1]
+			}
+		}
+
+		_, err = br.move(ctx, tx, &domain.BalanceTransaction{
+			UserID:          line.UserID,
+			Amount:          line.Amount,
+			TransactionType: entry.EntryType,
+			ReferenceID:     entry.ReferenceID,
+			CounterpartyID:  counterpartyID,
+			JournalEntryID:  entry.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findAccountID resolves an account by code, wallet accounts are opened on first use
+func (br *BalanceRepository) findAccountID(ctx context.Context, tx pgx.Tx, code string, userID uint64) (uint64, error) {
+	if userID != 0 {
+		insertQuery := sq.Insert(br.AccountTableName).
+			Columns("code", "name", "account_type", "user_id").
+			Values(code, fmt.Sprintf("Wallet of user %d", userID), domain.AccountLiability, userID).
+			Suffix("ON CONFLICT (code) DO NOTHING").PlaceholderFormat(sq.Dollar)
+
+		sql, args, err := insertQuery.ToSql()
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return 0, err
+		}
+	}
+
+	query := sq.Select("id").
+		From(br.AccountTableName).
+		Where(sq.Eq{"code": code}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var id uint64
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("account %s not found", code)
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// checkTransferLimit compares the amount, plus what the user already sent today
+// and this month, against the caps
+func (br *BalanceRepository) checkTransferLimit(ctx context.Context, tx pgx.Tx, userID uint64, amount float64, limit domain.TransferLimit) error {
+	if limit.PerTransaction > 0 && amount > limit.PerTransaction {
+		return consts.ErrTransferAmountLimitExceeded
+	}
+
+	if limit.Daily <= 0 && limit.Monthly <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	query := sq.Select().
+		Column(sq.Expr("COALESCE(SUM(-amount) FILTER (WHERE created_at >= ?), 0)", dayStart)).
+		Column("COALESCE(SUM(-amount), 0)").
+		From(br.LedgerTableName).
+		Where(sq.Eq{"user_id": userID, "transaction_type": domain.TransactionTransfer}).
+		Where(sq.Lt{"amount": 0}).
+		Where(sq.GtOrEq{"created_at": monthStart}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	var sentToday, sentThisMonth float64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&sentToday, &sentThisMonth); err != nil {
+		return err
+	}
+
+	if limit.Daily > 0 && sentToday+amount > limit.Daily {
+		return consts.ErrDailyTransferLimitExceeded
+	}
+	if limit.Monthly > 0 && sentThisMonth+amount > limit.Monthly {
+		return consts.ErrMonthlyTransferLimitExceeded
+	}
+
+	return nil
+}
+
+// closeHold moves the order's active hold to the given status, it returns nil when there is none
+func (br *BalanceRepository) closeHold(ctx context.Context, tx pgx.Tx, orderID int, status domain.BalanceHoldStatus) (*domain.BalanceHold, error) {
+	query := sq.Update(br.HoldTableName).
+		Set("status", status).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"order_id": orderID, "status": domain.BalanceHoldHeld}).
+		Suffix("RETURNING id, user_id, order_id, amount, status, expires_at, created_at, updated_at").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var hold domain.BalanceHold
+	err = tx.QueryRow(ctx, sql, args...).Scan(
+		&hold.ID,
+		&hold.UserID,
+		&hold.OrderID,
+		&hold.Amount,
+		&hold.Status,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// settledHold explains why the order has no active hold: nil when it is
+// already paid, ErrHoldReleased when its funds were released and
+// ErrHoldNotFound when it never had one
+func (br *BalanceRepository) settledHold(ctx context.Context, tx pgx.Tx, orderID int) error {
+	query := sq.Select("status").
+		From(br.HoldTableName).
+		Where(sq.Eq{"order_id": orderID}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	var status domain.BalanceHoldStatus
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&status); err != nil {
+		if err == pgx.ErrNoRows {
+			return consts.ErrHoldNotFound
+		}
+		return err
+	}
+
+	switch status {
+	case domain.BalanceHoldCaptured:
+		return nil
+	case domain.BalanceHoldReleased:
+		return consts.ErrHoldReleased
+	}
+
+	return consts.ErrHoldNotFound
+}
+
+// adjustHeld changes the user's held balance by delta
+func (br *BalanceRepository) adjustHeld(ctx context.Context, tx pgx.Tx, userID uint64, delta float64) error {
+	query := sq.Update(br.TableName).
+		Set("held_balance", sq.Expr("held_balance + ?", delta)).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"user_id": userID}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	return err
+}
+
+// lockBalance reads the user's balance and holds a row lock until the transaction ends
+func (br *BalanceRepository) lockBalance(ctx context.Context, tx pgx.Tx, userID uint64) (float64, error) {
+	query := sq.Select("balance").
+		From(br.TableName).
+		Where(sq.Eq{"user_id": userID}).
+		Suffix("FOR UPDATE").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var balance float64
+	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// move applies a signed amount to the user's balance projection and appends the
+// matching statement row, both inside the given transaction
+func (br *BalanceRepository) move(ctx context.Context, tx pgx.Tx, entry *domain.BalanceTransaction) (*domain.BalanceTransaction, error) {
+	balance, err := br.lockBalance(ctx, tx, entry.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New("balance record not found")
+		}
+		return nil, err
+	}
+
+	newBalance := balance + entry.Amount
+	if newBalance < 0 {
+		return nil, consts.ErrInsufficientBalance
+	}
+
+	tNow := time.Now()
+	updateQuery := sq.Update(br.TableName).
+		Set("balance", newBalance).
+		Set("updated_at", tNow).
+		Where(sq.Eq{"user_id": entry.UserID}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := updateQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.BalanceBefore = balance
+	entry.BalanceAfter = newBalance
+	entry.CreatedAt = tNow
+
+	insertQuery := sq.Insert(br.LedgerTableName).
+		Columns("user_id", "amount", "balance_before", "balance_after", "transaction_type", "reference_id", "counterparty_id", "journal_entry_id", "created_at").
+		Values(entry.UserID, entry.Amount, entry.BalanceBefore, entry.BalanceAfter, entry.TransactionType, nullUint64(entry.ReferenceID), nullUint64(entry.CounterpartyID), nullUint64(entry.JournalEntryID), entry.CreatedAt).
+		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err = insertQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (br *BalanceRepository) findByUserID(ctx context.Context, tx pgx.Tx, userID uint64) (*domain.Balance, error) {
+	var balance domain.Balance
+
+	query := sq.Select("id", "user_id", "balance", "held_balance", "created_at", "updated_at").
+		From(br.TableName).
+		Where(sq.Eq{"user_id": userID}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&balance.ID, &balance.UserID, &balance.Balance, &balance.HeldBalance, &balance.CreatedAt, &balance.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
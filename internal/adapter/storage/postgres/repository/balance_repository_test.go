@@ -0,0 +1,253 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres/repository"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// newTestOrder inserts a pending order of the user
+func newTestOrder(t *testing.T, db *postgres.DB, userID uint64, total float64) int {
+	t.Helper()
+
+	var id int
+	err := db.QueryRow(context.Background(),
+		"INSERT INTO orders (user_id, total_price, status) VALUES ($1, $2, 'pending') RETURNING id",
+		userID, total,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("inserting order: %v", err)
+	}
+
+	return id
+}
+
+// checkWallet compares the balance and held balance of a wallet with want, and
+// the projection with the journal, the statement and the active holds
+func checkWallet(t *testing.T, db *postgres.DB, userID uint64, wantBalance, wantHeld float64) {
+	t.Helper()
+
+	var balance, held, journal, statement, holds float64
+	err := db.QueryRow(context.Background(), `
+		SELECT b.balance, b.held_balance,
+			COALESCE((SELECT SUM(l.amount) FROM journal_lines l JOIN accounts a ON a.id = l.account_id WHERE a.user_id = b.user_id), 0),
+			COALESCE((SELECT SUM(t.amount) FROM balance_transactions t WHERE t.user_id = b.user_id), 0),
+			COALESCE((SELECT SUM(h.amount) FROM balance_holds h WHERE h.user_id = b.user_id AND h.status = 'held'), 0)
+		FROM balances b
+		WHERE b.user_id = $1`, userID,
+	).Scan(&balance, &held, &journal, &statement, &holds)
+	if err != nil {
+		t.Fatalf("reading wallet: %v", err)
+	}
+
+	if balance != wantBalance || held != wantHeld {
+		t.Fatalf("wallet of %d = %v held %v, want %v held %v", userID, balance, held, wantBalance, wantHeld)
+	}
+	if journal != balance || statement != balance {
+		t.Fatalf("wallet of %d = %v, journal %v and statement %v", userID, balance, journal, statement)
+	}
+	if holds != held {
+		t.Fatalf("held balance of %d = %v, active holds %v", userID, held, holds)
+	}
+}
+
+// checkJournalBalanced fails when an entry touching the wallets of users does
+// not sum to zero
+func checkJournalBalanced(t *testing.T, db *postgres.DB, users []uint64) {
+	t.Helper()
+
+	var unbalanced int
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM (
+			SELECT l.journal_entry_id
+			FROM journal_lines l
+			WHERE l.journal_entry_id IN (
+				SELECT w.journal_entry_id FROM journal_lines w JOIN accounts a ON a.id = w.account_id WHERE a.user_id = ANY($1)
+			)
+			GROUP BY l.journal_entry_id
+			HAVING SUM(l.amount) <> 0
+		) e`, users,
+	).Scan(&unbalanced)
+	if err != nil {
+		t.Fatalf("reading journal: %v", err)
+	}
+
+	if unbalanced != 0 {
+		t.Fatalf("%d journal entries do not sum to zero", unbalanced)
+	}
+}
+
+func TestBalanceRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewBalanceRepository(db)
+	ctx := context.Background()
+
+	t.Run("transfer", func(t *testing.T) {
+		users := newTestWallets(t, db, 2, 100)
+
+		sender, receiver, err := repo.Transfer(ctx, users[0], users[1], 30, 0, "", domain.TransferLimit{})
+		if err != nil {
+			t.Fatalf("Transfer: %v", err)
+		}
+		if sender.Balance != 70 || receiver.Balance != 130 {
+			t.Fatalf("Transfer = %v and %v, want 70 and 130", sender.Balance, receiver.Balance)
+		}
+
+		if _, _, err := repo.Transfer(ctx, users[0], users[1], 71, 0, "", domain.TransferLimit{}); !errors.Is(err, consts.ErrInsufficientBalance) {
+			t.Fatalf("Transfer over the balance = %v, want ErrInsufficientBalance", err)
+		}
+
+		checkWallet(t, db, users[0], 70, 0)
+		checkWallet(t, db, users[1], 130, 0)
+		checkJournalBalanced(t, db, users)
+	})
+
+	t.Run("hold capture and release", func(t *testing.T) {
+		users := newTestWallets(t, db, 1, 100)
+		captured := newTestOrder(t, db, users[0], 20)
+		released := newTestOrder(t, db, users[0], 10)
+		expiresAt := time.Now().Add(time.Hour)
+
+		if _, err := repo.Hold(ctx, users[0], captured, 20, expiresAt); err != nil {
+			t.Fatalf("Hold: %v", err)
+		}
+		checkWallet(t, db, users[0], 80, 20)
+
+		if err := repo.CaptureHold(ctx, captured); err != nil {
+			t.Fatalf("CaptureHold: %v", err)
+		}
+		checkWallet(t, db, users[0], 80, 0)
+
+		// a captured order is paid, capturing or paying it again charges nothing
+		if err := repo.CaptureHold(ctx, captured); err != nil {
+			t.Fatalf("second CaptureHold: %v", err)
+		}
+		if err := repo.Pay(ctx, users[0], captured, 20); err != nil {
+			t.Fatalf("Pay of a captured order: %v", err)
+		}
+		checkWallet(t, db, users[0], 80, 0)
+
+		if _, err := repo.Hold(ctx, users[0], released, 10, expiresAt); err != nil {
+			t.Fatalf("Hold: %v", err)
+		}
+		checkWallet(t, db, users[0], 70, 10)
+
+		if err := repo.ReleaseHold(ctx, released); err != nil {
+			t.Fatalf("ReleaseHold: %v", err)
+		}
+		if err := repo.ReleaseHold(ctx, released); err != nil {
+			t.Fatalf("second ReleaseHold: %v", err)
+		}
+		checkWallet(t, db, users[0], 80, 0)
+
+		if err := repo.CaptureHold(ctx, released); !errors.Is(err, consts.ErrHoldReleased) {
+			t.Fatalf("CaptureHold of a released hold = %v, want ErrHoldReleased", err)
+		}
+		checkWallet(t, db, users[0], 80, 0)
+		checkJournalBalanced(t, db, users)
+	})
+
+	t.Run("refund is capped at the payment", func(t *testing.T) {
+		users := newTestWallets(t, db, 1, 100)
+		paid := newTestOrder(t, db, users[0], 50)
+		unpaid := newTestOrder(t, db, users[0], 50)
+
+		if err := repo.Pay(ctx, users[0], paid, 50); err != nil {
+			t.Fatalf("Pay: %v", err)
+		}
+		checkWallet(t, db, users[0], 50, 0)
+
+		if err := repo.Refund(ctx, users[0], paid, 30); err != nil {
+			t.Fatalf("Refund: %v", err)
+		}
+		if err := repo.Refund(ctx, users[0], paid, 30); !errors.Is(err, consts.ErrRefundExceedsPayment) {
+			t.Fatalf("Refund over the rest = %v, want ErrRefundExceedsPayment", err)
+		}
+		if err := repo.Refund(ctx, users[0], paid, 20); err != nil {
+			t.Fatalf("Refund of the rest: %v", err)
+		}
+		if err := repo.Refund(ctx, users[0], paid, 0.01); !errors.Is(err, consts.ErrRefundExceedsPayment) {
+			t.Fatalf("Refund of a refunded order = %v, want ErrRefundExceedsPayment", err)
+		}
+		if err := repo.Refund(ctx, users[0], unpaid, 10); !errors.Is(err, consts.ErrRefundExceedsPayment) {
+			t.Fatalf("Refund of an unpaid order = %v, want ErrRefundExceedsPayment", err)
+		}
+
+		checkWallet(t, db, users[0], 100, 0)
+		checkJournalBalanced(t, db, users)
+	})
+
+	t.Run("idempotency key replay", func(t *testing.T) {
+		users := newTestWallets(t, db, 2, 100)
+		key := "repository-test:" + time.Now().Format(time.RFC3339Nano)
+
+		if _, _, err := repo.Transfer(ctx, users[0], users[1], 25, 0, key, domain.TransferLimit{}); err != nil {
+			t.Fatalf("Transfer: %v", err)
+		}
+		if _, _, err := repo.Transfer(ctx, users[0], users[1], 25, 0, key, domain.TransferLimit{}); !errors.Is(err, consts.ErrAlreadyPosted) {
+			t.Fatalf("replayed Transfer = %v, want ErrAlreadyPosted", err)
+		}
+
+		checkWallet(t, db, users[0], 75, 0)
+		checkWallet(t, db, users[1], 125, 0)
+	})
+
+	t.Run("unbalanced entry", func(t *testing.T) {
+		users := newTestWallets(t, db, 1, 100)
+
+		err := repo.Post(ctx, &domain.JournalEntry{
+			EntryType:   domain.TransactionDeposit,
+			Description: "Unbalanced",
+			Lines: []domain.JournalLine{
+				{AccountCode: domain.AccountPaymentClearing, Amount: -10},
+				{UserID: users[0], Amount: 20},
+			},
+		})
+		if !errors.Is(err, consts.ErrUnbalancedJournal) {
+			t.Fatalf("Post = %v, want ErrUnbalancedJournal", err)
+		}
+
+		checkWallet(t, db, users[0], 100, 0)
+	})
+
+	t.Run("rebuild clears drift", func(t *testing.T) {
+		users := newTestWallets(t, db, 1, 100)
+
+		if _, err := db.Exec(ctx, "UPDATE balances SET balance = 999 WHERE user_id = $1", users[0]); err != nil {
+			t.Fatalf("corrupting balance: %v", err)
+		}
+
+		drifted := func() *domain.BalanceReconciliation {
+			t.Helper()
+			mismatches, err := repo.Reconcile(ctx)
+			if err != nil {
+				t.Fatalf("Reconcile: %v", err)
+			}
+			for _, mismatch := range mismatches {
+				if mismatch.UserID == users[0] {
+					return &mismatch
+				}
+			}
+			return nil
+		}
+
+		if mismatch := drifted(); mismatch == nil || mismatch.LedgerSum != 100 || mismatch.Difference != 899 {
+			t.Fatalf("Reconcile = %+v, want the 899 drift of the wallet", mismatch)
+		}
+
+		if _, err := repo.RebuildBalances(ctx); err != nil {
+			t.Fatalf("RebuildBalances: %v", err)
+		}
+
+		if mismatch := drifted(); mismatch != nil {
+			t.Fatalf("Reconcile after RebuildBalances = %+v, want no drift", mismatch)
+		}
+		checkWallet(t, db, users[0], 100, 0)
+	})
+}
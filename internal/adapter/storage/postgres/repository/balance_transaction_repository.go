@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type BalanceTransactionRepository struct {
+	db        *postgres.DB
+	TableName string
+}
+
+func NewBalanceTransactionRepository(db *postgres.DB) *BalanceTransactionRepository {
+	return &BalanceTransactionRepository{
+		db:        db,
+		TableName: "balance_transactions",
+	}
+}
+
+// Finds retrieves a page of ledger rows, newest first
+func (r *BalanceTransactionRepository) Finds(ctx context.Context, filter domain.BalanceTransactionFilter) ([]domain.BalanceTransaction, error) {
+	query := r.buildQuery(filter)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []domain.BalanceTransaction
+	for rows.Next() {
+		transaction, err := scanBalanceTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// Stream walks every matching ledger row without buffering the result set
+func (r *BalanceTransactionRepository) Stream(ctx context.Context, filter domain.BalanceTransactionFilter, fn func(domain.BalanceTransaction) error) error {
+	sql, args, err := r.buildQuery(filter).ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanBalanceTransaction(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *BalanceTransactionRepository) buildQuery(filter domain.BalanceTransactionFilter) sq.SelectBuilder {
+	query := r.db.QueryBuilder.Select("id", "user_id", "amount", "balance_before", "balance_after", "transaction_type", "COALESCE(reference_id, 0)", "COALESCE(counterparty_id, 0)", "created_at").
+		From(r.TableName).
+		Where(sq.Eq{"user_id": filter.UserID}).
+		OrderBy("id DESC")
+
+	if filter.TransactionType != "" {
+		query = query.Where(sq.Eq{"transaction_type": filter.TransactionType})
+	}
+	if !filter.From.IsZero() {
+		query = query.Where(sq.GtOrEq{"created_at": filter.From})
+	}
+	if !filter.To.IsZero() {
+		query = query.Where(sq.Lt{"created_at": filter.To})
+	}
+	if filter.MinAmount > 0 {
+		query = query.Where(sq.GtOrEq{"ABS(amount)": filter.MinAmount})
+	}
+	if filter.MaxAmount > 0 {
+		query = query.Where(sq.LtOrEq{"ABS(amount)": filter.MaxAmount})
+	}
+	if filter.CounterpartyID != 0 {
+		query = query.Where(sq.Eq{"counterparty_id": filter.CounterpartyID})
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where(sq.Lt{"id": filter.BeforeID})
+	}
+
+	return query
+}
+
+func scanBalanceTransaction(rows pgx.Rows) (domain.BalanceTransaction, error) {
+	var transaction domain.BalanceTransaction
+	err := rows.Scan(
+		&transaction.ID,
+		&transaction.UserID,
+		&transaction.Amount,
+		&transaction.BalanceBefore,
+		&transaction.BalanceAfter,
+		&transaction.TransactionType,
+		&transaction.ReferenceID,
+		&transaction.CounterpartyID,
+		&transaction.CreatedAt,
+	)
+
+	return transaction, err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -22,11 +23,23 @@ func NewCartItemRepository(db *postgres.DB) *CartItemRepository {
 	}
 }
 
+var cartItemColumns = []string{
+	"id",
+	"cart_id",
+	"product_id",
+	"COALESCE(variant_id, 0)",
+	"quantity",
+	"price",
+	"product_name",
+	"created_at",
+	"updated_at",
+}
+
 // FindOne retrieves a single Categories by ID
 func (r *CartItemRepository) FindOne(ctx context.Context, id int) (*domain.CartItem, error) {
 	var cartItem domain.CartItem
 
-	query := r.db.QueryBuilder.Select("id", "cart_id", "product_id", "quantity", "created_at", "updated_at").
+	query := r.db.QueryBuilder.Select(cartItemColumns...).
 		From(r.TableName).
 		Where(sq.Eq{"id": id}).
 		Limit(1)
@@ -40,7 +53,10 @@ func (r *CartItemRepository) FindOne(ctx context.Context, id int) (*domain.CartI
 		&cartItem.ID,
 		&cartItem.CartID,
 		&cartItem.ProductID,
+		&cartItem.VariantID,
 		&cartItem.Quantity,
+		&cartItem.Price,
+		&cartItem.ProductName,
 		&cartItem.CreatedAt,
 		&cartItem.UpdatedAt,
 	)
@@ -55,10 +71,14 @@ func (r *CartItemRepository) FindOne(ctx context.Context, id int) (*domain.CartI
 }
 
 func (r *CartItemRepository) FindOneByFilters(ctx context.Context, filter map[string]interface{}) (*domain.CartItem, error) {
-	query := r.db.QueryBuilder.Select("*").From(r.TableName)
+	query := r.db.QueryBuilder.Select(cartItemColumns...).From(r.TableName)
 
-	// Apply filters if provided
+	// Apply filters if provided, variant_id 0 matches the lines without a variant
 	for key, value := range filter {
+		if key == "variant_id" {
+			query = query.Where(sq.Eq{"COALESCE(variant_id, 0)": value})
+			continue
+		}
 		query = query.Where(sq.Eq{key: value})
 	}
 
@@ -72,7 +92,10 @@ func (r *CartItemRepository) FindOneByFilters(ctx context.Context, filter map[st
 		&cartItem.ID,
 		&cartItem.CartID,
 		&cartItem.ProductID,
+		&cartItem.VariantID,
 		&cartItem.Quantity,
+		&cartItem.Price,
+		&cartItem.ProductName,
 		&cartItem.CreatedAt,
 		&cartItem.UpdatedAt,
 	)
@@ -87,10 +110,14 @@ func (r *CartItemRepository) FindOneByFilters(ctx context.Context, filter map[st
 }
 
 func (r *CartItemRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.CartItem, error) {
-	query := r.db.QueryBuilder.Select("*").From(r.TableName)
+	query := r.db.QueryBuilder.Select(cartItemColumns...).From(r.TableName)
 
-	// Apply filters if provided
+	// Apply filters if provided, variant_id 0 matches the lines without a variant
 	for key, value := range filter {
+		if key == "variant_id" {
+			query = query.Where(sq.Eq{"COALESCE(variant_id, 0)": value})
+			continue
+		}
 		query = query.Where(sq.Eq{key: value})
 	}
 
@@ -112,7 +139,10 @@ func (r *CartItemRepository) Finds(ctx context.Context, filter map[string]interf
 			&cartItem.ID,
 			&cartItem.CartID,
 			&cartItem.ProductID,
+			&cartItem.VariantID,
 			&cartItem.Quantity,
+			&cartItem.Price,
+			&cartItem.ProductName,
 			&cartItem.CreatedAt,
 			&cartItem.UpdatedAt,
 		)
@@ -125,12 +155,109 @@ func (r *CartItemRepository) Finds(ctx context.Context, filter map[string]interf
 	return cartItems, nil
 }
 
+// FindsWithProducts retrieves the items of a cart together with their current
+// products in one query, the product is nil when it has been deleted
+func (r *CartItemRepository) FindsWithProducts(ctx context.Context, cartID int) ([]domain.CartItemWithProduct, error) {
+	query := r.db.QueryBuilder.Select(
+		"ci.id",
+		"ci.cart_id",
+		"ci.product_id",
+		"COALESCE(ci.variant_id, 0)",
+		"ci.quantity",
+		"ci.price",
+		"ci.product_name",
+		"ci.created_at",
+		"ci.updated_at",
+		"p.id",
+		"p.name",
+		"COALESCE(p.description, '')",
+		"p.price",
+		"p.stock",
+		"p.category_id",
+		"p.created_at",
+		"p.updated_at",
+	).
+		From(r.TableName + " ci").
+		LeftJoin("products p ON p.id = ci.product_id").
+		Where(sq.Eq{"ci.cart_id": cartID}).
+		OrderBy("ci.id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.CartItemWithProduct
+	for rows.Next() {
+		var (
+			item       domain.CartItemWithProduct
+			productID  *int
+			product    domain.Product
+			categoryID *int
+			createdAt  *time.Time
+			updatedAt  *time.Time
+			name       *string
+			price      *float64
+			stock      *int
+		)
+		err := rows.Scan(
+			&item.ID,
+			&item.CartID,
+			&item.ProductID,
+			&item.VariantID,
+			&item.Quantity,
+			&item.Price,
+			&item.ProductName,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&productID,
+			&name,
+			&product.Description,
+			&price,
+			&stock,
+			&categoryID,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if productID != nil {
+			product.ID = *productID
+			product.Name = *name
+			product.Price = *price
+			product.Stock = *stock
+			if categoryID != nil {
+				product.CategoryID = *categoryID
+			}
+			if createdAt != nil {
+				product.CreatedAt = *createdAt
+			}
+			if updatedAt != nil {
+				product.UpdatedAt = *updatedAt
+			}
+			item.Product = &product
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
 // Store inserts a new Categories into the database
 func (r *CartItemRepository) Store(ctx context.Context, data *domain.CartItem) error {
 	query := r.db.QueryBuilder.Insert(r.TableName).
-		Columns("cart_id", "product_id", "quantity", "created_at", "updated_at").
-		Values(data.CartID, data.ProductID, data.Quantity, data.CreatedAt, data.UpdatedAt).
-		Suffix("RETURNING id, cart_id, product_id, quantity, created_at, updated_at")
+		Columns("cart_id", "product_id", "variant_id", "quantity", "price", "product_name", "created_at", "updated_at").
+		Values(data.CartID, data.ProductID, nullInt64(int64(data.VariantID)), data.Quantity, data.Price, data.ProductName, data.CreatedAt, data.UpdatedAt).
+		Suffix("RETURNING " + strings.Join(cartItemColumns, ", "))
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -141,7 +268,10 @@ func (r *CartItemRepository) Store(ctx context.Context, data *domain.CartItem) e
 		&data.ID,
 		&data.CartID,
 		&data.ProductID,
+		&data.VariantID,
 		&data.Quantity,
+		&data.Price,
+		&data.ProductName,
 		&data.CreatedAt,
 		&data.UpdatedAt,
 	)
@@ -156,9 +286,11 @@ func (r *CartItemRepository) Store(ctx context.Context, data *domain.CartItem) e
 func (r *CartItemRepository) Update(ctx context.Context, id int, updatedData domain.CartItem) error {
 	query := r.db.QueryBuilder.Update(r.TableName).
 		Set("quantity", updatedData.Quantity).
+		Set("price", updatedData.Price).
+		Set("product_name", updatedData.ProductName).
 		Set("updated_at", time.Now()).
 		Where(sq.Eq{"id": id}).
-		Suffix("RETURNING *")
+		Suffix("RETURNING " + strings.Join(cartItemColumns, ", "))
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -169,7 +301,10 @@ func (r *CartItemRepository) Update(ctx context.Context, id int, updatedData dom
 		&updatedData.ID,
 		&updatedData.CartID,
 		&updatedData.ProductID,
+		&updatedData.VariantID,
 		&updatedData.Quantity,
+		&updatedData.Price,
+		&updatedData.ProductName,
 		&updatedData.CreatedAt,
 		&updatedData.UpdatedAt,
 	)
@@ -213,3 +348,86 @@ func (r *CartItemRepository) DeleteByProductID(ctx context.Context, product_id i
 
 	return nil
 }
+
+func (r *CartItemRepository) Delete(ctx context.Context, id int) error {
+	query := r.db.QueryBuilder.Delete(r.TableName).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ApplyChanges removes and upserts lines of a cart in one transaction. An
+// upsert with an ID updates that line, one without is inserted and gets its ID
+func (r *CartItemRepository) ApplyChanges(ctx context.Context, cartID int, upserts []domain.CartItem, removals []domain.CartLine) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if len(removals) > 0 {
+		lines := make(sq.Or, 0, len(removals))
+		for _, line := range removals {
+			lines = append(lines, sq.Eq{"product_id": line.ProductID, "COALESCE(variant_id, 0)": line.VariantID})
+		}
+
+		sql, args, err := r.db.QueryBuilder.Delete(r.TableName).
+			Where(sq.Eq{"cart_id": cartID}).
+			Where(lines).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	for i := range upserts {
+		item := &upserts[i]
+
+		if item.ID > 0 {
+			sql, args, err := r.db.QueryBuilder.Update(r.TableName).
+				Set("quantity", item.Quantity).
+				Set("price", item.Price).
+				Set("product_name", item.ProductName).
+				Set("updated_at", item.UpdatedAt).
+				Where(sq.Eq{"id": item.ID, "cart_id": cartID}).
+				ToSql()
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx, sql, args...); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sql, args, err := r.db.QueryBuilder.Insert(r.TableName).
+			Columns("cart_id", "product_id", "variant_id", "quantity", "price", "product_name", "created_at", "updated_at").
+			Values(cartID, item.ProductID, nullInt64(int64(item.VariantID)), item.Quantity, item.Price, item.ProductName, item.CreatedAt, item.UpdatedAt).
+			Suffix("RETURNING id").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&item.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
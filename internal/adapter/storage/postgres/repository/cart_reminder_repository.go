@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type CartReminderRepository struct {
+	db        *postgres.DB
+	TableName string
+
+	// with the Redis cart backend the carts only reach Postgres as snapshots
+	fromSnapshots bool
+}
+
+// NewCartReminderRepository creates the reminder repository, fromSnapshots
+// reads idle carts from cart_snapshots instead of carts
+func NewCartReminderRepository(db *postgres.DB, fromSnapshots bool) *CartReminderRepository {
+	return &CartReminderRepository{
+		db:            db,
+		TableName:     "cart_reminders",
+		fromSnapshots: fromSnapshots,
+	}
+}
+
+// FindIdleCarts retrieves the user carts last changed before
+// filter.UpdatedBefore that got fewer than filter.MaxReminders reminders, none
+// of them after filter.LastReminderBefore. A checked out cart is deleted, so
+// it is never found
+func (r *CartReminderRepository) FindIdleCarts(ctx context.Context, filter domain.IdleCartFilter) ([]domain.IdleCart, error) {
+	table, idColumn := "carts", "c.id"
+	if r.fromSnapshots {
+		table, idColumn = "cart_snapshots", "c.cart_id"
+	}
+
+	query := r.db.QueryBuilder.Select(
+		idColumn,
+		"c.user_id",
+		"COALESCE(u.name, '')",
+		"u.email",
+		"c.updated_at",
+		"COUNT(r.id)",
+	).
+		From(table+" c").
+		Join("users u ON u.id = c.user_id").
+		LeftJoin(r.TableName+" r ON r.cart_id = "+idColumn).
+		Where(sq.LtOrEq{"c.updated_at": filter.UpdatedBefore}).
+		Where("NOT EXISTS (SELECT 1 FROM cart_reminder_opt_outs o WHERE o.user_id = c.user_id)").
+		GroupBy(idColumn, "c.user_id", "u.name", "u.email", "c.updated_at").
+		Having(sq.Lt{"COUNT(r.id)": filter.MaxReminders}).
+		Having(sq.Or{sq.Expr("MAX(r.sent_at) IS NULL"), sq.LtOrEq{"MAX(r.sent_at)": filter.LastReminderBefore}}).
+		OrderBy("c.updated_at").
+		Limit(uint64(filter.Limit))
+
+	if r.fromSnapshots {
+		query = query.Where("c.deleted_at IS NULL")
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var carts []domain.IdleCart
+	for rows.Next() {
+		var cart domain.IdleCart
+		err := rows.Scan(
+			&cart.CartID,
+			&cart.UserID,
+			&cart.Name,
+			&cart.Email,
+			&cart.UpdatedAt,
+			&cart.RemindersSent,
+		)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, cart)
+	}
+
+	return carts, rows.Err()
+}
+
+// Store records a sent reminder
+func (r *CartReminderRepository) Store(ctx context.Context, data *domain.CartReminder) error {
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("cart_id", "user_id", "cart_updated_at", "item_count", "cart_total", "sent_at").
+		Values(data.CartID, data.UserID, data.CartUpdatedAt, data.ItemCount, data.CartTotal, data.SentAt).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRow(ctx, sql, args...).Scan(&data.ID)
+}
+
+// Stats counts the carts reminded since the given time and the ones whose
+// user placed an order, other than a cancelled one, between their first
+// reminder and window after their last. Revenue is the total of the first
+// such order of every converted cart
+func (r *CartReminderRepository) Stats(ctx context.Context, since time.Time, window time.Duration) (*domain.CartReminderStats, error) {
+	sql := `
+WITH reminded AS (
+	SELECT cart_id, user_id, COUNT(*) AS sent, MIN(sent_at) AS first_sent, MAX(sent_at) AS last_sent
+	FROM cart_reminders
+	WHERE sent_at >= $1
+	GROUP BY cart_id, user_id
+), converted AS (
+	SELECT DISTINCT ON (rm.cart_id) rm.cart_id, o.total_price
+	FROM reminded rm
+	JOIN orders o ON o.user_id = rm.user_id
+		AND o.created_at >= rm.first_sent
+		AND o.created_at <= rm.last_sent + make_interval(secs => $2)
+		AND o.status <> 'cancelled'
+	ORDER BY rm.cart_id, o.created_at
+)
+SELECT
+	COALESCE((SELECT SUM(sent) FROM reminded), 0)::BIGINT,
+	(SELECT COUNT(*) FROM reminded),
+	(SELECT COUNT(*) FROM converted),
+	COALESCE((SELECT SUM(total_price) FROM converted), 0)::FLOAT8`
+
+	stats := domain.CartReminderStats{Since: since}
+	err := r.db.QueryRow(ctx, sql, since, window.Seconds()).Scan(
+		&stats.RemindersSent,
+		&stats.CartsReminded,
+		&stats.CartsConverted,
+		&stats.Revenue,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.CartsReminded > 0 {
+		stats.ConversionRate = float64(stats.CartsConverted) / float64(stats.CartsReminded)
+	}
+
+	return &stats, nil
+}
+
+// SetOptOut turns the cart reminders of a user off or back on
+func (r *CartReminderRepository) SetOptOut(ctx context.Context, userID int, optOut bool) error {
+	var (
+		sql  string
+		args []interface{}
+		err  error
+	)
+
+	if optOut {
+		sql, args, err = r.db.QueryBuilder.Insert("cart_reminder_opt_outs").
+			Columns("user_id", "created_at").
+			Values(userID, time.Now()).
+			Suffix("ON CONFLICT (user_id) DO NOTHING").
+			ToSql()
+	} else {
+		sql, args, err = r.db.QueryBuilder.Delete("cart_reminder_opt_outs").
+			Where(sq.Eq{"user_id": userID}).
+			ToSql()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -22,69 +22,51 @@ func NewCartRepository(db *postgres.DB) *CartRepository {
 	}
 }
 
-func (r *CartRepository) FindOne(ctx context.Context, id int) (*domain.Cart, error) {
-	var Cart domain.Cart
+// guest carts have no user, they scan with a zero UserID
+var cartColumns = []string{
+	"id",
+	"COALESCE(user_id, 0)",
+	"COALESCE(guest_id, '')",
+	"expires_at",
+	"created_at",
+	"updated_at",
+}
 
-	query := r.db.QueryBuilder.Select("id", "user_id", "created_at", "updated_at").
+func (r *CartRepository) FindOne(ctx context.Context, id int) (*domain.Cart, error) {
+	query := r.db.QueryBuilder.Select(cartColumns...).
 		From(r.TableName).
 		Where(sq.Eq{"id": id}).
 		Limit(1)
 
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
-
-	err = r.db.QueryRow(ctx, sql, args...).Scan(
-		&Cart.ID,
-		&Cart.UserID,
-		&Cart.CreatedAt,
-		&Cart.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	return &Cart, nil
+	return r.findOne(ctx, query)
 }
 
 func (r *CartRepository) FindByUserID(ctx context.Context, userID int) (*domain.Cart, error) {
-	var cart domain.Cart
-
-	query := r.db.QueryBuilder.Select("id", "user_id", "created_at", "updated_at").
+	query := r.db.QueryBuilder.Select(cartColumns...).
 		From(r.TableName).
 		Where(sq.Eq{"user_id": userID}).
 		Limit(1)
 
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
+	return r.findOne(ctx, query)
+}
 
-	err = r.db.QueryRow(ctx, sql, args...).Scan(
-		&cart.ID,
-		&cart.UserID,
-		&cart.CreatedAt,
-		&cart.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, nil
-		}
-		return nil, err
-	}
+// FindByGuestID retrieves a guest cart that has not expired yet
+func (r *CartRepository) FindByGuestID(ctx context.Context, guestID string) (*domain.Cart, error) {
+	query := r.db.QueryBuilder.Select(cartColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"guest_id": guestID}).
+		Where("expires_at > NOW()").
+		Limit(1)
 
-	return &cart, nil
+	return r.findOne(ctx, query)
 }
 
-// Store inserts a new Categories into the database
+// Store inserts a new cart for a user or a guest into the database
 func (r *CartRepository) Store(ctx context.Context, data *domain.Cart) error {
+	now := time.Now()
 	query := r.db.QueryBuilder.Insert(r.TableName).
-		Columns("user_id", "created_at", "updated_at").
-		Values(data.UserID, time.Now(), time.Now()).
+		Columns("user_id", "guest_id", "expires_at", "created_at", "updated_at").
+		Values(nullInt64(int64(data.UserID)), nullString(data.GuestID), data.ExpiresAt, now, now).
 		Suffix("RETURNING id")
 
 	sql, args, err := query.ToSql()
@@ -97,27 +79,45 @@ func (r *CartRepository) Store(ctx context.Context, data *domain.Cart) error {
 		return err
 	}
 
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
 	return nil
 }
 
-// Update modifies an existing Categories in the database
+// Update touches the cart, a guest cart also gets its new expiry
 func (r *CartRepository) Update(ctx context.Context, id int, updatedData domain.Cart) error {
 	query := r.db.QueryBuilder.Update(r.TableName).
 		Set("updated_at", time.Now()).
-		Where(sq.Eq{"id": id}).
-		Suffix("RETURNING *")
+		Where(sq.Eq{"id": id})
+
+	if updatedData.ExpiresAt != nil {
+		query = query.Set("expires_at", updatedData.ExpiresAt)
+	}
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return err
 	}
 
-	err = r.db.QueryRow(ctx, sql, args...).Scan(
-		&updatedData.ID,
-		&updatedData.UserID,
-		&updatedData.CreatedAt,
-		&updatedData.UpdatedAt,
-	)
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CartRepository) Delete(ctx context.Context, id int) error {
+	query := r.db.QueryBuilder.Delete(r.TableName).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
 	if err != nil {
 		return err
 	}
@@ -141,3 +141,49 @@ func (r *CartRepository) DeleteByUserID(ctx context.Context, userID int) error {
 
 	return nil
 }
+
+// DeleteExpiredGuests removes the guest carts that expired before now, their
+// items go with them through the cart_id foreign key
+func (r *CartRepository) DeleteExpiredGuests(ctx context.Context, now time.Time) (int64, error) {
+	query := r.db.QueryBuilder.Delete(r.TableName).
+		Where("guest_id IS NOT NULL").
+		Where(sq.LtOrEq{"expires_at": now})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *CartRepository) findOne(ctx context.Context, query sq.SelectBuilder) (*domain.Cart, error) {
+	var cart domain.Cart
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(
+		&cart.ID,
+		&cart.UserID,
+		&cart.GuestID,
+		&cart.ExpiresAt,
+		&cart.CreatedAt,
+		&cart.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &cart, nil
+}
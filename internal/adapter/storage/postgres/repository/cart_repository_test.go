@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres/repository"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/storagetest"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+// newTestDB connects to the database in TEST_DB_* and migrates it, the suite
+// is skipped without TEST_DB_HOST
+func newTestDB(t *testing.T) *postgres.DB {
+	t.Helper()
+
+	if os.Getenv("TEST_DB_HOST") == "" {
+		t.Skip("TEST_DB_HOST is not set")
+	}
+
+	db, err := postgres.New(context.Background(), &config.DB{
+		Connection: "postgres",
+		User:       os.Getenv("TEST_DB_USER"),
+		Password:   os.Getenv("TEST_DB_PASSWORD"),
+		Host:       os.Getenv("TEST_DB_HOST"),
+		Port:       os.Getenv("TEST_DB_PORT"),
+		Name:       os.Getenv("TEST_DB_NAME"),
+	})
+	if err != nil {
+		t.Fatalf("connecting to Postgres: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	return db
+}
+
+func TestCartRepositoryContract(t *testing.T) {
+	db := newTestDB(t)
+
+	storagetest.CartRepositoryContract(t, func(t *testing.T) storagetest.CartFixture {
+		ctx := context.Background()
+		suffix := time.Now().UnixNano()
+
+		fixture := storagetest.CartFixture{
+			Carts:     repository.NewCartRepository(db),
+			CartItems: repository.NewCartItemRepository(db),
+			Products:  repository.NewProductRepository(db),
+			Variants:  repository.NewProductVariantRepository(db),
+		}
+
+		for i := 0; i < 2; i++ {
+			var id int
+			err := db.QueryRow(ctx,
+				"INSERT INTO users (name, email, password, role) VALUES ($1, $2, 'secret', 'customer') RETURNING id",
+				"cart contract", fmt.Sprintf("cart-contract-%d-%d@example.com", suffix, i),
+			).Scan(&id)
+			if err != nil {
+				t.Fatalf("inserting user: %v", err)
+			}
+			fixture.UserIDs = append(fixture.UserIDs, id)
+		}
+
+		for i := 0; i < 3; i++ {
+			var id int
+			err := db.QueryRow(ctx,
+				"INSERT INTO products (name, price, stock) VALUES ($1, $2, $3) RETURNING id",
+				fmt.Sprintf("cart contract %d-%d", suffix, i), storagetest.ProductPrice, storagetest.ProductStock,
+			).Scan(&id)
+			if err != nil {
+				t.Fatalf("inserting product: %v", err)
+			}
+			fixture.ProductIDs = append(fixture.ProductIDs, id)
+		}
+
+		err := db.QueryRow(ctx,
+			"INSERT INTO products (name, price, stock) VALUES ($1, $2, $3) RETURNING id",
+			fmt.Sprintf("cart contract %d-variants", suffix), storagetest.ProductPrice, storagetest.ProductStock,
+		).Scan(&fixture.VariantProductID)
+		if err != nil {
+			t.Fatalf("inserting product: %v", err)
+		}
+
+		override := float64(storagetest.VariantPrice)
+		for i, price := range []*float64{&override, nil} {
+			variant := &domain.ProductVariant{
+				ProductID: fixture.VariantProductID,
+				SKU:       fmt.Sprintf("CART-%d-%d", suffix, i),
+				Price:     price,
+				Stock:     storagetest.VariantStock,
+				Options:   map[string]string{"size": fmt.Sprint(i)},
+			}
+			if err := fixture.Variants.Store(ctx, variant); err != nil {
+				t.Fatalf("inserting variant: %v", err)
+			}
+			fixture.VariantIDs = append(fixture.VariantIDs, variant.ID)
+		}
+
+		t.Cleanup(func() {
+			db.Exec(ctx, "DELETE FROM carts WHERE user_id = ANY($1)", fixture.UserIDs)
+			db.Exec(ctx, "DELETE FROM users WHERE id = ANY($1)", fixture.UserIDs)
+			db.Exec(ctx, "DELETE FROM products WHERE id = ANY($1)", append(fixture.ProductIDs, fixture.VariantProductID))
+		})
+
+		return fixture
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type CartSnapshotRepository struct {
+	db        *postgres.DB
+	TableName string
+}
+
+func NewCartSnapshotRepository(db *postgres.DB) *CartSnapshotRepository {
+	return &CartSnapshotRepository{
+		db:        db,
+		TableName: "cart_snapshots",
+	}
+}
+
+// Upsert stores the latest state of a cart, a cart that comes back after being
+// marked deleted is live again
+func (r *CartSnapshotRepository) Upsert(ctx context.Context, data *domain.CartSnapshot) error {
+	items := data.Items
+	if items == nil {
+		items = []domain.CartItem{}
+	}
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	var (
+		totalQuantity int
+		totalValue    float64
+	)
+	for _, item := range items {
+		totalQuantity += item.Quantity
+		totalValue += item.Price * float64(item.Quantity)
+	}
+
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("cart_id", "user_id", "guest_id", "items", "total_quantity", "total_value", "created_at", "updated_at", "deleted_at").
+		Values(data.CartID, nullInt64(int64(data.UserID)), nullString(data.GuestID), string(itemsJSON), totalQuantity, totalValue, time.Now(), data.UpdatedAt, data.DeletedAt).
+		Suffix(`ON CONFLICT (cart_id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			guest_id = EXCLUDED.guest_id,
+			items = EXCLUDED.items,
+			total_quantity = EXCLUDED.total_quantity,
+			total_value = EXCLUDED.total_value,
+			updated_at = EXCLUDED.updated_at,
+			deleted_at = EXCLUDED.deleted_at`)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkDeleted records that a cart is gone, keeping its last items
+func (r *CartSnapshotRepository) MarkDeleted(ctx context.Context, cartID int, deletedAt time.Time) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("deleted_at", deletedAt).
+		Where(sq.Eq{"cart_id": cartID}).
+		Where("deleted_at IS NULL")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
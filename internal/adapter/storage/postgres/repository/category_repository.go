@@ -22,38 +22,35 @@ func NewCategoryRepository(db *postgres.DB) *CategoryRepository {
 	}
 }
 
-func (r *CategoryRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.Category, error) {
-	query := r.db.QueryBuilder.Select("*").From(r.TableName)
+var categorySortColumns = sortColumns{
+	"name": {column: "name", cast: "text"},
+}
+
+// Finds retrieves one page of the categories matching the filter
+func (r *CategoryRepository) Finds(ctx context.Context, filter map[string]interface{}, page domain.PageRequest) (*domain.Page[domain.Category], error) {
+	query := r.db.QueryBuilder.Select("id", "name").From(r.TableName)
 
 	for key, value := range filter {
 		query = query.Where(sq.Eq{key: value})
 	}
 
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
-
-	rows, err := r.db.Query(ctx, sql, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var categories []domain.Category
-	for rows.Next() {
-		var category domain.Category
-		err := rows.Scan(
-			&category.ID,
-			&category.Name,
-		)
-		if err != nil {
-			return nil, err
-		}
-		categories = append(categories, category)
-	}
-
-	return categories, nil
+	return findPage(ctx, pageQuery[domain.Category]{
+		db:      r.db,
+		query:   query,
+		idCol:   "id",
+		columns: categorySortColumns,
+		scan: func(rows pgx.Rows) (domain.Category, error) {
+			var category domain.Category
+			err := rows.Scan(&category.ID, &category.Name)
+			return category, err
+		},
+		key: func(category domain.Category, sort string) (interface{}, int) {
+			if sort == "name" {
+				return category.Name, category.ID
+			}
+			return category.ID, category.ID
+		},
+	}, page)
 }
 
 // FindOne retrieves a single Categories by ID
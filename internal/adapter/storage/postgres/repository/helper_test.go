@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres/repository"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+// newTestUsers inserts n customers that are deleted with the test
+func newTestUsers(t *testing.T, db *postgres.DB, n int) []uint64 {
+	t.Helper()
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+
+	var ids []uint64
+	for i := 0; i < n; i++ {
+		var id uint64
+		err := db.QueryRow(ctx,
+			"INSERT INTO users (name, email, password, role) VALUES ($1, $2, 'secret', 'customer') RETURNING id",
+			t.Name(), fmt.Sprintf("repository-test-%d-%d@example.com", suffix, i),
+		).Scan(&id)
+		if err != nil {
+			t.Fatalf("inserting user: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	t.Cleanup(func() {
+		db.Exec(ctx, "DELETE FROM users WHERE id = ANY($1)", ids)
+	})
+
+	return ids
+}
+
+// newTestWallets inserts n customers with a wallet holding deposit each
+func newTestWallets(t *testing.T, db *postgres.DB, n int, deposit float64) []uint64 {
+	t.Helper()
+
+	ctx := context.Background()
+	balances := repository.NewBalanceRepository(db)
+
+	ids := newTestUsers(t, db, n)
+	for _, id := range ids {
+		now := time.Now()
+		if err := balances.Store(ctx, &domain.Balance{UserID: id, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("creating wallet: %v", err)
+		}
+		if deposit > 0 {
+			if err := balances.Deposit(ctx, id, deposit); err != nil {
+				t.Fatalf("depositing: %v", err)
+			}
+		}
+	}
+
+	return ids
+}
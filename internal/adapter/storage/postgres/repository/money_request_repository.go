@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type MoneyRequestRepository struct {
+	db             *postgres.DB
+	TableName      string
+	PayerTableName string
+}
+
+func NewMoneyRequestRepository(db *postgres.DB) *MoneyRequestRepository {
+	return &MoneyRequestRepository{
+		db:             db,
+		TableName:      "money_requests",
+		PayerTableName: "money_request_payers",
+	}
+}
+
+// expiry is not stored, a pending request past expires_at reads as expired
+const moneyRequestStatus = "CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END"
+
+var moneyRequestColumns = []string{
+	"id",
+	"requester_id",
+	"amount",
+	"COALESCE(note, '')",
+	moneyRequestStatus,
+	"expires_at",
+	"created_at",
+	"updated_at",
+}
+
+// Store inserts the request and its payers in one transaction
+func (r *MoneyRequestRepository) Store(ctx context.Context, data *domain.MoneyRequest) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	query := sq.Insert(r.TableName).
+		Columns("requester_id", "amount", "note", "status", "expires_at", "created_at", "updated_at").
+		Values(data.RequesterID, data.Amount, nullString(data.Note), domain.MoneyRequestPending, data.ExpiresAt, now, now).
+		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&data.ID); err != nil {
+		return err
+	}
+
+	for i := range data.Payers {
+		payer := &data.Payers[i]
+		query := sq.Insert(r.PayerTableName).
+			Columns("request_id", "payer_id", "amount", "status", "created_at", "updated_at").
+			Values(data.ID, payer.PayerID, payer.Amount, domain.MoneyRequestPayerPending, now, now).
+			Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow(ctx, sql, args...).Scan(&payer.ID); err != nil {
+			return err
+		}
+
+		payer.RequestID = data.ID
+		payer.Status = domain.MoneyRequestPayerPending
+		payer.UpdatedAt = now
+	}
+
+	data.Status = domain.MoneyRequestPending
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return tx.Commit(ctx)
+}
+
+// Finds retrieves the requests matching the filter with their payers, newest
+// first. The filter accepts requester_id, payer_id and status
+func (r *MoneyRequestRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.MoneyRequest, error) {
+	query := r.db.QueryBuilder.Select(moneyRequestColumns...).
+		From(r.TableName).
+		OrderBy("id DESC")
+
+	for key, value := range filter {
+		switch key {
+		case "payer_id":
+			query = query.Where(sq.Expr("id IN (SELECT request_id FROM "+r.PayerTableName+" WHERE payer_id = ?)", value))
+		case "status":
+			query = query.Where(sq.Expr(moneyRequestStatus+" = ?", value))
+		default:
+			query = query.Where(sq.Eq{key: value})
+		}
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []domain.MoneyRequest
+	for rows.Next() {
+		request, err := scanMoneyRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(requests) == 0 {
+		return requests, nil
+	}
+
+	ids := make([]uint64, 0, len(requests))
+	for _, request := range requests {
+		ids = append(ids, request.ID)
+	}
+
+	payers, err := r.findPayers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range requests {
+		requests[i].Payers = payers[requests[i].ID]
+	}
+
+	return requests, nil
+}
+
+// FindOne retrieves a single request with its payers
+func (r *MoneyRequestRepository) FindOne(ctx context.Context, id uint64) (*domain.MoneyRequest, error) {
+	query := r.db.QueryBuilder.Select(moneyRequestColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := scanMoneyRequest(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	payers, err := r.findPayers(ctx, []uint64{id})
+	if err != nil {
+		return nil, err
+	}
+	request.Payers = payers[id]
+
+	return request, nil
+}
+
+// ClosePayer moves a pending share to paid or declined while the request is
+// open, it fails with ErrMoneyRequestClosed when the share or the request is
+// no longer open
+func (r *MoneyRequestRepository) ClosePayer(ctx context.Context, requestID, payerID uint64, status domain.MoneyRequestPayerStatus) error {
+	now := time.Now()
+	query := r.db.QueryBuilder.Update(r.PayerTableName).
+		Set("status", status).
+		Set("updated_at", now).
+		Where(sq.Eq{"request_id": requestID, "payer_id": payerID, "status": domain.MoneyRequestPayerPending}).
+		Where(sq.Expr(
+			"EXISTS (SELECT 1 FROM "+r.TableName+" WHERE id = request_id AND status = ? AND expires_at > NOW())",
+			domain.MoneyRequestPending,
+		))
+
+	if status == domain.MoneyRequestPayerPaid {
+		query = query.Set("paid_at", now)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrMoneyRequestClosed
+	}
+
+	return nil
+}
+
+// ReopenPayer returns a paid share to pending after its transfer failed, and
+// reopens the request if another payer completed it in the meantime
+func (r *MoneyRequestRepository) ReopenPayer(ctx context.Context, requestID, payerID uint64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	query := sq.Update(r.PayerTableName).
+		Set("status", domain.MoneyRequestPayerPending).
+		Set("paid_at", nil).
+		Set("updated_at", now).
+		Where(sq.Eq{"request_id": requestID, "payer_id": payerID, "status": domain.MoneyRequestPayerPaid}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	query = sq.Update(r.TableName).
+		Set("status", domain.MoneyRequestPending).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": requestID, "status": domain.MoneyRequestCompleted}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err = query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Complete closes an open request once none of its payers is pending
+func (r *MoneyRequestRepository) Complete(ctx context.Context, id uint64) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("status", domain.MoneyRequestCompleted).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": domain.MoneyRequestPending}).
+		Where(sq.Expr("NOT EXISTS (SELECT 1 FROM "+r.PayerTableName+" WHERE request_id = ? AND status = ?)", id, domain.MoneyRequestPayerPending))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	return err
+}
+
+// Cancel closes an open request and every share that has not been paid
+func (r *MoneyRequestRepository) Cancel(ctx context.Context, id uint64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	query := sq.Update(r.TableName).
+		Set("status", domain.MoneyRequestCancelled).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id, "status": domain.MoneyRequestPending}).
+		Where("expires_at > NOW()").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrMoneyRequestClosed
+	}
+
+	query = sq.Update(r.PayerTableName).
+		Set("status", domain.MoneyRequestPayerCancelled).
+		Set("updated_at", now).
+		Where(sq.Eq{"request_id": id, "status": domain.MoneyRequestPayerPending}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err = query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// findPayers loads the payers of the given requests keyed by request ID
+func (r *MoneyRequestRepository) findPayers(ctx context.Context, requestIDs []uint64) (map[uint64][]domain.MoneyRequestPayer, error) {
+	query := r.db.QueryBuilder.Select(
+		"p.id",
+		"p.request_id",
+		"p.payer_id",
+		"p.amount",
+		"CASE WHEN p.status = 'pending' AND m.expires_at <= NOW() THEN 'expired' ELSE p.status END",
+		"p.paid_at",
+		"p.updated_at",
+	).
+		From(r.PayerTableName + " p").
+		Join(r.TableName + " m ON m.id = p.request_id").
+		Where(sq.Eq{"p.request_id": requestIDs}).
+		OrderBy("p.id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payers := make(map[uint64][]domain.MoneyRequestPayer)
+	for rows.Next() {
+		var payer domain.MoneyRequestPayer
+		err := rows.Scan(&payer.ID, &payer.RequestID, &payer.PayerID, &payer.Amount, &payer.Status, &payer.PaidAt, &payer.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		payers[payer.RequestID] = append(payers[payer.RequestID], payer)
+	}
+
+	return payers, rows.Err()
+}
+
+func scanMoneyRequest(row pgx.Row) (*domain.MoneyRequest, error) {
+	var request domain.MoneyRequest
+	err := row.Scan(
+		&request.ID,
+		&request.RequesterID,
+		&request.Amount,
+		&request.Note,
+		&request.Status,
+		&request.ExpiresAt,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
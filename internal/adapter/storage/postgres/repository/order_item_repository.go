@@ -21,11 +21,21 @@ func NewOrderItemRepository(db *postgres.DB) *OrderItemRepository {
 	}
 }
 
+var orderItemColumns = []string{
+	"id",
+	"order_id",
+	"product_id",
+	"COALESCE(variant_id, 0)",
+	"COALESCE(sku, '')",
+	"quantity",
+	"price",
+}
+
 // FindOne retrieves a single Categories by ID
 func (r *OrderItemRepository) FindOne(ctx context.Context, id int) (*domain.OrderItem, error) {
 	var orderItem domain.OrderItem
 
-	query := r.db.QueryBuilder.Select("id", "order_id", "product_id", "quantity", "price").
+	query := r.db.QueryBuilder.Select(orderItemColumns...).
 		From(r.TableName).
 		Where(sq.Eq{"id": id}).
 		Limit(1)
@@ -39,6 +49,8 @@ func (r *OrderItemRepository) FindOne(ctx context.Context, id int) (*domain.Orde
 		&orderItem.ID,
 		&orderItem.OrderID,
 		&orderItem.ProductID,
+		&orderItem.VariantID,
+		&orderItem.SKU,
 		&orderItem.Quantity,
 		&orderItem.Price,
 	)
@@ -55,8 +67,8 @@ func (r *OrderItemRepository) FindOne(ctx context.Context, id int) (*domain.Orde
 // Store inserts a new Categories into the database
 func (r *OrderItemRepository) Store(ctx context.Context, data *domain.OrderItem) error {
 	query := r.db.QueryBuilder.Insert(r.TableName).
-		Columns("order_id", "product_id", "quantity", "price").
-		Values(data.OrderID, data.ProductID, data.Quantity, data.Price).
+		Columns("order_id", "product_id", "variant_id", "sku", "quantity", "price").
+		Values(data.OrderID, data.ProductID, nullInt64(int64(data.VariantID)), nullString(data.SKU), data.Quantity, data.Price).
 		Suffix("RETURNING id")
 
 	sql, args, err := query.ToSql()
@@ -72,6 +84,32 @@ func (r *OrderItemRepository) Store(ctx context.Context, data *domain.OrderItem)
 	return nil
 }
 
+// StoreBatch inserts the items of an order in one statement
+func (r *OrderItemRepository) StoreBatch(ctx context.Context, items []domain.OrderItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("order_id", "product_id", "variant_id", "sku", "quantity", "price")
+
+	for _, item := range items {
+		query = query.Values(item.OrderID, item.ProductID, nullInt64(int64(item.VariantID)), nullString(item.SKU), item.Quantity, item.Price)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *OrderItemRepository) Update(ctx context.Context, id int, updatedData domain.OrderItem) error {
 	query := r.db.QueryBuilder.Update(r.TableName).
 		Set("status", sq.Expr("COALESCE(?, quantity)", updatedData.Quantity)).
@@ -116,7 +154,7 @@ func (r *OrderItemRepository) Delete(ctx context.Context, id int) error {
 }
 
 func (r *OrderItemRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.OrderItem, error) {
-	query := r.db.QueryBuilder.Select("*").From(r.TableName)
+	query := r.db.QueryBuilder.Select(orderItemColumns...).From(r.TableName)
 
 	for key, value := range filter {
 		query = query.Where(sq.Eq{key: value})
@@ -140,6 +178,8 @@ func (r *OrderItemRepository) Finds(ctx context.Context, filter map[string]inter
 			&orderItem.ID,
 			&orderItem.OrderID,
 			&orderItem.ProductID,
+			&orderItem.VariantID,
+			&orderItem.SKU,
 			&orderItem.Quantity,
 			&orderItem.Price,
 		)
@@ -21,44 +21,51 @@ func NewOrderRepository(db *postgres.DB) *OrderRepository {
 	}
 }
 
-func (r *OrderRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.Order, error) {
-	query := r.db.QueryBuilder.Select("*").From(r.TableName)
+var orderSortColumns = sortColumns{
+	"created_at":  {column: "created_at", cast: "timestamp"},
+	"total_price": {column: "total_price", cast: "numeric"},
+	"status":      {column: "status", cast: "text"},
+}
+
+// Finds retrieves one page of the orders matching the filter
+func (r *OrderRepository) Finds(ctx context.Context, filter map[string]interface{}, page domain.PageRequest) (*domain.Page[domain.Order], error) {
+	query := r.db.QueryBuilder.Select("id", "user_id", "total_price", "status", "created_at").From(r.TableName)
 
 	for key, value := range filter {
 		query = query.Where(sq.Eq{key: value})
 	}
 
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
-
-	rows, err := r.db.Query(ctx, sql, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var orders []domain.Order
-	for rows.Next() {
-		var order domain.Order
-		err := rows.Scan(
-			&order.ID,
-			&order.UserID,
-			&order.TotalPrice,
-			&order.Status,
-			&order.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		orders = append(orders, order)
-	}
-
-	return orders, nil
+	return findPage(ctx, pageQuery[domain.Order]{
+		db:      r.db,
+		query:   query,
+		idCol:   "id",
+		columns: orderSortColumns,
+		scan: func(rows pgx.Rows) (domain.Order, error) {
+			var order domain.Order
+			err := rows.Scan(
+				&order.ID,
+				&order.UserID,
+				&order.TotalPrice,
+				&order.Status,
+				&order.CreatedAt,
+			)
+			return order, err
+		},
+		key: func(order domain.Order, sort string) (interface{}, int) {
+			switch sort {
+			case "created_at":
+				return order.CreatedAt, order.ID
+			case "total_price":
+				return order.TotalPrice, order.ID
+			case "status":
+				return order.Status, order.ID
+			default:
+				return order.ID, order.ID
+			}
+		},
+	}, page)
 }
 
-// FindOne retrieves a single Categories by ID
 func (r *OrderRepository) FindOne(ctx context.Context, id int, userID int) (*domain.Order, error) {
 	var Order domain.Order
 
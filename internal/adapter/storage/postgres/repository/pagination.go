@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+// sortColumn is a column a listing can be sorted on, cast is the type the
+// cursor value is compared as
+type sortColumn struct {
+	column string
+	cast   string
+}
+
+// sortColumns maps the sort names a listing accepts to their columns, every
+// listing can be sorted on id
+type sortColumns map[string]sortColumn
+
+// pageQuery runs a listing query one page at a time, see findPage
+type pageQuery[T any] struct {
+	db      *postgres.DB
+	query   sq.SelectBuilder
+	idCol   string
+	columns sortColumns
+	scan    func(pgx.Rows) (T, error)
+	// key returns the sort value and ID of a row for the next cursor
+	key func(row T, sort string) (interface{}, int)
+}
+
+// findPage counts the rows of q.query and reads the requested page of them,
+// one row more than the limit tells whether there is a next page. With a
+// cursor the page starts after the row it points at, compared on (sort, id)
+// so rows with the same sort value are neither skipped nor repeated
+func findPage[T any](ctx context.Context, q pageQuery[T], page domain.PageRequest) (*domain.Page[T], error) {
+	sort := page.Sort
+	if sort == "" {
+		sort = "id"
+	}
+
+	column, ok := q.columns[sort]
+	if sort == "id" {
+		column, ok = sortColumn{column: q.idCol, cast: "int"}, true
+	}
+	if !ok {
+		return nil, consts.ErrInvalidSort
+	}
+
+	total, err := countRows(ctx, q.db, q.query)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, compare := "ASC", ">"
+	if page.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	query := q.query
+	if page.After != nil {
+		if page.After.Sort != sort || page.After.Desc != page.Desc {
+			return nil, consts.ErrInvalidCursor
+		}
+
+		query = query.Where(
+			fmt.Sprintf("(%s, %s) %s (?::%s, ?::int)", column.column, q.idCol, compare, column.cast),
+			page.After.Value, page.After.ID,
+		)
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+
+	if column.column == q.idCol {
+		query = query.OrderBy(q.idCol + " " + direction)
+	} else {
+		query = query.OrderBy(column.column+" "+direction, q.idCol+" "+direction)
+	}
+	query = query.Limit(page.Limit + 1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &domain.Page[T]{Total: total}
+	for rows.Next() {
+		row, err := q.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if uint64(len(result.Items)) > page.Limit {
+		result.Items = result.Items[:page.Limit]
+
+		value, id := q.key(result.Items[len(result.Items)-1], sort)
+		result.Next = &domain.PageCursor{
+			Sort:  sort,
+			Desc:  page.Desc,
+			Value: cursorValue(value),
+			ID:    id,
+		}
+	}
+
+	return result, nil
+}
+
+// countRows counts the rows of a listing query that has no ORDER BY or LIMIT
+func countRows(ctx context.Context, db *postgres.DB, query sq.SelectBuilder) (int64, error) {
+	sql, args, err := query.RemoveColumns().Columns("COUNT(*)").ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	if err := db.QueryRow(ctx, sql, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// cursorValue formats a sort value so Postgres can cast it back
+func cursorValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type PayoutDestinationRepository struct {
+	db        *postgres.DB
+	TableName string
+}
+
+func NewPayoutDestinationRepository(db *postgres.DB) *PayoutDestinationRepository {
+	return &PayoutDestinationRepository{
+		db:        db,
+		TableName: "payout_destinations",
+	}
+}
+
+// Finds retrieves the payout destinations matching the filter
+func (r *PayoutDestinationRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.PayoutDestination, error) {
+	query := r.db.QueryBuilder.Select("id", "user_id", "bank_name", "account_number", "account_name", "created_at", "updated_at").
+		From(r.TableName).
+		OrderBy("id")
+
+	for key, value := range filter {
+		query = query.Where(sq.Eq{key: value})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var destinations []domain.PayoutDestination
+	for rows.Next() {
+		var destination domain.PayoutDestination
+		err := rows.Scan(
+			&destination.ID,
+			&destination.UserID,
+			&destination.BankName,
+			&destination.AccountNumber,
+			&destination.AccountName,
+			&destination.CreatedAt,
+			&destination.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, destination)
+	}
+
+	return destinations, rows.Err()
+}
+
+// FindOne retrieves a single payout destination by ID
+func (r *PayoutDestinationRepository) FindOne(ctx context.Context, id uint64) (*domain.PayoutDestination, error) {
+	var destination domain.PayoutDestination
+
+	query := r.db.QueryBuilder.Select("id", "user_id", "bank_name", "account_number", "account_name", "created_at", "updated_at").
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(
+		&destination.ID,
+		&destination.UserID,
+		&destination.BankName,
+		&destination.AccountNumber,
+		&destination.AccountName,
+		&destination.CreatedAt,
+		&destination.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &destination, nil
+}
+
+// Store inserts a new payout destination
+func (r *PayoutDestinationRepository) Store(ctx context.Context, data *domain.PayoutDestination) error {
+	now := time.Now()
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("user_id", "bank_name", "account_number", "account_name", "created_at", "updated_at").
+		Values(data.UserID, data.BankName, data.AccountNumber, data.AccountName, now, now).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID)
+	if err != nil {
+		return err
+	}
+
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// Delete removes a payout destination by ID
+func (r *PayoutDestinationRepository) Delete(ctx context.Context, id uint64) error {
+	query := r.db.QueryBuilder.Delete(r.TableName).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
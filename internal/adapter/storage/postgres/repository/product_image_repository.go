@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type ProductImageRepository struct {
+	db        *postgres.DB
+	TableName string
+}
+
+func NewProductImageRepository(db *postgres.DB) *ProductImageRepository {
+	return &ProductImageRepository{
+		db:        db,
+		TableName: "product_images",
+	}
+}
+
+var productImageColumns = []string{
+	"id",
+	"product_id",
+	"object_key",
+	"content_type",
+	"size",
+	"position",
+	"is_primary",
+	"derivatives",
+	"derivatives_status",
+	"created_at",
+}
+
+// FindOne retrieves a single image by ID
+func (r *ProductImageRepository) FindOne(ctx context.Context, id int) (*domain.ProductImage, error) {
+	query := r.db.QueryBuilder.Select(productImageColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := scanProductImage(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// FindByProductIDs retrieves the galleries of several products in one query,
+// each in gallery order
+func (r *ProductImageRepository) FindByProductIDs(ctx context.Context, productIDs []int) ([]domain.ProductImage, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	query := r.db.QueryBuilder.Select(productImageColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"product_id": productIDs}).
+		OrderBy("product_id", "position", "id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []domain.ProductImage
+	for rows.Next() {
+		image, err := scanProductImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	return images, rows.Err()
+}
+
+// Store appends an image to the end of the gallery, the first image of a
+// product becomes its primary image. An object key already in use is
+// ErrConflictingData
+func (r *ProductImageRepository) Store(ctx context.Context, data *domain.ProductImage) error {
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("product_id", "object_key", "content_type", "size", "position", "is_primary", "created_at").
+		Values(
+			data.ProductID,
+			data.ObjectKey,
+			data.ContentType,
+			data.Size,
+			sq.Expr("(SELECT COALESCE(MAX(position) + 1, 0) FROM "+r.TableName+" WHERE product_id = ?)", data.ProductID),
+			sq.Expr("NOT EXISTS (SELECT 1 FROM "+r.TableName+" WHERE product_id = ? AND is_primary)", data.ProductID),
+			time.Now(),
+		).
+		Suffix("RETURNING id, position, is_primary, derivatives_status, created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID, &data.Position, &data.IsPrimary, &data.DerivativesStatus, &data.CreatedAt)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+
+	return nil
+}
+
+// Delete removes an image of a product, when it was the primary image the
+// first image left in the gallery takes its place
+func (r *ProductImageRepository) Delete(ctx context.Context, productID, id int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := sq.Delete(r.TableName).
+		Where(sq.Eq{"id": id, "product_id": productID}).
+		Suffix("RETURNING is_primary").
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	var wasPrimary bool
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&wasPrimary); err != nil {
+		if err == pgx.ErrNoRows {
+			return consts.ErrDataNotFound
+		}
+		return err
+	}
+
+	if wasPrimary {
+		sql, args, err = sq.Update(r.TableName).
+			Set("is_primary", true).
+			Where(sq.Expr("id = (SELECT id FROM "+r.TableName+" WHERE product_id = ? ORDER BY position, id LIMIT 1)", productID)).
+			PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetPrimary makes an image the primary image of its product
+func (r *ProductImageRepository) SetPrimary(ctx context.Context, productID, id int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// the previous primary image is cleared first to keep one per product
+	sql, args, err := sq.Update(r.TableName).
+		Set("is_primary", false).
+		Where(sq.Eq{"product_id": productID, "is_primary": true}).
+		Where(sq.NotEq{"id": id}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	sql, args, err = sq.Update(r.TableName).
+		Set("is_primary", true).
+		Where(sq.Eq{"id": id, "product_id": productID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrDataNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Reorder sets the gallery order of a product to the order of ids
+func (r *ProductImageRepository) Reorder(ctx context.Context, productID int, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	position := sq.Case("id")
+	for i, id := range ids {
+		position = position.When(sq.Expr("?::int", id), sq.Expr("?::int", i))
+	}
+
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("position", position).
+		Where(sq.Eq{"product_id": productID, "id": ids})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDerivatives records the resized copies of an image and how their
+// generation went
+func (r *ProductImageRepository) UpdateDerivatives(ctx context.Context, id int, derivatives map[domain.ImageSize]domain.ImageDerivative, status string) error {
+	raw := []byte("{}")
+	if derivatives != nil {
+		var err error
+		if raw, err = json.Marshal(derivatives); err != nil {
+			return err
+		}
+	}
+
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("derivatives", sq.Expr("?::jsonb", string(raw))).
+		Set("derivatives_status", status).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrDataNotFound
+	}
+
+	return nil
+}
+
+func scanProductImage(row pgx.Row) (domain.ProductImage, error) {
+	var image domain.ProductImage
+	err := row.Scan(
+		&image.ID,
+		&image.ProductID,
+		&image.ObjectKey,
+		&image.ContentType,
+		&image.Size,
+		&image.Position,
+		&image.IsPrimary,
+		&image.Derivatives,
+		&image.DerivativesStatus,
+		&image.CreatedAt,
+	)
+
+	return image, err
+}
@@ -22,59 +22,88 @@ func NewProductRepository(db *postgres.DB) *ProductRepository {
 	}
 }
 
-// Finds retrieves multiple products based on the provided filter
-func (r *ProductRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.Product, error) {
-	query := r.db.QueryBuilder.Select("*").From(r.TableName)
+// productColumns are the columns scanProduct reads
+var productColumns = []string{
+	"id",
+	"name",
+	"COALESCE(description, '')",
+	"price",
+	"stock",
+	"COALESCE(category_id, 0)",
+	"attributes",
+	"created_at",
+	"updated_at",
+}
+
+var productSortColumns = sortColumns{
+	"name":       {column: "name", cast: "text"},
+	"price":      {column: "price", cast: "numeric"},
+	"stock":      {column: "stock", cast: "int"},
+	"created_at": {column: "created_at", cast: "timestamp"},
+}
+
+// Finds retrieves one page of the products matching the filter, a search
+// filter is a full-text search on the name and description that matches word
+// prefixes
+func (r *ProductRepository) Finds(ctx context.Context, filter map[string]interface{}, page domain.PageRequest) (*domain.Page[domain.Product], error) {
+	query := r.db.QueryBuilder.Select(productColumns...).From(r.TableName + " p")
 
 	for key, value := range filter {
 		if key == "search" {
-			query = query.Where(sq.Or{
-				sq.Like{"LOWER(name)": "%" + value.(string) + "%"},
-			})
+			query = query.Where(textMatch(value.(string), false))
 			continue
 		}
 
 		query = query.Where(sq.Eq{key: value})
 	}
 
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
+	return findPage(ctx, pageQuery[domain.Product]{
+		db:      r.db,
+		query:   query,
+		idCol:   "id",
+		columns: productSortColumns,
+		scan:    scanProduct,
+		key:     productSortKey,
+	}, page)
+}
 
-	rows, err := r.db.Query(ctx, sql, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+func scanProduct(rows pgx.Rows) (domain.Product, error) {
+	var product domain.Product
+	err := rows.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.Stock,
+		&product.CategoryID,
+		&product.Attributes,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
 
-	var products []domain.Product
-	for rows.Next() {
-		var product domain.Product
-		err := rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.Stock,
-			&product.CategoryID,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		products = append(products, product)
-	}
+	return product, err
+}
 
-	return products, nil
+func productSortKey(product domain.Product, sort string) (interface{}, int) {
+	switch sort {
+	case "name":
+		return product.Name, product.ID
+	case "price":
+		return product.Price, product.ID
+	case "stock":
+		return product.Stock, product.ID
+	case "created_at":
+		return product.CreatedAt, product.ID
+	default:
+		return product.ID, product.ID
+	}
 }
 
 // FindOne retrieves a single product by ID
 func (r *ProductRepository) FindOne(ctx context.Context, id int) (*domain.Product, error) {
 	var product domain.Product
 
-	query := r.db.QueryBuilder.Select("*").
+	query := r.db.QueryBuilder.Select(productColumns...).
 		From(r.TableName).
 		Where(sq.Eq{"id": id}).
 		Limit(1)
@@ -91,6 +120,7 @@ func (r *ProductRepository) FindOne(ctx context.Context, id int) (*domain.Produc
 		&product.Price,
 		&product.Stock,
 		&product.CategoryID,
+		&product.Attributes,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 	)
@@ -107,8 +137,8 @@ func (r *ProductRepository) FindOne(ctx context.Context, id int) (*domain.Produc
 // Store inserts a new product into the database
 func (r *ProductRepository) Store(ctx context.Context, data *domain.Product) error {
 	query := r.db.QueryBuilder.Insert(r.TableName).
-		Columns("name", "description", "price", "stock", "category_id", "created_at", "updated_at").
-		Values(data.Name, data.Description, data.Price, data.Stock, data.CategoryID, time.Now(), time.Now()).
+		Columns("name", "description", "price", "stock", "category_id", "attributes", "created_at", "updated_at").
+		Values(data.Name, data.Description, data.Price, data.Stock, data.CategoryID, sq.Expr("?::jsonb", jsonObject(data.Attributes)), time.Now(), time.Now()).
 		Suffix("RETURNING id")
 
 	sql, args, err := query.ToSql()
@@ -124,29 +154,28 @@ func (r *ProductRepository) Store(ctx context.Context, data *domain.Product) err
 	return nil
 }
 
-// Update modifies an existing product in the database
+// Update modifies an existing product in the database, nil attributes are
+// left as they are
 func (r *ProductRepository) Update(ctx context.Context, id int, updatedData domain.Product) error {
+	var attributes interface{}
+	if updatedData.Attributes != nil {
+		attributes = jsonObject(updatedData.Attributes)
+	}
+
 	query := r.db.QueryBuilder.Update(r.TableName).
 		Set("name", sq.Expr("COALESCE(?, name)", updatedData.Name)).
 		Set("price", sq.Expr("COALESCE(?, price)", updatedData.Price)).
 		Set("stock", sq.Expr("COALESCE(?, stock)", updatedData.Stock)).
+		Set("attributes", sq.Expr("COALESCE(?::jsonb, attributes)", attributes)).
 		Set("updated_at", time.Now()).
-		Where(sq.Eq{"id": id}).
-		Suffix("RETURNING *")
+		Where(sq.Eq{"id": id})
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return err
 	}
 
-	err = r.db.QueryRow(ctx, sql, args...).Scan(
-		&updatedData.ID,
-		&updatedData.Name,
-		&updatedData.Price,
-		&updatedData.Stock,
-		&updatedData.CreatedAt,
-		&updatedData.UpdatedAt,
-	)
+	_, err = r.db.Exec(ctx, sql, args...)
 	if err != nil {
 		return err
 	}
@@ -154,6 +183,40 @@ func (r *ProductRepository) Update(ctx context.Context, id int, updatedData doma
 	return nil
 }
 
+// FindByIDs retrieves the products with the given IDs in one query, IDs of
+// deleted products are skipped
+func (r *ProductRepository) FindByIDs(ctx context.Context, ids []int) ([]domain.Product, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := r.db.QueryBuilder.Select(productColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": ids})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]domain.Product, 0, len(ids))
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
 // Delete removes a product by ID from the database
 func (r *ProductRepository) Delete(ctx context.Context, id int) error {
 	query := r.db.QueryBuilder.Delete(r.TableName).
@@ -189,3 +252,35 @@ func (r *ProductRepository) UpdateStock(ctx context.Context, productID, newStock
 
 	return nil
 }
+
+// UpdateStocks sets the stock of several products in one statement, stocks is
+// keyed by product ID
+func (r *ProductRepository) UpdateStocks(ctx context.Context, stocks map[int]int) error {
+	if len(stocks) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(stocks))
+	stock := sq.Case("id")
+	for id, newStock := range stocks {
+		ids = append(ids, id)
+		stock = stock.When(sq.Expr("?::int", id), sq.Expr("?::int", newStock))
+	}
+
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("stock", stock).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": ids})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
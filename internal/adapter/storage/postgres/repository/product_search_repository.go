@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// productSearchSorts are the columns a search can be sorted on besides
+// relevance
+var productSearchSorts = map[string]string{
+	"id":         "p.id",
+	"name":       "p.name",
+	"price":      "p.price",
+	"stock":      "p.stock",
+	"created_at": "p.created_at",
+}
+
+// Search retrieves one page of the products matching a search along with the
+// category and price facets of all the matches
+func (r *ProductRepository) Search(ctx context.Context, search domain.ProductSearch) (*domain.ProductSearchResult, error) {
+	sort := search.Sort
+	if sort == "" {
+		sort = "relevance"
+	}
+
+	_, ok := productSearchSorts[sort]
+	if !ok && sort != "relevance" {
+		return nil, consts.ErrInvalidSort
+	}
+
+	query := r.db.QueryBuilder.Select(productColumns...).
+		From(r.TableName + " p").
+		Where(searchFilters(search, true, true))
+
+	total, err := countRows(ctx, r.db, query)
+	if err != nil {
+		return nil, err
+	}
+
+	direction := "ASC"
+	if search.Desc {
+		direction = "DESC"
+	}
+
+	if sort == "relevance" {
+		rank, args := textRank(search.Query, search.Fuzzy)
+		if rank != "" {
+			query = query.OrderByClause(rank+" DESC", args...)
+		}
+		query = query.OrderBy("p.id " + direction)
+	} else {
+		query = query.OrderBy(productSearchSorts[sort]+" "+direction, "p.id "+direction)
+	}
+
+	sql, args, err := query.Limit(search.Limit).Offset(search.Offset).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &domain.ProductSearchResult{
+		Items: []domain.Product{},
+		Total: total,
+		Fuzzy: search.Fuzzy,
+	}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Categories, err = r.categoryFacets(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Prices, err = r.priceFacets(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// categoryFacets counts the matches per category, most matches first
+func (r *ProductRepository) categoryFacets(ctx context.Context, search domain.ProductSearch) ([]domain.CategoryFacet, error) {
+	query := r.db.QueryBuilder.Select("COALESCE(p.category_id, 0)", "COALESCE(c.name, '')", "COUNT(*)").
+		From(r.TableName+" p").
+		LeftJoin("categories c ON c.id = p.category_id").
+		Where(searchFilters(search, false, true)).
+		GroupBy("p.category_id", "c.name").
+		OrderBy("COUNT(*) DESC", "p.category_id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := []domain.CategoryFacet{}
+	for rows.Next() {
+		var facet domain.CategoryFacet
+		if err := rows.Scan(&facet.CategoryID, &facet.Name, &facet.Count); err != nil {
+			return nil, err
+		}
+		facets = append(facets, facet)
+	}
+
+	return facets, rows.Err()
+}
+
+// priceFacets counts the matches in every price bucket of the search with a
+// single scan
+func (r *ProductRepository) priceFacets(ctx context.Context, search domain.ProductSearch) ([]domain.PriceFacet, error) {
+	if len(search.PriceBuckets) == 0 {
+		return []domain.PriceFacet{}, nil
+	}
+
+	query := r.db.QueryBuilder.Select().
+		From(r.TableName + " p").
+		Where(searchFilters(search, true, false))
+
+	for _, bucket := range search.PriceBuckets {
+		if bucket.Max > 0 {
+			query = query.Column(sq.Expr("COUNT(*) FILTER (WHERE p.price >= ? AND p.price < ?)", bucket.Min, bucket.Max))
+		} else {
+			query = query.Column(sq.Expr("COUNT(*) FILTER (WHERE p.price >= ?)", bucket.Min))
+		}
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	facets := make([]domain.PriceFacet, len(search.PriceBuckets))
+	dest := make([]interface{}, len(facets))
+	for i, bucket := range search.PriceBuckets {
+		facets[i].PriceBucket = bucket
+		dest[i] = &facets[i].Count
+	}
+
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// searchFilters returns the conditions of a search on products aliased p,
+// category and price tell whether the category and the price range apply
+func searchFilters(search domain.ProductSearch, category, price bool) sq.And {
+	filters := sq.And{textMatch(search.Query, search.Fuzzy)}
+
+	if category && search.CategoryID != 0 {
+		filters = append(filters, sq.Eq{"p.category_id": search.CategoryID})
+	}
+	if price && search.MinPrice != nil {
+		filters = append(filters, sq.GtOrEq{"p.price": *search.MinPrice})
+	}
+	if price && search.MaxPrice != nil {
+		filters = append(filters, sq.LtOrEq{"p.price": *search.MaxPrice})
+	}
+	if search.InStock {
+		filters = append(filters, sq.Gt{"p.stock": 0})
+	}
+	if len(search.Attributes) > 0 {
+		filters = append(filters, sq.Expr("p.attributes @> ?::jsonb", jsonObject(search.Attributes)))
+	}
+
+	return filters
+}
+
+// textMatch matches the words of term against products aliased p, a
+// full-text search matches word prefixes and a fuzzy one the trigram word
+// similarity with the name. A term without words matches everything
+func textMatch(term string, fuzzy bool) sq.Sqlizer {
+	query := prefixTsQuery(term)
+	if query == "" {
+		return sq.Expr("TRUE")
+	}
+
+	if fuzzy {
+		return sq.Expr("? <% p.name", strings.TrimSpace(term))
+	}
+
+	return sq.Expr("p.search_vector @@ to_tsquery('english', ?)", query)
+}
+
+// textRank is the relevance of a product to term, it is empty when term has
+// no words
+func textRank(term string, fuzzy bool) (string, []interface{}) {
+	query := prefixTsQuery(term)
+	if query == "" {
+		return "", nil
+	}
+
+	if fuzzy {
+		return "word_similarity(?, p.name)", []interface{}{strings.TrimSpace(term)}
+	}
+
+	return "ts_rank_cd(p.search_vector, to_tsquery('english', ?))", []interface{}{query}
+}
+
+// prefixTsQuery turns the words of term into a tsquery matching all of them
+// as prefixes, e.g. "red sho" gives "red:* & sho:*". Anything but letters and
+// digits separates words, so the result is always a valid tsquery
+func prefixTsQuery(term string) string {
+	words := strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// jsonObject encodes string pairs such as product attributes for a jsonb
+// column
+func jsonObject(values map[string]string) string {
+	if values == nil {
+		return "{}"
+	}
+
+	raw, _ := json.Marshal(values)
+
+	return string(raw)
+}
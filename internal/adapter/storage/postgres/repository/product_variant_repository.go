@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type ProductVariantRepository struct {
+	db              *postgres.DB
+	TableName       string
+	OptionTableName string
+}
+
+func NewProductVariantRepository(db *postgres.DB) *ProductVariantRepository {
+	return &ProductVariantRepository{
+		db:              db,
+		TableName:       "product_variants",
+		OptionTableName: "product_options",
+	}
+}
+
+var productVariantColumns = []string{
+	"id",
+	"product_id",
+	"sku",
+	"price",
+	"stock",
+	"COALESCE(barcode, '')",
+	"options",
+	"created_at",
+	"updated_at",
+}
+
+// FindOptions retrieves the options of a product in display order
+func (r *ProductVariantRepository) FindOptions(ctx context.Context, productID int) ([]domain.ProductOption, error) {
+	query := r.db.QueryBuilder.Select("id", "product_id", "name", `"values"`, "position", "created_at").
+		From(r.OptionTableName).
+		Where(sq.Eq{"product_id": productID}).
+		OrderBy("position", "id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	options := []domain.ProductOption{}
+	for rows.Next() {
+		var option domain.ProductOption
+		err := rows.Scan(
+			&option.ID,
+			&option.ProductID,
+			&option.Name,
+			&option.Values,
+			&option.Position,
+			&option.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+
+	return options, rows.Err()
+}
+
+// StoreOption inserts an option of a product, a product has one option per
+// name
+func (r *ProductVariantRepository) StoreOption(ctx context.Context, data *domain.ProductOption) error {
+	values := data.Values
+	if values == nil {
+		values = []string{}
+	}
+
+	query := r.db.QueryBuilder.Insert(r.OptionTableName).
+		Columns("product_id", "name", `"values"`, "position", "created_at").
+		Values(data.ProductID, data.Name, values, data.Position, time.Now()).
+		Suffix("RETURNING id, created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID, &data.CreatedAt)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+
+	return nil
+}
+
+// DeleteOption removes an option of a product
+func (r *ProductVariantRepository) DeleteOption(ctx context.Context, productID, id int) error {
+	query := r.db.QueryBuilder.Delete(r.OptionTableName).
+		Where(sq.Eq{"id": id, "product_id": productID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// FindOne retrieves a single variant by ID
+func (r *ProductVariantRepository) FindOne(ctx context.Context, id int) (*domain.ProductVariant, error) {
+	query := r.db.QueryBuilder.Select(productVariantColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := scanProductVariant(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+// FindByProductIDs retrieves the variants of several products in one query,
+// ordered by ID
+func (r *ProductVariantRepository) FindByProductIDs(ctx context.Context, productIDs []int) ([]domain.ProductVariant, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	query := r.db.QueryBuilder.Select(productVariantColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"product_id": productIDs}).
+		OrderBy("id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []domain.ProductVariant
+	for rows.Next() {
+		variant, err := scanProductVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, rows.Err()
+}
+
+// Store inserts a new variant, a SKU, barcode or set of options already in
+// use is ErrConflictingData
+func (r *ProductVariantRepository) Store(ctx context.Context, data *domain.ProductVariant) error {
+	now := time.Now()
+
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("product_id", "sku", "price", "stock", "barcode", "options", "created_at", "updated_at").
+		Values(data.ProductID, data.SKU, data.Price, data.Stock, nullString(data.Barcode), sq.Expr("?::jsonb", jsonObject(data.Options)), now, now).
+		Suffix("RETURNING id, created_at, updated_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID, &data.CreatedAt, &data.UpdatedAt)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+
+	return nil
+}
+
+// Update replaces the SKU, price, stock, barcode and options of a variant
+func (r *ProductVariantRepository) Update(ctx context.Context, id int, updatedData domain.ProductVariant) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("sku", updatedData.SKU).
+		Set("price", updatedData.Price).
+		Set("stock", updatedData.Stock).
+		Set("barcode", nullString(updatedData.Barcode)).
+		Set("options", sq.Expr("?::jsonb", jsonObject(updatedData.Options))).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+
+	return nil
+}
+
+// Delete removes a variant, its cart lines go with it
+func (r *ProductVariantRepository) Delete(ctx context.Context, id int) error {
+	query := r.db.QueryBuilder.Delete(r.TableName).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ProductVariantRepository) UpdateStock(ctx context.Context, id, newStock int) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("stock", newStock).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStocks sets the stock of several variants in one statement, stocks is
+// keyed by variant ID
+func (r *ProductVariantRepository) UpdateStocks(ctx context.Context, stocks map[int]int) error {
+	if len(stocks) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(stocks))
+	stock := sq.Case("id")
+	for id, newStock := range stocks {
+		ids = append(ids, id)
+		stock = stock.When(sq.Expr("?::int", id), sq.Expr("?::int", newStock))
+	}
+
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("stock", stock).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": ids})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanProductVariant(row pgx.Row) (domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	err := row.Scan(
+		&variant.ID,
+		&variant.ProductID,
+		&variant.SKU,
+		&variant.Price,
+		&variant.Stock,
+		&variant.Barcode,
+		&variant.Options,
+		&variant.CreatedAt,
+		&variant.UpdatedAt,
+	)
+
+	return variant, err
+}
+
+// uniqueViolation turns a unique constraint error into ErrConflictingData
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return consts.ErrConflictingData
+	}
+
+	return err
+}
@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type ScheduledTransferRepository struct {
+	db           *postgres.DB
+	TableName    string
+	RunTableName string
+}
+
+func NewScheduledTransferRepository(db *postgres.DB) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{
+		db:           db,
+		TableName:    "scheduled_transfers",
+		RunTableName: "scheduled_transfer_runs",
+	}
+}
+
+var scheduledTransferColumns = []string{
+	"id",
+	"user_id",
+	"recipient_id",
+	"amount",
+	"frequency",
+	"status",
+	"start_at",
+	"end_at",
+	"next_run_at",
+	"run_count",
+	"failure_count",
+	"COALESCE(last_error, '')",
+	"created_at",
+	"updated_at",
+}
+
+// Store inserts an active schedule whose first run is at StartAt
+func (r *ScheduledTransferRepository) Store(ctx context.Context, data *domain.ScheduledTransfer) error {
+	now := time.Now()
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("user_id", "recipient_id", "amount", "frequency", "status", "start_at", "end_at", "next_run_at", "created_at", "updated_at").
+		Values(data.UserID, data.RecipientID, data.Amount, data.Frequency, domain.ScheduledTransferActive, data.StartAt, data.EndAt, data.StartAt, now, now).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID)
+	if err != nil {
+		return err
+	}
+
+	data.Status = domain.ScheduledTransferActive
+	data.NextRunAt = data.StartAt
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// Finds retrieves the schedules matching the filter, newest first
+func (r *ScheduledTransferRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.ScheduledTransfer, error) {
+	query := r.db.QueryBuilder.Select(scheduledTransferColumns...).
+		From(r.TableName).
+		OrderBy("id DESC")
+
+	for key, value := range filter {
+		query = query.Where(sq.Eq{key: value})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []domain.ScheduledTransfer
+	for rows.Next() {
+		schedule, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// FindOne retrieves a single schedule by ID
+func (r *ScheduledTransferRepository) FindOne(ctx context.Context, id uint64) (*domain.ScheduledTransfer, error) {
+	query := r.db.QueryBuilder.Select(scheduledTransferColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := scanScheduledTransfer(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// Update saves the editable fields of an active or paused schedule, it fails
+// with ErrScheduledTransferClosed once the schedule is closed
+func (r *ScheduledTransferRepository) Update(ctx context.Context, data *domain.ScheduledTransfer) error {
+	now := time.Now()
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("amount", data.Amount).
+		Set("frequency", data.Frequency).
+		Set("status", data.Status).
+		Set("start_at", data.StartAt).
+		Set("end_at", data.EndAt).
+		Set("next_run_at", data.NextRunAt).
+		Set("run_count", data.RunCount).
+		Set("updated_at", now).
+		Where(sq.Eq{
+			"id":     data.ID,
+			"status": []domain.ScheduledTransferStatus{domain.ScheduledTransferActive, domain.ScheduledTransferPaused},
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrScheduledTransferClosed
+	}
+
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// ClaimDue leases up to limit active schedules that are due, a leased schedule
+// is skipped by other workers until it is recorded, released or the lease ends
+func (r *ScheduledTransferRepository) ClaimDue(ctx context.Context, now time.Time, limit uint64, lease time.Duration) ([]domain.ScheduledTransfer, error) {
+	// the subquery keeps ? placeholders, the outer builder numbers them
+	due := sq.Select("id").
+		From(r.TableName).
+		Where(sq.Eq{"status": domain.ScheduledTransferActive}).
+		Where(sq.LtOrEq{"next_run_at": now}).
+		Where(sq.Or{sq.Eq{"locked_until": nil}, sq.Lt{"locked_until": now}}).
+		OrderBy("next_run_at").
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	dueSQL, dueArgs, err := due.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("locked_until", now.Add(lease)).
+		Where("id IN ("+dueSQL+")", dueArgs...).
+		Suffix("RETURNING " + strings.Join(scheduledTransferColumns, ", "))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []domain.ScheduledTransfer
+	for rows.Next() {
+		schedule, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// Release drops the lease on a schedule without recording a run, so the next
+// tick picks it up again
+func (r *ScheduledTransferRepository) Release(ctx context.Context, id uint64) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("locked_until", nil).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	return err
+}
+
+// RecordRun appends the run and saves the schedule's progress in one
+// transaction, releasing its lease
+func (r *ScheduledTransferRepository) RecordRun(ctx context.Context, schedule *domain.ScheduledTransfer, run *domain.ScheduledTransferRun) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	insert := sq.Insert(r.RunTableName).
+		Columns("scheduled_transfer_id", "status", "amount", "error", "scheduled_at", "created_at").
+		Values(schedule.ID, run.Status, run.Amount, nullString(run.Error), run.ScheduledAt, now).
+		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := insert.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&run.ID); err != nil {
+		return err
+	}
+
+	// a schedule paused or cancelled while it was running keeps that status
+	update := sq.Update(r.TableName).
+		Set("status", sq.Expr("CASE WHEN status = ? THEN ? ELSE status END", domain.ScheduledTransferActive, schedule.Status)).
+		Set("next_run_at", schedule.NextRunAt).
+		Set("run_count", schedule.RunCount).
+		Set("failure_count", schedule.FailureCount).
+		Set("last_error", nullString(schedule.LastError)).
+		Set("locked_until", nil).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": schedule.ID}).PlaceholderFormat(sq.Dollar)
+
+	sql, args, err = update.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	run.ScheduledTransferID = schedule.ID
+	run.CreatedAt = now
+
+	return tx.Commit(ctx)
+}
+
+// FindRuns retrieves the run history of a schedule, newest first
+func (r *ScheduledTransferRepository) FindRuns(ctx context.Context, scheduleID uint64) ([]domain.ScheduledTransferRun, error) {
+	query := r.db.QueryBuilder.Select("id", "scheduled_transfer_id", "status", "amount", "COALESCE(error, '')", "scheduled_at", "created_at").
+		From(r.RunTableName).
+		Where(sq.Eq{"scheduled_transfer_id": scheduleID}).
+		OrderBy("id DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []domain.ScheduledTransferRun
+	for rows.Next() {
+		var run domain.ScheduledTransferRun
+		err := rows.Scan(&run.ID, &run.ScheduledTransferID, &run.Status, &run.Amount, &run.Error, &run.ScheduledAt, &run.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func scanScheduledTransfer(row pgx.Row) (*domain.ScheduledTransfer, error) {
+	var schedule domain.ScheduledTransfer
+	var endAt sql.NullTime
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.UserID,
+		&schedule.RecipientID,
+		&schedule.Amount,
+		&schedule.Frequency,
+		&schedule.Status,
+		&schedule.StartAt,
+		&endAt,
+		&schedule.NextRunAt,
+		&schedule.RunCount,
+		&schedule.FailureCount,
+		&schedule.LastError,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if endAt.Valid {
+		schedule.EndAt = &endAt.Time
+	}
+
+	return &schedule, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type TransferInquiryRepository struct {
+	db        *postgres.DB
+	TableName string
+}
+
+func NewTransferInquiryRepository(db *postgres.DB) *TransferInquiryRepository {
+	return &TransferInquiryRepository{
+		db:        db,
+		TableName: "transfer_inquiries",
+	}
+}
+
+// Store inserts an inquiry, the inquiries that expired are removed on the way
+// so the table only keeps the open ones and those used since
+func (r *TransferInquiryRepository) Store(ctx context.Context, data *domain.TransferInquiry) error {
+	now := time.Now()
+
+	sql, args, err := r.db.QueryBuilder.Delete(r.TableName).
+		Where(sq.Lt{"expires_at": now}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	sql, args, err = r.db.QueryBuilder.Insert(r.TableName).
+		Columns("id", "sender_id", "recipient_id", "recipient_name", "amount", "verification", "otp_hash", "expires_at", "created_at").
+		Values(data.ID, data.SenderID, data.RecipientID, data.RecipientName, data.Amount, data.Verification, nullString(data.OTPHash), data.ExpiresAt, now).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	return err
+}
+
+// FindOne retrieves an inquiry that is neither used nor expired
+func (r *TransferInquiryRepository) FindOne(ctx context.Context, id string) (*domain.TransferInquiry, error) {
+	sql, args, err := r.db.QueryBuilder.Select(
+		"id",
+		"sender_id",
+		"recipient_id",
+		"recipient_name",
+		"amount",
+		"verification",
+		"COALESCE(otp_hash, '')",
+		"expires_at",
+	).
+		From(r.TableName).
+		Where(r.open(id)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var inquiry domain.TransferInquiry
+	err = r.db.QueryRow(ctx, sql, args...).Scan(
+		&inquiry.ID,
+		&inquiry.SenderID,
+		&inquiry.RecipientID,
+		&inquiry.RecipientName,
+		&inquiry.Amount,
+		&inquiry.Verification,
+		&inquiry.OTPHash,
+		&inquiry.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &inquiry, nil
+}
+
+// AddAttempt counts a verification attempt on an open inquiry and returns the
+// attempts made so far, concurrent attempts each get their own count
+func (r *TransferInquiryRepository) AddAttempt(ctx context.Context, id string) (int, error) {
+	sql, args, err := r.db.QueryBuilder.Update(r.TableName).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Where(r.open(id)).
+		Suffix("RETURNING attempts").
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var attempts int
+	if err := r.db.QueryRow(ctx, sql, args...).Scan(&attempts); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, consts.ErrTransferInquiryNotFound
+		}
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// Consume closes an open inquiry, it fails with ErrTransferInquiryNotFound
+// when the inquiry was already used or has expired, so only one of concurrent
+// callers consumes it
+func (r *TransferInquiryRepository) Consume(ctx context.Context, id string) error {
+	sql, args, err := r.db.QueryBuilder.Update(r.TableName).
+		Set("consumed_at", time.Now()).
+		Where(r.open(id)).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrTransferInquiryNotFound
+	}
+
+	return nil
+}
+
+// open matches the inquiry while it can still be confirmed
+func (r *TransferInquiryRepository) open(id string) sq.And {
+	return sq.And{
+		sq.Eq{"id": id, "consumed_at": nil},
+		sq.Gt{"expires_at": time.Now()},
+	}
+}
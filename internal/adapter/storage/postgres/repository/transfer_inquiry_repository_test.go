@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres/repository"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/google/uuid"
+)
+
+func TestTransferInquiryRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewTransferInquiryRepository(db)
+	ctx := context.Background()
+
+	newInquiry := func(t *testing.T, expiresAt time.Time) *domain.TransferInquiry {
+		users := newTestUsers(t, db, 2)
+		inquiry := &domain.TransferInquiry{
+			ID:            uuid.NewString(),
+			SenderID:      users[0],
+			RecipientID:   users[1],
+			RecipientName: "R***",
+			Amount:        125.5,
+			Verification:  domain.TransferVerificationOTP,
+			OTPHash:       "hash",
+			ExpiresAt:     expiresAt,
+		}
+		if err := repo.Store(ctx, inquiry); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+		return inquiry
+	}
+
+	t.Run("find and consume once", func(t *testing.T) {
+		inquiry := newInquiry(t, time.Now().Add(time.Minute))
+
+		found, err := repo.FindOne(ctx, inquiry.ID)
+		if err != nil || found == nil {
+			t.Fatalf("FindOne = %v, %v", found, err)
+		}
+		if found.SenderID != inquiry.SenderID || found.Amount != inquiry.Amount || found.OTPHash != inquiry.OTPHash {
+			t.Fatalf("FindOne = %+v, want %+v", found, inquiry)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- repo.Consume(ctx, inquiry.ID)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		consumed := 0
+		for err := range errs {
+			switch {
+			case err == nil:
+				consumed++
+			case !errors.Is(err, consts.ErrTransferInquiryNotFound):
+				t.Fatalf("Consume: %v", err)
+			}
+		}
+		if consumed != 1 {
+			t.Fatalf("%d concurrent Consume calls succeeded, want 1", consumed)
+		}
+
+		if found, err := repo.FindOne(ctx, inquiry.ID); err != nil || found != nil {
+			t.Fatalf("FindOne after Consume = %v, %v, want nil", found, err)
+		}
+	})
+
+	t.Run("attempts are counted", func(t *testing.T) {
+		inquiry := newInquiry(t, time.Now().Add(time.Minute))
+
+		for want := 1; want <= 3; want++ {
+			attempts, err := repo.AddAttempt(ctx, inquiry.ID)
+			if err != nil || attempts != want {
+				t.Fatalf("AddAttempt = %d, %v, want %d", attempts, err, want)
+			}
+		}
+
+		if err := repo.Consume(ctx, inquiry.ID); err != nil {
+			t.Fatalf("Consume: %v", err)
+		}
+		if _, err := repo.AddAttempt(ctx, inquiry.ID); !errors.Is(err, consts.ErrTransferInquiryNotFound) {
+			t.Fatalf("AddAttempt after Consume = %v, want ErrTransferInquiryNotFound", err)
+		}
+	})
+
+	t.Run("expired inquiries are closed", func(t *testing.T) {
+		inquiry := newInquiry(t, time.Now().Add(50*time.Millisecond))
+		time.Sleep(100 * time.Millisecond)
+
+		if found, err := repo.FindOne(ctx, inquiry.ID); err != nil || found != nil {
+			t.Fatalf("FindOne = %v, %v, want nil", found, err)
+		}
+		if err := repo.Consume(ctx, inquiry.ID); !errors.Is(err, consts.ErrTransferInquiryNotFound) {
+			t.Fatalf("Consume = %v, want ErrTransferInquiryNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type TransferReviewRepository struct {
+	db        *postgres.DB
+	TableName string
+}
+
+func NewTransferReviewRepository(db *postgres.DB) *TransferReviewRepository {
+	return &TransferReviewRepository{
+		db:        db,
+		TableName: "transfer_reviews",
+	}
+}
+
+var transferReviewColumns = []string{
+	"id",
+	"sender_id",
+	"recipient_id",
+	"amount",
+	"status",
+	"COALESCE(note, '')",
+	"COALESCE(reviewed_by, 0)",
+	"created_at",
+	"updated_at",
+}
+
+// Store inserts a pending transfer review
+func (r *TransferReviewRepository) Store(ctx context.Context, data *domain.TransferReview) error {
+	now := time.Now()
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("sender_id", "recipient_id", "amount", "status", "created_at", "updated_at").
+		Values(data.SenderID, data.RecipientID, data.Amount, domain.TransferReviewPending, now, now).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID)
+	if err != nil {
+		return err
+	}
+
+	data.Status = domain.TransferReviewPending
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// Finds retrieves the transfer reviews matching the filter, newest first
+func (r *TransferReviewRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.TransferReview, error) {
+	query := r.db.QueryBuilder.Select(transferReviewColumns...).
+		From(r.TableName).
+		OrderBy("id DESC")
+
+	for key, value := range filter {
+		query = query.Where(sq.Eq{key: value})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []domain.TransferReview
+	for rows.Next() {
+		review, err := scanTransferReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// FindOne retrieves a single transfer review by ID
+func (r *TransferReviewRepository) FindOne(ctx context.Context, id uint64) (*domain.TransferReview, error) {
+	query := r.db.QueryBuilder.Select(transferReviewColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := scanTransferReview(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// UpdateStatus moves a review from one status to another, it fails with
+// ErrTransferReviewClosed when the review is no longer in the expected status
+func (r *TransferReviewRepository) UpdateStatus(ctx context.Context, id uint64, from, to domain.TransferReviewStatus, adminID uint64, note string) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("status", to).
+		Set("reviewed_by", adminID).
+		Set("note", sq.Expr("COALESCE(?, note)", nullString(note))).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": from})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrTransferReviewClosed
+	}
+
+	return nil
+}
+
+func scanTransferReview(row pgx.Row) (*domain.TransferReview, error) {
+	var review domain.TransferReview
+	err := row.Scan(
+		&review.ID,
+		&review.SenderID,
+		&review.RecipientID,
+		&review.Amount,
+		&review.Status,
+		&review.Note,
+		&review.ReviewedBy,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
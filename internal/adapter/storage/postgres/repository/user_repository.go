@@ -25,8 +25,8 @@ func NewUserRepository(db *postgres.DB) *UserRepository {
 // CreateUser creates a new user in the database
 func (ur *UserRepository) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
 	query := ur.db.QueryBuilder.Insert("users").
-		Columns("name", "email", "password", "role", "created_at", "updated_at").
-		Values(user.Name, user.Email, user.Password, user.Role, user.CreatedAt, user.UpdatedAt).
+		Columns("name", "email", "password", "role", "created_at", "updated_at", "phone").
+		Values(user.Name, user.Email, user.Password, user.Role, user.CreatedAt, user.UpdatedAt, user.Phone).
 		Suffix("RETURNING *")
 
 	sql, args, err := query.ToSql()
@@ -42,6 +42,7 @@ func (ur *UserRepository) CreateUser(ctx context.Context, user *domain.User) (*d
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Phone,
 	)
 	if err != nil {
 		return nil, err
@@ -71,6 +72,7 @@ func (ur *UserRepository) GetUserByID(ctx context.Context, id uint64) (*domain.U
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Phone,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -103,6 +105,7 @@ func (ur *UserRepository) GetUserByEmail(ctx context.Context, email string) (*do
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Phone,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -114,53 +117,82 @@ func (ur *UserRepository) GetUserByEmail(ctx context.Context, email string) (*do
 	return &user, nil
 }
 
-func (ur *UserRepository) ListUsers(ctx context.Context, offset, limit uint64) ([]domain.User, error) {
+func (ur *UserRepository) GetUserByPhone(ctx context.Context, phone string) (*domain.User, error) {
 	var user domain.User
-	var users []domain.User
-
-	if limit == 0 {
-		limit = 10
-	}
-
-	if offset == 0 {
-		offset = 1
-	}
 
 	query := ur.db.QueryBuilder.Select("*").
 		From("users").
-		OrderBy("id").
-		Limit(limit).
-		Offset((offset - 1) * limit)
+		Where(sq.Eq{"phone": phone}).
+		Limit(1)
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := ur.db.Query(ctx, sql, args...)
+	err = ur.db.QueryRow(ctx, sql, args...).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.Phone,
+	)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consts.ErrDataNotFound
+		}
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		err := rows.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Email,
-			&user.Password,
-			&user.Role,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
 
-		users = append(users, user)
-	}
+	return &user, nil
+}
+
+var userSortColumns = sortColumns{
+	"name":       {column: "name", cast: "text"},
+	"email":      {column: "email", cast: "text"},
+	"created_at": {column: "created_at", cast: "timestamp"},
+}
 
-	return users, nil
+// ListUsers retrieves one page of the users
+func (ur *UserRepository) ListUsers(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.User], error) {
+	query := ur.db.QueryBuilder.Select("id", "name", "email", "password", "role", "created_at", "updated_at", "phone").
+		From("users")
+
+	return findPage(ctx, pageQuery[domain.User]{
+		db:      ur.db,
+		query:   query,
+		idCol:   "id",
+		columns: userSortColumns,
+		scan: func(rows pgx.Rows) (domain.User, error) {
+			var user domain.User
+			err := rows.Scan(
+				&user.ID,
+				&user.Name,
+				&user.Email,
+				&user.Password,
+				&user.Role,
+				&user.CreatedAt,
+				&user.UpdatedAt,
+				&user.Phone,
+			)
+			return user, err
+		},
+		key: func(user domain.User, sort string) (interface{}, int) {
+			switch sort {
+			case "name":
+				return user.Name, int(user.ID)
+			case "email":
+				return user.Email, int(user.ID)
+			case "created_at":
+				return user.CreatedAt, int(user.ID)
+			default:
+				return int(user.ID), int(user.ID)
+			}
+		},
+	}, page)
 }
 
 func (ur *UserRepository) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
@@ -170,6 +202,7 @@ func (ur *UserRepository) UpdateUser(ctx context.Context, user *domain.User) (*d
 		Set("email", sq.Expr("COALESCE(?, email)", nullString(user.Email))).
 		Set("password", sq.Expr("COALESCE(?, password)", nullString(user.Password))).
 		Set("role", sq.Expr("COALESCE(?, role)", nullString(string(user.Role)))).
+		Set("phone", sq.Expr("COALESCE(?, phone)", nullString(user.Phone))).
 		Set("updated_at", time.Now()).
 		Where(sq.Eq{"id": user.ID}).
 		Suffix("RETURNING *")
@@ -187,6 +220,7 @@ func (ur *UserRepository) UpdateUser(ctx context.Context, user *domain.User) (*d
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Phone,
 	)
 	if err != nil {
 		return nil, err
@@ -234,6 +268,7 @@ func (ur *UserRepository) GetUserByToken(ctx context.Context, token string) (*do
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Phone,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type WalletAdjustmentRepository struct {
+	db        *postgres.DB
+	TableName string
+	ledger    *BalanceRepository
+}
+
+func NewWalletAdjustmentRepository(db *postgres.DB) *WalletAdjustmentRepository {
+	return &WalletAdjustmentRepository{
+		db:        db,
+		TableName: "wallet_adjustments",
+		ledger:    NewBalanceRepository(db),
+	}
+}
+
+var walletAdjustmentColumns = []string{
+	"id",
+	"user_id",
+	"direction",
+	"amount",
+	"reason_code",
+	"note",
+	"status",
+	"requested_by",
+	"COALESCE(reviewed_by, 0)",
+	"COALESCE(rejection_reason, '')",
+	"COALESCE(journal_entry_id, 0)",
+	"created_at",
+	"updated_at",
+}
+
+// Store inserts the adjustment, one stored as applied is posted to the ledger
+// in the same transaction while a pending one waits for Approve
+func (r *WalletAdjustmentRepository) Store(ctx context.Context, data *domain.WalletAdjustment) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	query := sq.Insert(r.TableName).
+		Columns("user_id", "direction", "amount", "reason_code", "note", "status", "requested_by", "created_at", "updated_at").
+		Values(data.UserID, data.Direction, data.Amount, data.ReasonCode, data.Note, domain.AdjustmentPending, data.RequestedBy, now, now).
+		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&data.ID); err != nil {
+		return err
+	}
+
+	if data.Status == domain.AdjustmentApplied {
+		if err := r.apply(ctx, tx, data, data.RequestedBy); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	data.Status = domain.AdjustmentPending
+	if data.JournalEntryID != 0 {
+		data.Status = domain.AdjustmentApplied
+	}
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// Finds retrieves the adjustments matching the filter, newest first. Besides
+// the columns the filter accepts from and to as bounds on created_at
+func (r *WalletAdjustmentRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.WalletAdjustment, error) {
+	query := r.db.QueryBuilder.Select(walletAdjustmentColumns...).
+		From(r.TableName).
+		OrderBy("id DESC")
+
+	for key, value := range filter {
+		switch key {
+		case "from":
+			query = query.Where(sq.GtOrEq{"created_at": value})
+		case "to":
+			query = query.Where(sq.Lt{"created_at": value})
+		default:
+			query = query.Where(sq.Eq{key: value})
+		}
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjustments []domain.WalletAdjustment
+	for rows.Next() {
+		adjustment, err := scanWalletAdjustment(rows)
+		if err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, *adjustment)
+	}
+
+	return adjustments, rows.Err()
+}
+
+// FindOne retrieves a single adjustment by ID
+func (r *WalletAdjustmentRepository) FindOne(ctx context.Context, id uint64) (*domain.WalletAdjustment, error) {
+	query := r.db.QueryBuilder.Select(walletAdjustmentColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment, err := scanWalletAdjustment(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// Approve posts a pending adjustment to the ledger on behalf of adminID, it
+// fails with ErrAdjustmentClosed once the adjustment is no longer pending
+func (r *WalletAdjustmentRepository) Approve(ctx context.Context, id uint64, adminID uint64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := sq.Select(walletAdjustmentColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id, "status": domain.AdjustmentPending}).
+		Suffix("FOR UPDATE").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	adjustment, err := scanWalletAdjustment(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return consts.ErrAdjustmentClosed
+		}
+		return err
+	}
+
+	if err := r.apply(ctx, tx, adjustment, adminID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Reject closes a pending adjustment without touching the wallet
+func (r *WalletAdjustmentRepository) Reject(ctx context.Context, id uint64, adminID uint64, reason string) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("status", domain.AdjustmentRejected).
+		Set("reviewed_by", adminID).
+		Set("rejection_reason", reason).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": domain.AdjustmentPending})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrAdjustmentClosed
+	}
+
+	return nil
+}
+
+// apply posts the adjustment against the adjustments account with adminID as
+// the author of the journal entry and marks it applied
+func (r *WalletAdjustmentRepository) apply(ctx context.Context, tx pgx.Tx, data *domain.WalletAdjustment, adminID uint64) error {
+	entry := &domain.JournalEntry{
+		EntryType:   domain.TransactionAdjustment,
+		ReferenceID: data.ID,
+		Description: "Wallet adjustment: " + data.ReasonCode,
+		CreatedBy:   adminID,
+		Lines: []domain.JournalLine{
+			{UserID: data.UserID, Amount: data.SignedAmount()},
+			{AccountCode: domain.AccountWalletAdjustments, Amount: -data.SignedAmount()},
+		},
+	}
+	if err := r.ledger.post(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	query := sq.Update(r.TableName).
+		Set("status", domain.AdjustmentApplied).
+		Set("journal_entry_id", entry.ID).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": data.ID}).PlaceholderFormat(sq.Dollar)
+
+	// the requester applying their own adjustment is not a review
+	if adminID != data.RequestedBy {
+		query = query.Set("reviewed_by", adminID)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	data.JournalEntryID = entry.ID
+
+	return nil
+}
+
+func scanWalletAdjustment(row pgx.Row) (*domain.WalletAdjustment, error) {
+	var adjustment domain.WalletAdjustment
+	err := row.Scan(
+		&adjustment.ID,
+		&adjustment.UserID,
+		&adjustment.Direction,
+		&adjustment.Amount,
+		&adjustment.ReasonCode,
+		&adjustment.Note,
+		&adjustment.Status,
+		&adjustment.RequestedBy,
+		&adjustment.ReviewedBy,
+		&adjustment.RejectionReason,
+		&adjustment.JournalEntryID,
+		&adjustment.CreatedAt,
+		&adjustment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adjustment, nil
+}
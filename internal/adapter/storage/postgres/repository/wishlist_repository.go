@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type WishlistRepository struct {
+	db        *postgres.DB
+	TableName string
+}
+
+func NewWishlistRepository(db *postgres.DB) *WishlistRepository {
+	return &WishlistRepository{
+		db:        db,
+		TableName: "wishlists",
+	}
+}
+
+var wishlistColumns = []string{
+	"w.id",
+	"w.user_id",
+	"w.name",
+	"w.is_default",
+	"COALESCE(w.share_token, '')",
+	"(SELECT COUNT(*) FROM wishlist_items i WHERE i.wishlist_id = w.id)",
+	"w.created_at",
+	"w.updated_at",
+}
+
+func (r *WishlistRepository) FindOne(ctx context.Context, id int) (*domain.Wishlist, error) {
+	query := r.db.QueryBuilder.Select(wishlistColumns...).
+		From(r.TableName + " w").
+		Where(sq.Eq{"w.id": id}).
+		Limit(1)
+
+	return r.findOne(ctx, query)
+}
+
+// FindByUserID retrieves the lists of a user, the default list first
+func (r *WishlistRepository) FindByUserID(ctx context.Context, userID int) ([]domain.Wishlist, error) {
+	query := r.db.QueryBuilder.Select(wishlistColumns...).
+		From(r.TableName+" w").
+		Where(sq.Eq{"w.user_id": userID}).
+		OrderBy("w.is_default DESC", "w.created_at", "w.id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wishlists []domain.Wishlist
+	for rows.Next() {
+		wishlist, err := scanWishlist(rows)
+		if err != nil {
+			return nil, err
+		}
+		wishlists = append(wishlists, *wishlist)
+	}
+
+	return wishlists, rows.Err()
+}
+
+func (r *WishlistRepository) FindDefault(ctx context.Context, userID int) (*domain.Wishlist, error) {
+	query := r.db.QueryBuilder.Select(wishlistColumns...).
+		From(r.TableName + " w").
+		Where(sq.Eq{"w.user_id": userID, "w.is_default": true}).
+		Limit(1)
+
+	return r.findOne(ctx, query)
+}
+
+func (r *WishlistRepository) FindByShareToken(ctx context.Context, token string) (*domain.Wishlist, error) {
+	query := r.db.QueryBuilder.Select(wishlistColumns...).
+		From(r.TableName + " w").
+		Where(sq.Eq{"w.share_token": token}).
+		Limit(1)
+
+	return r.findOne(ctx, query)
+}
+
+func (r *WishlistRepository) findOne(ctx context.Context, query sq.SelectBuilder) (*domain.Wishlist, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	wishlist, err := scanWishlist(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return wishlist, nil
+}
+
+func scanWishlist(row pgx.Row) (*domain.Wishlist, error) {
+	var wishlist domain.Wishlist
+	err := row.Scan(
+		&wishlist.ID,
+		&wishlist.UserID,
+		&wishlist.Name,
+		&wishlist.IsDefault,
+		&wishlist.ShareToken,
+		&wishlist.ItemCount,
+		&wishlist.CreatedAt,
+		&wishlist.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wishlist, nil
+}
+
+func (r *WishlistRepository) Store(ctx context.Context, data *domain.Wishlist) error {
+	now := time.Now()
+	query := r.db.QueryBuilder.Insert(r.TableName).
+		Columns("user_id", "name", "is_default", "created_at", "updated_at").
+		Values(data.UserID, data.Name, data.IsDefault, now, now).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID)
+	if err != nil {
+		return err
+	}
+
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// Update sets the name and the share token of a list, an empty token stops
+// sharing it
+func (r *WishlistRepository) Update(ctx context.Context, id int, updatedData domain.Wishlist) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("name", updatedData.Name).
+		Set("share_token", nullString(updatedData.ShareToken)).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the list together with its items
+func (r *WishlistRepository) Delete(ctx context.Context, id int) error {
+	query := r.db.QueryBuilder.Delete(r.TableName).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *WishlistRepository) FindItem(ctx context.Context, wishlistID, productID int) (*domain.WishlistItem, error) {
+	query := r.db.QueryBuilder.Select("id", "wishlist_id", "product_id", "price", "created_at").
+		From("wishlist_items").
+		Where(sq.Eq{"wishlist_id": wishlistID, "product_id": productID}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var item domain.WishlistItem
+	err = r.db.QueryRow(ctx, sql, args...).Scan(
+		&item.ID,
+		&item.WishlistID,
+		&item.ProductID,
+		&item.Price,
+		&item.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// FindItemsWithProducts retrieves the items of a list together with their
+// current products, newest first. Items go with their deleted product
+func (r *WishlistRepository) FindItemsWithProducts(ctx context.Context, wishlistID int) ([]domain.WishlistItemWithProduct, error) {
+	query := r.db.QueryBuilder.Select(
+		"i.id",
+		"i.wishlist_id",
+		"i.product_id",
+		"i.price",
+		"i.created_at",
+		"p.id",
+		"p.name",
+		"COALESCE(p.description, '')",
+		"p.price",
+		"p.stock",
+		"COALESCE(p.category_id, 0)",
+		"p.created_at",
+		"p.updated_at",
+	).
+		From("wishlist_items i").
+		Join("products p ON p.id = i.product_id").
+		Where(sq.Eq{"i.wishlist_id": wishlistID}).
+		OrderBy("i.created_at DESC", "i.id DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.WishlistItemWithProduct
+	for rows.Next() {
+		var (
+			item    domain.WishlistItemWithProduct
+			product domain.Product
+		)
+		err := rows.Scan(
+			&item.ID,
+			&item.WishlistID,
+			&item.ProductID,
+			&item.Price,
+			&item.CreatedAt,
+			&product.ID,
+			&product.Name,
+			&product.Description,
+			&product.Price,
+			&product.Stock,
+			&product.CategoryID,
+			&product.CreatedAt,
+			&product.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		item.Product = &product
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// AddItem saves a product in a list, a product already in the list keeps
+// its saved price
+func (r *WishlistRepository) AddItem(ctx context.Context, data *domain.WishlistItem) error {
+	now := time.Now()
+	query := r.db.QueryBuilder.Insert("wishlist_items").
+		Columns("wishlist_id", "product_id", "price", "created_at").
+		Values(data.WishlistID, data.ProductID, data.Price, now).
+		Suffix("ON CONFLICT (wishlist_id, product_id) DO UPDATE SET wishlist_id = EXCLUDED.wishlist_id RETURNING id, price, created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRow(ctx, sql, args...).Scan(&data.ID, &data.Price, &data.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return r.touch(ctx, data.WishlistID)
+}
+
+func (r *WishlistRepository) RemoveItem(ctx context.Context, wishlistID, productID int) error {
+	query := r.db.QueryBuilder.Delete("wishlist_items").
+		Where(sq.Eq{"wishlist_id": wishlistID, "product_id": productID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return r.touch(ctx, wishlistID)
+}
+
+func (r *WishlistRepository) touch(ctx context.Context, id int) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	return err
+}
+
+// FindUserIDsByProductID retrieves the users who saved the product in any of
+// their lists
+func (r *WishlistRepository) FindUserIDsByProductID(ctx context.Context, productID int) ([]int, error) {
+	query := r.db.QueryBuilder.Select("DISTINCT w.user_id").
+		From("wishlist_items i").
+		Join(r.TableName + " w ON w.id = i.wishlist_id").
+		Where(sq.Eq{"i.product_id": productID}).
+		OrderBy("w.user_id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
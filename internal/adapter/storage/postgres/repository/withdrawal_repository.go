@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/jackc/pgx/v5"
+)
+
+type WithdrawalRepository struct {
+	db        *postgres.DB
+	TableName string
+	ledger    *BalanceRepository
+}
+
+func NewWithdrawalRepository(db *postgres.DB) *WithdrawalRepository {
+	return &WithdrawalRepository{
+		db:        db,
+		TableName: "withdrawals",
+		ledger:    NewBalanceRepository(db),
+	}
+}
+
+var withdrawalColumns = []string{
+	"id",
+	"user_id",
+	"COALESCE(destination_id, 0)",
+	"bank_name",
+	"account_number",
+	"account_name",
+	"amount",
+	"status",
+	"COALESCE(provider_reference, '')",
+	"COALESCE(rejection_reason, '')",
+	"COALESCE(reviewed_by, 0)",
+	"created_at",
+	"updated_at",
+}
+
+// Store creates the withdrawal request and moves the amount from the wallet
+// into the pending withdrawals account in the same transaction
+func (r *WithdrawalRepository) Store(ctx context.Context, data *domain.Withdrawal) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	query := sq.Insert(r.TableName).
+		Columns("user_id", "destination_id", "bank_name", "account_number", "account_name", "amount", "status", "created_at", "updated_at").
+		Values(data.UserID, nullUint64(data.DestinationID), data.BankName, data.AccountNumber, data.AccountName, data.Amount, domain.WithdrawalRequested, now, now).
+		Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&data.ID); err != nil {
+		return err
+	}
+
+	err = r.ledger.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionWithdrawal,
+		ReferenceID: data.ID,
+		Description: "Withdrawal requested",
+		Lines: []domain.JournalLine{
+			{UserID: data.UserID, Amount: -data.Amount},
+			{AccountCode: domain.AccountWithdrawalsPending, Amount: data.Amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	data.Status = domain.WithdrawalRequested
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// Finds retrieves the withdrawals matching the filter, newest first
+func (r *WithdrawalRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.Withdrawal, error) {
+	query := r.db.QueryBuilder.Select(withdrawalColumns...).
+		From(r.TableName).
+		OrderBy("id DESC")
+
+	for key, value := range filter {
+		query = query.Where(sq.Eq{key: value})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdrawals []domain.Withdrawal
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, *withdrawal)
+	}
+
+	return withdrawals, rows.Err()
+}
+
+// FindOne retrieves a single withdrawal by ID
+func (r *WithdrawalRepository) FindOne(ctx context.Context, id uint64) (*domain.Withdrawal, error) {
+	query := r.db.QueryBuilder.Select(withdrawalColumns...).
+		From(r.TableName).
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal, err := scanWithdrawal(r.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// Approve moves a requested withdrawal to approved, the funds stay held
+func (r *WithdrawalRepository) Approve(ctx context.Context, id uint64, adminID uint64) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("status", domain.WithdrawalApproved).
+		Set("reviewed_by", adminID).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": domain.WithdrawalRequested})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrInvalidWithdrawalStatus
+	}
+
+	return nil
+}
+
+// ClaimPayout moves an approved withdrawal to processing so that only one
+// approval sends its payout. A withdrawal still processing since before
+// staleBefore can be claimed again, its payout was interrupted
+func (r *WithdrawalRepository) ClaimPayout(ctx context.Context, id uint64, staleBefore time.Time) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("status", domain.WithdrawalProcessing).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Or{
+			sq.Eq{"status": domain.WithdrawalApproved},
+			sq.And{sq.Eq{"status": domain.WithdrawalProcessing}, sq.Lt{"updated_at": staleBefore}},
+		})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return consts.ErrInvalidWithdrawalStatus
+	}
+
+	return nil
+}
+
+// ReleasePayout returns a processing withdrawal to approved after its payout
+// failed, so it can be approved again
+func (r *WithdrawalRepository) ReleasePayout(ctx context.Context, id uint64) error {
+	query := r.db.QueryBuilder.Update(r.TableName).
+		Set("status", domain.WithdrawalApproved).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": domain.WithdrawalProcessing})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, sql, args...)
+	return err
+}
+
+// MarkPaid settles a processing withdrawal, the held funds leave the platform
+// through payment clearing
+func (r *WithdrawalRepository) MarkPaid(ctx context.Context, id uint64, providerReference string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := sq.Update(r.TableName).
+		Set("status", domain.WithdrawalPaid).
+		Set("provider_reference", providerReference).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": domain.WithdrawalProcessing}).
+		Suffix("RETURNING amount").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	var amount float64
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&amount); err != nil {
+		if err == pgx.ErrNoRows {
+			return consts.ErrInvalidWithdrawalStatus
+		}
+		return err
+	}
+
+	err = r.ledger.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionWithdrawal,
+		ReferenceID: id,
+		Description: "Withdrawal paid out",
+		Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountWithdrawalsPending, Amount: -amount},
+			{AccountCode: domain.AccountPaymentClearing, Amount: amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Reject closes a withdrawal that has not been paid out and releases the held
+// funds back to the wallet. An approved withdrawal whose payout failed can be
+// rejected, one being paid out can't
+func (r *WithdrawalRepository) Reject(ctx context.Context, id uint64, adminID uint64, reason string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := sq.Update(r.TableName).
+		Set("status", domain.WithdrawalRejected).
+		Set("rejection_reason", reason).
+		Set("reviewed_by", adminID).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": []domain.WithdrawalStatus{domain.WithdrawalRequested, domain.WithdrawalApproved}}).
+		Suffix("RETURNING user_id, amount").PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	var (
+		userID uint64
+		amount float64
+	)
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&userID, &amount); err != nil {
+		if err == pgx.ErrNoRows {
+			return consts.ErrInvalidWithdrawalStatus
+		}
+		return err
+	}
+
+	err = r.ledger.post(ctx, tx, &domain.JournalEntry{
+		EntryType:   domain.TransactionWithdrawalReversal,
+		ReferenceID: id,
+		Description: "Withdrawal rejected",
+		Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountWithdrawalsPending, Amount: -amount},
+			{UserID: userID, Amount: amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanWithdrawal(row pgx.Row) (*domain.Withdrawal, error) {
+	var withdrawal domain.Withdrawal
+	err := row.Scan(
+		&withdrawal.ID,
+		&withdrawal.UserID,
+		&withdrawal.DestinationID,
+		&withdrawal.BankName,
+		&withdrawal.AccountNumber,
+		&withdrawal.AccountName,
+		&withdrawal.Amount,
+		&withdrawal.Status,
+		&withdrawal.ProviderReference,
+		&withdrawal.RejectionReason,
+		&withdrawal.ReviewedBy,
+		&withdrawal.CreatedAt,
+		&withdrawal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &withdrawal, nil
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/postgres/repository"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/service"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// failingProvider refuses every payout
+type failingProvider struct{}
+
+func (failingProvider) Payout(ctx context.Context, withdrawal domain.Withdrawal, idempotencyKey string) (string, error) {
+	return "", errors.New("bank unavailable")
+}
+
+func TestWithdrawalRejectAfterFailedPayout(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	repo := repository.NewWithdrawalRepository(db)
+	balances := repository.NewBalanceRepository(db)
+	svc := service.NewWithdrawalService(repo, repository.NewPayoutDestinationRepository(db), failingProvider{}, nil, &config.Business{})
+
+	newWithdrawal := func(t *testing.T) *domain.Withdrawal {
+		userID := newTestWallets(t, db, 1, 100)[0]
+		withdrawal := &domain.Withdrawal{
+			UserID:        userID,
+			BankName:      "Test Bank",
+			AccountNumber: "1234567890",
+			AccountName:   "Test",
+			Amount:        40,
+		}
+		if err := repo.Store(ctx, withdrawal); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+		return withdrawal
+	}
+
+	checkBalance := func(t *testing.T, userID uint64, want float64) {
+		t.Helper()
+		balance, err := balances.GetBalance(ctx, userID)
+		if err != nil || balance != want {
+			t.Fatalf("GetBalance = %v, %v, want %v", balance, err, want)
+		}
+	}
+
+	t.Run("approved after a failed payout", func(t *testing.T) {
+		withdrawal := newWithdrawal(t)
+		checkBalance(t, withdrawal.UserID, 60)
+
+		if _, err := svc.Approve(ctx, 1, withdrawal.ID); !errors.Is(err, consts.ErrPayoutFailed) {
+			t.Fatalf("Approve = %v, want ErrPayoutFailed", err)
+		}
+		found, err := repo.FindOne(ctx, withdrawal.ID)
+		if err != nil || found.Status != domain.WithdrawalApproved {
+			t.Fatalf("status after failed payout = %+v, %v, want approved", found, err)
+		}
+
+		response, err := svc.Reject(ctx, 1, withdrawal.ID, dto.RejectWithdrawalRequest{Reason: "bank account closed"})
+		if err != nil {
+			t.Fatalf("Reject: %v", err)
+		}
+		if response.Status != domain.WithdrawalRejected {
+			t.Fatalf("status = %s, want rejected", response.Status)
+		}
+		checkBalance(t, withdrawal.UserID, 100)
+
+		if _, err := svc.Reject(ctx, 1, withdrawal.ID, dto.RejectWithdrawalRequest{Reason: "again"}); !errors.Is(err, consts.ErrInvalidWithdrawalStatus) {
+			t.Fatalf("second Reject = %v, want ErrInvalidWithdrawalStatus", err)
+		}
+		checkBalance(t, withdrawal.UserID, 100)
+	})
+
+	t.Run("processing", func(t *testing.T) {
+		withdrawal := newWithdrawal(t)
+
+		if err := repo.Approve(ctx, withdrawal.ID, 1); err != nil {
+			t.Fatalf("Approve: %v", err)
+		}
+		if err := repo.ClaimPayout(ctx, withdrawal.ID, withdrawal.CreatedAt); err != nil {
+			t.Fatalf("ClaimPayout: %v", err)
+		}
+
+		if err := repo.Reject(ctx, withdrawal.ID, 1, "too late"); !errors.Is(err, consts.ErrInvalidWithdrawalStatus) {
+			t.Fatalf("Reject = %v, want ErrInvalidWithdrawalStatus", err)
+		}
+		checkBalance(t, withdrawal.UserID, 60)
+	})
+}
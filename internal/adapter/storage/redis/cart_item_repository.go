@@ -0,0 +1,402 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/redis/go-redis/v9"
+)
+
+// CartItemRepository keeps cart items in Redis hashes that expire with their
+// cart, see NewCartRepositories
+type CartItemRepository struct {
+	*cartStore
+	products port.ProductRepository
+}
+
+func (r *CartItemRepository) FindOne(ctx context.Context, id int) (*domain.CartItem, error) {
+	items, err := r.loadItems(ctx, []string{strconv.Itoa(id)})
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	return &items[0], nil
+}
+
+func (r *CartItemRepository) FindOneByFilters(ctx context.Context, filter map[string]interface{}) (*domain.CartItem, error) {
+	items, err := r.Finds(ctx, filter)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	return &items[0], nil
+}
+
+// Finds retrieves the cart items matching every filter, which can be on
+// id, cart_id, product_id and variant_id. The filter needs one of the first
+// three
+func (r *CartItemRepository) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.CartItem, error) {
+	values := make(map[string]int, len(filter))
+	for key, value := range filter {
+		switch key {
+		case "id", "cart_id", "product_id", "variant_id":
+		default:
+			return nil, fmt.Errorf("cart items cannot be filtered by %q", key)
+		}
+
+		id, ok := value.(int)
+		if !ok {
+			return nil, fmt.Errorf("cart item filter %q must be an int, got %T", key, value)
+		}
+		values[key] = id
+	}
+
+	var (
+		ids []string
+		err error
+		// the product whose index the IDs were read from
+		indexed int
+	)
+	if id, ok := values["id"]; ok {
+		ids = []string{strconv.Itoa(id)}
+	} else if cartID, ok := values["cart_id"]; ok {
+		ids, err = r.itemIDs(ctx, cartID)
+	} else if productID, ok := values["product_id"]; ok {
+		ids, err = r.client.SMembers(ctx, productCartItemsKey(productID)).Result()
+		indexed = productID
+	} else {
+		return nil, fmt.Errorf("cart items need a filter on id, cart_id or product_id")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.loadItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if indexed > 0 && len(items) < len(ids) {
+		if err := r.dropExpiredItems(ctx, indexed, ids, items); err != nil {
+			return nil, err
+		}
+	}
+
+	var cartItems []domain.CartItem
+	for _, item := range items {
+		if matchesCartItem(item, values) {
+			cartItems = append(cartItems, item)
+		}
+	}
+
+	return cartItems, nil
+}
+
+// FindsWithProducts retrieves the items of a cart together with their current
+// products, the product is nil when it has been deleted
+func (r *CartItemRepository) FindsWithProducts(ctx context.Context, cartID int) ([]domain.CartItemWithProduct, error) {
+	items, err := r.Finds(ctx, map[string]interface{}{"cart_id": cartID})
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	found, err := r.products.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int]*domain.Product, len(found))
+	for i := range found {
+		products[found[i].ID] = &found[i]
+	}
+
+	result := make([]domain.CartItemWithProduct, 0, len(items))
+	for _, item := range items {
+		result = append(result, domain.CartItemWithProduct{CartItem: item, Product: products[item.ProductID]})
+	}
+
+	return result, nil
+}
+
+// Store adds a line to an existing cart, a cart holds one line per product
+// and variant
+func (r *CartItemRepository) Store(ctx context.Context, data *domain.CartItem) error {
+	cart, err := r.loadCart(ctx, data.CartID)
+	if err != nil {
+		return err
+	}
+
+	if cart == nil {
+		return consts.ErrDataNotFound
+	}
+
+	id, err := r.client.Incr(ctx, cartItemSeqKey).Result()
+	if err != nil {
+		return err
+	}
+
+	item := *data
+	item.ID = int(id)
+	line := item.Line().Key()
+
+	// the line is claimed in the same MULTI block as the item is written, the
+	// WATCH on the cart's lines aborts it when another change got in between
+	claim := func(tx *redis.Tx) error {
+		taken, err := tx.HExists(ctx, cartItemsKey(cart.ID), line).Result()
+		if err != nil {
+			return err
+		}
+
+		if taken {
+			return consts.ErrConflictingData
+		}
+
+		itemIDs, err := tx.HVals(ctx, cartItemsKey(cart.ID)).Result()
+		if err != nil {
+			return err
+		}
+
+		stale, err := r.staleProductItems(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, cartItemsKey(cart.ID), line, item.ID)
+			pipe.HSet(ctx, cartItemKey(item.ID), cartItemFields(&item))
+			pipe.SAdd(ctx, productCartItemsKey(item.ProductID), item.ID)
+			if len(stale) > 0 {
+				pipe.SRem(ctx, productCartItemsKey(item.ProductID), stale...)
+			}
+			r.expireCart(ctx, pipe, cart, append(itemIDs, strconv.Itoa(item.ID)))
+			r.markDirty(ctx, pipe, cart.ID)
+			return nil
+		})
+
+		return err
+	}
+
+	for i := 0; ; i++ {
+		err = r.client.Watch(ctx, claim, cartItemsKey(cart.ID))
+		if err != redis.TxFailedErr || i == maxCartWatchRetries {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	data.ID = item.ID
+
+	return nil
+}
+
+// Update changes the quantity and price of a line
+func (r *CartItemRepository) Update(ctx context.Context, id int, updatedData domain.CartItem) error {
+	item, err := r.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if item == nil {
+		return consts.ErrDataNotFound
+	}
+
+	item.Quantity = updatedData.Quantity
+	item.Price = updatedData.Price
+	item.ProductName = updatedData.ProductName
+	item.UpdatedAt = time.Now()
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, cartItemKey(id), cartItemFields(item))
+		r.markDirty(ctx, pipe, item.CartID)
+		return nil
+	})
+
+	return err
+}
+
+func (r *CartItemRepository) DeleteByCartID(ctx context.Context, cartID int) error {
+	itemIDs, err := r.itemIDs(ctx, cartID)
+	if err != nil {
+		return err
+	}
+
+	items, err := r.loadItems(ctx, itemIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		keys := []string{cartItemsKey(cartID)}
+		for _, item := range items {
+			keys = append(keys, cartItemKey(item.ID))
+			pipe.SRem(ctx, productCartItemsKey(item.ProductID), item.ID)
+		}
+		pipe.Del(ctx, keys...)
+		r.markDirty(ctx, pipe, cartID)
+		return nil
+	})
+
+	return err
+}
+
+// DeleteByProductID takes the product out of every cart
+func (r *CartItemRepository) DeleteByProductID(ctx context.Context, product_id int) error {
+	itemIDs, err := r.client.SMembers(ctx, productCartItemsKey(product_id)).Result()
+	if err != nil {
+		return err
+	}
+
+	items, err := r.loadItems(ctx, itemIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		keys := []string{productCartItemsKey(product_id)}
+		for _, item := range items {
+			keys = append(keys, cartItemKey(item.ID))
+			pipe.HDel(ctx, cartItemsKey(item.CartID), item.Line().Key())
+			r.markDirty(ctx, pipe, item.CartID)
+		}
+		pipe.Del(ctx, keys...)
+		return nil
+	})
+
+	return err
+}
+
+func (r *CartItemRepository) Delete(ctx context.Context, id int) error {
+	item, err := r.FindOne(ctx, id)
+	if err != nil || item == nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, cartItemKey(id))
+		pipe.HDel(ctx, cartItemsKey(item.CartID), item.Line().Key())
+		pipe.SRem(ctx, productCartItemsKey(item.ProductID), id)
+		r.markDirty(ctx, pipe, item.CartID)
+		return nil
+	})
+
+	return err
+}
+
+// ApplyChanges removes and upserts lines of a cart in one MULTI block. An
+// upsert with an ID overwrites that line, one without is added and gets its ID
+func (r *CartItemRepository) ApplyChanges(ctx context.Context, cartID int, upserts []domain.CartItem, removals []domain.CartLine) error {
+	cart, err := r.loadCart(ctx, cartID)
+	if err != nil {
+		return err
+	}
+
+	if cart == nil {
+		return consts.ErrDataNotFound
+	}
+
+	// the item IDs of the removed lines, keyed by line
+	removed := make(map[domain.CartLine]string, len(removals))
+	if len(removals) > 0 {
+		fields := make([]string, 0, len(removals))
+		for _, line := range removals {
+			fields = append(fields, line.Key())
+		}
+
+		ids, err := r.client.HMGet(ctx, cartItemsKey(cartID), fields...).Result()
+		if err != nil {
+			return err
+		}
+
+		for i, id := range ids {
+			if id, ok := id.(string); ok {
+				removed[removals[i]] = id
+			}
+		}
+	}
+
+	items := make([]domain.CartItem, len(upserts))
+	copy(items, upserts)
+	for i := range items {
+		items[i].CartID = cartID
+		if items[i].ID > 0 {
+			continue
+		}
+
+		id, err := r.client.Incr(ctx, cartItemSeqKey).Result()
+		if err != nil {
+			return err
+		}
+		items[i].ID = int(id)
+	}
+
+	itemIDs, err := r.itemIDs(ctx, cartID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for line, id := range removed {
+			pipe.Del(ctx, "cart_item:"+id)
+			pipe.HDel(ctx, cartItemsKey(cartID), line.Key())
+			pipe.SRem(ctx, productCartItemsKey(line.ProductID), id)
+		}
+
+		for i := range items {
+			item := &items[i]
+			pipe.HSet(ctx, cartItemKey(item.ID), cartItemFields(item))
+			pipe.HSet(ctx, cartItemsKey(cartID), item.Line().Key(), item.ID)
+			pipe.SAdd(ctx, productCartItemsKey(item.ProductID), item.ID)
+			itemIDs = append(itemIDs, strconv.Itoa(item.ID))
+		}
+
+		r.expireCart(ctx, pipe, cart, itemIDs)
+		r.markDirty(ctx, pipe, cartID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		upserts[i].ID = items[i].ID
+	}
+
+	return nil
+}
+
+func matchesCartItem(item domain.CartItem, filter map[string]int) bool {
+	for key, value := range filter {
+		switch key {
+		case "id":
+			if item.ID != value {
+				return false
+			}
+		case "cart_id":
+			if item.CartID != value {
+				return false
+			}
+		case "product_id":
+			if item.ProductID != value {
+				return false
+			}
+		case "variant_id":
+			if item.VariantID != value {
+				return false
+			}
+		}
+	}
+
+	return true
+}
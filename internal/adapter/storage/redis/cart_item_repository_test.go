@@ -0,0 +1,129 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/redis"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/storagetest"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestCartItemRepository(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+
+	ctx := context.Background()
+	products := storagetest.NewProductStore()
+	carts, cartItems, err := redis.NewCartRepositories(ctx, &config.Redis{
+		Addr:     addr,
+		Password: os.Getenv("TEST_REDIS_PASSWORD"),
+	}, products, redis.CartOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("connecting to Redis: %v", err)
+	}
+
+	client := goredis.NewClient(&goredis.Options{Addr: addr, Password: os.Getenv("TEST_REDIS_PASSWORD")})
+	defer client.Close()
+
+	newCart := func(t *testing.T, expiresAt *time.Time) *domain.Cart {
+		t.Helper()
+		cart := &domain.Cart{UserID: newID()}
+		if expiresAt != nil {
+			cart = &domain.Cart{GuestID: fmt.Sprintf("guest-%d", newID()), ExpiresAt: expiresAt}
+		}
+		if err := carts.Store(ctx, cart); err != nil {
+			t.Fatalf("storing cart: %v", err)
+		}
+		return cart
+	}
+
+	t.Run("concurrent adds of a line", func(t *testing.T) {
+		cart := newCart(t, nil)
+		productID := newID()
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- cartItems.Store(ctx, &domain.CartItem{CartID: cart.ID, ProductID: productID, Quantity: 1, Price: 10})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		stored := 0
+		for err := range errs {
+			switch {
+			case err == nil:
+				stored++
+			case !errors.Is(err, consts.ErrConflictingData):
+				t.Fatalf("Store: %v", err)
+			}
+		}
+		if stored != 1 {
+			t.Fatalf("%d concurrent adds of a line succeeded, want 1", stored)
+		}
+
+		items, err := cartItems.Finds(ctx, map[string]interface{}{"cart_id": cart.ID})
+		if err != nil || len(items) != 1 {
+			t.Fatalf("Finds = %v, %v, want the one line", items, err)
+		}
+
+		// the adds that lost must not leave items behind in the product index
+		if n := client.SCard(ctx, fmt.Sprintf("cart_item:product:%d", productID)).Val(); n != 1 {
+			t.Fatalf("product index holds %d items, want 1", n)
+		}
+	})
+
+	t.Run("expired items leave the product index", func(t *testing.T) {
+		productID := newID()
+		index := fmt.Sprintf("cart_item:product:%d", productID)
+
+		expiresAt := time.Now().Add(100 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			cart := newCart(t, &expiresAt)
+			if err := cartItems.Store(ctx, &domain.CartItem{CartID: cart.ID, ProductID: productID, Quantity: 1, Price: 10}); err != nil {
+				t.Fatalf("Store: %v", err)
+			}
+		}
+
+		time.Sleep(250 * time.Millisecond)
+
+		cart := newCart(t, nil)
+		if err := cartItems.Store(ctx, &domain.CartItem{CartID: cart.ID, ProductID: productID, Quantity: 1, Price: 10}); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+		if n := client.SCard(ctx, index).Val(); n != 1 {
+			t.Fatalf("product index holds %d items after an add, want 1", n)
+		}
+
+		expiresSoon := time.Now().Add(100 * time.Millisecond)
+		expired := newCart(t, &expiresSoon)
+		if err := cartItems.Store(ctx, &domain.CartItem{CartID: expired.ID, ProductID: productID, Quantity: 1, Price: 10}); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+
+		time.Sleep(250 * time.Millisecond)
+
+		items, err := cartItems.Finds(ctx, map[string]interface{}{"product_id": productID})
+		if err != nil || len(items) != 1 || items[0].CartID != cart.ID {
+			t.Fatalf("Finds = %v, %v, want the line of cart %d", items, err, cart.ID)
+		}
+		if n := client.SCard(ctx, index).Val(); n != 1 {
+			t.Fatalf("product index holds %d items after Finds, want 1", n)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/redis/go-redis/v9"
+)
+
+// CartRepository keeps carts in Redis hashes that expire on their own, see
+// NewCartRepositories
+type CartRepository struct {
+	*cartStore
+}
+
+func (r *CartRepository) FindOne(ctx context.Context, id int) (*domain.Cart, error) {
+	return r.loadCart(ctx, id)
+}
+
+func (r *CartRepository) FindByUserID(ctx context.Context, userID int) (*domain.Cart, error) {
+	return r.loadCartByKey(ctx, cartUserKey(userID))
+}
+
+// FindByGuestID retrieves a guest cart that has not expired yet
+func (r *CartRepository) FindByGuestID(ctx context.Context, guestID string) (*domain.Cart, error) {
+	cart, err := r.loadCartByKey(ctx, cartGuestKey(guestID))
+	if err != nil || cart == nil {
+		return nil, err
+	}
+
+	if cart.ExpiresAt == nil || !cart.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+
+	return cart, nil
+}
+
+// Store creates a cart for a user or a guest, an owner can only have one
+func (r *CartRepository) Store(ctx context.Context, data *domain.Cart) error {
+	id, err := r.client.Incr(ctx, cartSeqKey).Result()
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.client.SetNX(ctx, r.ownerKey(data), id, 0).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		// the owner key may outlive a cart whose hash was lost, only a live
+		// cart blocks a new one
+		existing, err := r.loadCartByKey(ctx, r.ownerKey(data))
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			return consts.ErrConflictingData
+		}
+
+		if err := r.client.Set(ctx, r.ownerKey(data), id, 0).Err(); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	cart := *data
+	cart.ID = int(id)
+	cart.CreatedAt = now
+	cart.UpdatedAt = now
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, cartKey(cart.ID), cartFields(&cart))
+		r.expireCart(ctx, pipe, &cart, nil)
+		r.markDirty(ctx, pipe, cart.ID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data.ID = cart.ID
+	data.CreatedAt = now
+	data.UpdatedAt = now
+
+	return nil
+}
+
+// Update touches the cart, a guest cart also gets its new expiry. Every key of
+// the cart gets the new expiry, a user cart lives for another CartOptions.TTL
+func (r *CartRepository) Update(ctx context.Context, id int, updatedData domain.Cart) error {
+	cart, err := r.loadCart(ctx, id)
+	if err != nil || cart == nil {
+		return err
+	}
+
+	cart.UpdatedAt = time.Now()
+	if updatedData.ExpiresAt != nil {
+		cart.ExpiresAt = updatedData.ExpiresAt
+	}
+
+	itemIDs, err := r.itemIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, cartKey(id), cartFields(cart))
+		r.expireCart(ctx, pipe, cart, itemIDs)
+		r.markDirty(ctx, pipe, id)
+		return nil
+	})
+
+	return err
+}
+
+// Delete removes the cart together with its items
+func (r *CartRepository) Delete(ctx context.Context, id int) error {
+	cart, err := r.loadCart(ctx, id)
+	if err != nil || cart == nil {
+		return err
+	}
+
+	itemIDs, err := r.itemIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	items, err := r.loadItems(ctx, itemIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		keys := []string{cartKey(id), r.ownerKey(cart), cartItemsKey(id)}
+		for _, item := range items {
+			keys = append(keys, cartItemKey(item.ID))
+			pipe.SRem(ctx, productCartItemsKey(item.ProductID), item.ID)
+		}
+		pipe.Del(ctx, keys...)
+		r.markDirty(ctx, pipe, id)
+		return nil
+	})
+
+	return err
+}
+
+func (r *CartRepository) DeleteByUserID(ctx context.Context, userID int) error {
+	cart, err := r.FindByUserID(ctx, userID)
+	if err != nil || cart == nil {
+		return err
+	}
+
+	return r.Delete(ctx, cart.ID)
+}
+
+// DeleteExpiredGuests has nothing to do, Redis drops guest carts by itself
+// once they pass their expiry
+func (r *CartRepository) DeleteExpiredGuests(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,67 @@
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/redis"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/storagetest"
+)
+
+// fixtures take fresh user and product IDs so runs against the same Redis do
+// not share carts
+var nextID = time.Now().UnixNano() % 1_000_000_000
+
+func newID() int {
+	return int(atomic.AddInt64(&nextID, 1))
+}
+
+func TestCartRepositoryContract(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+
+	storagetest.CartRepositoryContract(t, func(t *testing.T) storagetest.CartFixture {
+		products := storagetest.NewProductStore()
+		variants := storagetest.NewVariantStore()
+		carts, cartItems, err := redis.NewCartRepositories(context.Background(), &config.Redis{
+			Addr:     addr,
+			Password: os.Getenv("TEST_REDIS_PASSWORD"),
+		}, products, redis.CartOptions{TTL: time.Hour, WriteBehind: true})
+		if err != nil {
+			t.Fatalf("connecting to Redis: %v", err)
+		}
+
+		fixture := storagetest.CartFixture{
+			Carts:     carts,
+			CartItems: cartItems,
+			Products:  products,
+			Variants:  variants,
+			UserIDs:   []int{newID(), newID()},
+		}
+
+		for i := 0; i < 3; i++ {
+			id := newID()
+			products.Add(id, fmt.Sprintf("product %d", id))
+			fixture.ProductIDs = append(fixture.ProductIDs, id)
+		}
+
+		fixture.VariantProductID = newID()
+		products.Add(fixture.VariantProductID, fmt.Sprintf("product %d", fixture.VariantProductID))
+
+		override := float64(storagetest.VariantPrice)
+		for _, price := range []*float64{&override, nil} {
+			id := newID()
+			variants.Add(id, fixture.VariantProductID, fmt.Sprintf("SKU-%d", id), price)
+			fixture.VariantIDs = append(fixture.VariantIDs, id)
+		}
+
+		return fixture
+	})
+}
@@ -0,0 +1,357 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/redis/go-redis/v9"
+)
+
+// a cart is the hash cart:{id} found through cart:user:{user id} or
+// cart:guest:{guest id}. Its lines are the hashes cart_item:{id}, listed in
+// cart:{id}:items by line key (see domain.CartLine) and in
+// cart_item:product:{product id} so a deleted product can be taken out of
+// every cart
+const (
+	cartSeqKey     = "cart:seq"
+	cartItemSeqKey = "cart_item:seq"
+	cartDirtyKey   = "cart:dirty"
+)
+
+const (
+	// maxCartWatchRetries is how often a change is retried when its WATCH was
+	// broken by another change of the same cart
+	maxCartWatchRetries = 10
+	// productIndexSample is how many IDs of a product index are checked for
+	// expired items each time a line of the product is added
+	productIndexSample = 16
+)
+
+func cartKey(id int) string {
+	return fmt.Sprintf("cart:%d", id)
+}
+
+func cartUserKey(userID int) string {
+	return fmt.Sprintf("cart:user:%d", userID)
+}
+
+func cartGuestKey(guestID string) string {
+	return "cart:guest:" + guestID
+}
+
+func cartItemsKey(cartID int) string {
+	return fmt.Sprintf("cart:%d:items", cartID)
+}
+
+func cartItemKey(id int) string {
+	return fmt.Sprintf("cart_item:%d", id)
+}
+
+func productCartItemsKey(productID int) string {
+	return fmt.Sprintf("cart_item:product:%d", productID)
+}
+
+// CartOptions configures the Redis cart repositories
+type CartOptions struct {
+	// TTL is how long a user cart lives after its last change, 0 keeps it.
+	// Guest carts expire at their own ExpiresAt
+	TTL time.Duration
+	// WriteBehind queues every changed cart for CartWriteBehind
+	WriteBehind bool
+}
+
+type cartStore struct {
+	client *redis.Client
+	opts   CartOptions
+}
+
+// NewCartRepositories creates the Redis backed cart and cart item
+// repositories. Products stay in Postgres, products is used to join the cart
+// items with their current product
+func NewCartRepositories(ctx context.Context, config *config.Redis, products port.ProductRepository, opts CartOptions) (*CartRepository, *CartItemRepository, error) {
+	client, err := newClient(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := &cartStore{client: client, opts: opts}
+
+	return &CartRepository{store}, &CartItemRepository{cartStore: store, products: products}, nil
+}
+
+func (s *cartStore) ownerKey(cart *domain.Cart) string {
+	if cart.IsGuest() {
+		return cartGuestKey(cart.GuestID)
+	}
+
+	return cartUserKey(cart.UserID)
+}
+
+// expiresAt is when the keys of the cart expire, zero when they are kept
+func (s *cartStore) expiresAt(cart *domain.Cart) time.Time {
+	if cart.ExpiresAt != nil {
+		return *cart.ExpiresAt
+	}
+
+	if s.opts.TTL > 0 {
+		return time.Now().Add(s.opts.TTL)
+	}
+
+	return time.Time{}
+}
+
+// expireCart gives every key of the cart the cart's expiry
+func (s *cartStore) expireCart(ctx context.Context, pipe redis.Pipeliner, cart *domain.Cart, itemIDs []string) {
+	keys := []string{cartKey(cart.ID), s.ownerKey(cart), cartItemsKey(cart.ID)}
+	for _, id := range itemIDs {
+		keys = append(keys, "cart_item:"+id)
+	}
+
+	at := s.expiresAt(cart)
+	for _, key := range keys {
+		if at.IsZero() {
+			pipe.Persist(ctx, key)
+		} else {
+			pipe.PExpireAt(ctx, key, at)
+		}
+	}
+}
+
+func (s *cartStore) markDirty(ctx context.Context, pipe redis.Pipeliner, cartIDs ...int) {
+	if !s.opts.WriteBehind {
+		return
+	}
+
+	for _, id := range cartIDs {
+		pipe.SAdd(ctx, cartDirtyKey, id)
+	}
+}
+
+func (s *cartStore) loadCart(ctx context.Context, id int) (*domain.Cart, error) {
+	values, err := s.client.HGetAll(ctx, cartKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return parseCart(values)
+}
+
+// loadCartByKey follows an owner key to its cart, nil when either has expired
+func (s *cartStore) loadCartByKey(ctx context.Context, key string) (*domain.Cart, error) {
+	id, err := s.client.Get(ctx, key).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return s.loadCart(ctx, id)
+}
+
+func (s *cartStore) itemIDs(ctx context.Context, cartID int) ([]string, error) {
+	return s.client.HVals(ctx, cartItemsKey(cartID)).Result()
+}
+
+// loadItems reads the given cart items ordered by ID, skipping the ones that
+// have expired
+func (s *cartStore) loadItems(ctx context.Context, ids []string) ([]domain.CartItem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipe.HGetAll(ctx, "cart_item:"+id))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var items []domain.CartItem
+	for _, cmd := range cmds {
+		values := cmd.Val()
+		if len(values) == 0 {
+			continue
+		}
+
+		item, err := parseCartItem(values)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	return items, nil
+}
+
+// staleProductItems samples the index of a product for the IDs of items that
+// expired with their cart. The index has no expiry of its own, it is kept in
+// check by dropping these whenever a line of the product is added
+func (s *cartStore) staleProductItems(ctx context.Context, productID int) ([]interface{}, error) {
+	ids, err := s.client.SRandMemberN(ctx, productCartItemsKey(productID), productIndexSample).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(ids))
+	for _, id := range ids {
+		cmds = append(cmds, pipe.Exists(ctx, "cart_item:"+id))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var stale []interface{}
+	for i, cmd := range cmds {
+		if cmd.Val() == 0 {
+			stale = append(stale, ids[i])
+		}
+	}
+
+	return stale, nil
+}
+
+// dropExpiredItems removes from the index of a product the IDs that were read
+// from it but whose item has expired
+func (s *cartStore) dropExpiredItems(ctx context.Context, productID int, ids []string, items []domain.CartItem) error {
+	live := make(map[string]bool, len(items))
+	for _, item := range items {
+		live[strconv.Itoa(item.ID)] = true
+	}
+
+	var stale []interface{}
+	for _, id := range ids {
+		if !live[id] {
+			stale = append(stale, id)
+		}
+	}
+
+	return s.client.SRem(ctx, productCartItemsKey(productID), stale...).Err()
+}
+
+func cartFields(cart *domain.Cart) map[string]interface{} {
+	fields := map[string]interface{}{
+		"id":         cart.ID,
+		"user_id":    cart.UserID,
+		"guest_id":   cart.GuestID,
+		"expires_at": "",
+		"created_at": formatTime(cart.CreatedAt),
+		"updated_at": formatTime(cart.UpdatedAt),
+	}
+
+	if cart.ExpiresAt != nil {
+		fields["expires_at"] = formatTime(*cart.ExpiresAt)
+	}
+
+	return fields
+}
+
+func parseCart(values map[string]string) (*domain.Cart, error) {
+	var (
+		cart domain.Cart
+		err  error
+	)
+
+	if cart.ID, err = strconv.Atoi(values["id"]); err != nil {
+		return nil, err
+	}
+	if cart.UserID, err = strconv.Atoi(values["user_id"]); err != nil {
+		return nil, err
+	}
+	cart.GuestID = values["guest_id"]
+
+	if values["expires_at"] != "" {
+		expiresAt, err := parseTime(values["expires_at"])
+		if err != nil {
+			return nil, err
+		}
+		cart.ExpiresAt = &expiresAt
+	}
+
+	if cart.CreatedAt, err = parseTime(values["created_at"]); err != nil {
+		return nil, err
+	}
+	if cart.UpdatedAt, err = parseTime(values["updated_at"]); err != nil {
+		return nil, err
+	}
+
+	return &cart, nil
+}
+
+func cartItemFields(item *domain.CartItem) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           item.ID,
+		"cart_id":      item.CartID,
+		"product_id":   item.ProductID,
+		"variant_id":   item.VariantID,
+		"quantity":     item.Quantity,
+		"price":        strconv.FormatFloat(item.Price, 'f', -1, 64),
+		"product_name": item.ProductName,
+		"created_at":   formatTime(item.CreatedAt),
+		"updated_at":   formatTime(item.UpdatedAt),
+	}
+}
+
+func parseCartItem(values map[string]string) (*domain.CartItem, error) {
+	var (
+		item domain.CartItem
+		err  error
+	)
+
+	if item.ID, err = strconv.Atoi(values["id"]); err != nil {
+		return nil, err
+	}
+	if item.CartID, err = strconv.Atoi(values["cart_id"]); err != nil {
+		return nil, err
+	}
+	if item.ProductID, err = strconv.Atoi(values["product_id"]); err != nil {
+		return nil, err
+	}
+	// lines stored before variants have no variant_id
+	if value, ok := values["variant_id"]; ok {
+		if item.VariantID, err = strconv.Atoi(value); err != nil {
+			return nil, err
+		}
+	}
+	if item.Quantity, err = strconv.Atoi(values["quantity"]); err != nil {
+		return nil, err
+	}
+	if item.Price, err = strconv.ParseFloat(values["price"], 64); err != nil {
+		return nil, err
+	}
+	item.ProductName = values["product_name"]
+
+	if item.CreatedAt, err = parseTime(values["created_at"]); err != nil {
+		return nil, err
+	}
+	if item.UpdatedAt, err = parseTime(values["updated_at"]); err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+)
+
+// CartWriteBehind copies the carts changed in Redis to Postgres for
+// analytics. It drains the set of changed cart IDs the repositories fill when
+// CartOptions.WriteBehind is on. A cart that expires in Redis keeps its last
+// snapshot, which is how abandoned carts show up
+type CartWriteBehind struct {
+	store     *cartStore
+	snapshots port.CartSnapshotRepository
+}
+
+func NewCartWriteBehind(carts *CartRepository, snapshots port.CartSnapshotRepository) *CartWriteBehind {
+	return &CartWriteBehind{
+		store:     carts.cartStore,
+		snapshots: snapshots,
+	}
+}
+
+// Flush copies up to batch changed carts and returns how many were copied.
+// The carts left when a copy fails are queued again
+func (w *CartWriteBehind) Flush(ctx context.Context, batch int) (int, error) {
+	ids, err := w.store.client.SPopN(ctx, cartDirtyKey, int64(batch)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := w.flushCart(ctx, id); err != nil {
+			requeue := make([]interface{}, 0, len(ids)-i)
+			for _, id := range ids[i:] {
+				requeue = append(requeue, id)
+			}
+			w.store.client.SAdd(ctx, cartDirtyKey, requeue...)
+
+			return i, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (w *CartWriteBehind) flushCart(ctx context.Context, id string) error {
+	cartID, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+
+	cart, err := w.store.loadCart(ctx, cartID)
+	if err != nil {
+		return err
+	}
+
+	if cart == nil {
+		return w.snapshots.MarkDeleted(ctx, cartID, time.Now())
+	}
+
+	itemIDs, err := w.store.itemIDs(ctx, cartID)
+	if err != nil {
+		return err
+	}
+
+	items, err := w.store.loadItems(ctx, itemIDs)
+	if err != nil {
+		return err
+	}
+
+	return w.snapshots.Upsert(ctx, &domain.CartSnapshot{
+		CartID:    cart.ID,
+		UserID:    cart.UserID,
+		GuestID:   cart.GuestID,
+		Items:     items,
+		UpdatedAt: cart.UpdatedAt,
+	})
+}
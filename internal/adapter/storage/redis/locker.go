@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/locker"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/redis/go-redis/v9"
+)
+
+// the scripts only touch the key while it still holds the caller's token, so
+// a lock that expired and was obtained by someone else is left alone
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+	refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	ttlScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PTTL", KEYS[1])
+end
+return -3`)
+)
+
+type Locker struct {
+	client *redis.Client
+}
+
+// NewLocker creates a Redis backed distributed locker
+func NewLocker(ctx context.Context, config *config.Redis) (port.Locker, error) {
+	client, err := newClient(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Locker{client}, nil
+}
+
+// NewUncheckedLocker creates a Redis backed distributed locker without
+// checking Redis is up, for use behind a fallback that covers it until Redis
+// answers
+func NewUncheckedLocker(config *config.Redis) port.Locker {
+	return &Locker{newUncheckedClient(config)}
+}
+
+// Obtain sets the key to a random token if it is free, retrying with an
+// exponential backoff while it is held by someone else
+func (l *Locker) Obtain(ctx context.Context, key string, ttl time.Duration, opts port.LockOptions) (port.Lock, error) {
+	token, err := locker.NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = locker.Retry(ctx, opts, func() (bool, error) {
+		return l.client.SetNX(ctx, key, token, ttl).Result()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &redisLock{client: l.client, key: key, token: token}
+	if opts.AutoRenew {
+		lock.stopRenew = locker.Renew(ttl, func(ctx context.Context) error {
+			return lock.Refresh(ctx, ttl)
+		})
+	}
+
+	return lock, nil
+}
+
+type redisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+
+	mu        sync.Mutex
+	stopRenew func()
+}
+
+func (l *redisLock) Key() string {
+	return l.key
+}
+
+func (l *redisLock) Token() string {
+	return l.token
+}
+
+func (l *redisLock) TTL(ctx context.Context) (time.Duration, error) {
+	ms, err := ttlScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	// -3 is a token mismatch, -2 a missing key
+	if ms < 0 {
+		return 0, nil
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	ok, err := refreshScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+
+	if ok == 0 {
+		return consts.ErrLockNotHeld
+	}
+
+	return nil
+}
+
+func (l *redisLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	if l.stopRenew != nil {
+		l.stopRenew()
+		l.stopRenew = nil
+	}
+	l.mu.Unlock()
+
+	deleted, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return consts.ErrLockNotHeld
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package redis_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/redis"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/storage/storagetest"
+)
+
+func TestLockerContract(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+
+	locker, err := redis.NewLocker(context.Background(), &config.Redis{
+		Addr:     addr,
+		Password: os.Getenv("TEST_REDIS_PASSWORD"),
+	})
+	if err != nil {
+		t.Fatalf("connecting to Redis: %v", err)
+	}
+
+	storagetest.LockerContract(t, locker)
+}
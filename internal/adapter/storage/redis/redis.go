@@ -2,7 +2,6 @@ package redis
 
 import (
 	"context"
-	"errors"
 	"time"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
@@ -16,18 +15,39 @@ type Redis struct {
 
 // New creates a new instance of Redis
 func New(ctx context.Context, config *config.Redis) (port.CacheInterface, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     config.Addr,
-		Password: config.Password,
-		DB:       0,
-	})
+	client, err := newClient(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Redis{client}, nil
+}
+
+// NewUnchecked creates the cache without checking Redis is up, its calls fail
+// until Redis answers
+func NewUnchecked(config *config.Redis) port.CacheInterface {
+	return &Redis{newUncheckedClient(config)}
+}
+
+func newClient(ctx context.Context, config *config.Redis) (*redis.Client, error) {
+	client := newUncheckedClient(config)
 
 	_, err := client.Ping(ctx).Result()
 	if err != nil {
 		return nil, err
 	}
 
-	return &Redis{client}, nil
+	return client, nil
+}
+
+// newUncheckedClient creates the client without checking Redis is reachable,
+// the connection is made on first use
+func newUncheckedClient(config *config.Redis) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       0,
+	})
 }
 
 // Set stores the value in Redis
@@ -41,6 +61,28 @@ func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
 	return []byte(res), err
 }
 
+// GetDel retrieves the value and removes the key in one step, only one of
+// concurrent callers gets the value
+func (r *Redis) GetDel(ctx context.Context, key string) ([]byte, error) {
+	return r.client.GetDel(ctx, key).Bytes()
+}
+
+// Incr increments a counter and returns its new value, the counter expires
+// after ttl
+func (r *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
 // Delete removes a key from Redis
 func (r *Redis) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
@@ -77,24 +119,3 @@ func (r *Redis) DeleteByPrefix(ctx context.Context, prefix string) error {
 func (r *Redis) Close() error {
 	return r.client.Close()
 }
-
-// AcquireLock tries to acquire a lock using SETNX
-func (r *Redis) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
-	acquired, err := r.client.SetNX(ctx, key, "locked", ttl).Result()
-	if err != nil {
-		return false, err
-	}
-	return acquired, nil
-}
-
-// ReleaseLock deletes the lock key
-func (r *Redis) ReleaseLock(ctx context.Context, key string) error {
-	deleted, err := r.client.Del(ctx, key).Result()
-	if err != nil {
-		return err
-	}
-	if deleted == 0 {
-		return errors.New("lock not found or already released")
-	}
-	return nil
-}
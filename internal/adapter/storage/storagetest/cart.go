@@ -0,0 +1,449 @@
+// Package storagetest holds the contract suites every storage backend of a
+// repository port has to pass
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/internal/core/service"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// the products of a CartFixture all have this price and stock, their variants
+// this stock and the first variant this price
+const (
+	ProductPrice = 10000
+	ProductStock = 10
+	VariantPrice = 12000
+	VariantStock = 3
+)
+
+// CartFixture is a cart backend under test. UserIDs holds two users and
+// ProductIDs three products that exist for this fixture only. VariantIDs holds
+// two variants of the product VariantProductID, the first one priced at
+// VariantPrice and the second one at the product price
+type CartFixture struct {
+	Carts            port.CartRepository
+	CartItems        port.CartItemRepository
+	Products         port.ProductRepository
+	Variants         port.ProductVariantRepository
+	UserIDs          []int
+	ProductIDs       []int
+	VariantProductID int
+	VariantIDs       []int
+}
+
+// CartRepositoryContract runs CartService against the cart repositories of a
+// backend, newFixture is called for every subtest
+func CartRepositoryContract(t *testing.T, newFixture func(t *testing.T) CartFixture) {
+	rules := &config.Business{
+		MaxCartItems:       10,
+		MaxQuantityPerItem: 5,
+		GuestCartTTL:       time.Hour,
+	}
+
+	newService := func(f CartFixture, rules *config.Business) *service.CartService {
+		return service.NewCartService(f.CartItems, f.Carts, nil, nil, f.Products, f.Variants, rules, "contract-secret")
+	}
+
+	t.Run("add and get", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		ctx := context.Background()
+		owner := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		mustAdd(t, svc, owner, f.ProductIDs[0], 2)
+		mustAdd(t, svc, owner, f.ProductIDs[0], 1)
+		mustAdd(t, svc, owner, f.ProductIDs[1], 1)
+
+		cart, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if cart.TotalProducts != 2 || cart.TotalItems != 4 {
+			t.Fatalf("got %d products and %d items, want 2 and 4", cart.TotalProducts, cart.TotalItems)
+		}
+
+		if cart.TotalPrice != 4*ProductPrice {
+			t.Errorf("got total price %v, want %v", cart.TotalPrice, 4*ProductPrice)
+		}
+
+		line := findLine(t, cart, f.ProductIDs[0])
+		if line.Quantity != 3 || line.AddedPrice != ProductPrice || line.Status != domain.CartItemAvailable {
+			t.Errorf("got line %+v, want quantity 3 added at %v and available", line, ProductPrice)
+		}
+
+		other, err := svc.GetCart(ctx, domain.CartOwner{UserID: f.UserIDs[1]})
+		if err != nil {
+			t.Fatalf("GetCart of another user: %v", err)
+		}
+
+		if len(other.Items) != 0 {
+			t.Errorf("another user sees %d items", len(other.Items))
+		}
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		owner := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		mustAdd(t, svc, owner, f.ProductIDs[0], 4)
+
+		if _, err := svc.AddToCart(context.Background(), owner, f.ProductIDs[0], 0, 2); !errors.Is(err, consts.ErrQuantityLimitExceeded) {
+			t.Errorf("got %v, want %v", err, consts.ErrQuantityLimitExceeded)
+		}
+
+		limited := *rules
+		limited.MaxCartItems = 1
+		if _, err := newService(f, &limited).AddToCart(context.Background(), owner, f.ProductIDs[1], 0, 1); !errors.Is(err, consts.ErrCartItemLimitExceeded) {
+			t.Errorf("got %v, want %v", err, consts.ErrCartItemLimitExceeded)
+		}
+	})
+
+	t.Run("update and remove", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		ctx := context.Background()
+		owner := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		mustAdd(t, svc, owner, f.ProductIDs[0], 2)
+
+		before, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		again, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if before.Version != again.Version {
+			t.Errorf("version changed without a change to the cart")
+		}
+
+		err = svc.UpdateCart(ctx, owner, dto.UpdateCartRequest{ProductID: f.ProductIDs[0], Quantity: 5})
+		if err != nil {
+			t.Fatalf("UpdateCart: %v", err)
+		}
+
+		after, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if line := findLine(t, after, f.ProductIDs[0]); line.Quantity != 5 {
+			t.Errorf("got quantity %d, want 5", line.Quantity)
+		}
+
+		if after.Version == before.Version {
+			t.Errorf("version did not change with the quantity")
+		}
+
+		if err := svc.RemoveFromCart(ctx, owner, f.ProductIDs[0], 0); err != nil {
+			t.Fatalf("RemoveFromCart: %v", err)
+		}
+
+		if err := svc.RemoveFromCart(ctx, owner, f.ProductIDs[0], 0); !errors.Is(err, consts.ErrDataNotFound) {
+			t.Errorf("removing twice got %v, want %v", err, consts.ErrDataNotFound)
+		}
+
+		empty, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if len(empty.Items) != 0 {
+			t.Errorf("got %d items after removing the only one", len(empty.Items))
+		}
+	})
+
+	t.Run("bulk changes", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		ctx := context.Background()
+		owner := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		mustAdd(t, svc, owner, f.ProductIDs[0], 1)
+		mustAdd(t, svc, owner, f.ProductIDs[1], 1)
+
+		_, lineErrors, err := svc.BulkUpdateCart(ctx, owner, []dto.CartItemOperation{
+			{Op: dto.CartOperationUpsert, ProductID: f.ProductIDs[0], Quantity: 3},
+			{Op: dto.CartOperationUpsert, ProductID: f.ProductIDs[2], Quantity: ProductStock + 1},
+			{Op: dto.CartOperationUpsert, ProductID: -1, Quantity: 1},
+			{Op: dto.CartOperationRemove, ProductID: f.ProductIDs[1]},
+		})
+		if !errors.Is(err, consts.ErrInvalidCartOperations) {
+			t.Fatalf("got %v, want %v", err, consts.ErrInvalidCartOperations)
+		}
+
+		if len(lineErrors) != 2 || lineErrors[0].Index != 1 || lineErrors[1].Index != 2 {
+			t.Fatalf("got line errors %+v, want operations 1 and 2", lineErrors)
+		}
+
+		unchanged, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if len(unchanged.Items) != 2 || findLine(t, unchanged, f.ProductIDs[0]).Quantity != 1 {
+			t.Fatalf("a rejected bulk change modified the cart: %+v", unchanged.Items)
+		}
+
+		_, lineErrors, err = svc.BulkUpdateCart(ctx, owner, []dto.CartItemOperation{
+			{Op: dto.CartOperationUpsert, ProductID: f.ProductIDs[0], Quantity: 3},
+			{Op: dto.CartOperationUpsert, ProductID: f.ProductIDs[2], Quantity: 2},
+			{Op: dto.CartOperationRemove, ProductID: f.ProductIDs[1]},
+		})
+		if err != nil {
+			t.Fatalf("BulkUpdateCart: %v %+v", err, lineErrors)
+		}
+
+		cart, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if cart.TotalProducts != 2 || cart.TotalItems != 5 {
+			t.Fatalf("got %d products and %d items, want 2 and 5", cart.TotalProducts, cart.TotalItems)
+		}
+
+		if err := svc.ClearCart(ctx, owner); err != nil {
+			t.Fatalf("ClearCart: %v", err)
+		}
+
+		cleared, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if len(cleared.Items) != 0 {
+			t.Errorf("got %d items after clearing the cart", len(cleared.Items))
+		}
+	})
+
+	t.Run("guest cart merges on login", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		ctx := context.Background()
+		user := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		token, err := svc.AddToCart(ctx, domain.CartOwner{}, f.ProductIDs[0], 0, 2)
+		if err != nil {
+			t.Fatalf("AddToCart as guest: %v", err)
+		}
+
+		if token == "" {
+			t.Fatal("a new guest cart returned no token")
+		}
+
+		guest := domain.CartOwner{GuestToken: token}
+		if next := mustAdd(t, svc, guest, f.ProductIDs[1], 1); next != token {
+			t.Errorf("the guest token changed from %q to %q", token, next)
+		}
+
+		mustAdd(t, svc, user, f.ProductIDs[0], 1)
+
+		if err := svc.MergeGuestCart(ctx, user.UserID, token); err != nil {
+			t.Fatalf("MergeGuestCart: %v", err)
+		}
+
+		cart, err := svc.GetCart(ctx, user)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if line := findLine(t, cart, f.ProductIDs[0]); line.Quantity != 3 {
+			t.Errorf("got merged quantity %d, want 3", line.Quantity)
+		}
+
+		if line := findLine(t, cart, f.ProductIDs[1]); line.Quantity != 1 {
+			t.Errorf("got quantity %d for the guest only product, want 1", line.Quantity)
+		}
+
+		left, err := svc.GetCart(ctx, guest)
+		if err != nil {
+			t.Fatalf("GetCart as guest: %v", err)
+		}
+
+		if len(left.Items) != 0 {
+			t.Errorf("the guest cart still has %d items after the merge", len(left.Items))
+		}
+	})
+
+	t.Run("expired guest cart", func(t *testing.T) {
+		f := newFixture(t)
+		shortLived := *rules
+		shortLived.GuestCartTTL = 50 * time.Millisecond
+		svc := newService(f, &shortLived)
+		ctx := context.Background()
+
+		token := mustAdd(t, svc, domain.CartOwner{}, f.ProductIDs[0], 1)
+		time.Sleep(100 * time.Millisecond)
+
+		cart, err := svc.GetCart(ctx, domain.CartOwner{GuestToken: token})
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if len(cart.Items) != 0 {
+			t.Errorf("an expired guest cart still has %d items", len(cart.Items))
+		}
+
+		if _, err := svc.CleanupGuestCarts(ctx, time.Now()); err != nil {
+			t.Fatalf("CleanupGuestCarts: %v", err)
+		}
+
+		if _, err := svc.GetCart(ctx, domain.CartOwner{GuestToken: "forged." + token}); !errors.Is(err, consts.ErrInvalidCartToken) {
+			t.Errorf("got %v for a forged token, want %v", err, consts.ErrInvalidCartToken)
+		}
+	})
+
+	t.Run("deleted product", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		ctx := context.Background()
+		owner := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		mustAdd(t, svc, owner, f.ProductIDs[0], 1)
+		mustAdd(t, svc, owner, f.ProductIDs[2], 1)
+
+		if err := f.CartItems.DeleteByProductID(ctx, f.ProductIDs[2]); err != nil {
+			t.Fatalf("DeleteByProductID: %v", err)
+		}
+
+		cart, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if len(cart.Items) != 1 || cart.Items[0].ProductID != f.ProductIDs[0] {
+			t.Errorf("got items %+v, want only product %d", cart.Items, f.ProductIDs[0])
+		}
+	})
+
+	t.Run("variant lines", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		ctx := context.Background()
+		owner := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		if _, err := svc.AddToCart(ctx, owner, f.VariantProductID, 0, 1); !errors.Is(err, consts.ErrVariantRequired) {
+			t.Errorf("adding without a variant got %v, want %v", err, consts.ErrVariantRequired)
+		}
+
+		if _, err := svc.AddToCart(ctx, owner, f.ProductIDs[0], f.VariantIDs[0], 1); !errors.Is(err, consts.ErrVariantNotFound) {
+			t.Errorf("adding the variant of another product got %v, want %v", err, consts.ErrVariantNotFound)
+		}
+
+		if _, err := svc.AddToCart(ctx, owner, f.VariantProductID, f.VariantIDs[0], VariantStock+1); !errors.Is(err, consts.ErrInsufficientStock) {
+			t.Errorf("adding more than the variant stock got %v, want %v", err, consts.ErrInsufficientStock)
+		}
+
+		for _, variantID := range f.VariantIDs {
+			if _, err := svc.AddToCart(ctx, owner, f.VariantProductID, variantID, 1); err != nil {
+				t.Fatalf("AddToCart(%d, %d): %v", f.VariantProductID, variantID, err)
+			}
+		}
+		if _, err := svc.AddToCart(ctx, owner, f.VariantProductID, f.VariantIDs[0], 1); err != nil {
+			t.Fatalf("AddToCart(%d, %d): %v", f.VariantProductID, f.VariantIDs[0], err)
+		}
+
+		cart, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if cart.TotalProducts != 2 || cart.TotalItems != 3 {
+			t.Fatalf("got %d products and %d items, want 2 and 3", cart.TotalProducts, cart.TotalItems)
+		}
+
+		if cart.TotalPrice != 2*VariantPrice+ProductPrice {
+			t.Errorf("got total price %v, want %v", cart.TotalPrice, 2*VariantPrice+ProductPrice)
+		}
+
+		if err := svc.RemoveFromCart(ctx, owner, f.VariantProductID, f.VariantIDs[0]); err != nil {
+			t.Fatalf("RemoveFromCart: %v", err)
+		}
+
+		left, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if len(left.Items) != 1 || left.Items[0].VariantID != f.VariantIDs[1] {
+			t.Errorf("got items %+v, want only variant %d", left.Items, f.VariantIDs[1])
+		}
+	})
+
+	t.Run("checkout clears the cart", func(t *testing.T) {
+		f := newFixture(t)
+		svc := newService(f, rules)
+		ctx := context.Background()
+		owner := domain.CartOwner{UserID: f.UserIDs[0]}
+
+		mustAdd(t, svc, owner, f.ProductIDs[0], 1)
+
+		// the same calls CheckoutService makes once the order is placed
+		cart, err := f.Carts.FindByUserID(ctx, owner.UserID)
+		if err != nil || cart == nil {
+			t.Fatalf("FindByUserID: %v, %v", cart, err)
+		}
+
+		if err := f.CartItems.DeleteByCartID(ctx, cart.ID); err != nil {
+			t.Fatalf("DeleteByCartID: %v", err)
+		}
+
+		if err := f.Carts.DeleteByUserID(ctx, owner.UserID); err != nil {
+			t.Fatalf("DeleteByUserID: %v", err)
+		}
+
+		if cart, err := f.Carts.FindByUserID(ctx, owner.UserID); err != nil || cart != nil {
+			t.Fatalf("got cart %+v, %v after checkout, want none", cart, err)
+		}
+
+		mustAdd(t, svc, owner, f.ProductIDs[1], 1)
+
+		next, err := svc.GetCart(ctx, owner)
+		if err != nil {
+			t.Fatalf("GetCart: %v", err)
+		}
+
+		if len(next.Items) != 1 || next.Items[0].ProductID != f.ProductIDs[1] {
+			t.Errorf("got items %+v in the next cart, want only product %d", next.Items, f.ProductIDs[1])
+		}
+	})
+}
+
+func mustAdd(t *testing.T, svc *service.CartService, owner domain.CartOwner, productID, quantity int) string {
+	t.Helper()
+
+	token, err := svc.AddToCart(context.Background(), owner, productID, 0, quantity)
+	if err != nil {
+		t.Fatalf("AddToCart(%d, %d): %v", productID, quantity, err)
+	}
+
+	return token
+}
+
+func findLine(t *testing.T, cart dto.CartResponse, productID int) dto.CartItemResponse {
+	t.Helper()
+
+	for _, line := range cart.Items {
+		if line.ProductID == productID {
+			return line
+		}
+	}
+
+	t.Fatalf("product %d is not in the cart", productID)
+	return dto.CartItemResponse{}
+}
@@ -0,0 +1,155 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// LockerContract runs the behaviour every port.Locker backend has to share.
+// The locks of a run use keys of their own, so runs against the same backend
+// don't contend
+func LockerContract(t *testing.T, locker port.Locker) {
+	ctx := context.Background()
+	noRetry := port.LockOptions{}
+
+	newKey := func(t *testing.T) string {
+		return fmt.Sprintf("locker-contract:%s:%d", t.Name(), time.Now().UnixNano())
+	}
+
+	t.Run("obtain", func(t *testing.T) {
+		key := newKey(t)
+
+		lock, err := locker.Obtain(ctx, key, time.Minute, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain: %v", err)
+		}
+		defer lock.Release(ctx)
+
+		if lock.Key() != key || lock.Token() == "" {
+			t.Fatalf("lock = %q/%q, want key %q and a token", lock.Key(), lock.Token(), key)
+		}
+
+		if _, err := locker.Obtain(ctx, key, time.Minute, noRetry); !errors.Is(err, consts.ErrLockNotObtained) {
+			t.Fatalf("second Obtain = %v, want ErrLockNotObtained", err)
+		}
+
+		retry := port.LockOptions{RetryCount: 2, RetryBackoff: 10 * time.Millisecond}
+		start := time.Now()
+		if _, err := locker.Obtain(ctx, key, time.Minute, retry); !errors.Is(err, consts.ErrLockNotObtained) {
+			t.Fatalf("Obtain with retries = %v, want ErrLockNotObtained", err)
+		}
+		if waited := time.Since(start); waited < 30*time.Millisecond {
+			t.Fatalf("Obtain gave up after %v, want the 10ms and 20ms backoffs", waited)
+		}
+
+		ttl, err := lock.TTL(ctx)
+		if err != nil || ttl <= 0 || ttl > time.Minute {
+			t.Fatalf("TTL = %v, %v, want up to a minute", ttl, err)
+		}
+	})
+
+	t.Run("release", func(t *testing.T) {
+		key := newKey(t)
+
+		lock, err := locker.Obtain(ctx, key, time.Minute, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain: %v", err)
+		}
+
+		if err := lock.Release(ctx); err != nil {
+			t.Fatalf("Release: %v", err)
+		}
+		if err := lock.Release(ctx); !errors.Is(err, consts.ErrLockNotHeld) {
+			t.Fatalf("second Release = %v, want ErrLockNotHeld", err)
+		}
+
+		again, err := locker.Obtain(ctx, key, time.Minute, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain after Release: %v", err)
+		}
+		again.Release(ctx)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		key := newKey(t)
+
+		lock, err := locker.Obtain(ctx, key, 100*time.Millisecond, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain: %v", err)
+		}
+
+		time.Sleep(250 * time.Millisecond)
+
+		if ttl, err := lock.TTL(ctx); err != nil || ttl != 0 {
+			t.Fatalf("TTL after expiry = %v, %v, want 0", ttl, err)
+		}
+		if err := lock.Refresh(ctx, time.Minute); !errors.Is(err, consts.ErrLockNotHeld) {
+			t.Fatalf("Refresh after expiry = %v, want ErrLockNotHeld", err)
+		}
+
+		again, err := locker.Obtain(ctx, key, time.Minute, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain after expiry: %v", err)
+		}
+		again.Release(ctx)
+	})
+
+	t.Run("lost token", func(t *testing.T) {
+		key := newKey(t)
+
+		lost, err := locker.Obtain(ctx, key, 100*time.Millisecond, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain: %v", err)
+		}
+
+		time.Sleep(250 * time.Millisecond)
+
+		holder, err := locker.Obtain(ctx, key, time.Minute, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain after expiry: %v", err)
+		}
+		defer holder.Release(ctx)
+
+		// the expired lock must not touch the one that took over its key
+		if err := lost.Refresh(ctx, time.Minute); !errors.Is(err, consts.ErrLockNotHeld) {
+			t.Fatalf("Refresh of the lost lock = %v, want ErrLockNotHeld", err)
+		}
+		if err := lost.Release(ctx); !errors.Is(err, consts.ErrLockNotHeld) {
+			t.Fatalf("Release of the lost lock = %v, want ErrLockNotHeld", err)
+		}
+		if _, err := locker.Obtain(ctx, key, time.Minute, noRetry); !errors.Is(err, consts.ErrLockNotObtained) {
+			t.Fatalf("Obtain while taken over = %v, want ErrLockNotObtained", err)
+		}
+	})
+
+	t.Run("refresh and auto renew", func(t *testing.T) {
+		refreshed, err := locker.Obtain(ctx, newKey(t), 150*time.Millisecond, noRetry)
+		if err != nil {
+			t.Fatalf("Obtain: %v", err)
+		}
+		renewed, err := locker.Obtain(ctx, newKey(t), 150*time.Millisecond, port.LockOptions{AutoRenew: true})
+		if err != nil {
+			t.Fatalf("Obtain with AutoRenew: %v", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			time.Sleep(100 * time.Millisecond)
+			if err := refreshed.Refresh(ctx, 150*time.Millisecond); err != nil {
+				t.Fatalf("Refresh %d: %v", i, err)
+			}
+		}
+
+		if err := refreshed.Release(ctx); err != nil {
+			t.Fatalf("Release of the refreshed lock: %v", err)
+		}
+		if err := renewed.Release(ctx); err != nil {
+			t.Fatalf("Release of the renewed lock: %v", err)
+		}
+	})
+}
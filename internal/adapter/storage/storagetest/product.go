@@ -0,0 +1,113 @@
+package storagetest
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+)
+
+// ProductStore is an in-memory ProductRepository for backends that keep carts
+// away from Postgres. Only the lookups the cart needs are implemented
+type ProductStore struct {
+	port.ProductRepository
+
+	mu       sync.Mutex
+	products map[int]domain.Product
+}
+
+func NewProductStore() *ProductStore {
+	return &ProductStore{products: make(map[int]domain.Product)}
+}
+
+// Add stores a product with the given ID at the fixture price and stock
+func (s *ProductStore) Add(id int, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.products[id] = domain.Product{ID: id, Name: name, Price: ProductPrice, Stock: ProductStock}
+}
+
+func (s *ProductStore) FindOne(ctx context.Context, id int) (*domain.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	product, ok := s.products[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &product, nil
+}
+
+func (s *ProductStore) FindByIDs(ctx context.Context, ids []int) ([]domain.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var products []domain.Product
+	for _, id := range ids {
+		if product, ok := s.products[id]; ok {
+			products = append(products, product)
+		}
+	}
+
+	return products, nil
+}
+
+// VariantStore is an in-memory ProductVariantRepository for backends that
+// keep carts away from Postgres. Only the lookups the cart needs are
+// implemented
+type VariantStore struct {
+	port.ProductVariantRepository
+
+	mu       sync.Mutex
+	variants map[int]domain.ProductVariant
+}
+
+func NewVariantStore() *VariantStore {
+	return &VariantStore{variants: make(map[int]domain.ProductVariant)}
+}
+
+// Add stores a variant of a product at the fixture variant stock, price
+// overrides the product price when set
+func (s *VariantStore) Add(id, productID int, sku string, price *float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.variants[id] = domain.ProductVariant{ID: id, ProductID: productID, SKU: sku, Price: price, Stock: VariantStock}
+}
+
+func (s *VariantStore) FindOne(ctx context.Context, id int) (*domain.ProductVariant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	variant, ok := s.variants[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &variant, nil
+}
+
+func (s *VariantStore) FindByProductIDs(ctx context.Context, productIDs []int) ([]domain.ProductVariant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[int]bool, len(productIDs))
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+
+	var variants []domain.ProductVariant
+	for _, variant := range s.variants {
+		if wanted[variant.ProductID] {
+			variants = append(variants, variant)
+		}
+	}
+
+	sort.Slice(variants, func(i, j int) bool { return variants[i].ID < variants[j].ID })
+
+	return variants, nil
+}
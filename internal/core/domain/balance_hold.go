@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type BalanceHoldStatus string
+
+const (
+	BalanceHoldHeld     BalanceHoldStatus = "held"
+	BalanceHoldCaptured BalanceHoldStatus = "captured"
+	BalanceHoldReleased BalanceHoldStatus = "released"
+)
+
+// BalanceHold reserves wallet funds for an order between checkout and payment
+type BalanceHold struct {
+	ID        uint64            `json:"id"`
+	UserID    uint64            `json:"user_id"`
+	OrderID   int               `json:"order_id"`
+	Amount    float64           `json:"amount"`
+	Status    BalanceHoldStatus `json:"status"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+type TransactionType string
+
+const (
+	TransactionOpeningBalance     TransactionType = "opening_balance"
+	TransactionDeposit            TransactionType = "deposit"
+	TransactionWithdrawal         TransactionType = "withdrawal"
+	TransactionWithdrawalReversal TransactionType = "withdrawal_reversal"
+	TransactionTransfer           TransactionType = "transfer"
+	TransactionPayment            TransactionType = "payment"
+	TransactionHold               TransactionType = "hold"
+	TransactionHoldRelease        TransactionType = "hold_release"
+	TransactionRefund             TransactionType = "refund"
+	TransactionAdjustment         TransactionType = "adjustment"
+)
+
+// BalanceTransaction is a ledger row, Amount is signed so that the sum of a
+// user's rows always equals the current balance
+type BalanceTransaction struct {
+	ID              uint64          `json:"id"`
+	UserID          uint64          `json:"user_id"`
+	Amount          float64         `json:"amount"`
+	BalanceBefore   float64         `json:"balance_before"`
+	BalanceAfter    float64         `json:"balance_after"`
+	TransactionType TransactionType `json:"transaction_type"`
+	ReferenceID     uint64          `json:"reference_id,omitempty"`
+	CounterpartyID  uint64          `json:"counterparty_id,omitempty"`
+	JournalEntryID  uint64          `json:"journal_entry_id,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// BalanceReconciliation compares the stored balance against the ledger
+type BalanceReconciliation struct {
+	UserID     uint64  `json:"user_id"`
+	Balance    float64 `json:"balance"`
+	LedgerSum  float64 `json:"ledger_sum"`
+	Difference float64 `json:"difference"`
+}
+
+// BalanceTransactionFilter narrows down a user's ledger rows, zero values are ignored
+type BalanceTransactionFilter struct {
+	UserID          uint64
+	TransactionType TransactionType
+	From            time.Time
+	To              time.Time
+	MinAmount       float64
+	MaxAmount       float64
+	CounterpartyID  uint64
+	BeforeID        uint64
+	Limit           uint64
+}
@@ -3,8 +3,22 @@ package domain
 import "time"
 
 type Cart struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	GuestID   string     `json:"-"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsGuest reports whether the cart belongs to a visitor who has not signed in
+func (c *Cart) IsGuest() bool {
+	return c.GuestID != ""
+}
+
+// CartOwner identifies the cart a request works on, the signed in user's cart
+// when UserID is set and otherwise the guest cart of GuestToken
+type CartOwner struct {
+	UserID     int
+	GuestToken string
 }
@@ -1,12 +1,74 @@
 package domain
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
+// CartItem is a line of a cart. VariantID is 0 for a product without variants
 type CartItem struct {
-	ID        int       `json:"id"`
-	CartID    int       `json:"cart_id"`
-	ProductID int       `json:"product_id"`
-	Quantity  int       `json:"quantity"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int       `json:"id"`
+	CartID      int       `json:"cart_id"`
+	ProductID   int       `json:"product_id"`
+	VariantID   int       `json:"variant_id,omitempty"`
+	Quantity    int       `json:"quantity"`
+	Price       float64   `json:"price"`
+	ProductName string    `json:"product_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Line returns what the item is a line of, a cart holds one line per product
+// and variant
+func (i *CartItem) Line() CartLine {
+	return CartLine{ProductID: i.ProductID, VariantID: i.VariantID}
+}
+
+// CartLine identifies a line of a cart by its product and variant
+type CartLine struct {
+	ProductID int
+	VariantID int
+}
+
+// Key is the line as a string, the product ID alone for a product without
+// variants
+func (l CartLine) Key() string {
+	if l.VariantID == 0 {
+		return strconv.Itoa(l.ProductID)
+	}
+
+	return strconv.Itoa(l.ProductID) + ":" + strconv.Itoa(l.VariantID)
+}
+
+// CartItemWithProduct is a cart item together with its current product, which
+// is nil once the product has been deleted. The cart service replaces the
+// product of a variant line by the product as sold through the variant
+type CartItemWithProduct struct {
+	CartItem
+	Product *Product
+}
+
+// CartItemStatus tells whether a cart line can still be checked out as it was added
+type CartItemStatus string
+
+const (
+	CartItemAvailable    CartItemStatus = "available"
+	CartItemPriceChanged CartItemStatus = "price_changed"
+	CartItemOutOfStock   CartItemStatus = "out_of_stock"
+	CartItemUnavailable  CartItemStatus = "unavailable"
+)
+
+// Status compares the line with the current state of its product, nil when
+// the product has been deleted
+func (i *CartItem) Status(product *Product) CartItemStatus {
+	switch {
+	case product == nil:
+		return CartItemUnavailable
+	case product.Stock < i.Quantity:
+		return CartItemOutOfStock
+	case product.Price != i.Price:
+		return CartItemPriceChanged
+	default:
+		return CartItemAvailable
+	}
 }
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// CartReminder is one reminder email sent for an idle cart
+type CartReminder struct {
+	ID            int       `json:"id"`
+	CartID        int       `json:"cart_id"`
+	UserID        int       `json:"user_id"`
+	CartUpdatedAt time.Time `json:"cart_updated_at"`
+	ItemCount     int       `json:"item_count"`
+	CartTotal     float64   `json:"cart_total"`
+	SentAt        time.Time `json:"sent_at"`
+}
+
+// IdleCart is a signed in user's cart that has not changed since UpdatedAt,
+// with the number of reminders already sent for it
+type IdleCart struct {
+	CartID        int
+	UserID        int
+	Name          string
+	Email         string
+	UpdatedAt     time.Time
+	RemindersSent int
+}
+
+// IdleCartFilter selects the carts due for a reminder, carts of users who
+// opted out are never returned
+type IdleCartFilter struct {
+	UpdatedBefore      time.Time
+	LastReminderBefore time.Time
+	MaxReminders       int
+	Limit              int
+}
+
+// CartReminderStats sums up the reminders sent since a date. A reminded cart
+// converts when its user places an order within the attribution window of
+// a reminder
+type CartReminderStats struct {
+	Since          time.Time `json:"since"`
+	RemindersSent  int       `json:"reminders_sent"`
+	CartsReminded  int       `json:"carts_reminded"`
+	CartsConverted int       `json:"carts_converted"`
+	ConversionRate float64   `json:"conversion_rate"`
+	Revenue        float64   `json:"revenue"`
+}
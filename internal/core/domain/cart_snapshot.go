@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// CartSnapshot is the last known state of a cart kept in Redis, copied to
+// Postgres for analytics. DeletedAt is set once the cart was checked out,
+// merged or removed
+type CartSnapshot struct {
+	CartID    int        `json:"cart_id"`
+	UserID    int        `json:"user_id"`
+	GuestID   string     `json:"guest_id"`
+	Items     []CartItem `json:"items"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type AccountType string
+
+const (
+	AccountAsset     AccountType = "asset"
+	AccountLiability AccountType = "liability"
+	AccountRevenue   AccountType = "revenue"
+	AccountEquity    AccountType = "equity"
+)
+
+// System account codes, user wallets use WalletAccountCode
+const (
+	AccountPlatformRevenue    = "platform_revenue"
+	AccountPaymentClearing    = "payment_clearing"
+	AccountRefundsPayable     = "refunds_payable"
+	AccountOpeningEquity      = "opening_equity"
+	AccountWithdrawalsPending = "withdrawals_pending"
+	AccountWalletHolds        = "wallet_holds"
+	AccountWalletAdjustments  = "wallet_adjustments"
+)
+
+type Account struct {
+	ID          uint64      `json:"id"`
+	Code        string      `json:"code"`
+	Name        string      `json:"name"`
+	AccountType AccountType `json:"account_type"`
+	UserID      uint64      `json:"user_id,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// JournalEntry groups the lines of one business event, the lines always sum to
+// zero. An entry with an IdempotencyKey is posted at most once
+type JournalEntry struct {
+	ID             uint64          `json:"id"`
+	EntryType      TransactionType `json:"entry_type"`
+	ReferenceID    uint64          `json:"reference_id,omitempty"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+	Description    string          `json:"description"`
+	CreatedBy      uint64          `json:"created_by,omitempty"`
+	Lines          []JournalLine   `json:"lines"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// JournalLine moves Amount into the account, a negative amount moves it out.
+// UserID is set for wallet lines, AccountCode for system accounts
+type JournalLine struct {
+	ID             uint64  `json:"id"`
+	JournalEntryID uint64  `json:"journal_entry_id"`
+	AccountID      uint64  `json:"account_id"`
+	AccountCode    string  `json:"account_code"`
+	UserID         uint64  `json:"user_id,omitempty"`
+	Amount         float64 `json:"amount"`
+}
+
+// WalletAccountCode returns the account code of the user's wallet
+func WalletAccountCode(userID uint64) string {
+	return fmt.Sprintf("wallet:%d", userID)
+}
+
+// IsBalanced reports whether the lines sum to zero, compared in cents
+func (e *JournalEntry) IsBalanced() bool {
+	if len(e.Lines) < 2 {
+		return false
+	}
+
+	var total int64
+	for _, line := range e.Lines {
+		total += int64(math.Round(line.Amount * 100))
+	}
+
+	return total == 0
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+type MoneyRequestStatus string
+
+const (
+	MoneyRequestPending   MoneyRequestStatus = "pending"
+	MoneyRequestCompleted MoneyRequestStatus = "completed"
+	MoneyRequestCancelled MoneyRequestStatus = "cancelled"
+	// MoneyRequestExpired is never stored, a pending request past its expiry reads as expired
+	MoneyRequestExpired MoneyRequestStatus = "expired"
+)
+
+type MoneyRequestPayerStatus string
+
+const (
+	MoneyRequestPayerPending   MoneyRequestPayerStatus = "pending"
+	MoneyRequestPayerPaid      MoneyRequestPayerStatus = "paid"
+	MoneyRequestPayerDeclined  MoneyRequestPayerStatus = "declined"
+	MoneyRequestPayerCancelled MoneyRequestPayerStatus = "cancelled"
+	MoneyRequestPayerExpired   MoneyRequestPayerStatus = "expired"
+)
+
+// MoneyRequest asks one or more users to pay the requester, a split bill is
+// a request whose total is shared between the payers
+type MoneyRequest struct {
+	ID          uint64              `json:"id"`
+	RequesterID uint64              `json:"requester_id"`
+	Amount      float64             `json:"amount"`
+	Note        string              `json:"note"`
+	Status      MoneyRequestStatus  `json:"status"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+	Payers      []MoneyRequestPayer `json:"payers"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// MoneyRequestPayer is one user's share of a money request
+type MoneyRequestPayer struct {
+	ID        uint64                  `json:"id"`
+	RequestID uint64                  `json:"request_id"`
+	PayerID   uint64                  `json:"payer_id"`
+	Amount    float64                 `json:"amount"`
+	Status    MoneyRequestPayerStatus `json:"status"`
+	PaidAt    *time.Time              `json:"paid_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+// Payer returns the share of the given user, or nil if they were not asked to pay
+func (r *MoneyRequest) Payer(userID uint64) *MoneyRequestPayer {
+	for i := range r.Payers {
+		if r.Payers[i].PayerID == userID {
+			return &r.Payers[i]
+		}
+	}
+
+	return nil
+}
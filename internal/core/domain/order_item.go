@@ -1,9 +1,13 @@
 package domain
 
+// OrderItem is a line of an order, VariantID and SKU are only set for a
+// product with variants
 type OrderItem struct {
 	ID        int     `json:"id"`
 	OrderID   int     `json:"order_id"`
 	ProductID int     `json:"product_id"`
+	VariantID int     `json:"variant_id,omitempty"`
+	SKU       string  `json:"sku,omitempty"`
 	Quantity  int     `json:"quantity"`
 	Price     float64 `json:"price"`
 }
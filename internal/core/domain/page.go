@@ -0,0 +1,28 @@
+package domain
+
+// PageRequest asks for one page of a listing sorted on Sort, then on ID. The
+// page starts After a cursor when one is given, otherwise at Offset
+type PageRequest struct {
+	Sort   string
+	Desc   bool
+	Limit  uint64
+	Offset uint64
+	After  *PageCursor
+}
+
+// PageCursor points at the last row of a page by its sort value and ID, it
+// is only valid for the sort it was made with
+type PageCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// Page is one page of a listing, Total counts every matching row and Next is
+// nil on the last page
+type Page[T any] struct {
+	Items []T
+	Total int64
+	Next  *PageCursor
+}
@@ -4,13 +4,16 @@ import (
 	"time"
 )
 
+// Product is an item for sale, Images is only loaded for product responses
 type Product struct {
-	ID          int       `bson:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	CategoryID  int       `json:"category_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int               `bson:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	Stock       int               `json:"stock"`
+	CategoryID  int               `json:"category_id"`
+	Attributes  map[string]string `json:"attributes"`
+	Images      []ProductImage    `json:"images,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
 }
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// ImageSize names a resized copy of a product image
+type ImageSize string
+
+const (
+	ImageSizeThumbnail ImageSize = "thumbnail"
+	ImageSizeMedium    ImageSize = "medium"
+	ImageSizeLarge     ImageSize = "large"
+	// ImageSizeOriginal is the image as it was uploaded
+	ImageSizeOriginal ImageSize = "original"
+)
+
+// the progress of the derivatives of a product image
+const (
+	DerivativesPending = "pending"
+	DerivativesReady   = "ready"
+	DerivativesFailed  = "failed"
+)
+
+// ProductImage is an image in the gallery of a product, ordered by Position.
+// ObjectKey names the file in the storage and URL is where clients load it
+type ProductImage struct {
+	ID                int                           `json:"id"`
+	ProductID         int                           `json:"product_id"`
+	ObjectKey         string                        `json:"object_key"`
+	URL               string                        `json:"url"`
+	ContentType       string                        `json:"content_type"`
+	Size              int64                         `json:"size"`
+	Position          int                           `json:"position"`
+	IsPrimary         bool                          `json:"is_primary"`
+	Derivatives       map[ImageSize]ImageDerivative `json:"derivatives,omitempty"`
+	DerivativesStatus string                        `json:"derivatives_status"`
+	CreatedAt         time.Time                     `json:"created_at"`
+}
+
+// ImageDerivative is a resized JPEG copy of a product image
+type ImageDerivative struct {
+	ObjectKey string `json:"object_key"`
+	URL       string `json:"url,omitempty"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+// SizedURL is the URL of the image in the given size, the original while that
+// size has not been generated
+func (i ProductImage) SizedURL(size ImageSize) string {
+	if derivative, ok := i.Derivatives[size]; ok && derivative.URL != "" {
+		return derivative.URL
+	}
+
+	return i.URL
+}
@@ -0,0 +1,51 @@
+package domain
+
+// ProductSearch is a product search. Query is matched with full-text search
+// on the name and description, or with trigram similarity on the name when
+// Fuzzy is set. The other fields filter the matches, Attributes must all be
+// present on a product
+type ProductSearch struct {
+	Query      string
+	Fuzzy      bool
+	CategoryID int
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    bool
+	Attributes map[string]string
+	// Sort is "relevance" or a product column, relevance needs a Query
+	Sort         string
+	Desc         bool
+	Limit        uint64
+	Offset       uint64
+	PriceBuckets []PriceBucket
+}
+
+// PriceBucket is the price range [Min, Max), a zero Max has no upper bound
+type PriceBucket struct {
+	Min float64
+	Max float64
+}
+
+// CategoryFacet counts the matching products of a category
+type CategoryFacet struct {
+	CategoryID int
+	Name       string
+	Count      int64
+}
+
+// PriceFacet counts the matching products in a price bucket
+type PriceFacet struct {
+	PriceBucket
+	Count int64
+}
+
+// ProductSearchResult is one page of a product search. The category facets
+// ignore the category filter and the price facets ignore the price range, so
+// every choice of a filter keeps its count
+type ProductSearchResult struct {
+	Items      []Product
+	Total      int64
+	Fuzzy      bool
+	Categories []CategoryFacet
+	Prices     []PriceFacet
+}
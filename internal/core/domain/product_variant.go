@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProductOption is an option type of a product such as size or color. A
+// variant picks one of Values, any value when Values is empty
+type ProductOption struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	Name      string    `json:"name"`
+	Values    []string  `json:"values"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Allows reports whether a variant can pick value for this option
+func (o *ProductOption) Allows(value string) bool {
+	if len(o.Values) == 0 {
+		return value != ""
+	}
+
+	for _, allowed := range o.Values {
+		if allowed == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProductVariant is a version of a product sold with its own SKU and stock.
+// Options holds one value per option of the product, e.g. {"size": "M"}.
+// Price overrides the product price when set
+type ProductVariant struct {
+	ID        int               `json:"id"`
+	ProductID int               `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Price     *float64          `json:"price"`
+	Stock     int               `json:"stock"`
+	Barcode   string            `json:"barcode"`
+	Options   map[string]string `json:"options"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Title names the variant by its option values ordered by option name, e.g.
+// "red / M"
+func (v *ProductVariant) Title() string {
+	names := make([]string, 0, len(v.Options))
+	for name := range v.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, v.Options[name])
+	}
+
+	return strings.Join(values, " / ")
+}
+
+// Apply returns the product as sold through the variant, at the variant's
+// price and stock and named after the variant
+func (v *ProductVariant) Apply(product *Product) *Product {
+	sold := *product
+	sold.Stock = v.Stock
+	if v.Price != nil {
+		sold.Price = *v.Price
+	}
+	if title := v.Title(); title != "" {
+		sold.Name = product.Name + " (" + title + ")"
+	}
+
+	return &sold
+}
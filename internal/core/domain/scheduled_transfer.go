@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+type ScheduledTransferFrequency string
+
+const (
+	ScheduledTransferOnce    ScheduledTransferFrequency = "once"
+	ScheduledTransferWeekly  ScheduledTransferFrequency = "weekly"
+	ScheduledTransferMonthly ScheduledTransferFrequency = "monthly"
+)
+
+type ScheduledTransferStatus string
+
+const (
+	ScheduledTransferActive    ScheduledTransferStatus = "active"
+	ScheduledTransferPaused    ScheduledTransferStatus = "paused"
+	ScheduledTransferCompleted ScheduledTransferStatus = "completed"
+	ScheduledTransferCancelled ScheduledTransferStatus = "cancelled"
+	ScheduledTransferFailed    ScheduledTransferStatus = "failed"
+)
+
+type ScheduledTransferRunStatus string
+
+const (
+	ScheduledTransferRunSucceeded ScheduledTransferRunStatus = "succeeded"
+	ScheduledTransferRunFailed    ScheduledTransferRunStatus = "failed"
+)
+
+// ScheduledTransfer is a wallet transfer executed by the scheduler at
+// StartAt, and then every week or month for recurring schedules
+type ScheduledTransfer struct {
+	ID           uint64                     `json:"id"`
+	UserID       uint64                     `json:"user_id"`
+	RecipientID  uint64                     `json:"recipient_id"`
+	Amount       float64                    `json:"amount"`
+	Frequency    ScheduledTransferFrequency `json:"frequency"`
+	Status       ScheduledTransferStatus    `json:"status"`
+	StartAt      time.Time                  `json:"start_at"`
+	EndAt        *time.Time                 `json:"end_at"`
+	NextRunAt    time.Time                  `json:"next_run_at"`
+	RunCount     int                        `json:"run_count"`
+	FailureCount int                        `json:"failure_count"`
+	LastError    string                     `json:"last_error"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	UpdatedAt    time.Time                  `json:"updated_at"`
+}
+
+// ScheduledTransferRun records the outcome of one execution of a schedule
+type ScheduledTransferRun struct {
+	ID                  uint64                     `json:"id"`
+	ScheduledTransferID uint64                     `json:"scheduled_transfer_id"`
+	Status              ScheduledTransferRunStatus `json:"status"`
+	Amount              float64                    `json:"amount"`
+	Error               string                     `json:"error"`
+	ScheduledAt         time.Time                  `json:"scheduled_at"`
+	CreatedAt           time.Time                  `json:"created_at"`
+}
+
+// RunAt returns when the n-th run (zero based) of the schedule is due. Months
+// are counted from StartAt so a schedule on the 31st runs on the last day of
+// shorter months instead of drifting
+func (s *ScheduledTransfer) RunAt(n int) time.Time {
+	switch s.Frequency {
+	case ScheduledTransferWeekly:
+		return s.StartAt.AddDate(0, 0, 7*n)
+	case ScheduledTransferMonthly:
+		month := time.Date(s.StartAt.Year(), s.StartAt.Month()+time.Month(n), 1, s.StartAt.Hour(), s.StartAt.Minute(), s.StartAt.Second(), s.StartAt.Nanosecond(), s.StartAt.Location())
+		lastDay := month.AddDate(0, 1, -1).Day()
+		day := s.StartAt.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return month.AddDate(0, 0, day-1)
+	default:
+		return s.StartAt
+	}
+}
+
+// HasNextRun reports whether the schedule runs again after run n
+func (s *ScheduledTransfer) HasNextRun(n int) bool {
+	if s.Frequency == ScheduledTransferOnce {
+		return false
+	}
+	if s.EndAt == nil {
+		return true
+	}
+
+	return !s.RunAt(n + 1).After(*s.EndAt)
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// TransferLimit caps the outgoing transfers of a role, zero means unlimited
+type TransferLimit struct {
+	PerTransaction float64 `json:"per_transaction"`
+	Daily          float64 `json:"daily"`
+	Monthly        float64 `json:"monthly"`
+}
+
+type TransferVerification string
+
+const (
+	TransferVerificationNone   TransferVerification = "none"
+	TransferVerificationOTP    TransferVerification = "otp"
+	TransferVerificationReview TransferVerification = "review"
+)
+
+// TransferInquiry is the confirmation step of a transfer, it stays open until
+// the sender confirms it or it expires
+type TransferInquiry struct {
+	ID            string               `json:"id"`
+	SenderID      uint64               `json:"sender_id"`
+	RecipientID   uint64               `json:"recipient_id"`
+	RecipientName string               `json:"recipient_name"`
+	Amount        float64              `json:"amount"`
+	Verification  TransferVerification `json:"verification"`
+	OTPHash       string               `json:"otp_hash,omitempty"`
+	ExpiresAt     time.Time            `json:"expires_at"`
+}
+
+type TransferReviewStatus string
+
+const (
+	TransferReviewPending  TransferReviewStatus = "pending"
+	TransferReviewApproved TransferReviewStatus = "approved"
+	TransferReviewRejected TransferReviewStatus = "rejected"
+	TransferReviewFailed   TransferReviewStatus = "failed"
+)
+
+// TransferReview is a transfer above the review threshold waiting for an admin
+type TransferReview struct {
+	ID          uint64               `json:"id"`
+	SenderID    uint64               `json:"sender_id"`
+	RecipientID uint64               `json:"recipient_id"`
+	Amount      float64              `json:"amount"`
+	Status      TransferReviewStatus `json:"status"`
+	Note        string               `json:"note"`
+	ReviewedBy  uint64               `json:"reviewed_by"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
@@ -15,6 +15,7 @@ type User struct {
 	ID        uint64    `json:"id"`
 	Name      string    `json:"name"`
 	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
 	Password  string    `json:"password"`
 	Role      UserRole  `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+type AdjustmentDirection string
+
+const (
+	AdjustmentCredit AdjustmentDirection = "credit"
+	AdjustmentDebit  AdjustmentDirection = "debit"
+)
+
+type AdjustmentStatus string
+
+const (
+	AdjustmentPending  AdjustmentStatus = "pending"
+	AdjustmentApplied  AdjustmentStatus = "applied"
+	AdjustmentRejected AdjustmentStatus = "rejected"
+)
+
+// Reason codes an admin must pick for a manual adjustment
+const (
+	AdjustmentReasonGoodwill     = "goodwill"
+	AdjustmentReasonCorrection   = "correction"
+	AdjustmentReasonCompensation = "compensation"
+	AdjustmentReasonChargeback   = "chargeback"
+	AdjustmentReasonOther        = "other"
+)
+
+// WalletAdjustment is a manual credit or debit of a user's wallet made by an
+// admin. Adjustments above the approval threshold stay pending until a second
+// admin approves them
+type WalletAdjustment struct {
+	ID              uint64              `json:"id"`
+	UserID          uint64              `json:"user_id"`
+	Direction       AdjustmentDirection `json:"direction"`
+	Amount          float64             `json:"amount"`
+	ReasonCode      string              `json:"reason_code"`
+	Note            string              `json:"note"`
+	Status          AdjustmentStatus    `json:"status"`
+	RequestedBy     uint64              `json:"requested_by"`
+	ReviewedBy      uint64              `json:"reviewed_by"`
+	RejectionReason string              `json:"rejection_reason"`
+	JournalEntryID  uint64              `json:"journal_entry_id"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// SignedAmount is the amount moved into the wallet, negative for a debit
+func (a *WalletAdjustment) SignedAmount() float64 {
+	if a.Direction == AdjustmentDebit {
+		return -a.Amount
+	}
+
+	return a.Amount
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Wishlist is a named list of products a user parked outside the cart. A list
+// with a ShareToken can be read by anyone holding the token
+type Wishlist struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	Name       string    `json:"name"`
+	IsDefault  bool      `json:"is_default"`
+	ShareToken string    `json:"share_token,omitempty"`
+	ItemCount  int       `json:"item_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WishlistItem is a product saved in a list with its price at the time
+type WishlistItem struct {
+	ID         int       `json:"id"`
+	WishlistID int       `json:"wishlist_id"`
+	ProductID  int       `json:"product_id"`
+	Price      float64   `json:"price"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WishlistItemWithProduct is a saved product together with its current
+// product. Deleting a product takes it out of every list
+type WishlistItemWithProduct struct {
+	WishlistItem
+	Product *Product
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+type WithdrawalStatus string
+
+const (
+	WithdrawalRequested  WithdrawalStatus = "requested"
+	WithdrawalApproved   WithdrawalStatus = "approved"
+	WithdrawalProcessing WithdrawalStatus = "processing"
+	WithdrawalPaid       WithdrawalStatus = "paid"
+	WithdrawalRejected   WithdrawalStatus = "rejected"
+)
+
+// PayoutDestination is a bank account a user can withdraw to
+type PayoutDestination struct {
+	ID            uint64    `json:"id"`
+	UserID        uint64    `json:"user_id"`
+	BankName      string    `json:"bank_name"`
+	AccountNumber string    `json:"account_number"`
+	AccountName   string    `json:"account_name"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Withdrawal holds the requested amount out of the wallet until an admin
+// approves and pays it out, or rejects it and the funds are released
+type Withdrawal struct {
+	ID                uint64           `json:"id"`
+	UserID            uint64           `json:"user_id"`
+	DestinationID     uint64           `json:"destination_id"`
+	BankName          string           `json:"bank_name"`
+	AccountNumber     string           `json:"account_number"`
+	AccountName       string           `json:"account_name"`
+	Amount            float64          `json:"amount"`
+	Status            WithdrawalStatus `json:"status"`
+	ProviderReference string           `json:"provider_reference"`
+	RejectionReason   string           `json:"rejection_reason"`
+	ReviewedBy        uint64           `json:"reviewed_by"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
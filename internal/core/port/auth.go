@@ -18,6 +18,6 @@ type TokenInterface interface {
 }
 
 type AuthService interface {
-	Login(ctx context.Context, email, password string) (dto.LoginResponse, error)
+	Login(ctx context.Context, email, password, cartToken string) (dto.LoginResponse, error)
 	RefreshToken(ctx context.Context, refreshToken string) (string, error)
 }
@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
@@ -10,17 +11,55 @@ import (
 type BalanceRepository interface {
 	GetBalance(ctx context.Context, userID uint64) (float64, error)
 	Deposit(ctx context.Context, userID uint64, amount float64) error
-	Withdraw(ctx context.Context, userID uint64, amount float64) error
 	// Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64) error
-	Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64) (*domain.Balance, *domain.Balance, error)
+	Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64, referenceID uint64, idempotencyKey string, limit domain.TransferLimit) (*domain.Balance, *domain.Balance, error)
 	Store(ctx context.Context, data *domain.Balance) error
+	Pay(ctx context.Context, userID uint64, orderID int, amount float64) error
+	Reconcile(ctx context.Context) ([]domain.BalanceReconciliation, error)
+	Refund(ctx context.Context, userID uint64, orderID int, amount float64) error
+	Post(ctx context.Context, entry *domain.JournalEntry) error
+	RebuildBalances(ctx context.Context) (int64, error)
+	GetHeldBalance(ctx context.Context, userID uint64) (float64, error)
+	Hold(ctx context.Context, userID uint64, orderID int, amount float64, expiresAt time.Time) (*domain.BalanceHold, error)
+	CaptureHold(ctx context.Context, orderID int) error
+	ReleaseHold(ctx context.Context, orderID int) error
 }
 
 type BalanceService interface {
-	Withdraw(ctx context.Context, userID uint64, amount float64) error
 	// Deposit(ctx context.Context, userID uint64, amount float64) error
 	Deposit(ctx context.Context, userID uint64, amount float64) (*dto.DepositResponse, error)
 	// Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64) error
-	Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64) (*dto.TransferResponse, error)
+	Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64, referenceID uint64) (*dto.TransferResponse, error)
+	TransferOnce(ctx context.Context, fromUserID, toUserID uint64, amount float64, referenceID uint64, idempotencyKey string) (*dto.TransferResponse, error)
+	VerifyTransfer(ctx context.Context, userID uint64, recipientID uint64, amount float64, request dto.TransferRequest) error
 	CheckBalance(ctx context.Context, userID uint64) (dto.BalanceResponse, error)
+	Pay(ctx context.Context, userID uint64, orderID int, amount float64) error
+	Reconcile(ctx context.Context) ([]domain.BalanceReconciliation, error)
+	Refund(ctx context.Context, userID uint64, orderID int, amount float64) error
+	Hold(ctx context.Context, userID uint64, orderID int, amount float64, expiresAt time.Time) (*domain.BalanceHold, error)
+	CaptureHold(ctx context.Context, userID uint64, orderID int) error
+	ReleaseHold(ctx context.Context, userID uint64, orderID int) error
+	InquireTransfer(ctx context.Context, userID uint64, request dto.TransferInquiryRequest) (*dto.TransferInquiryResponse, error)
+	ConfirmTransfer(ctx context.Context, userID uint64, request dto.TransferRequest) (*dto.TransferConfirmationResponse, error)
+	ListTransferReviews(ctx context.Context, request dto.ListTransferReviewRequest) ([]domain.TransferReview, error)
+	ApproveTransferReview(ctx context.Context, adminID uint64, id uint64) (*dto.TransferResponse, error)
+	RejectTransferReview(ctx context.Context, adminID uint64, id uint64, request dto.RejectTransferReviewRequest) error
+	ListTransactions(ctx context.Context, userID uint64, request dto.ListBalanceTransactionRequest) (*dto.ListBalanceTransactionResponse, error)
+	ExportTransactions(ctx context.Context, userID uint64, request dto.ListBalanceTransactionRequest, fn func(domain.BalanceTransaction) error) error
+}
+
+// TransferInquiryRepository keeps the transfers waiting for the sender's
+// confirmation
+type TransferInquiryRepository interface {
+	Store(ctx context.Context, data *domain.TransferInquiry) error
+	FindOne(ctx context.Context, id string) (*domain.TransferInquiry, error)
+	AddAttempt(ctx context.Context, id string) (int, error)
+	Consume(ctx context.Context, id string) error
+}
+
+type TransferReviewRepository interface {
+	Store(ctx context.Context, data *domain.TransferReview) error
+	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.TransferReview, error)
+	FindOne(ctx context.Context, id uint64) (*domain.TransferReview, error)
+	UpdateStatus(ctx context.Context, id uint64, from, to domain.TransferReviewStatus, adminID uint64, note string) error
 }
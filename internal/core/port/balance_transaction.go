@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type BalanceTransactionRepository interface {
+	Finds(ctx context.Context, filter domain.BalanceTransactionFilter) ([]domain.BalanceTransaction, error)
+	Stream(ctx context.Context, filter domain.BalanceTransactionFilter, fn func(domain.BalanceTransaction) error) error
+}
@@ -8,9 +8,9 @@ import (
 type CacheInterface interface {
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 	Get(ctx context.Context, key string) ([]byte, error)
+	GetDel(ctx context.Context, key string) ([]byte, error)
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
 	Delete(ctx context.Context, key string) error
 	DeleteByPrefix(ctx context.Context, prefix string) error
 	Close() error
-	AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
-	ReleaseLock(ctx context.Context, key string) error
 }
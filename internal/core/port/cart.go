@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
@@ -11,13 +12,20 @@ type CartRepository interface {
 	FindOne(ctx context.Context, id int) (*domain.Cart, error)
 	Store(ctx context.Context, data *domain.Cart) error
 	Update(ctx context.Context, id int, updatedData domain.Cart) error
+	Delete(ctx context.Context, id int) error
 	DeleteByUserID(ctx context.Context, userID int) error
 	FindByUserID(ctx context.Context, userID int) (*domain.Cart, error)
+	FindByGuestID(ctx context.Context, guestID string) (*domain.Cart, error)
+	DeleteExpiredGuests(ctx context.Context, now time.Time) (int64, error)
 }
 
 type CartService interface {
-	GetCart(ctx context.Context, userID int) (dto.CartResponse, error)
-	AddToCart(ctx context.Context, userID int, productID int, quantity int) error
-	RemoveFromCart(ctx context.Context, userID int, productID int) error
-	UpdateCart(ctx context.Context, userID int, request dto.UpdateCartRequest) error
+	GetCart(ctx context.Context, owner domain.CartOwner) (dto.CartResponse, error)
+	AddToCart(ctx context.Context, owner domain.CartOwner, productID, variantID int, quantity int) (string, error)
+	RemoveFromCart(ctx context.Context, owner domain.CartOwner, productID, variantID int) error
+	UpdateCart(ctx context.Context, owner domain.CartOwner, request dto.UpdateCartRequest) error
+	BulkUpdateCart(ctx context.Context, owner domain.CartOwner, operations []dto.CartItemOperation) (string, []dto.CartLineError, error)
+	ClearCart(ctx context.Context, owner domain.CartOwner) error
+	MergeGuestCart(ctx context.Context, userID int, guestToken string) error
+	CleanupGuestCarts(ctx context.Context, now time.Time) (int64, error)
 }
@@ -14,4 +14,7 @@ type CartItemRepository interface {
 	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.CartItem, error)
 	FindOneByFilters(ctx context.Context, filter map[string]interface{}) (*domain.CartItem, error)
 	DeleteByProductID(ctx context.Context, product_id int) error
+	Delete(ctx context.Context, id int) error
+	FindsWithProducts(ctx context.Context, cartID int) ([]domain.CartItemWithProduct, error)
+	ApplyChanges(ctx context.Context, cartID int, upserts []domain.CartItem, removals []domain.CartLine) error
 }
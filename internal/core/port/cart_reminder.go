@@ -0,0 +1,22 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type CartReminderRepository interface {
+	FindIdleCarts(ctx context.Context, filter domain.IdleCartFilter) ([]domain.IdleCart, error)
+	Store(ctx context.Context, data *domain.CartReminder) error
+	Stats(ctx context.Context, since time.Time, window time.Duration) (*domain.CartReminderStats, error)
+	SetOptOut(ctx context.Context, userID int, optOut bool) error
+}
+
+type CartReminderService interface {
+	SendReminders(ctx context.Context, now time.Time) (int, error)
+	Stats(ctx context.Context, since time.Time) (*domain.CartReminderStats, error)
+	SetReminders(ctx context.Context, userID int, enabled bool) error
+	Unsubscribe(ctx context.Context, token string) error
+}
@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type CartSnapshotRepository interface {
+	Upsert(ctx context.Context, data *domain.CartSnapshot) error
+	MarkDeleted(ctx context.Context, cartID int, deletedAt time.Time) error
+}
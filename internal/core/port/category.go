@@ -8,7 +8,7 @@ import (
 )
 
 type CategoryRepository interface {
-	Finds(ctx context.Context, filter map[string]interface{}) (response []domain.Category, err error)
+	Finds(ctx context.Context, filter map[string]interface{}, page domain.PageRequest) (*domain.Page[domain.Category], error)
 	FindOne(ctx context.Context, id int) (response *domain.Category, err error)
 	Store(ctx context.Context, data *domain.Category) error
 	Update(ctx context.Context, id int, updatedData domain.Category) error
@@ -18,7 +18,7 @@ type CategoryRepository interface {
 type CategoryService interface {
 	FindOne(ctx context.Context, CategoryID int) (response *domain.Category, err error)
 	Store(ctx context.Context, data dto.CategoryRequest) error
-	Finds(ctx context.Context, param dto.ListCategoryRequest) (response []domain.Category, err error)
+	Finds(ctx context.Context, param dto.ListCategoryRequest) (*dto.PageResponse[dto.CategoryResponse], error)
 	Update(ctx context.Context, id int, data dto.CategoryRequest) error
 	Delete(ctx context.Context, id int) error
 }
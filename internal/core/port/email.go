@@ -0,0 +1,5 @@
+package port
+
+type EmailSender interface {
+	SendEmail(payload interface{}, pathTemplate string, to []string, subject string) error
+}
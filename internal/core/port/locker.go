@@ -0,0 +1,42 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+// LockOptions controls how Obtain waits for a contended lock and whether the
+// lock keeps itself alive while it is held
+type LockOptions struct {
+	// RetryCount is how many more attempts are made after the first one fails
+	RetryCount int
+	// RetryBackoff is the wait before the first retry, it doubles after each
+	// attempt up to MaxBackoff
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	// AutoRenew refreshes the lock in the background until it is released, so
+	// an operation that outlives the TTL keeps its lock
+	AutoRenew bool
+}
+
+// Locker hands out distributed locks, a lock obtained by one caller can't be
+// extended or released by another
+type Locker interface {
+	// Obtain acquires the lock on key for ttl, it returns consts.ErrLockNotObtained
+	// once every attempt found the lock held by someone else
+	Obtain(ctx context.Context, key string, ttl time.Duration, opts LockOptions) (Lock, error)
+}
+
+// Lock is a held lock identified by a random token
+type Lock interface {
+	Key() string
+	Token() string
+	// TTL returns the time left on the lock, zero once it is no longer held
+	TTL(ctx context.Context) (time.Duration, error)
+	// Refresh extends the lock to ttl from now, it returns consts.ErrLockNotHeld
+	// when the lock expired or was taken over
+	Refresh(ctx context.Context, ttl time.Duration) error
+	// Release deletes the lock only while it still holds the token, it returns
+	// consts.ErrLockNotHeld when the lock expired or was taken over
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type MoneyRequestRepository interface {
+	Store(ctx context.Context, data *domain.MoneyRequest) error
+	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.MoneyRequest, error)
+	FindOne(ctx context.Context, id uint64) (*domain.MoneyRequest, error)
+	ClosePayer(ctx context.Context, requestID, payerID uint64, status domain.MoneyRequestPayerStatus) error
+	ReopenPayer(ctx context.Context, requestID, payerID uint64) error
+	Complete(ctx context.Context, id uint64) error
+	Cancel(ctx context.Context, id uint64) error
+}
+
+type MoneyRequestService interface {
+	Create(ctx context.Context, userID uint64, request dto.MoneyRequestRequest) (*domain.MoneyRequest, error)
+	ListSent(ctx context.Context, userID uint64, request dto.ListMoneyRequestRequest) ([]domain.MoneyRequest, error)
+	ListReceived(ctx context.Context, userID uint64, request dto.ListMoneyRequestRequest) ([]domain.MoneyRequest, error)
+	Get(ctx context.Context, userID uint64, id uint64) (*domain.MoneyRequest, error)
+	Pay(ctx context.Context, userID uint64, id uint64, request dto.PayMoneyRequestRequest) (*dto.TransferResponse, error)
+	Decline(ctx context.Context, userID uint64, id uint64) error
+	Cancel(ctx context.Context, userID uint64, id uint64) error
+}
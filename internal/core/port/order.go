@@ -3,6 +3,7 @@ package port
 import (
 	"context"
 
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 )
 
@@ -12,11 +13,11 @@ type OrderRepository interface {
 	Store(ctx context.Context, data *domain.Order) error
 	Update(ctx context.Context, id int, updatedData *domain.Order) error
 	Delete(ctx context.Context, id int) error
-	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.Order, error)
+	Finds(ctx context.Context, filter map[string]interface{}, page domain.PageRequest) (*domain.Page[domain.Order], error)
 }
 
 type OrderService interface {
 	UpdateStatusOrder(ctx context.Context, orderID int, status string) error
-	ListOrders(ctx context.Context, userId int) ([]domain.Order, error)
+	ListOrders(ctx context.Context, userId int, param dto.ListOrderRequest) (*dto.PageResponse[domain.Order], error)
 	GetOrder(ctx context.Context, orderID int, userID int) (*domain.Order, error)
 }
@@ -11,6 +11,7 @@ type OrderItemRepository interface {
 	Finds(ctx context.Context, filter map[string]interface{}) (response []domain.OrderItem, err error)
 	FindOne(ctx context.Context, id int) (response *domain.OrderItem, err error)
 	Store(ctx context.Context, data *domain.OrderItem) error
+	StoreBatch(ctx context.Context, items []domain.OrderItem) error
 	Update(ctx context.Context, id int, updatedData domain.OrderItem) error
 	Delete(ctx context.Context, id int) error
 }
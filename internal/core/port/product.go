@@ -8,18 +8,22 @@ import (
 )
 
 type ProductRepository interface {
-	Finds(ctx context.Context, filter map[string]interface{}) (response []domain.Product, err error)
+	Finds(ctx context.Context, filter map[string]interface{}, page domain.PageRequest) (*domain.Page[domain.Product], error)
 	FindOne(ctx context.Context, id int) (response *domain.Product, err error)
 	Store(ctx context.Context, data *domain.Product) error
 	Update(ctx context.Context, id int, updatedData domain.Product) error
 	Delete(ctx context.Context, id int) error
 	UpdateStock(ctx context.Context, id, newStock int) error
+	FindByIDs(ctx context.Context, ids []int) ([]domain.Product, error)
+	UpdateStocks(ctx context.Context, stocks map[int]int) error
+	Search(ctx context.Context, search domain.ProductSearch) (*domain.ProductSearchResult, error)
 }
 
 type ProductService interface {
 	FindOne(ctx context.Context, productID int) (response *domain.Product, err error)
 	Store(ctx context.Context, data dto.ProductRequest) error
-	Finds(ctx context.Context, param dto.ListProductRequest) (response []domain.Product, err error)
+	Finds(ctx context.Context, param dto.ListProductRequest) (*dto.PageResponse[dto.ProductResponse], error)
 	Update(ctx context.Context, id int, data dto.ProductRequest) error
 	Delete(ctx context.Context, id int) error
+	Search(ctx context.Context, param dto.SearchProductRequest) (*dto.SearchProductResponse, error)
 }
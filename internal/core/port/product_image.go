@@ -0,0 +1,30 @@
+package port
+
+import (
+	"context"
+	"mime/multipart"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type ProductImageRepository interface {
+	FindOne(ctx context.Context, id int) (*domain.ProductImage, error)
+	FindByProductIDs(ctx context.Context, productIDs []int) ([]domain.ProductImage, error)
+	Store(ctx context.Context, data *domain.ProductImage) error
+	Delete(ctx context.Context, productID, id int) error
+	SetPrimary(ctx context.Context, productID, id int) error
+	Reorder(ctx context.Context, productID int, ids []int) error
+	UpdateDerivatives(ctx context.Context, id int, derivatives map[domain.ImageSize]domain.ImageDerivative, status string) error
+}
+
+type ProductImageService interface {
+	ListImages(ctx context.Context, productID int) ([]domain.ProductImage, error)
+	UploadImage(ctx context.Context, productID int, file multipart.File, size int64) (*domain.ProductImage, error)
+	CreateUploadURL(ctx context.Context, productID int, request dto.ProductImageUploadURLRequest) (*dto.ProductImageUploadURLResponse, error)
+	ConfirmUpload(ctx context.Context, productID int, request dto.ConfirmProductImageRequest) (*domain.ProductImage, error)
+	DeleteImage(ctx context.Context, productID, imageID int) error
+	SetPrimary(ctx context.Context, productID, imageID int) error
+	ReorderImages(ctx context.Context, productID int, request dto.ReorderProductImagesRequest) error
+	GenerateDerivatives(ctx context.Context, imageID int) error
+}
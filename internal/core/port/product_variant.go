@@ -0,0 +1,32 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type ProductVariantRepository interface {
+	FindOptions(ctx context.Context, productID int) ([]domain.ProductOption, error)
+	StoreOption(ctx context.Context, data *domain.ProductOption) error
+	DeleteOption(ctx context.Context, productID, id int) error
+	FindOne(ctx context.Context, id int) (*domain.ProductVariant, error)
+	FindByProductIDs(ctx context.Context, productIDs []int) ([]domain.ProductVariant, error)
+	Store(ctx context.Context, data *domain.ProductVariant) error
+	Update(ctx context.Context, id int, updatedData domain.ProductVariant) error
+	Delete(ctx context.Context, id int) error
+	UpdateStock(ctx context.Context, id, newStock int) error
+	UpdateStocks(ctx context.Context, stocks map[int]int) error
+}
+
+type ProductVariantService interface {
+	ListOptions(ctx context.Context, productID int) ([]domain.ProductOption, error)
+	CreateOption(ctx context.Context, productID int, request dto.ProductOptionRequest) (*domain.ProductOption, error)
+	DeleteOption(ctx context.Context, productID, optionID int) error
+	ListVariants(ctx context.Context, productID int) ([]dto.ProductVariantResponse, error)
+	GetVariant(ctx context.Context, productID, variantID int) (*dto.ProductVariantResponse, error)
+	CreateVariant(ctx context.Context, productID int, request dto.ProductVariantRequest) (*dto.ProductVariantResponse, error)
+	UpdateVariant(ctx context.Context, productID, variantID int, request dto.ProductVariantRequest) error
+	DeleteVariant(ctx context.Context, productID, variantID int) error
+}
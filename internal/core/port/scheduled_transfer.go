@@ -0,0 +1,29 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type ScheduledTransferRepository interface {
+	Store(ctx context.Context, data *domain.ScheduledTransfer) error
+	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.ScheduledTransfer, error)
+	FindOne(ctx context.Context, id uint64) (*domain.ScheduledTransfer, error)
+	Update(ctx context.Context, data *domain.ScheduledTransfer) error
+	ClaimDue(ctx context.Context, now time.Time, limit uint64, lease time.Duration) ([]domain.ScheduledTransfer, error)
+	Release(ctx context.Context, id uint64) error
+	RecordRun(ctx context.Context, schedule *domain.ScheduledTransfer, run *domain.ScheduledTransferRun) error
+	FindRuns(ctx context.Context, scheduleID uint64) ([]domain.ScheduledTransferRun, error)
+}
+
+type ScheduledTransferService interface {
+	Create(ctx context.Context, userID uint64, request dto.ScheduledTransferRequest) (*domain.ScheduledTransfer, error)
+	List(ctx context.Context, userID uint64, request dto.ListScheduledTransferRequest) ([]domain.ScheduledTransfer, error)
+	Get(ctx context.Context, userID uint64, id uint64) (*dto.ScheduledTransferDetailResponse, error)
+	Update(ctx context.Context, userID uint64, id uint64, request dto.UpdateScheduledTransferRequest) (*domain.ScheduledTransfer, error)
+	Cancel(ctx context.Context, userID uint64, id uint64) error
+	RunDue(ctx context.Context, now time.Time) error
+}
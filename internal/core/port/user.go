@@ -11,7 +11,8 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) (*domain.User, error)
 	GetUserByID(ctx context.Context, id uint64) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
-	ListUsers(ctx context.Context, skip, limit uint64) ([]domain.User, error)
+	GetUserByPhone(ctx context.Context, phone string) (*domain.User, error)
+	ListUsers(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.User], error)
 	UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error)
 	DeleteUser(ctx context.Context, id uint64) error
 	GetUserByToken(ctx context.Context, token string) (*domain.User, error)
@@ -21,7 +22,7 @@ type UserRepository interface {
 type UserService interface {
 	Register(ctx context.Context, user *domain.User) (*domain.User, error)
 	GetUser(ctx context.Context, id uint64) (*domain.User, error)
-	ListUsers(ctx context.Context, skip, limit uint64) ([]domain.User, error)
+	ListUsers(ctx context.Context, param dto.ListUserRequest) (*dto.PageResponse[dto.UserResponse], error)
 	UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error)
 	DeleteUser(ctx context.Context, id uint64) error
 	GetProfile(ctx context.Context, id uint64) (*dto.GetProfile, error)
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type WalletAdjustmentRepository interface {
+	Store(ctx context.Context, data *domain.WalletAdjustment) error
+	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.WalletAdjustment, error)
+	FindOne(ctx context.Context, id uint64) (*domain.WalletAdjustment, error)
+	Approve(ctx context.Context, id uint64, adminID uint64) error
+	Reject(ctx context.Context, id uint64, adminID uint64, reason string) error
+}
+
+type WalletAdjustmentService interface {
+	Create(ctx context.Context, adminID uint64, request dto.WalletAdjustmentRequest) (*domain.WalletAdjustment, error)
+	List(ctx context.Context, request dto.ListWalletAdjustmentRequest) ([]domain.WalletAdjustment, error)
+	Get(ctx context.Context, id uint64) (*domain.WalletAdjustment, error)
+	Approve(ctx context.Context, adminID uint64, id uint64) (*domain.WalletAdjustment, error)
+	Reject(ctx context.Context, adminID uint64, id uint64, request dto.RejectWalletAdjustmentRequest) (*domain.WalletAdjustment, error)
+}
@@ -0,0 +1,38 @@
+package port
+
+import (
+	"context"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type WishlistRepository interface {
+	FindOne(ctx context.Context, id int) (*domain.Wishlist, error)
+	FindByUserID(ctx context.Context, userID int) ([]domain.Wishlist, error)
+	FindDefault(ctx context.Context, userID int) (*domain.Wishlist, error)
+	FindByShareToken(ctx context.Context, token string) (*domain.Wishlist, error)
+	Store(ctx context.Context, data *domain.Wishlist) error
+	Update(ctx context.Context, id int, updatedData domain.Wishlist) error
+	Delete(ctx context.Context, id int) error
+	FindItem(ctx context.Context, wishlistID, productID int) (*domain.WishlistItem, error)
+	FindItemsWithProducts(ctx context.Context, wishlistID int) ([]domain.WishlistItemWithProduct, error)
+	AddItem(ctx context.Context, data *domain.WishlistItem) error
+	RemoveItem(ctx context.Context, wishlistID, productID int) error
+	FindUserIDsByProductID(ctx context.Context, productID int) ([]int, error)
+}
+
+type WishlistService interface {
+	List(ctx context.Context, userID int) ([]domain.Wishlist, error)
+	Create(ctx context.Context, userID int, request dto.WishlistRequest) (*domain.Wishlist, error)
+	Get(ctx context.Context, userID, id int) (*dto.WishlistResponse, error)
+	Rename(ctx context.Context, userID, id int, request dto.WishlistRequest) error
+	Delete(ctx context.Context, userID, id int) error
+	AddItem(ctx context.Context, userID, id int, productID int) error
+	RemoveItem(ctx context.Context, userID, id int, productID int) error
+	MoveToCart(ctx context.Context, userID, id int, productID, variantID int, quantity int) error
+	SaveForLater(ctx context.Context, userID int, request dto.SaveForLaterRequest) (*domain.Wishlist, error)
+	Share(ctx context.Context, userID, id int) (string, error)
+	Unshare(ctx context.Context, userID, id int) error
+	GetShared(ctx context.Context, token string) (*dto.WishlistResponse, error)
+}
@@ -0,0 +1,45 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+)
+
+type PayoutDestinationRepository interface {
+	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.PayoutDestination, error)
+	FindOne(ctx context.Context, id uint64) (*domain.PayoutDestination, error)
+	Store(ctx context.Context, data *domain.PayoutDestination) error
+	Delete(ctx context.Context, id uint64) error
+}
+
+type WithdrawalRepository interface {
+	Store(ctx context.Context, data *domain.Withdrawal) error
+	Finds(ctx context.Context, filter map[string]interface{}) ([]domain.Withdrawal, error)
+	FindOne(ctx context.Context, id uint64) (*domain.Withdrawal, error)
+	Approve(ctx context.Context, id uint64, adminID uint64) error
+	ClaimPayout(ctx context.Context, id uint64, staleBefore time.Time) error
+	ReleasePayout(ctx context.Context, id uint64) error
+	MarkPaid(ctx context.Context, id uint64, providerReference string) error
+	Reject(ctx context.Context, id uint64, adminID uint64, reason string) error
+}
+
+// PayoutProvider sends an approved withdrawal to the user's bank account and
+// returns the provider's reference for it. A payout sent again with the same
+// idempotency key returns the first one instead of paying twice
+type PayoutProvider interface {
+	Payout(ctx context.Context, withdrawal domain.Withdrawal, idempotencyKey string) (string, error)
+}
+
+type WithdrawalService interface {
+	AddDestination(ctx context.Context, userID uint64, request dto.PayoutDestinationRequest) (*dto.PayoutDestinationResponse, error)
+	ListDestinations(ctx context.Context, userID uint64) ([]dto.PayoutDestinationResponse, error)
+	DeleteDestination(ctx context.Context, userID uint64, id uint64) error
+	Withdraw(ctx context.Context, userID uint64, request dto.WithdrawRequest) (*dto.WithdrawalResponse, error)
+	ListWithdrawals(ctx context.Context, userID uint64, request dto.ListWithdrawalRequest) ([]dto.WithdrawalResponse, error)
+	ListAllWithdrawals(ctx context.Context, request dto.ListWithdrawalRequest) ([]dto.WithdrawalResponse, error)
+	Approve(ctx context.Context, adminID uint64, id uint64) (*dto.WithdrawalResponse, error)
+	Reject(ctx context.Context, adminID uint64, id uint64, request dto.RejectWithdrawalRequest) (*dto.WithdrawalResponse, error)
+}
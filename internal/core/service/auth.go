@@ -11,20 +11,25 @@ import (
 )
 
 type AuthService struct {
-	repo port.UserRepository
-	ts   port.TokenInterface
-	log  *zap.Logger
+	repo    port.UserRepository
+	ts      port.TokenInterface
+	cartSvc port.CartService
+	log     *zap.Logger
 }
 
-func NewAuthService(repo port.UserRepository, ts port.TokenInterface, log *zap.Logger) *AuthService {
+func NewAuthService(repo port.UserRepository, ts port.TokenInterface, cartSvc port.CartService, log *zap.Logger) *AuthService {
 	return &AuthService{
 		repo,
 		ts,
+		cartSvc,
 		log,
 	}
 }
 
-func (as *AuthService) Login(ctx context.Context, email, password string) (dto.LoginResponse, error) {
+// Login signs the user in and merges the guest cart of cartToken, if any, into
+// their cart. A failed merge is logged and leaves the guest cart in place, it
+// never fails the login
+func (as *AuthService) Login(ctx context.Context, email, password, cartToken string) (dto.LoginResponse, error) {
 	user, err := as.repo.GetUserByEmail(ctx, email)
 	if err != nil {
 		as.log.Error(err.Error())
@@ -52,6 +57,12 @@ func (as *AuthService) Login(ctx context.Context, email, password string) (dto.L
 		return dto.LoginResponse{}, consts.ErrTokenCreation
 	}
 
+	if cartToken != "" {
+		if err := as.cartSvc.MergeGuestCart(ctx, int(user.ID), cartToken); err != nil {
+			as.log.Error("Failed to merge guest cart", zap.Uint64("user_id", user.ID), zap.Error(err))
+		}
+	}
+
 	return dto.LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
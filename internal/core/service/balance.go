@@ -2,52 +2,136 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
 	"time"
 
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
 	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"github.com/google/uuid"
 )
 
 type BalanceService struct {
-	repo  port.BalanceRepository
-	redis port.CacheInterface
+	repo            port.BalanceRepository
+	transactionRepo port.BalanceTransactionRepository
+	userRepo        port.UserRepository
+	reviewRepo      port.TransferReviewRepository
+	orderRepo       port.OrderRepository
+	paymentRepo     port.PaymentRepository
+	inquiryRepo     port.TransferInquiryRepository
+	locker          port.Locker
+	mailer          port.EmailSender
+	rules           *config.Business
 }
 
-func NewBalanceService(repo port.BalanceRepository, redis port.CacheInterface) *BalanceService {
-	return &BalanceService{repo: repo, redis: redis}
+func NewBalanceService(repo port.BalanceRepository, transactionRepo port.BalanceTransactionRepository, userRepo port.UserRepository, reviewRepo port.TransferReviewRepository, orderRepo port.OrderRepository, paymentRepo port.PaymentRepository, inquiryRepo port.TransferInquiryRepository, locker port.Locker, mailer port.EmailSender, rules *config.Business) *BalanceService {
+	return &BalanceService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+		reviewRepo:      reviewRepo,
+		orderRepo:       orderRepo,
+		paymentRepo:     paymentRepo,
+		inquiryRepo:     inquiryRepo,
+		locker:          locker,
+		mailer:          mailer,
+		rules:           rules,
+	}
 }
 
-func (bs *BalanceService) Withdraw(ctx context.Context, userID uint64, amount float64) error {
-	lockKey := fmt.Sprintf("balance_lock:%d", userID)
-	lockTTL := 5 * time.Second
+// maxOTPAttempts is how many codes a transfer inquiry accepts before it is discarded
+const maxOTPAttempts = 3
 
-	acquired, err := bs.redis.AcquireLock(ctx, lockKey, lockTTL)
+// Pay debits the wallet for an order and records the order ID in the ledger
+func (bs *BalanceService) Pay(ctx context.Context, userID uint64, orderID int, amount float64) error {
+	unlock, err := lockWallets(ctx, bs.locker, bs.rules, userID)
 	if err != nil {
 		return err
 	}
-	if !acquired {
-		return errors.New("another transaction is in progress, try again later")
-	}
-	defer bs.redis.ReleaseLock(ctx, lockKey)
+	defer unlock()
 
-	return bs.repo.Withdraw(ctx, userID, amount)
+	return bs.repo.Pay(ctx, userID, orderID, amount)
 }
 
-func (bs *BalanceService) Deposit(ctx context.Context, userID uint64, amount float64) (*dto.DepositResponse, error) {
-	lockKey := fmt.Sprintf("balance_lock:%d", userID)
-	lockTTL := 5 * time.Second
+// Refund credits an order amount back to the wallet out of platform revenue,
+// only orders of the user paid from the wallet can be refunded and never for
+// more than was paid
+func (bs *BalanceService) Refund(ctx context.Context, userID uint64, orderID int, amount float64) error {
+	order, err := bs.orderRepo.FindOne(ctx, orderID, int(userID))
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return consts.ErrDataNotFound
+	}
+
+	payment, err := bs.paymentRepo.FindByUserIDandOrderID(ctx, int(userID), orderID)
+	if err != nil {
+		return err
+	}
+	if payment == nil || payment.PaymentMethod != "balance" || payment.PaymentStatus != "completed" {
+		return consts.ErrOrderNotPaidByWallet
+	}
+
+	unlock, err := lockWallets(ctx, bs.locker, bs.rules, userID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	acquired, err := bs.redis.AcquireLock(ctx, lockKey, lockTTL)
+	return bs.repo.Refund(ctx, userID, orderID, amount)
+}
+
+// Hold authorizes the order total against the available balance until the order is paid or released
+func (bs *BalanceService) Hold(ctx context.Context, userID uint64, orderID int, amount float64, expiresAt time.Time) (*domain.BalanceHold, error) {
+	unlock, err := lockWallets(ctx, bs.locker, bs.rules, userID)
 	if err != nil {
 		return nil, err
 	}
-	if !acquired {
-		return nil, errors.New("another transaction is in progress, try again later")
+	defer unlock()
+
+	return bs.repo.Hold(ctx, userID, orderID, amount, expiresAt)
+}
+
+// CaptureHold pays the order with the funds held at checkout
+func (bs *BalanceService) CaptureHold(ctx context.Context, userID uint64, orderID int) error {
+	unlock, err := lockWallets(ctx, bs.locker, bs.rules, userID)
+	if err != nil {
+		return err
 	}
-	defer bs.redis.ReleaseLock(ctx, lockKey)
+	defer unlock()
+
+	return bs.repo.CaptureHold(ctx, orderID)
+}
+
+// ReleaseHold returns the funds held for the order to the available balance
+func (bs *BalanceService) ReleaseHold(ctx context.Context, userID uint64, orderID int) error {
+	unlock, err := lockWallets(ctx, bs.locker, bs.rules, userID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return bs.repo.ReleaseHold(ctx, orderID)
+}
+
+func (bs *BalanceService) Deposit(ctx context.Context, userID uint64, amount float64) (*dto.DepositResponse, error) {
+	unlock, err := lockWallets(ctx, bs.locker, bs.rules, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	err = bs.repo.Deposit(ctx, userID, amount)
 	if err != nil {
@@ -61,7 +145,16 @@ func (bs *BalanceService) Deposit(ctx context.Context, userID uint64, amount flo
 
 	return &dto.DepositResponse{Balance: amount}, nil
 }
-func (bs *BalanceService) Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64) (*dto.TransferResponse, error) {
+
+// Transfer moves funds between two wallets, referenceID is recorded on the
+// ledger entry and is zero for plain transfers
+func (bs *BalanceService) Transfer(ctx context.Context, fromUserID, toUserID uint64, amount float64, referenceID uint64) (*dto.TransferResponse, error) {
+	return bs.TransferOnce(ctx, fromUserID, toUserID, amount, referenceID, "")
+}
+
+// TransferOnce is Transfer for callers that may retry it, a transfer whose
+// idempotency key was already posted returns ErrAlreadyPosted
+func (bs *BalanceService) TransferOnce(ctx context.Context, fromUserID, toUserID uint64, amount float64, referenceID uint64, idempotencyKey string) (*dto.TransferResponse, error) {
 
 	// Validate input parameters
 	if amount <= 0 {
@@ -72,33 +165,19 @@ func (bs *BalanceService) Transfer(ctx context.Context, fromUserID, toUserID uin
 		return nil, consts.ErrCannotSendBalanceSameAccount
 	}
 
-	// Acquire locks in a consistent order to prevent deadlocks
-	// Always lock the lower ID first to prevent potential deadlock scenarios
-	firstLockID, secondLockID := fromUserID, toUserID
-	if toUserID < fromUserID {
-		firstLockID, secondLockID = toUserID, fromUserID
-	}
-
-	firstLockKey := fmt.Sprintf("balance_lock:%d", firstLockID)
-	secondLockKey := fmt.Sprintf("balance_lock:%d", secondLockID)
-	lockTTL := 5 * time.Second
-
-	// Acquire first lock
-	firstAcquired, err := bs.redis.AcquireLock(ctx, firstLockKey, lockTTL)
-	if err != nil || !firstAcquired {
-		return nil, errors.New("unable to lock first account, try again later")
+	unlock, err := lockWallets(ctx, bs.locker, bs.rules, fromUserID, toUserID)
+	if err != nil {
+		return nil, err
 	}
-	defer bs.redis.ReleaseLock(ctx, firstLockKey)
+	defer unlock()
 
-	// Acquire second lock
-	secondAcquired, err := bs.redis.AcquireLock(ctx, secondLockKey, lockTTL)
-	if err != nil || !secondAcquired {
-		return nil, errors.New("unable to lock second account, try again later")
+	sender, err := bs.userRepo.GetUserByID(ctx, fromUserID)
+	if err != nil {
+		return nil, err
 	}
-	defer bs.redis.ReleaseLock(ctx, secondLockKey)
 
 	// Now perform the actual transfer
-	fromBalance, toBalance, err := bs.repo.Transfer(ctx, fromUserID, toUserID, amount)
+	fromBalance, toBalance, err := bs.repo.Transfer(ctx, fromUserID, toUserID, amount, referenceID, idempotencyKey, bs.transferLimitFor(sender))
 	if err != nil {
 		return nil, err
 	}
@@ -124,5 +203,359 @@ func (bs *BalanceService) CheckBalance(ctx context.Context, userID uint64) (dto.
 		return dto.BalanceResponse{}, err
 	}
 
-	return dto.BalanceResponse{Balance: balance}, nil
+	held, err := bs.repo.GetHeldBalance(ctx, userID)
+	if err != nil {
+		return dto.BalanceResponse{}, err
+	}
+
+	return dto.BalanceResponse{Balance: balance, HeldBalance: held}, nil
+}
+
+// InquireTransfer resolves the recipient and prepares a transfer for the sender to
+// confirm, it emails a verification code when the amount needs a second factor
+func (bs *BalanceService) InquireTransfer(ctx context.Context, userID uint64, request dto.TransferInquiryRequest) (*dto.TransferInquiryResponse, error) {
+	recipient, err := bs.findRecipient(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == userID {
+		return nil, consts.ErrCannotSendBalanceSameAccount
+	}
+
+	sender, err := bs.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := bs.transferLimitFor(sender)
+	if limit.PerTransaction > 0 && request.Amount > limit.PerTransaction {
+		return nil, consts.ErrTransferAmountLimitExceeded
+	}
+
+	inquiry := &domain.TransferInquiry{
+		ID:            uuid.NewString(),
+		SenderID:      userID,
+		RecipientID:   recipient.ID,
+		RecipientName: util.MaskName(recipient.Name),
+		Amount:        request.Amount,
+		Verification:  domain.TransferVerification(bs.rules.TransferVerificationFor(request.Amount)),
+		ExpiresAt:     time.Now().Add(bs.rules.TransferInquiryTTL),
+	}
+
+	if inquiry.Verification == domain.TransferVerificationOTP {
+		code, err := generateOTP()
+		if err != nil {
+			return nil, err
+		}
+		inquiry.OTPHash = hashOTP(inquiry.ID, code)
+
+		payload := map[string]interface{}{
+			"Name":          sender.Name,
+			"Amount":        strconv.FormatFloat(inquiry.Amount, 'f', 2, 64),
+			"RecipientName": inquiry.RecipientName,
+			"Code":          code,
+			"ExpiresAt":     inquiry.ExpiresAt.Format(time.RFC1123),
+		}
+		if err := bs.mailer.SendEmail(payload, consts.TemplateTransferOTP, []string{sender.Email}, "Confirm your transfer"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bs.saveInquiry(ctx, inquiry); err != nil {
+		return nil, err
+	}
+
+	response := dto.NewTransferInquiryResponse(*inquiry)
+	return &response, nil
+}
+
+// ConfirmTransfer executes a confirmed inquiry, or queues it for an admin when
+// the amount is above the review threshold
+func (bs *BalanceService) ConfirmTransfer(ctx context.Context, userID uint64, request dto.TransferRequest) (*dto.TransferConfirmationResponse, error) {
+	inquiry, err := bs.findInquiry(ctx, request.InquiryID)
+	if err != nil {
+		return nil, err
+	}
+	if inquiry.SenderID != userID {
+		return nil, consts.ErrTransferInquiryNotFound
+	}
+
+	if err := bs.consumeInquiry(ctx, inquiry, request.OTP); err != nil {
+		return nil, err
+	}
+
+	if inquiry.Verification == domain.TransferVerificationReview {
+		review := &domain.TransferReview{
+			SenderID:    inquiry.SenderID,
+			RecipientID: inquiry.RecipientID,
+			Amount:      inquiry.Amount,
+		}
+		if err := bs.reviewRepo.Store(ctx, review); err != nil {
+			return nil, err
+		}
+
+		return &dto.TransferConfirmationResponse{Status: "pending_review", Review: review}, nil
+	}
+
+	transfer, err := bs.Transfer(ctx, inquiry.SenderID, inquiry.RecipientID, inquiry.Amount, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TransferConfirmationResponse{Status: "completed", Transfer: transfer}, nil
+}
+
+// VerifyTransfer checks a transfer made outside the inquiry flow against a
+// confirmed inquiry of the same recipient and amount, and consumes it
+func (bs *BalanceService) VerifyTransfer(ctx context.Context, userID uint64, recipientID uint64, amount float64, request dto.TransferRequest) error {
+	inquiry, err := bs.findInquiry(ctx, request.InquiryID)
+	if err != nil {
+		return err
+	}
+	if inquiry.SenderID != userID {
+		return consts.ErrTransferInquiryNotFound
+	}
+
+	if inquiry.RecipientID != recipientID || math.Round(inquiry.Amount*100) != math.Round(amount*100) {
+		return consts.ErrTransferInquiryMismatch
+	}
+
+	return bs.consumeInquiry(ctx, inquiry, request.OTP)
+}
+
+// consumeInquiry checks the code of an inquiry and removes it, an inquiry can
+// only be used once and only one of concurrent callers consumes it
+func (bs *BalanceService) consumeInquiry(ctx context.Context, inquiry *domain.TransferInquiry, otp string) error {
+	if inquiry.Verification == domain.TransferVerificationOTP {
+		// every attempt is counted before the code is compared, so parallel
+		// guesses cannot get past the limit
+		attempts, err := bs.inquiryRepo.AddAttempt(ctx, inquiry.ID)
+		if err != nil {
+			return err
+		}
+
+		if attempts > maxOTPAttempts {
+			_ = bs.inquiryRepo.Consume(ctx, inquiry.ID)
+			return consts.ErrTransferInquiryNotFound
+		}
+
+		if hashOTP(inquiry.ID, otp) != inquiry.OTPHash {
+			if attempts == maxOTPAttempts {
+				_ = bs.inquiryRepo.Consume(ctx, inquiry.ID)
+			}
+			return consts.ErrInvalidOTP
+		}
+	}
+
+	return bs.inquiryRepo.Consume(ctx, inquiry.ID)
+}
+
+func (bs *BalanceService) ListTransferReviews(ctx context.Context, request dto.ListTransferReviewRequest) ([]domain.TransferReview, error) {
+	filter := make(map[string]interface{})
+	if request.Status != "" {
+		filter["status"] = request.Status
+	}
+
+	return bs.reviewRepo.Finds(ctx, filter)
+}
+
+// ApproveTransferReview executes a reviewed transfer, a transfer that fails is
+// closed as failed with the reason in its note
+func (bs *BalanceService) ApproveTransferReview(ctx context.Context, adminID uint64, id uint64) (*dto.TransferResponse, error) {
+	review, err := bs.reviewRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	err = bs.reviewRepo.UpdateStatus(ctx, id, domain.TransferReviewPending, domain.TransferReviewApproved, adminID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := bs.Transfer(ctx, review.SenderID, review.RecipientID, review.Amount, 0)
+	if err != nil {
+		if updateErr := bs.reviewRepo.UpdateStatus(ctx, id, domain.TransferReviewApproved, domain.TransferReviewFailed, adminID, err.Error()); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (bs *BalanceService) RejectTransferReview(ctx context.Context, adminID uint64, id uint64, request dto.RejectTransferReviewRequest) error {
+	review, err := bs.reviewRepo.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+	if review == nil {
+		return consts.ErrDataNotFound
+	}
+
+	return bs.reviewRepo.UpdateStatus(ctx, id, domain.TransferReviewPending, domain.TransferReviewRejected, adminID, request.Note)
+}
+
+// Reconcile lists the balances that do not match the sum of their ledger movements
+func (bs *BalanceService) Reconcile(ctx context.Context) ([]domain.BalanceReconciliation, error) {
+	return bs.repo.Reconcile(ctx)
+}
+
+// ListTransactions returns a page of the user's ledger, newest first
+func (bs *BalanceService) ListTransactions(ctx context.Context, userID uint64, request dto.ListBalanceTransactionRequest) (*dto.ListBalanceTransactionResponse, error) {
+	filter, err := newBalanceTransactionFilter(userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Cursor != "" {
+		filter.BeforeID, err = decodeCursor(request.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// fetch one extra row to know whether there is a next page
+	filter.Limit = limit + 1
+
+	transactions, err := bs.transactionRepo.Finds(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListBalanceTransactionResponse{
+		Items: make([]dto.BalanceTransactionResponse, 0, len(transactions)),
+	}
+
+	if uint64(len(transactions)) > limit {
+		transactions = transactions[:limit]
+		response.NextCursor = encodeCursor(transactions[len(transactions)-1].ID)
+	}
+
+	for _, transaction := range transactions {
+		response.Items = append(response.Items, dto.NewBalanceTransactionResponse(transaction))
+	}
+
+	return response, nil
+}
+
+// ExportTransactions streams every ledger row matching the request to fn
+func (bs *BalanceService) ExportTransactions(ctx context.Context, userID uint64, request dto.ListBalanceTransactionRequest, fn func(domain.BalanceTransaction) error) error {
+	filter, err := newBalanceTransactionFilter(userID, request)
+	if err != nil {
+		return err
+	}
+
+	return bs.transactionRepo.Stream(ctx, filter, fn)
+}
+
+func newBalanceTransactionFilter(userID uint64, request dto.ListBalanceTransactionRequest) (domain.BalanceTransactionFilter, error) {
+	filter := domain.BalanceTransactionFilter{
+		UserID:          userID,
+		TransactionType: domain.TransactionType(request.Type),
+		MinAmount:       request.MinAmount,
+		MaxAmount:       request.MaxAmount,
+		CounterpartyID:  request.CounterpartyID,
+	}
+
+	if request.From != "" {
+		from, err := time.Parse(time.DateOnly, request.From)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = from
+	}
+
+	if request.To != "" {
+		to, err := time.Parse(time.DateOnly, request.To)
+		if err != nil {
+			return filter, err
+		}
+		// the end date is inclusive
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+func encodeCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+func decodeCursor(cursor string) (uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, consts.ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return 0, consts.ErrInvalidCursor
+	}
+
+	return id, nil
+}
+
+func (bs *BalanceService) findRecipient(ctx context.Context, request dto.TransferInquiryRequest) (*domain.User, error) {
+	switch {
+	case request.RecipientID != 0:
+		return bs.userRepo.GetUserByID(ctx, request.RecipientID)
+	case request.RecipientEmail != "":
+		return bs.userRepo.GetUserByEmail(ctx, request.RecipientEmail)
+	default:
+		return bs.userRepo.GetUserByPhone(ctx, request.RecipientPhone)
+	}
+}
+
+// transferLimitFor returns the transfer caps of the user's role
+func (bs *BalanceService) transferLimitFor(user *domain.User) domain.TransferLimit {
+	limit := bs.rules.TransferLimitFor(string(user.Role))
+
+	return domain.TransferLimit{
+		PerTransaction: limit.PerTransaction,
+		Daily:          limit.Daily,
+		Monthly:        limit.Monthly,
+	}
+}
+
+func (bs *BalanceService) saveInquiry(ctx context.Context, inquiry *domain.TransferInquiry) error {
+	if !inquiry.ExpiresAt.After(time.Now()) {
+		return consts.ErrTransferInquiryNotFound
+	}
+
+	return bs.inquiryRepo.Store(ctx, inquiry)
+}
+
+func (bs *BalanceService) findInquiry(ctx context.Context, id string) (*domain.TransferInquiry, error) {
+	inquiry, err := bs.inquiryRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inquiry == nil {
+		return nil, consts.ErrTransferInquiryNotFound
+	}
+
+	return inquiry, nil
+}
+
+// generateOTP returns a random six digit code
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashOTP(inquiryID, code string) string {
+	sum := sha256.Sum256([]byte(inquiryID + ":" + code))
+	return hex.EncodeToString(sum[:])
 }
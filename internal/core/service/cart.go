@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
@@ -16,6 +17,9 @@ type CartService struct {
 	OrderRepo     port.OrderRepository
 	OrderItemRepo port.OrderItemRepository
 	ProductRepo   port.ProductRepository
+	VariantRepo   port.ProductVariantRepository
+	Rules         *config.Business
+	TokenSecret   []byte
 }
 
 func NewCartService(
@@ -24,6 +28,9 @@ func NewCartService(
 	orderRepo port.OrderRepository,
 	orderItemRepo port.OrderItemRepository,
 	productRepo port.ProductRepository,
+	variantRepo port.ProductVariantRepository,
+	rules *config.Business,
+	tokenSecret string,
 ) *CartService {
 	return &CartService{
 		CartItemRepo:  cartItemRepo,
@@ -31,15 +38,20 @@ func NewCartService(
 		OrderRepo:     orderRepo,
 		OrderItemRepo: orderItemRepo,
 		ProductRepo:   productRepo,
+		VariantRepo:   variantRepo,
+		Rules:         rules,
+		TokenSecret:   []byte(tokenSecret),
 	}
 }
 
-func (s *CartService) GetCart(ctx context.Context, userID int) (dto.CartResponse, error) {
+// GetCart shows the cart at current prices, flagging each line whose price
+// changed since it was added or whose product ran out of stock or was deleted
+func (s *CartService) GetCart(ctx context.Context, owner domain.CartOwner) (dto.CartResponse, error) {
 	var (
 		response dto.CartResponse
 	)
 
-	cart, err := s.CartRepo.FindByUserID(ctx, userID)
+	cart, err := s.findCart(ctx, owner)
 	if err != nil {
 		return response, err
 	}
@@ -48,92 +60,88 @@ func (s *CartService) GetCart(ctx context.Context, userID int) (dto.CartResponse
 		return response, nil
 	}
 
-	cartItems, err := s.CartItemRepo.Finds(ctx, map[string]interface{}{"cart_id": cart.ID})
+	cartItems, err := s.CartItemRepo.FindsWithProducts(ctx, cart.ID)
 	if err != nil {
 		return response, err
 	}
 
-	var totalPrice, quantity float64
-	for _, item := range cartItems {
-		product, err := s.ProductRepo.FindOne(ctx, item.ProductID)
-		if err != nil {
-			return response, err
-		}
-
-		totalPrice += product.Price * float64(item.Quantity)
-		quantity += float64(item.Quantity)
-
-		response.Items = append(response.Items, dto.CartItemResponse{
-			Name:      product.Name,
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			Price:     product.Price,
-		})
+	if _, err := sellLines(ctx, s.VariantRepo, cartItems); err != nil {
+		return response, err
 	}
 
-	response.TotalProducts = len(response.Items)
-	response.TotalItems = int(quantity)
-	response.TotalPrice = totalPrice
-
-	return response, nil
+	return newCartResponse(cartItems), nil
 }
 
-func (s *CartService) AddToCart(ctx context.Context, userID int, productID int, quantity int) error {
-	cart, err := s.CartRepo.FindByUserID(ctx, userID)
+// AddToCart adds a product to the owner's cart at its current price, creating
+// the cart when needed. A product with variants needs the variant, which is
+// sold at its own price and stock. For a guest it returns the cart token the
+// client has to send from now on
+func (s *CartService) AddToCart(ctx context.Context, owner domain.CartOwner, productID, variantID int, quantity int) (string, error) {
+	product, err := s.findProduct(ctx, productID, variantID)
 	if err != nil {
-		return err
-	}
-
-	if cart == nil {
-		cart = &domain.Cart{
-			UserID: userID,
-		}
-		if err := s.CartRepo.Store(ctx, cart); err != nil {
-			return err
-		}
+		return "", err
 	}
 
-	product, err := s.ProductRepo.FindOne(ctx, productID)
+	cart, token, err := s.findOrCreateCart(ctx, owner)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	existCartItem, err := s.CartItemRepo.FindOneByFilters(ctx, map[string]interface{}{"cart_id": cart.ID, "product_id": productID})
+	existCartItem, err := s.CartItemRepo.FindOneByFilters(ctx, map[string]interface{}{"cart_id": cart.ID, "product_id": productID, "variant_id": variantID})
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	tNow := time.Now()
 	cartItem := domain.CartItem{
-		CartID:    cart.ID,
-		ProductID: productID,
-		Quantity:  quantity,
-		CreatedAt: tNow,
-		UpdatedAt: tNow,
+		CartID:      cart.ID,
+		ProductID:   productID,
+		VariantID:   variantID,
+		Quantity:    quantity,
+		Price:       product.Price,
+		ProductName: product.Name,
+		CreatedAt:   tNow,
+		UpdatedAt:   tNow,
 	}
 
 	if existCartItem == nil {
+		if err := s.checkItemLimit(ctx, cart.ID); err != nil {
+			return "", err
+		}
+
+		if err := s.checkQuantityLimit(quantity); err != nil {
+			return "", err
+		}
+
+		if product.Stock < quantity {
+			return "", consts.ErrInsufficientStock
+		}
+
 		err = s.CartItemRepo.Store(ctx, &cartItem)
 		if err != nil {
-			return err
+			return "", err
 		}
 	} else {
-		if product.Stock < quantity {
-			return consts.ErrInsufficientStock
+		cartItem.Quantity += existCartItem.Quantity
+		if product.Stock < cartItem.Quantity {
+			return "", consts.ErrInsufficientStock
+		}
+
+		if err := s.checkQuantityLimit(cartItem.Quantity); err != nil {
+			return "", err
 		}
 
-		cartItem.Quantity += existCartItem.Quantity
 		err := s.CartItemRepo.Update(ctx, existCartItem.ID, cartItem)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 
-	return nil
+	return token, s.touch(ctx, cart)
 }
 
-func (s *CartService) RemoveFromCart(ctx context.Context, userID int, productID int) error {
-	cart, err := s.CartRepo.FindByUserID(ctx, userID)
+func (s *CartService) RemoveFromCart(ctx context.Context, owner domain.CartOwner, productID, variantID int) error {
+	cart, err := s.findCart(ctx, owner)
 	if err != nil {
 		return err
 	}
@@ -142,7 +150,7 @@ func (s *CartService) RemoveFromCart(ctx context.Context, userID int, productID
 		return consts.ErrEmptyCart
 	}
 
-	existCartItem, err := s.CartItemRepo.FindOneByFilters(ctx, map[string]interface{}{"cart_id": cart.ID, "product_id": productID})
+	existCartItem, err := s.CartItemRepo.FindOneByFilters(ctx, map[string]interface{}{"cart_id": cart.ID, "product_id": productID, "variant_id": variantID})
 	if err != nil {
 		return err
 	}
@@ -151,16 +159,16 @@ func (s *CartService) RemoveFromCart(ctx context.Context, userID int, productID
 		return consts.ErrDataNotFound
 	}
 
-	err = s.CartItemRepo.DeleteByProductID(ctx, productID)
+	err = s.CartItemRepo.Delete(ctx, existCartItem.ID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.touch(ctx, cart)
 }
 
-func (s *CartService) UpdateCart(ctx context.Context, userID int, request dto.UpdateCartRequest) error {
-	cart, err := s.CartRepo.FindByUserID(ctx, userID)
+func (s *CartService) UpdateCart(ctx context.Context, owner domain.CartOwner, request dto.UpdateCartRequest) error {
+	cart, err := s.findCart(ctx, owner)
 	if err != nil {
 		return err
 	}
@@ -169,7 +177,7 @@ func (s *CartService) UpdateCart(ctx context.Context, userID int, request dto.Up
 		return consts.ErrEmptyCart
 	}
 
-	cartItem, err := s.CartItemRepo.FindOneByFilters(ctx, map[string]interface{}{"cart_id": cart.ID, "product_id": request.ProductID})
+	cartItem, err := s.CartItemRepo.FindOneByFilters(ctx, map[string]interface{}{"cart_id": cart.ID, "product_id": request.ProductID, "variant_id": request.VariantID})
 	if err != nil {
 		return err
 	}
@@ -178,8 +186,11 @@ func (s *CartService) UpdateCart(ctx context.Context, userID int, request dto.Up
 		return consts.ErrDataNotFound
 	}
 
-	product, err := s.ProductRepo.FindOne(ctx, request.ProductID)
+	product, err := s.findProduct(ctx, request.ProductID, request.VariantID)
 	if err != nil {
+		if err == consts.ErrDataNotFound || err == consts.ErrVariantNotFound || err == consts.ErrVariantRequired {
+			return consts.ErrCartItemUnavailable
+		}
 		return err
 	}
 
@@ -187,11 +198,404 @@ func (s *CartService) UpdateCart(ctx context.Context, userID int, request dto.Up
 		return consts.ErrInsufficientStock
 	}
 
+	if err := s.checkQuantityLimit(request.Quantity); err != nil {
+		return err
+	}
+
+	// changing the line accepts the current price
 	cartItem.Quantity = request.Quantity
+	cartItem.Price = product.Price
+	cartItem.ProductName = product.Name
 	err = s.CartItemRepo.Update(ctx, cartItem.ID, *cartItem)
 	if err != nil {
 		return err
 	}
 
+	return s.touch(ctx, cart)
+}
+
+// BulkUpdateCart applies a list of upsert and remove operations to the owner's
+// cart at once. When an operation is invalid nothing is changed and the reason
+// for every rejected operation comes back with ErrInvalidCartOperations. For
+// a guest it returns the cart token like AddToCart
+func (s *CartService) BulkUpdateCart(ctx context.Context, owner domain.CartOwner, operations []dto.CartItemOperation) (string, []dto.CartLineError, error) {
+	if len(operations) == 0 {
+		return "", nil, consts.ErrNoUpdatedData
+	}
+
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return "", nil, err
+	}
+
+	existing := make(map[domain.CartLine]domain.CartItem)
+	if cart != nil {
+		items, err := s.CartItemRepo.Finds(ctx, map[string]interface{}{"cart_id": cart.ID})
+		if err != nil {
+			return "", nil, err
+		}
+
+		for _, item := range items {
+			existing[item.Line()] = item
+		}
+	}
+
+	lookups := make([]domain.CartItem, 0, len(operations))
+	for _, op := range operations {
+		if op.Op == dto.CartOperationUpsert {
+			lookups = append(lookups, domain.CartItem{ProductID: op.ProductID, VariantID: op.VariantID})
+		}
+	}
+
+	products, err := loadProducts(ctx, s.ProductRepo, s.VariantRepo, lookups)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		lineErrors []dto.CartLineError
+		upserts    []domain.CartItem
+		removals   []domain.CartLine
+	)
+	seen := make(map[domain.CartLine]bool, len(operations))
+	lines := len(existing)
+	tNow := time.Now()
+	for i, op := range operations {
+		line := domain.CartLine{ProductID: op.ProductID, VariantID: op.VariantID}
+
+		err := consts.ErrDuplicateCartOperation
+		if !seen[line] {
+			err = s.checkOperation(op, existing, products)
+		}
+		seen[line] = true
+
+		if err != nil {
+			lineErrors = append(lineErrors, dto.CartLineError{Index: i, ProductID: op.ProductID, VariantID: op.VariantID, Message: err.Error()})
+			continue
+		}
+
+		if op.Op == dto.CartOperationRemove {
+			removals = append(removals, line)
+			lines--
+			continue
+		}
+
+		// changing the line accepts the current price
+		product := products[line]
+		item, found := existing[line]
+		if !found {
+			item = domain.CartItem{ProductID: op.ProductID, VariantID: op.VariantID, CreatedAt: tNow}
+			lines++
+		}
+		item.Quantity = op.Quantity
+		item.Price = product.Price
+		item.ProductName = product.Name
+		item.UpdatedAt = tNow
+		upserts = append(upserts, item)
+	}
+
+	if len(lineErrors) > 0 {
+		return "", lineErrors, consts.ErrInvalidCartOperations
+	}
+
+	if s.Rules.MaxCartItems > 0 && lines > s.Rules.MaxCartItems {
+		return "", nil, consts.ErrCartItemLimitExceeded
+	}
+
+	cart, token, err := s.findOrCreateCart(ctx, owner)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for i := range upserts {
+		upserts[i].CartID = cart.ID
+	}
+
+	if err := s.CartItemRepo.ApplyChanges(ctx, cart.ID, upserts, removals); err != nil {
+		return "", nil, err
+	}
+
+	return token, nil, s.touch(ctx, cart)
+}
+
+// ClearCart empties the owner's cart the way checkout does once the order is
+// placed, an owner without a cart has nothing to clear
+func (s *CartService) ClearCart(ctx context.Context, owner domain.CartOwner) error {
+	cart, err := s.findCart(ctx, owner)
+	if err != nil || cart == nil {
+		return err
+	}
+
+	return clearCart(ctx, s.CartRepo, s.CartItemRepo, cart)
+}
+
+// MergeGuestCart moves the guest cart of guestToken into the user's cart once
+// they sign in. A product or variant in both carts gets the sum of both quantities capped
+// at the stock and the per item limit, products that would go past the cart's
+// item limit or are out of stock stay behind. The guest cart is removed after
+func (s *CartService) MergeGuestCart(ctx context.Context, userID int, guestToken string) error {
+	guestID, err := parseCartToken(s.TokenSecret, guestToken)
+	if err != nil {
+		return err
+	}
+
+	guest, err := s.CartRepo.FindByGuestID(ctx, guestID)
+	if err != nil {
+		return err
+	}
+
+	if guest == nil {
+		return nil
+	}
+
+	guestItems, err := s.CartItemRepo.Finds(ctx, map[string]interface{}{"cart_id": guest.ID})
+	if err != nil {
+		return err
+	}
+
+	if len(guestItems) > 0 {
+		cart, _, err := s.findOrCreateCart(ctx, domain.CartOwner{UserID: userID})
+		if err != nil {
+			return err
+		}
+
+		if err := s.mergeItems(ctx, cart, guestItems); err != nil {
+			return err
+		}
+	}
+
+	if err := s.CartItemRepo.DeleteByCartID(ctx, guest.ID); err != nil {
+		return err
+	}
+
+	return s.CartRepo.Delete(ctx, guest.ID)
+}
+
+// CleanupGuestCarts removes the guest carts abandoned for longer than the
+// guest cart TTL and returns how many were removed
+func (s *CartService) CleanupGuestCarts(ctx context.Context, now time.Time) (int64, error) {
+	return s.CartRepo.DeleteExpiredGuests(ctx, now)
+}
+
+func (s *CartService) mergeItems(ctx context.Context, cart *domain.Cart, guestItems []domain.CartItem) error {
+	items, err := s.CartItemRepo.Finds(ctx, map[string]interface{}{"cart_id": cart.ID})
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[domain.CartLine]domain.CartItem, len(items))
+	for _, item := range items {
+		existing[item.Line()] = item
+	}
+
+	products, err := loadProducts(ctx, s.ProductRepo, s.VariantRepo, guestItems)
+	if err != nil {
+		return err
+	}
+
+	lines := len(items)
+	tNow := time.Now()
+	for _, guestItem := range guestItems {
+		product := products[guestItem.Line()]
+		if product == nil {
+			continue
+		}
+
+		item, found := existing[guestItem.Line()]
+		quantity := s.capQuantity(item.Quantity+guestItem.Quantity, product.Stock)
+		if quantity <= 0 {
+			continue
+		}
+
+		if found {
+			if quantity == item.Quantity {
+				continue
+			}
+
+			item.Quantity = quantity
+			if err := s.CartItemRepo.Update(ctx, item.ID, item); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if s.Rules.MaxCartItems > 0 && lines >= s.Rules.MaxCartItems {
+			continue
+		}
+
+		err = s.CartItemRepo.Store(ctx, &domain.CartItem{
+			CartID:      cart.ID,
+			ProductID:   guestItem.ProductID,
+			VariantID:   guestItem.VariantID,
+			Quantity:    quantity,
+			Price:       guestItem.Price,
+			ProductName: guestItem.ProductName,
+			CreatedAt:   tNow,
+			UpdatedAt:   tNow,
+		})
+		if err != nil {
+			return err
+		}
+		lines++
+	}
+
+	return s.touch(ctx, cart)
+}
+
+// findProduct returns the product as sold with the given variant, see sellAs
+func (s *CartService) findProduct(ctx context.Context, productID, variantID int) (*domain.Product, error) {
+	product, err := s.ProductRepo.FindOne(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	variants, err := s.VariantRepo.FindByProductIDs(ctx, []int{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	return sellAs(product, variants, variantID)
+}
+
+// findCart returns the cart of the owner, nil when it has none. An expired
+// guest cart reads as missing, a token with a bad signature is rejected
+func (s *CartService) findCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error) {
+	if owner.UserID > 0 {
+		return s.CartRepo.FindByUserID(ctx, owner.UserID)
+	}
+
+	if owner.GuestToken == "" {
+		return nil, nil
+	}
+
+	guestID, err := parseCartToken(s.TokenSecret, owner.GuestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CartRepo.FindByGuestID(ctx, guestID)
+}
+
+// findOrCreateCart returns the cart of the owner and creates it when missing,
+// a guest gets a new cart and token when theirs has expired. The token is
+// empty for a user's cart
+func (s *CartService) findOrCreateCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, string, error) {
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cart != nil {
+		if cart.IsGuest() {
+			return cart, owner.GuestToken, nil
+		}
+		return cart, "", nil
+	}
+
+	var token string
+	cart = &domain.Cart{
+		UserID: owner.UserID,
+	}
+
+	if owner.UserID == 0 {
+		token, cart.GuestID, err = newCartToken(s.TokenSecret)
+		if err != nil {
+			return nil, "", err
+		}
+
+		expiresAt := time.Now().Add(s.Rules.GuestCartTTL)
+		cart.ExpiresAt = &expiresAt
+	}
+
+	if err := s.CartRepo.Store(ctx, cart); err != nil {
+		return nil, "", err
+	}
+
+	return cart, token, nil
+}
+
+// touch marks the cart as changed, which also pushes back the expiry of a
+// guest cart
+func (s *CartService) touch(ctx context.Context, cart *domain.Cart) error {
+	if cart.IsGuest() {
+		expiresAt := time.Now().Add(s.Rules.GuestCartTTL)
+		cart.ExpiresAt = &expiresAt
+	}
+
+	return s.CartRepo.Update(ctx, cart.ID, *cart)
+}
+
+// checkOperation validates one bulk operation against the lines already in the
+// cart and the current products, both keyed by line
+func (s *CartService) checkOperation(op dto.CartItemOperation, existing map[domain.CartLine]domain.CartItem, products map[domain.CartLine]*domain.Product) error {
+	line := domain.CartLine{ProductID: op.ProductID, VariantID: op.VariantID}
+
+	switch op.Op {
+	case dto.CartOperationRemove:
+		if _, ok := existing[line]; !ok {
+			return consts.ErrProductNotInCart
+		}
+		return nil
+	case dto.CartOperationUpsert:
+		if op.Quantity <= 0 {
+			return consts.ErrInvalidQuantity
+		}
+
+		product := products[line]
+		if product == nil {
+			return consts.ErrProductNotFound
+		}
+
+		if product.Stock < op.Quantity {
+			return consts.ErrInsufficientStock
+		}
+
+		return s.checkQuantityLimit(op.Quantity)
+	default:
+		return consts.ErrInvalidCartOperation
+	}
+}
+
+// capQuantity limits a merged quantity to the stock and the per item maximum
+func (s *CartService) capQuantity(quantity, stock int) int {
+	if quantity > stock {
+		quantity = stock
+	}
+
+	if s.Rules.MaxQuantityPerItem > 0 && quantity > s.Rules.MaxQuantityPerItem {
+		quantity = s.Rules.MaxQuantityPerItem
+	}
+
+	return quantity
+}
+
+// checkItemLimit rejects a new cart line once the cart holds the maximum number of products
+func (s *CartService) checkItemLimit(ctx context.Context, cartID int) error {
+	if s.Rules.MaxCartItems <= 0 {
+		return nil
+	}
+
+	items, err := s.CartItemRepo.Finds(ctx, map[string]interface{}{"cart_id": cartID})
+	if err != nil {
+		return err
+	}
+
+	if len(items) >= s.Rules.MaxCartItems {
+		return consts.ErrCartItemLimitExceeded
+	}
+
+	return nil
+}
+
+// checkQuantityLimit rejects a cart line whose quantity is above the configured maximum
+func (s *CartService) checkQuantityLimit(quantity int) error {
+	if s.Rules.MaxQuantityPerItem > 0 && quantity > s.Rules.MaxQuantityPerItem {
+		return consts.ErrQuantityLimitExceeded
+	}
+
 	return nil
 }
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+)
+
+// queryLatency stands in for one database round trip
+const queryLatency = 50 * time.Microsecond
+
+// queryCounter records every repository call as one query
+type queryCounter struct {
+	queries int
+}
+
+func (c *queryCounter) query() {
+	c.queries++
+	time.Sleep(queryLatency)
+}
+
+// the fakes embed the port so only the methods used by cart and checkout need
+// an implementation
+type benchCartRepo struct {
+	port.CartRepository
+	*queryCounter
+	cart *domain.Cart
+}
+
+func (r *benchCartRepo) FindByUserID(ctx context.Context, userID int) (*domain.Cart, error) {
+	r.query()
+	return r.cart, nil
+}
+
+func (r *benchCartRepo) DeleteByUserID(ctx context.Context, userID int) error {
+	r.query()
+	return nil
+}
+
+type benchCartItemRepo struct {
+	port.CartItemRepository
+	*queryCounter
+	items    []domain.CartItem
+	products *benchProductRepo
+}
+
+func (r *benchCartItemRepo) Finds(ctx context.Context, filter map[string]interface{}) ([]domain.CartItem, error) {
+	r.query()
+	return r.items, nil
+}
+
+func (r *benchCartItemRepo) FindsWithProducts(ctx context.Context, cartID int) ([]domain.CartItemWithProduct, error) {
+	r.query()
+
+	items := make([]domain.CartItemWithProduct, 0, len(r.items))
+	for _, item := range r.items {
+		items = append(items, domain.CartItemWithProduct{CartItem: item, Product: r.products.products[item.ProductID]})
+	}
+
+	return items, nil
+}
+
+func (r *benchCartItemRepo) DeleteByCartID(ctx context.Context, cartID int) error {
+	r.query()
+	return nil
+}
+
+type benchProductRepo struct {
+	port.ProductRepository
+	*queryCounter
+	products map[int]*domain.Product
+}
+
+func (r *benchProductRepo) FindOne(ctx context.Context, id int) (*domain.Product, error) {
+	r.query()
+	return r.products[id], nil
+}
+
+func (r *benchProductRepo) FindByIDs(ctx context.Context, ids []int) ([]domain.Product, error) {
+	r.query()
+
+	products := make([]domain.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok {
+			products = append(products, *product)
+		}
+	}
+
+	return products, nil
+}
+
+func (r *benchProductRepo) UpdateStocks(ctx context.Context, stocks map[int]int) error {
+	r.query()
+	return nil
+}
+
+// benchVariantRepo holds no variants, so every product sells as itself
+type benchVariantRepo struct {
+	port.ProductVariantRepository
+	*queryCounter
+}
+
+func (r *benchVariantRepo) FindByProductIDs(ctx context.Context, productIDs []int) ([]domain.ProductVariant, error) {
+	r.query()
+	return nil, nil
+}
+
+func (r *benchVariantRepo) UpdateStocks(ctx context.Context, stocks map[int]int) error {
+	if len(stocks) > 0 {
+		r.query()
+	}
+	return nil
+}
+
+type benchOrderRepo struct {
+	port.OrderRepository
+	*queryCounter
+}
+
+func (r *benchOrderRepo) Store(ctx context.Context, data *domain.Order) error {
+	r.query()
+	data.ID = 1
+	return nil
+}
+
+type benchOrderItemRepo struct {
+	port.OrderItemRepository
+	*queryCounter
+}
+
+func (r *benchOrderItemRepo) StoreBatch(ctx context.Context, items []domain.OrderItem) error {
+	r.query()
+	return nil
+}
+
+type benchPaymentRepo struct {
+	port.PaymentRepository
+	*queryCounter
+}
+
+func (r *benchPaymentRepo) Store(ctx context.Context, data *domain.Payment) error {
+	r.query()
+	return nil
+}
+
+type benchStore struct {
+	counter   *queryCounter
+	carts     *benchCartRepo
+	cartItems *benchCartItemRepo
+	products  *benchProductRepo
+	variants  *benchVariantRepo
+}
+
+// newBenchStore fills a user cart with size products
+func newBenchStore(size int) *benchStore {
+	counter := &queryCounter{}
+	products := &benchProductRepo{queryCounter: counter, products: make(map[int]*domain.Product, size)}
+	cartItems := &benchCartItemRepo{queryCounter: counter, products: products}
+
+	for id := 1; id <= size; id++ {
+		products.products[id] = &domain.Product{ID: id, Name: fmt.Sprintf("product %d", id), Price: 10000, Stock: 100}
+		cartItems.items = append(cartItems.items, domain.CartItem{ID: id, CartID: 1, ProductID: id, Quantity: 1, Price: 10000})
+	}
+
+	return &benchStore{
+		counter:   counter,
+		carts:     &benchCartRepo{queryCounter: counter, cart: &domain.Cart{ID: 1, UserID: 1}},
+		cartItems: cartItems,
+		products:  products,
+		variants:  &benchVariantRepo{queryCounter: counter},
+	}
+}
+
+var benchCartSizes = []int{1, 10, 50}
+
+// BenchmarkLoadCartProducts compares looking up each product on its own, as
+// the cart did before, with batched lookups of the products and variants
+func BenchmarkLoadCartProducts(b *testing.B) {
+	for _, size := range benchCartSizes {
+		b.Run(fmt.Sprintf("per_item/items=%d", size), func(b *testing.B) {
+			store := newBenchStore(size)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, item := range store.cartItems.items {
+					if _, err := store.products.FindOne(ctx, item.ProductID); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(store.counter.queries)/float64(b.N), "queries/op")
+		})
+
+		b.Run(fmt.Sprintf("batched/items=%d", size), func(b *testing.B) {
+			store := newBenchStore(size)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := loadProducts(ctx, store.products, store.variants, store.cartItems.items); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(store.counter.queries)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+func BenchmarkGetCart(b *testing.B) {
+	for _, size := range benchCartSizes {
+		b.Run(fmt.Sprintf("items=%d", size), func(b *testing.B) {
+			store := newBenchStore(size)
+			svc := NewCartService(store.cartItems, store.carts, nil, nil, store.products, store.variants, &config.Business{}, "secret")
+			ctx := context.Background()
+			owner := domain.CartOwner{UserID: 1}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := svc.GetCart(ctx, owner); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(store.counter.queries)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+func BenchmarkCheckout(b *testing.B) {
+	for _, size := range benchCartSizes {
+		b.Run(fmt.Sprintf("items=%d", size), func(b *testing.B) {
+			store := newBenchStore(size)
+			cartSvc := NewCartService(store.cartItems, store.carts, nil, nil, store.products, store.variants, &config.Business{}, "secret")
+			svc := NewCheckoutService(
+				store.products,
+				store.variants,
+				&benchOrderRepo{queryCounter: store.counter},
+				&benchOrderItemRepo{queryCounter: store.counter},
+				store.carts,
+				store.cartItems,
+				&benchPaymentRepo{queryCounter: store.counter},
+				nil,
+				&config.Business{PaymentExpiry: time.Minute},
+			)
+			ctx := context.Background()
+
+			cart, err := cartSvc.GetCart(ctx, domain.CartOwner{UserID: 1})
+			if err != nil {
+				b.Fatal(err)
+			}
+			store.counter.queries = 0
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := svc.Checkout(ctx, 1, "bank_transfer", cart.Version); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(store.counter.queries)/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"go.uber.org/zap"
+)
+
+type CartReminderService struct {
+	repo         port.CartReminderRepository
+	cartItemRepo port.CartItemRepository
+	variantRepo  port.ProductVariantRepository
+	mailer       port.EmailSender
+	rules        *config.Business
+	tokenSecret  []byte
+	publicURL    string
+	log          *zap.Logger
+}
+
+func NewCartReminderService(repo port.CartReminderRepository, cartItemRepo port.CartItemRepository, variantRepo port.ProductVariantRepository, mailer port.EmailSender, rules *config.Business, tokenSecret string, publicURL string, log *zap.Logger) *CartReminderService {
+	return &CartReminderService{
+		repo:         repo,
+		cartItemRepo: cartItemRepo,
+		variantRepo:  variantRepo,
+		mailer:       mailer,
+		rules:        rules,
+		tokenSecret:  []byte(tokenSecret),
+		publicURL:    strings.TrimRight(publicURL, "/"),
+		log:          log,
+	}
+}
+
+// SendReminders emails the users whose cart has been idle for
+// CartReminderAfter, at most CartReminderMax times per cart and
+// CartReminderInterval apart. A failed cart is logged and retried on the next
+// run, it returns the number of reminders sent
+func (s *CartReminderService) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	if s.rules.CartReminderMax <= 0 {
+		return 0, nil
+	}
+
+	carts, err := s.repo.FindIdleCarts(ctx, domain.IdleCartFilter{
+		UpdatedBefore:      now.Add(-s.rules.CartReminderAfter),
+		LastReminderBefore: now.Add(-s.rules.CartReminderInterval),
+		MaxReminders:       s.rules.CartReminderMax,
+		Limit:              s.rules.CartReminderBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, cart := range carts {
+		ok, err := s.remind(ctx, cart, now)
+		if err != nil {
+			s.log.Error("Failed to send cart reminder", zap.Int("cart_id", cart.CartID), zap.Error(err))
+			continue
+		}
+
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// remind sends one reminder for the cart, a cart with nothing left to buy is
+// skipped
+func (s *CartReminderService) remind(ctx context.Context, cart domain.IdleCart, now time.Time) (bool, error) {
+	items, err := s.cartItemRepo.FindsWithProducts(ctx, cart.CartID)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := sellLines(ctx, s.variantRepo, items); err != nil {
+		return false, err
+	}
+
+	view := newCartResponse(items)
+
+	var lines []map[string]interface{}
+	for _, line := range view.Items {
+		if line.Status != domain.CartItemAvailable {
+			continue
+		}
+
+		lines = append(lines, map[string]interface{}{
+			"Name":     line.Name,
+			"Quantity": line.Quantity,
+			"Price":    strconv.FormatFloat(line.Price*float64(line.Quantity), 'f', 2, 64),
+		})
+	}
+
+	if len(lines) == 0 {
+		return false, nil
+	}
+
+	payload := map[string]interface{}{
+		"Name":           cart.Name,
+		"Items":          lines,
+		"Total":          strconv.FormatFloat(view.TotalPrice, 'f', 2, 64),
+		"UnsubscribeURL": s.unsubscribeURL(cart.UserID),
+	}
+
+	err = s.mailer.SendEmail(payload, consts.TemplateCartReminder, []string{cart.Email}, "You left items in your cart")
+	if err != nil {
+		return false, err
+	}
+
+	err = s.repo.Store(ctx, &domain.CartReminder{
+		CartID:        cart.CartID,
+		UserID:        cart.UserID,
+		CartUpdatedAt: cart.UpdatedAt,
+		ItemCount:     view.TotalItems,
+		CartTotal:     view.TotalPrice,
+		SentAt:        now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *CartReminderService) unsubscribeURL(userID int) string {
+	query := url.Values{"token": {newUnsubscribeToken(s.tokenSecret, userID)}}
+
+	return s.publicURL + "/api/v1/carts/reminders/unsubscribe?" + query.Encode()
+}
+
+// Stats sums up the reminders sent since the given time and the carts they
+// converted
+func (s *CartReminderService) Stats(ctx context.Context, since time.Time) (*domain.CartReminderStats, error) {
+	return s.repo.Stats(ctx, since, s.rules.CartReminderWindow)
+}
+
+// SetReminders turns the cart reminders of a user on or off
+func (s *CartReminderService) SetReminders(ctx context.Context, userID int, enabled bool) error {
+	return s.repo.SetOptOut(ctx, userID, !enabled)
+}
+
+// Unsubscribe turns the cart reminders off for the user of an unsubscribe
+// link
+func (s *CartReminderService) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := parseUnsubscribeToken(s.tokenSecret, token)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetOptOut(ctx, userID, true)
+}
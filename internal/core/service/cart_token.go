@@ -0,0 +1,74 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// newCartToken creates a guest ID and its signed token in the form
+// "<guest id>.<signature>"
+func newCartToken(secret []byte) (string, string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	guestID := base64.RawURLEncoding.EncodeToString(buf)
+
+	return guestID + "." + signCartToken(secret, guestID), guestID, nil
+}
+
+// parseCartToken checks the signature of a cart token and returns its guest ID
+func parseCartToken(secret []byte, token string) (string, error) {
+	guestID, signature, ok := strings.Cut(token, ".")
+	if !ok || guestID == "" {
+		return "", consts.ErrInvalidCartToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signCartToken(secret, guestID))) {
+		return "", consts.ErrInvalidCartToken
+	}
+
+	return guestID, nil
+}
+
+func signCartToken(secret []byte, guestID string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(guestID))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newUnsubscribeToken signs the user ID for the unsubscribe link of the cart
+// reminder emails, in the form "<user id>.<signature>"
+func newUnsubscribeToken(secret []byte, userID int) string {
+	id := strconv.Itoa(userID)
+
+	return id + "." + signCartToken(secret, "unsubscribe:"+id)
+}
+
+// parseUnsubscribeToken checks the signature of an unsubscribe token and
+// returns its user ID
+func parseUnsubscribeToken(secret []byte, token string) (int, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, consts.ErrInvalidUnsubscribeToken
+	}
+
+	userID, err := strconv.Atoi(id)
+	if err != nil || userID <= 0 {
+		return 0, consts.ErrInvalidUnsubscribeToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signCartToken(secret, "unsubscribe:"+id))) {
+		return 0, consts.ErrInvalidUnsubscribeToken
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// loadProducts finds the products of the given cart items in two queries,
+// one for the products and one for their variants, keyed by line. Each
+// product is the product as sold on the line, see sellAs. Lines that cannot
+// be sold, like those of deleted products, are missing from the map
+func loadProducts(ctx context.Context, productRepo port.ProductRepository, variantRepo port.ProductVariantRepository, items []domain.CartItem) (map[domain.CartLine]*domain.Product, error) {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	found, err := productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := loadVariants(ctx, variantRepo, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*domain.Product, len(found))
+	for i := range found {
+		byID[found[i].ID] = &found[i]
+	}
+
+	products := make(map[domain.CartLine]*domain.Product, len(items))
+	for _, item := range items {
+		product := byID[item.ProductID]
+		if product == nil {
+			continue
+		}
+
+		if sold, err := sellAs(product, variants[item.ProductID], item.VariantID); err == nil {
+			products[item.Line()] = sold
+		}
+	}
+
+	return products, nil
+}
+
+// loadVariants finds the variants of the given products, keyed by product ID
+func loadVariants(ctx context.Context, variantRepo port.ProductVariantRepository, productIDs []int) (map[int][]domain.ProductVariant, error) {
+	found, err := variantRepo.FindByProductIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make(map[int][]domain.ProductVariant)
+	for _, variant := range found {
+		variants[variant.ProductID] = append(variants[variant.ProductID], variant)
+	}
+
+	return variants, nil
+}
+
+// sellAs returns the product as sold on a line with the given variant. A
+// product without variants sells as itself, a product with variants only
+// through one of them at the variant's price and stock
+func sellAs(product *domain.Product, variants []domain.ProductVariant, variantID int) (*domain.Product, error) {
+	if variantID == 0 {
+		if len(variants) > 0 {
+			return nil, consts.ErrVariantRequired
+		}
+		return product, nil
+	}
+
+	for i := range variants {
+		if variants[i].ID == variantID {
+			return variants[i].Apply(product), nil
+		}
+	}
+
+	return nil, consts.ErrVariantNotFound
+}
+
+// sellLines replaces the product of every item by the product as sold on its
+// line, the product becomes nil when the line cannot be sold any more. It
+// returns the variants of the lines keyed by variant ID
+func sellLines(ctx context.Context, variantRepo port.ProductVariantRepository, items []domain.CartItemWithProduct) (map[int]*domain.ProductVariant, error) {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		if item.Product != nil {
+			ids = append(ids, item.ProductID)
+		}
+	}
+
+	variants, err := loadVariants(ctx, variantRepo, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lineVariants := make(map[int]*domain.ProductVariant)
+	for i := range items {
+		item := &items[i]
+		if item.Product == nil {
+			continue
+		}
+
+		productVariants := variants[item.ProductID]
+		item.Product, err = sellAs(item.Product, productVariants, item.VariantID)
+		if err != nil {
+			item.Product = nil
+			continue
+		}
+
+		for j := range productVariants {
+			if productVariants[j].ID == item.VariantID {
+				lineVariants[item.VariantID] = &productVariants[j]
+			}
+		}
+	}
+
+	return lineVariants, nil
+}
+
+// newCartResponse compares every item with its current product. Lines are
+// priced at the current price, lines whose product is gone are left out of
+// the totals
+func newCartResponse(items []domain.CartItemWithProduct) dto.CartResponse {
+	var response dto.CartResponse
+
+	for _, item := range items {
+		line := dto.CartItemResponse{
+			Name:       item.ProductName,
+			ProductID:  item.ProductID,
+			VariantID:  item.VariantID,
+			Price:      item.Price,
+			AddedPrice: item.Price,
+			Quantity:   item.Quantity,
+			Status:     item.Status(item.Product),
+		}
+
+		if item.Product != nil {
+			line.Name = item.Product.Name
+			line.Price = item.Product.Price
+
+			response.TotalPrice += item.Product.Price * float64(item.Quantity)
+			response.TotalItems += item.Quantity
+		}
+
+		if line.Status != domain.CartItemAvailable {
+			response.HasChanges = true
+		}
+
+		response.Items = append(response.Items, line)
+	}
+
+	response.TotalProducts = len(response.Items)
+	response.Version = cartVersion(response.Items)
+
+	return response
+}
+
+// cartVersion hashes what the user agrees to at checkout, the quantity, price
+// and status of every line
+func cartVersion(lines []dto.CartItemResponse) string {
+	sorted := make([]dto.CartItemResponse, len(lines))
+	copy(sorted, lines)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ProductID != sorted[j].ProductID {
+			return sorted[i].ProductID < sorted[j].ProductID
+		}
+		return sorted[i].VariantID < sorted[j].VariantID
+	})
+
+	var b strings.Builder
+	for _, line := range sorted {
+		b.WriteString(strconv.Itoa(line.ProductID))
+		if line.VariantID != 0 {
+			b.WriteByte('.')
+			b.WriteString(strconv.Itoa(line.VariantID))
+		}
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(line.Quantity))
+		b.WriteByte(':')
+		b.WriteString(strconv.FormatFloat(line.Price, 'f', 2, 64))
+		b.WriteByte(':')
+		b.WriteString(string(line.Status))
+		b.WriteByte(';')
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:16])
+}
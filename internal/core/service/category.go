@@ -40,18 +40,21 @@ func (s *CategoryService) FindOne(ctx context.Context, categoryID int) (*domain.
 	return data, nil
 }
 
-// Find multiple categorys
-func (s *CategoryService) Finds(ctx context.Context, param dto.ListCategoryRequest) ([]domain.Category, error) {
-	filter := make(map[string]interface{})
-
-	if param.Page != 0 {
-		filter["page"] = param.Page
+// Finds lists one page of categories
+func (s *CategoryService) Finds(ctx context.Context, param dto.ListCategoryRequest) (*dto.PageResponse[dto.CategoryResponse], error) {
+	page, err := newPageRequest(param.PageRequest)
+	if err != nil {
+		return nil, err
 	}
-	if param.PageSize != 0 {
-		filter["page_size"] = param.PageSize
+
+	categories, err := s.repo.Finds(ctx, map[string]interface{}{}, page)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.Finds(ctx, filter)
+	return newPageResponse(categories, page, func(category domain.Category) dto.CategoryResponse {
+		return dto.NewCategoryResponse(&category)
+	}), nil
 }
 
 // Update a category by ID
@@ -2,9 +2,9 @@ package service
 
 import (
 	"context"
-	"errors"
 	"time"
 
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
@@ -13,32 +13,44 @@ import (
 
 type CheckoutService struct {
 	ProductRepo   port.ProductRepository
+	VariantRepo   port.ProductVariantRepository
 	OrderRepo     port.OrderRepository
 	OrderItemRepo port.OrderItemRepository
 	CartRepo      port.CartRepository
 	CartItemRepo  port.CartItemRepository
 	PaymentRepo   port.PaymentRepository
+	BalanceSvc    port.BalanceService
+	Rules         *config.Business
 }
 
 func NewCheckoutService(
 	productRepo port.ProductRepository,
+	variantRepo port.ProductVariantRepository,
 	orderRepo port.OrderRepository,
 	orderItemRepo port.OrderItemRepository,
 	cartRepo port.CartRepository,
 	cartItemRepo port.CartItemRepository,
 	paymentRepo port.PaymentRepository,
+	balanceSvc port.BalanceService,
+	rules *config.Business,
 ) *CheckoutService {
 	return &CheckoutService{
 		ProductRepo:   productRepo,
+		VariantRepo:   variantRepo,
 		OrderRepo:     orderRepo,
 		OrderItemRepo: orderItemRepo,
 		CartRepo:      cartRepo,
 		CartItemRepo:  cartItemRepo,
 		PaymentRepo:   paymentRepo,
+		BalanceSvc:    balanceSvc,
+		Rules:         rules,
 	}
 }
 
-func (s *CheckoutService) Checkout(ctx context.Context, userID int, paymentMethod string) (*dto.CheckoutResponse, error) {
+// Checkout places an order for the cart at current prices. cartVersion must be
+// the version of the cart the user reviewed, so a price or stock change since
+// then is acknowledged before it is charged
+func (s *CheckoutService) Checkout(ctx context.Context, userID int, paymentMethod string, cartVersion string) (*dto.CheckoutResponse, error) {
 	cart, err := s.CartRepo.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -47,27 +59,54 @@ func (s *CheckoutService) Checkout(ctx context.Context, userID int, paymentMetho
 		return nil, consts.ErrEmptyCart
 	}
 
-	items, err := s.CartItemRepo.Finds(ctx, map[string]interface{}{"cart_id": cart.ID})
+	// the items come with their products, so the number of queries does not
+	// grow with the size of the cart
+	items, err := s.CartItemRepo.FindsWithProducts(ctx, cart.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(items) == 0 {
+		return nil, consts.ErrEmptyCart
+	}
+
+	variants, err := sellLines(ctx, s.VariantRepo, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if cartVersion == "" {
+		return nil, consts.ErrCartVersionRequired
+	}
+	if newCartResponse(items).Version != cartVersion {
+		return nil, consts.ErrCartChanged
+	}
+
+	if s.Rules.MaxCartItems > 0 && len(items) > s.Rules.MaxCartItems {
+		return nil, consts.ErrCartItemLimitExceeded
+	}
+
 	var totalPrice float64
 	for _, item := range items {
-		product, err := s.ProductRepo.FindOne(ctx, item.ProductID)
-		if err != nil {
-			return nil, err
+		if s.Rules.MaxQuantityPerItem > 0 && item.Quantity > s.Rules.MaxQuantityPerItem {
+			return nil, consts.ErrQuantityLimitExceeded
 		}
+
+		product := item.Product
 		if product == nil {
-			return nil, errors.New("product not found")
+			return nil, consts.ErrCartItemUnavailable
 		}
 		if product.Stock < item.Quantity {
-			return nil, errors.New("insufficient stock")
+			return nil, consts.ErrInsufficientStock
 		}
 
 		totalPrice += product.Price * float64(item.Quantity)
 	}
 
+	if maxTotal := s.Rules.MaxOrderTotalFor(paymentMethod); maxTotal > 0 && totalPrice > maxTotal {
+		return nil, consts.ErrOrderTotalLimitExceeded
+	}
+
 	tNow := time.Now()
 	order := &domain.Order{
 		UserID:     userID,
@@ -81,42 +120,78 @@ func (s *CheckoutService) Checkout(ctx context.Context, userID int, paymentMetho
 		return nil, err
 	}
 
-	for _, item := range items {
-		product, err := s.ProductRepo.FindOne(ctx, item.ProductID)
-		if err != nil {
+	expiredAt := tNow.Add(s.Rules.PaymentExpiryFor(paymentMethod))
+
+	// wallet payments reserve the total now so it cannot be spent before the order is paid
+	if paymentMethod == "balance" {
+		if _, err := s.BalanceSvc.Hold(ctx, uint64(userID), order.ID, totalPrice, expiredAt); err != nil {
+			if updateErr := s.OrderRepo.Update(ctx, order.ID, &domain.Order{Status: "cancelled"}); updateErr != nil {
+				return nil, updateErr
+			}
 			return nil, err
 		}
+	}
 
-		if err := s.OrderItemRepo.Store(ctx, &domain.OrderItem{
+	orderItems := make([]domain.OrderItem, 0, len(items))
+	stocks := make(map[int]int, len(items))
+	variantStocks := make(map[int]int)
+	var productItems, variantItems []domain.OrderItem
+	for _, item := range items {
+		orderItem := domain.OrderItem{
 			OrderID:   order.ID,
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
-			Price:     product.Price,
-		}); err != nil {
-			return nil, err
+			Price:     item.Product.Price,
 		}
 
-		newStock := product.Stock - item.Quantity
-		if err := s.ProductRepo.UpdateStock(ctx, item.ProductID, newStock); err != nil {
-			return nil, err
+		// a variant keeps its own stock, the product's is left alone
+		if variant := variants[item.VariantID]; variant != nil {
+			orderItem.SKU = variant.SKU
+			variantStocks[item.VariantID] = item.Product.Stock - item.Quantity
+			variantItems = append(variantItems, orderItem)
+		} else {
+			stocks[item.ProductID] = item.Product.Stock - item.Quantity
+			productItems = append(productItems, orderItem)
 		}
+
+		orderItems = append(orderItems, orderItem)
+	}
+
+	// until the payment row exists the expiry worker can't find the order, so
+	// a failure from here on cancels it, puts back the stock already taken
+	// and gives the held balance back
+	if err := s.OrderItemRepo.StoreBatch(ctx, orderItems); err != nil {
+		return nil, s.abandonOrder(ctx, userID, order.ID, paymentMethod, nil, err)
+	}
+
+	if err := s.ProductRepo.UpdateStocks(ctx, stocks); err != nil {
+		return nil, s.abandonOrder(ctx, userID, order.ID, paymentMethod, nil, err)
+	}
+
+	if err := s.VariantRepo.UpdateStocks(ctx, variantStocks); err != nil {
+		return nil, s.abandonOrder(ctx, userID, order.ID, paymentMethod, productItems, err)
 	}
 
-	expiredAt := tNow.Add(10 * time.Minute)
-	err = s.PaymentRepo.Store(ctx, &domain.Payment{
+	payment := &domain.Payment{
 		OrderID:       order.ID,
 		PaymentMethod: paymentMethod,
 		PaymentStatus: "pending",
 		UpdatedAt:     tNow,
 		CreatedAt:     tNow,
 		ExpiredAt:     expiredAt,
-	})
-	if err != nil {
-		return nil, err
+	}
+	if err := s.PaymentRepo.Store(ctx, payment); err != nil {
+		return nil, s.abandonOrder(ctx, userID, order.ID, paymentMethod, orderItems, err)
 	}
 
+	// the cart is still there to be checked out again, so this order must not
+	// be paid either
 	if err := s.ClearCart(ctx, userID, cart.ID); err != nil {
-		return nil, err
+		if updateErr := s.PaymentRepo.Update(ctx, payment.ID, &domain.Payment{PaymentStatus: "failed"}); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, s.abandonOrder(ctx, userID, order.ID, paymentMethod, orderItems, err)
 	}
 
 	return &dto.CheckoutResponse{
@@ -126,13 +201,72 @@ func (s *CheckoutService) Checkout(ctx context.Context, userID int, paymentMetho
 	}, nil
 }
 
+// abandonOrder cancels an order whose checkout failed part way, putting the
+// stock of the restock items back and releasing the balance held for a wallet
+// payment. It returns cause unless the clean up itself fails
+func (s *CheckoutService) abandonOrder(ctx context.Context, userID int, orderID int, paymentMethod string, restock []domain.OrderItem, cause error) error {
+	var cleanupErr error
+	for _, item := range restock {
+		if err := RestockOrderItem(ctx, s.ProductRepo, s.VariantRepo, item); err != nil && cleanupErr == nil {
+			cleanupErr = err
+		}
+	}
+
+	if paymentMethod == "balance" {
+		if err := s.BalanceSvc.ReleaseHold(ctx, uint64(userID), orderID); err != nil && cleanupErr == nil {
+			cleanupErr = err
+		}
+	}
+
+	if err := s.OrderRepo.Update(ctx, orderID, &domain.Order{Status: "cancelled"}); err != nil {
+		return err
+	}
+	if cleanupErr != nil {
+		return cleanupErr
+	}
+
+	return cause
+}
+
+// RestockOrderItem puts the quantity of an order item back into the stock of
+// its variant, or of its product when it was sold without one. Nothing is put
+// back when the variant or product has been deleted since
+func RestockOrderItem(ctx context.Context, productRepo port.ProductRepository, variantRepo port.ProductVariantRepository, item domain.OrderItem) error {
+	if item.VariantID != 0 {
+		variant, err := variantRepo.FindOne(ctx, item.VariantID)
+		if err != nil || variant == nil {
+			return err
+		}
+
+		return variantRepo.UpdateStock(ctx, item.VariantID, variant.Stock+item.Quantity)
+	}
+
+	product, err := productRepo.FindOne(ctx, item.ProductID)
+	if err != nil || product == nil {
+		return err
+	}
+
+	return productRepo.UpdateStock(ctx, item.ProductID, product.Stock+item.Quantity)
+}
+
 func (s *CheckoutService) ClearCart(ctx context.Context, userID int, cartID int) error {
-	err := s.CartRepo.DeleteByUserID(ctx, userID)
+	return clearCart(ctx, s.CartRepo, s.CartItemRepo, &domain.Cart{ID: cartID, UserID: userID})
+}
+
+// clearCart removes a cart and its items, a user's cart is looked up by its
+// owner as only one cart per user is kept
+func clearCart(ctx context.Context, cartRepo port.CartRepository, cartItemRepo port.CartItemRepository, cart *domain.Cart) error {
+	var err error
+	if cart.IsGuest() {
+		err = cartRepo.Delete(ctx, cart.ID)
+	} else {
+		err = cartRepo.DeleteByUserID(ctx, cart.UserID)
+	}
 	if err != nil {
 		return err
 	}
 
-	err = s.CartItemRepo.DeleteByCartID(ctx, cartID)
+	err = cartItemRepo.DeleteByCartID(ctx, cart.ID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"go.uber.org/zap"
+)
+
+type MoneyRequestService struct {
+	repo       port.MoneyRequestRepository
+	userRepo   port.UserRepository
+	balanceSvc port.BalanceService
+	rules      *config.Business
+	log        *zap.Logger
+}
+
+func NewMoneyRequestService(repo port.MoneyRequestRepository, userRepo port.UserRepository, balanceSvc port.BalanceService, rules *config.Business, log *zap.Logger) *MoneyRequestService {
+	return &MoneyRequestService{
+		repo:       repo,
+		userRepo:   userRepo,
+		balanceSvc: balanceSvc,
+		rules:      rules,
+		log:        log,
+	}
+}
+
+// Create asks the payers for money, a split amount is shared evenly in whole
+// cents with any remainder going to the first payers
+func (s *MoneyRequestService) Create(ctx context.Context, userID uint64, request dto.MoneyRequestRequest) (*domain.MoneyRequest, error) {
+	payers := request.Payers
+	if len(request.SplitWith) > 0 {
+		payers = splitAmount(request.SplitAmount, request.SplitWith, request.IncludeSelf)
+	}
+
+	expiresAt := time.Now().Add(s.rules.MoneyRequestTTL)
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(time.Now()) {
+			return nil, consts.ErrInvalidMoneyRequest
+		}
+		expiresAt = *request.ExpiresAt
+	}
+
+	moneyRequest := &domain.MoneyRequest{
+		RequesterID: userID,
+		Note:        request.Note,
+		ExpiresAt:   expiresAt,
+	}
+
+	seen := make(map[uint64]bool, len(payers))
+	for _, payer := range payers {
+		if payer.UserID == userID || seen[payer.UserID] || payer.Amount <= 0 {
+			return nil, consts.ErrInvalidMoneyRequest
+		}
+		seen[payer.UserID] = true
+
+		// a share is paid without the transfer review, so amounts that would
+		// need an admin review can't be requested
+		if s.rules.TransferVerificationFor(payer.Amount) == string(domain.TransferVerificationReview) {
+			return nil, consts.ErrTransferNeedsReview
+		}
+
+		if _, err := s.userRepo.GetUserByID(ctx, payer.UserID); err != nil {
+			return nil, err
+		}
+
+		moneyRequest.Amount += payer.Amount
+		moneyRequest.Payers = append(moneyRequest.Payers, domain.MoneyRequestPayer{
+			PayerID: payer.UserID,
+			Amount:  payer.Amount,
+		})
+	}
+
+	if len(moneyRequest.Payers) == 0 {
+		return nil, consts.ErrInvalidMoneyRequest
+	}
+
+	if err := s.repo.Store(ctx, moneyRequest); err != nil {
+		return nil, err
+	}
+
+	return moneyRequest, nil
+}
+
+func (s *MoneyRequestService) ListSent(ctx context.Context, userID uint64, request dto.ListMoneyRequestRequest) ([]domain.MoneyRequest, error) {
+	filter := map[string]interface{}{"requester_id": userID}
+	if request.Status != "" {
+		filter["status"] = request.Status
+	}
+
+	return s.repo.Finds(ctx, filter)
+}
+
+func (s *MoneyRequestService) ListReceived(ctx context.Context, userID uint64, request dto.ListMoneyRequestRequest) ([]domain.MoneyRequest, error) {
+	filter := map[string]interface{}{"payer_id": userID}
+	if request.Status != "" {
+		filter["status"] = request.Status
+	}
+
+	return s.repo.Finds(ctx, filter)
+}
+
+// Get returns a request visible to its requester and payers
+func (s *MoneyRequestService) Get(ctx context.Context, userID uint64, id uint64) (*domain.MoneyRequest, error) {
+	moneyRequest, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if moneyRequest == nil || (moneyRequest.RequesterID != userID && moneyRequest.Payer(userID) == nil) {
+		return nil, consts.ErrDataNotFound
+	}
+
+	return moneyRequest, nil
+}
+
+// Pay transfers the user's share to the requester with the request ID as the
+// ledger reference. A share that needs a verification code is confirmed with a
+// transfer inquiry first. The share is claimed so it can't be paid twice, and
+// handed back if the transfer fails
+func (s *MoneyRequestService) Pay(ctx context.Context, userID uint64, id uint64, request dto.PayMoneyRequestRequest) (*dto.TransferResponse, error) {
+	moneyRequest, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if moneyRequest == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	payer := moneyRequest.Payer(userID)
+	if payer == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	if s.rules.TransferVerificationFor(payer.Amount) == string(domain.TransferVerificationOTP) {
+		if request.InquiryID == "" {
+			return nil, consts.ErrTransferNeedsOTP
+		}
+
+		confirmation := dto.TransferRequest{InquiryID: request.InquiryID, OTP: request.OTP}
+		if err := s.balanceSvc.VerifyTransfer(ctx, userID, moneyRequest.RequesterID, payer.Amount, confirmation); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.ClosePayer(ctx, id, userID, domain.MoneyRequestPayerPaid); err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.balanceSvc.Transfer(ctx, userID, moneyRequest.RequesterID, payer.Amount, id)
+	if err != nil {
+		if reopenErr := s.repo.ReopenPayer(ctx, id, userID); reopenErr != nil {
+			s.log.Error("Failed to reopen money request share", zap.Uint64("money_request_id", id), zap.Uint64("payer_id", userID), zap.Error(reopenErr))
+		}
+		return nil, err
+	}
+
+	if err := s.repo.Complete(ctx, id); err != nil {
+		s.log.Error("Failed to complete money request", zap.Uint64("money_request_id", id), zap.Error(err))
+	}
+
+	return transfer, nil
+}
+
+func (s *MoneyRequestService) Decline(ctx context.Context, userID uint64, id uint64) error {
+	moneyRequest, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+	if moneyRequest == nil || moneyRequest.Payer(userID) == nil {
+		return consts.ErrDataNotFound
+	}
+
+	if err := s.repo.ClosePayer(ctx, id, userID, domain.MoneyRequestPayerDeclined); err != nil {
+		return err
+	}
+
+	return s.repo.Complete(ctx, id)
+}
+
+// Cancel withdraws a request, shares already paid stay paid
+func (s *MoneyRequestService) Cancel(ctx context.Context, userID uint64, id uint64) error {
+	moneyRequest, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+	if moneyRequest == nil || moneyRequest.RequesterID != userID {
+		return consts.ErrDataNotFound
+	}
+
+	return s.repo.Cancel(ctx, id)
+}
+
+// splitAmount shares total evenly in cents between the users, and the
+// requester too when includeSelf is set
+func splitAmount(total float64, userIDs []uint64, includeSelf bool) []dto.MoneyRequestPayerInput {
+	shares := int64(len(userIDs))
+	if includeSelf {
+		shares++
+	}
+
+	cents := int64(math.Round(total * 100))
+	share := cents / shares
+	remainder := cents - share*shares
+
+	payers := make([]dto.MoneyRequestPayerInput, 0, len(userIDs))
+	for _, userID := range userIDs {
+		amount := share
+		if remainder > 0 {
+			amount++
+			remainder--
+		}
+		payers = append(payers, dto.MoneyRequestPayerInput{UserID: userID, Amount: float64(amount) / 100})
+	}
+
+	return payers
+}
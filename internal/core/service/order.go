@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
 	"github.com/aldotp/ecommerce-go-api/pkg/consts"
@@ -42,12 +43,28 @@ func (s *OrderService) GetOrder(ctx context.Context, orderID int, userID int) (*
 	return s.OrderRepo.FindOne(ctx, orderID, userID)
 }
 
-func (s *OrderService) ListOrders(ctx context.Context, userId int) ([]domain.Order, error) {
+// ListOrders lists one page of the user's orders
+func (s *OrderService) ListOrders(ctx context.Context, userId int, param dto.ListOrderRequest) (*dto.PageResponse[domain.Order], error) {
+	page, err := newPageRequest(param.PageRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	filter := map[string]interface{}{}
 
 	if userId != 0 {
 		filter["user_id"] = userId
 	}
+	if param.Status != "" {
+		filter["status"] = param.Status
+	}
+
+	orders, err := s.OrderRepo.Finds(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
 
-	return s.OrderRepo.Finds(ctx, filter)
+	return newPageResponse(orders, page, func(order domain.Order) domain.Order {
+		return order
+	}), nil
 }
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+const defaultPageSize = 20
+
+// newPageRequest turns the paging query parameters into a page request, the
+// repository checks the sort field
+func newPageRequest(request dto.PageRequest) (domain.PageRequest, error) {
+	page := domain.PageRequest{
+		Sort:  strings.TrimPrefix(request.Sort, "-"),
+		Desc:  strings.HasPrefix(request.Sort, "-"),
+		Limit: request.PageSize,
+	}
+
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+
+	if request.Cursor != "" {
+		cursor, err := decodePageCursor(request.Cursor)
+		if err != nil {
+			return page, err
+		}
+		page.After = cursor
+	} else if request.Page > 1 {
+		page.Offset = (request.Page - 1) * page.Limit
+	}
+
+	return page, nil
+}
+
+// newPageResponse wraps a page in the listing envelope, converting every item
+// with convert
+func newPageResponse[T, R any](page *domain.Page[T], request domain.PageRequest, convert func(T) R) *dto.PageResponse[R] {
+	response := &dto.PageResponse[R]{
+		Items:    make([]R, 0, len(page.Items)),
+		Total:    page.Total,
+		PageSize: request.Limit,
+	}
+
+	if request.After == nil {
+		response.Page = request.Offset/request.Limit + 1
+	}
+
+	for _, item := range page.Items {
+		response.Items = append(response.Items, convert(item))
+	}
+
+	if page.Next != nil {
+		response.NextCursor = encodePageCursor(page.Next)
+	}
+
+	return response
+}
+
+func encodePageCursor(cursor *domain.PageCursor) string {
+	raw, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodePageCursor(value string) (*domain.PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, consts.ErrInvalidCursor
+	}
+
+	var cursor domain.PageCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort == "" {
+		return nil, consts.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/rabbitmq"
@@ -39,6 +40,10 @@ func (s *PaymentService) MakePayment(ctx context.Context, userID int, orderID in
 		return nil
 	}
 
+	if existPayment.PaymentStatus == "failed" {
+		return consts.ErrPaymentClosed
+	}
+
 	order, err := s.OrderRepo.FindOne(ctx, orderID, userID)
 	if err != nil {
 		return err
@@ -49,20 +54,15 @@ func (s *PaymentService) MakePayment(ctx context.Context, userID int, orderID in
 	}
 
 	if existPayment.PaymentMethod == "balance" {
-		balance, err := s.BalanceRepo.GetBalance(ctx, uint64(userID))
-		if err != nil {
-			return err
+		// a captured hold is an order already paid, a released one cannot be paid
+		err = s.BalanceSvc.CaptureHold(ctx, uint64(userID), orderID)
+		if errors.Is(err, consts.ErrHoldNotFound) {
+			// orders placed before checkout started holding funds are paid directly
+			err = s.payFromBalance(ctx, userID, order)
 		}
-
-		if balance < float64(order.TotalPrice) {
-			return consts.ErrInsufficientBalance
-		}
-
-		err = s.BalanceSvc.Withdraw(ctx, uint64(userID), float64(order.TotalPrice))
 		if err != nil {
 			return err
 		}
-
 	}
 
 	payment := domain.Payment{
@@ -88,3 +88,16 @@ func (s *PaymentService) MakePayment(ctx context.Context, userID int, orderID in
 
 	return nil
 }
+
+func (s *PaymentService) payFromBalance(ctx context.Context, userID int, order *domain.Order) error {
+	balance, err := s.BalanceRepo.GetBalance(ctx, uint64(userID))
+	if err != nil {
+		return err
+	}
+
+	if balance < float64(order.TotalPrice) {
+		return consts.ErrInsufficientBalance
+	}
+
+	return s.BalanceSvc.Pay(ctx, uint64(userID), order.ID, float64(order.TotalPrice))
+}
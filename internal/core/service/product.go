@@ -6,20 +6,33 @@ import (
 	"time"
 
 	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/rabbitmq"
 	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
 	"github.com/aldotp/ecommerce-go-api/internal/core/port"
 	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+	"go.uber.org/zap"
 )
 
 type ProductService struct {
-	repo  port.ProductRepository
-	cache port.CacheInterface
+	repo         port.ProductRepository
+	cache        port.CacheInterface
+	wishlistRepo port.WishlistRepository
+	imageRepo    port.ProductImageRepository
+	storage      gcs.StorageInterface
+	rabbitmq     rabbitmq.RabbitMqInterface
+	log          *zap.Logger
 }
 
-func NewProductService(repo port.ProductRepository, cache port.CacheInterface) *ProductService {
+func NewProductService(repo port.ProductRepository, cache port.CacheInterface, wishlistRepo port.WishlistRepository, imageRepo port.ProductImageRepository, storage gcs.StorageInterface, rabbitmq rabbitmq.RabbitMqInterface, log *zap.Logger) *ProductService {
 	return &ProductService{
-		repo:  repo,
-		cache: cache,
+		repo:         repo,
+		cache:        cache,
+		wishlistRepo: wishlistRepo,
+		imageRepo:    imageRepo,
+		storage:      storage,
+		rabbitmq:     rabbitmq,
+		log:          log,
 	}
 }
 
@@ -31,6 +44,7 @@ func (s *ProductService) Store(ctx context.Context, data dto.ProductRequest) err
 		Price:       data.Price,
 		Stock:       data.Stock,
 		CategoryID:  data.CategoryID,
+		Attributes:  data.Attributes,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -45,37 +59,183 @@ func (s *ProductService) FindOne(ctx context.Context, productID int) (*domain.Pr
 		return nil, consts.ErrDataNotFound
 	}
 
-	return product, nil
+	products := []domain.Product{*product}
+	if err := s.loadImages(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return &products[0], nil
 }
 
-// Find multiple products
-func (s *ProductService) Finds(ctx context.Context, param dto.ListProductRequest) ([]domain.Product, error) {
+// Finds lists one page of products
+func (s *ProductService) Finds(ctx context.Context, param dto.ListProductRequest) (*dto.PageResponse[dto.ProductResponse], error) {
+	page, err := newPageRequest(param.PageRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	filter := make(map[string]interface{})
 
-	if param.Page != 0 {
-		filter["page"] = param.Page
+	if param.Search != "" {
+		filter["search"] = strings.ToLower(param.Search)
 	}
-	if param.PageSize != 0 {
-		filter["page_size"] = param.PageSize
+
+	products, err := s.repo.Finds(ctx, filter, page)
+	if err != nil {
+		return nil, err
 	}
 
-	if param.Search != "" {
-		filter["search"] = strings.ToLower(param.Search)
+	if err := s.loadImages(ctx, products.Items); err != nil {
+		return nil, err
+	}
+
+	return newPageResponse(products, page, func(product domain.Product) dto.ProductResponse {
+		return dto.NewProductResponse(&product, domain.ImageSize(param.ImageSize))
+	}), nil
+}
+
+// priceBuckets are the price ranges a product search counts matches in
+var priceBuckets = []domain.PriceBucket{
+	{Min: 0, Max: 50},
+	{Min: 50, Max: 100},
+	{Min: 100, Max: 250},
+	{Min: 250, Max: 500},
+	{Min: 500, Max: 1000},
+	{Min: 1000},
+}
+
+// Search runs a full-text product search, when it matches nothing the search
+// is run again on name similarity so a misspelled query still finds products
+func (s *ProductService) Search(ctx context.Context, param dto.SearchProductRequest) (*dto.SearchProductResponse, error) {
+	pageSize := param.PageSize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	page := param.Page
+	if page == 0 {
+		page = 1
+	}
+
+	search := domain.ProductSearch{
+		Query:        param.Query,
+		CategoryID:   param.CategoryID,
+		MinPrice:     param.MinPrice,
+		MaxPrice:     param.MaxPrice,
+		InStock:      param.InStock,
+		Attributes:   param.Attributes,
+		Sort:         strings.TrimPrefix(param.Sort, "-"),
+		Desc:         strings.HasPrefix(param.Sort, "-"),
+		Limit:        pageSize,
+		Offset:       (page - 1) * pageSize,
+		PriceBuckets: priceBuckets,
+	}
+
+	result, err := s.repo.Search(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Total == 0 && strings.TrimSpace(param.Query) != "" {
+		search.Fuzzy = true
+
+		result, err = s.repo.Search(ctx, search)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.loadImages(ctx, result.Items); err != nil {
+		return nil, err
 	}
 
-	return s.repo.Finds(ctx, filter)
+	return dto.NewSearchProductResponse(result, page, pageSize, domain.ImageSize(param.ImageSize)), nil
 }
 
-// Update a product by ID
+// loadImages sets the gallery of every product with one query
+func (s *ProductService) loadImages(ctx context.Context, products []domain.Product) error {
+	ids := make([]int, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+
+	images, err := s.imageRepo.FindByProductIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	images = withImageURLs(s.storage, images)
+
+	byProduct := make(map[int][]domain.ProductImage)
+	for _, image := range images {
+		byProduct[image.ProductID] = append(byProduct[image.ProductID], image)
+	}
+
+	for i := range products {
+		products[i].Images = byProduct[products[i].ID]
+	}
+
+	return nil
+}
+
+// Update a product by ID, a lower price is announced to the users who saved
+// the product in a wishlist
 func (s *ProductService) Update(ctx context.Context, id int, data dto.ProductRequest) error {
+	product, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if product == nil {
+		return consts.ErrDataNotFound
+	}
+
 	updatedData := domain.Product{
-		Name:      data.Name,
-		Price:     data.Price,
-		Stock:     data.Stock,
-		UpdatedAt: time.Now(),
+		Name:       data.Name,
+		Price:      data.Price,
+		Stock:      data.Stock,
+		Attributes: data.Attributes,
+		UpdatedAt:  time.Now(),
+	}
+
+	if err := s.repo.Update(ctx, id, updatedData); err != nil {
+		return err
 	}
 
-	return s.repo.Update(ctx, id, updatedData)
+	if data.Price < product.Price {
+		s.publishPriceDrop(ctx, product, data.Price)
+	}
+
+	return nil
+}
+
+// publishPriceDrop queues a PriceDropEvent when the product is in a
+// wishlist. The product is already updated, a failure is only logged
+func (s *ProductService) publishPriceDrop(ctx context.Context, product *domain.Product, newPrice float64) {
+	userIDs, err := s.wishlistRepo.FindUserIDsByProductID(ctx, product.ID)
+	if err != nil {
+		s.log.Error("Failed to find the wishlists of a product", zap.Int("product_id", product.ID), zap.Error(err))
+		return
+	}
+
+	if len(userIDs) == 0 {
+		return
+	}
+
+	err = s.rabbitmq.Publish(ctx, rabbitmq.RabbitMqPublishRequest{
+		QueueName: consts.QueuePriceDrop,
+		Messages: dto.PriceDropEvent{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			OldPrice:    product.Price,
+			NewPrice:    newPrice,
+			UserIDs:     userIDs,
+			ChangedAt:   time.Now(),
+		},
+	})
+	if err != nil {
+		s.log.Error("Failed to publish price drop", zap.Int("product_id", product.ID), zap.Error(err))
+	}
 }
 
 // Delete a product by ID
@@ -0,0 +1,492 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/rabbitmq"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+	"github.com/aldotp/ecommerce-go-api/pkg/thumbnail"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// productImageTypes are the image types a product gallery accepts, with the
+// extension their files are stored under
+var productImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// imageDerivativeSizes are the resized copies made of every gallery image,
+// each fits a square of MaxSide pixels
+var imageDerivativeSizes = []struct {
+	Size    domain.ImageSize
+	MaxSide int
+}{
+	{Size: domain.ImageSizeThumbnail, MaxSide: 200},
+	{Size: domain.ImageSizeMedium, MaxSide: 600},
+	{Size: domain.ImageSizeLarge, MaxSide: 1200},
+}
+
+type ProductImageService struct {
+	repo        port.ProductImageRepository
+	productRepo port.ProductRepository
+	storage     gcs.StorageInterface
+	rabbitmq    rabbitmq.RabbitMqInterface
+	maxSize     int64
+	uploadTTL   time.Duration
+	log         *zap.Logger
+}
+
+// NewProductImageService creates the gallery service, storage may be nil when
+// no bucket is configured and every upload then fails with
+// ErrStorageNotConfigured
+func NewProductImageService(repo port.ProductImageRepository, productRepo port.ProductRepository, storage gcs.StorageInterface, rabbitmq rabbitmq.RabbitMqInterface, maxSize int64, uploadTTL time.Duration, log *zap.Logger) *ProductImageService {
+	return &ProductImageService{
+		repo:        repo,
+		productRepo: productRepo,
+		storage:     storage,
+		rabbitmq:    rabbitmq,
+		maxSize:     maxSize,
+		uploadTTL:   uploadTTL,
+		log:         log,
+	}
+}
+
+// ListImages lists the gallery of a product in order
+func (s *ProductImageService) ListImages(ctx context.Context, productID int) ([]domain.ProductImage, error) {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.repo.FindByProductIDs(ctx, []int{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	if images == nil {
+		images = []domain.ProductImage{}
+	}
+
+	return withImageURLs(s.storage, images), nil
+}
+
+// UploadImage stores an image sent through the API at the end of the gallery.
+// The type is taken from the file content, not from what the client claims
+func (s *ProductImageService) UploadImage(ctx context.Context, productID int, file multipart.File, size int64) (*domain.ProductImage, error) {
+	defer file.Close()
+
+	if s.storage == nil {
+		return nil, consts.ErrStorageNotConfigured
+	}
+
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	if size > s.maxSize {
+		return nil, consts.ErrImageTooLarge
+	}
+
+	mime, err := mimetype.DetectReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	ext, ok := productImageTypes[mime.String()]
+	if !ok {
+		return nil, consts.ErrUnsupportedImageType
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	image := &domain.ProductImage{
+		ProductID:   productID,
+		ObjectKey:   productImageKey(productID, ext),
+		ContentType: mime.String(),
+		Size:        size,
+	}
+
+	err = s.storage.Upload(ctx, &gcs.FileUploadObject{File: file, FileName: image.ObjectKey, ContentType: image.ContentType})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Store(ctx, image); err != nil {
+		s.storage.Delete(ctx, image.ObjectKey)
+		return nil, err
+	}
+
+	image.URL = s.storage.GenerateUrl(image.ObjectKey)
+	s.publishUploaded(ctx, image)
+
+	return image, nil
+}
+
+// CreateUploadURL returns a presigned URL to upload an image straight to the
+// bucket. The upload only joins the gallery once it is confirmed
+func (s *ProductImageService) CreateUploadURL(ctx context.Context, productID int, request dto.ProductImageUploadURLRequest) (*dto.ProductImageUploadURLResponse, error) {
+	if s.storage == nil {
+		return nil, consts.ErrStorageNotConfigured
+	}
+
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	ext, ok := productImageTypes[strings.ToLower(request.ContentType)]
+	if !ok {
+		return nil, consts.ErrUnsupportedImageType
+	}
+
+	if request.Size > s.maxSize {
+		return nil, consts.ErrImageTooLarge
+	}
+
+	key := productImageKey(productID, ext)
+	url, err := s.storage.GeneratePresignedUrl(key, s.uploadTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ProductImageUploadURLResponse{
+		ObjectKey: key,
+		UploadURL: url,
+		Method:    "PUT",
+		ExpiresAt: time.Now().Add(s.uploadTTL),
+	}, nil
+}
+
+// ConfirmUpload adds an image uploaded with a presigned URL to the gallery.
+// The file is checked like an API upload and removed when it is rejected
+func (s *ProductImageService) ConfirmUpload(ctx context.Context, productID int, request dto.ConfirmProductImageRequest) (*domain.ProductImage, error) {
+	if s.storage == nil {
+		return nil, consts.ErrStorageNotConfigured
+	}
+
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	// only keys handed out for this product can be confirmed, not the
+	// derivatives stored next to them
+	key := request.ObjectKey
+	if !isUploadKey(productID, key) {
+		return nil, consts.ErrImageNotUploaded
+	}
+
+	// the size is checked before the file is downloaded, a presigned upload
+	// is not bound to the size it was requested for
+	size, err := s.storage.Size(ctx, key)
+	if err == gcs.ErrObjectNotFound {
+		return nil, consts.ErrImageNotUploaded
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if size > s.maxSize {
+		s.storage.Delete(ctx, key)
+		return nil, consts.ErrImageTooLarge
+	}
+
+	content, err := s.storage.Download(ctx, key)
+	if err == gcs.ErrObjectNotFound {
+		return nil, consts.ErrImageNotUploaded
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := s.checkUpload(key, content)
+	if err != nil {
+		s.storage.Delete(ctx, key)
+		return nil, err
+	}
+
+	image.ProductID = productID
+	if err := s.repo.Store(ctx, image); err != nil {
+		return nil, err
+	}
+
+	image.URL = s.storage.GenerateUrl(image.ObjectKey)
+	s.publishUploaded(ctx, image)
+
+	return image, nil
+}
+
+// DeleteImage removes an image from the gallery and its files from the
+// storage
+func (s *ProductImageService) DeleteImage(ctx context.Context, productID, imageID int) error {
+	image, err := s.findImage(ctx, productID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, productID, imageID); err != nil {
+		return err
+	}
+
+	// the gallery no longer points at the file, so a failed delete only
+	// leaves an orphan in the bucket
+	if s.storage != nil {
+		s.storage.Delete(ctx, image.ObjectKey)
+		for _, derivative := range image.Derivatives {
+			s.storage.Delete(ctx, derivative.ObjectKey)
+		}
+	}
+
+	return nil
+}
+
+// SetPrimary makes an image the primary image of its product
+func (s *ProductImageService) SetPrimary(ctx context.Context, productID, imageID int) error {
+	if _, err := s.findImage(ctx, productID, imageID); err != nil {
+		return err
+	}
+
+	return s.repo.SetPrimary(ctx, productID, imageID)
+}
+
+// ReorderImages puts the gallery in the order of the request, which has to
+// list every image of the product exactly once
+func (s *ProductImageService) ReorderImages(ctx context.Context, productID int, request dto.ReorderProductImagesRequest) error {
+	if err := s.checkProduct(ctx, productID); err != nil {
+		return err
+	}
+
+	images, err := s.repo.FindByProductIDs(ctx, []int{productID})
+	if err != nil {
+		return err
+	}
+
+	if len(request.ImageIDs) != len(images) {
+		return consts.ErrInvalidImageOrder
+	}
+
+	listed := make(map[int]bool, len(images))
+	for _, image := range images {
+		listed[image.ID] = false
+	}
+
+	for _, id := range request.ImageIDs {
+		seen, ok := listed[id]
+		if !ok || seen {
+			return consts.ErrInvalidImageOrder
+		}
+		listed[id] = true
+	}
+
+	return s.repo.Reorder(ctx, productID, request.ImageIDs)
+}
+
+// GenerateDerivatives makes the resized JPEG copies of a gallery image and
+// records them on the image. An image that cannot be decoded is marked failed
+// and ErrInvalidImage returned, an image deleted in the meantime is skipped
+func (s *ProductImageService) GenerateDerivatives(ctx context.Context, imageID int) error {
+	if s.storage == nil {
+		return consts.ErrStorageNotConfigured
+	}
+
+	image, err := s.repo.FindOne(ctx, imageID)
+	if err != nil {
+		return err
+	}
+
+	if image == nil {
+		return nil
+	}
+
+	content, err := s.storage.Download(ctx, image.ObjectKey)
+	if err == gcs.ErrObjectNotFound {
+		return s.failDerivatives(ctx, imageID, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	src, err := thumbnail.Decode(content.Bytes())
+	if err != nil {
+		return s.failDerivatives(ctx, imageID, err)
+	}
+
+	derivatives := make(map[domain.ImageSize]domain.ImageDerivative, len(imageDerivativeSizes))
+	for _, derived := range imageDerivativeSizes {
+		resized := thumbnail.Fit(src, derived.MaxSide)
+
+		var buff bytes.Buffer
+		if err := thumbnail.EncodeJPEG(&buff, resized); err != nil {
+			return err
+		}
+
+		key := derivativeKey(image.ObjectKey, derived.Size)
+		err := s.storage.Upload(ctx, &gcs.FileUploadObject{File: gcs.NewBytesFile(buff.Bytes()), FileName: key, ContentType: "image/jpeg"})
+		if err != nil {
+			return err
+		}
+
+		derivatives[derived.Size] = domain.ImageDerivative{
+			ObjectKey: key,
+			Width:     resized.Bounds().Dx(),
+			Height:    resized.Bounds().Dy(),
+		}
+	}
+
+	err = s.repo.UpdateDerivatives(ctx, imageID, derivatives, domain.DerivativesReady)
+	if err == consts.ErrDataNotFound {
+		// deleted while resizing, its copies would be orphans
+		for _, derivative := range derivatives {
+			s.storage.Delete(ctx, derivative.ObjectKey)
+		}
+		return nil
+	}
+
+	return err
+}
+
+// failDerivatives marks the derivatives of an image failed, the image keeps
+// being served in its original size
+func (s *ProductImageService) failDerivatives(ctx context.Context, imageID int, cause error) error {
+	err := s.repo.UpdateDerivatives(ctx, imageID, nil, domain.DerivativesFailed)
+	if err != nil && err != consts.ErrDataNotFound {
+		return err
+	}
+
+	return fmt.Errorf("%w: %v", consts.ErrInvalidImage, cause)
+}
+
+// publishUploaded queues the derivatives of a new image. The image is already
+// in the gallery in its original size, a failure is only logged
+func (s *ProductImageService) publishUploaded(ctx context.Context, image *domain.ProductImage) {
+	if s.rabbitmq == nil {
+		return
+	}
+
+	err := s.rabbitmq.Publish(ctx, rabbitmq.RabbitMqPublishRequest{
+		QueueName: consts.QueueProductImageDerivatives,
+		Messages: dto.ProductImageUploadedEvent{
+			ImageID:    image.ID,
+			ProductID:  image.ProductID,
+			ObjectKey:  image.ObjectKey,
+			UploadedAt: image.CreatedAt,
+		},
+	})
+	if err != nil {
+		s.log.Error("Failed to publish product image upload", zap.Int("image_id", image.ID), zap.Error(err))
+	}
+}
+
+// checkUpload validates the content of an uploaded file and describes it as a
+// gallery image
+func (s *ProductImageService) checkUpload(key string, content *bytes.Buffer) (*domain.ProductImage, error) {
+	if int64(content.Len()) > s.maxSize {
+		return nil, consts.ErrImageTooLarge
+	}
+
+	mime := mimetype.Detect(content.Bytes())
+	if _, ok := productImageTypes[mime.String()]; !ok {
+		return nil, consts.ErrUnsupportedImageType
+	}
+
+	return &domain.ProductImage{
+		ObjectKey:   key,
+		ContentType: mime.String(),
+		Size:        int64(content.Len()),
+	}, nil
+}
+
+func (s *ProductImageService) checkProduct(ctx context.Context, productID int) error {
+	product, err := s.productRepo.FindOne(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if product == nil {
+		return consts.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// findImage returns an image of the product, the image of another product is
+// not found
+func (s *ProductImageService) findImage(ctx context.Context, productID, imageID int) (*domain.ProductImage, error) {
+	image, err := s.repo.FindOne(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if image == nil || image.ProductID != productID {
+		return nil, consts.ErrDataNotFound
+	}
+
+	return image, nil
+}
+
+// productImagePrefix is the folder of the images of a product in the storage
+func productImagePrefix(productID int) string {
+	return fmt.Sprintf("products/%d/", productID)
+}
+
+// productImageKey names a new image file of a product, the random name keeps
+// uploads from overwriting each other
+func productImageKey(productID int, ext string) string {
+	return productImagePrefix(productID) + uuid.NewString() + ext
+}
+
+// isUploadKey reports whether key has the form productImageKey gives the
+// images of the product
+func isUploadKey(productID int, key string) bool {
+	name, ok := strings.CutPrefix(key, productImagePrefix(productID))
+	if !ok {
+		return false
+	}
+
+	ext := path.Ext(name)
+	known := false
+	for _, imageExt := range productImageTypes {
+		known = known || ext == imageExt
+	}
+
+	id, err := uuid.Parse(strings.TrimSuffix(name, ext))
+
+	return known && err == nil && id.String() == strings.TrimSuffix(name, ext)
+}
+
+// derivativeKey names the copy of an image in a size, next to the image
+func derivativeKey(objectKey string, size domain.ImageSize) string {
+	return strings.TrimSuffix(objectKey, path.Ext(objectKey)) + "_" + string(size) + ".jpg"
+}
+
+// withImageURLs sets the URL of every image and its derivatives, storage may
+// be nil when images were stored before the bucket was unset
+func withImageURLs(storage gcs.StorageInterface, images []domain.ProductImage) []domain.ProductImage {
+	if storage == nil {
+		return images
+	}
+
+	for i := range images {
+		images[i].URL = storage.GenerateUrl(images[i].ObjectKey)
+		for size, derivative := range images[i].Derivatives {
+			derivative.URL = storage.GenerateUrl(derivative.ObjectKey)
+			images[i].Derivatives[size] = derivative
+		}
+	}
+
+	return images
+}
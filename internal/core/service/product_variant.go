@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+type ProductVariantService struct {
+	repo        port.ProductVariantRepository
+	productRepo port.ProductRepository
+}
+
+func NewProductVariantService(repo port.ProductVariantRepository, productRepo port.ProductRepository) *ProductVariantService {
+	return &ProductVariantService{
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+// ListOptions lists the option types of a product in display order
+func (s *ProductVariantService) ListOptions(ctx context.Context, productID int) ([]domain.ProductOption, error) {
+	if _, err := s.findProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindOptions(ctx, productID)
+}
+
+// CreateOption adds an option type to a product, option names are stored in
+// lower case so variants match them regardless of case
+func (s *ProductVariantService) CreateOption(ctx context.Context, productID int, request dto.ProductOptionRequest) (*domain.ProductOption, error) {
+	if _, err := s.findProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	option := &domain.ProductOption{
+		ProductID: productID,
+		Name:      strings.ToLower(strings.TrimSpace(request.Name)),
+		Values:    request.Values,
+		Position:  request.Position,
+	}
+
+	if err := s.repo.StoreOption(ctx, option); err != nil {
+		return nil, err
+	}
+
+	return option, nil
+}
+
+// DeleteOption removes an option type that no variant of the product uses
+func (s *ProductVariantService) DeleteOption(ctx context.Context, productID, optionID int) error {
+	options, err := s.repo.FindOptions(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	var option *domain.ProductOption
+	for i := range options {
+		if options[i].ID == optionID {
+			option = &options[i]
+		}
+	}
+
+	if option == nil {
+		return consts.ErrDataNotFound
+	}
+
+	variants, err := s.repo.FindByProductIDs(ctx, []int{productID})
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		if _, ok := variant.Options[option.Name]; ok {
+			return consts.ErrOptionInUse
+		}
+	}
+
+	return s.repo.DeleteOption(ctx, productID, optionID)
+}
+
+// ListVariants lists the variants of a product at the price they sell at
+func (s *ProductVariantService) ListVariants(ctx context.Context, productID int) ([]dto.ProductVariantResponse, error) {
+	product, err := s.findProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.repo.FindByProductIDs(ctx, []int{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ProductVariantResponse, 0, len(variants))
+	for i := range variants {
+		response = append(response, dto.NewProductVariantResponse(&variants[i], product))
+	}
+
+	return response, nil
+}
+
+func (s *ProductVariantService) GetVariant(ctx context.Context, productID, variantID int) (*dto.ProductVariantResponse, error) {
+	product, err := s.findProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := s.findVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.NewProductVariantResponse(variant, product)
+
+	return &response, nil
+}
+
+// CreateVariant adds a variant with one value for every option of the
+// product. A SKU, barcode or set of options already in use is
+// ErrConflictingData
+func (s *ProductVariantService) CreateVariant(ctx context.Context, productID int, request dto.ProductVariantRequest) (*dto.ProductVariantResponse, error) {
+	product, err := s.findProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := s.checkOptions(ctx, productID, request.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	variant := &domain.ProductVariant{
+		ProductID: productID,
+		SKU:       strings.TrimSpace(request.SKU),
+		Price:     request.Price,
+		Stock:     request.Stock,
+		Barcode:   strings.TrimSpace(request.Barcode),
+		Options:   options,
+	}
+
+	if err := s.repo.Store(ctx, variant); err != nil {
+		return nil, err
+	}
+
+	response := dto.NewProductVariantResponse(variant, product)
+
+	return &response, nil
+}
+
+// UpdateVariant replaces the SKU, price, stock, barcode and options of a
+// variant, a missing price falls back to the product price again
+func (s *ProductVariantService) UpdateVariant(ctx context.Context, productID, variantID int, request dto.ProductVariantRequest) error {
+	if _, err := s.findVariant(ctx, productID, variantID); err != nil {
+		return err
+	}
+
+	options, err := s.checkOptions(ctx, productID, request.Options)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, variantID, domain.ProductVariant{
+		SKU:     strings.TrimSpace(request.SKU),
+		Price:   request.Price,
+		Stock:   request.Stock,
+		Barcode: strings.TrimSpace(request.Barcode),
+		Options: options,
+	})
+}
+
+// DeleteVariant removes a variant along with the cart lines holding it,
+// orders keep the SKU they were placed with
+func (s *ProductVariantService) DeleteVariant(ctx context.Context, productID, variantID int) error {
+	if _, err := s.findVariant(ctx, productID, variantID); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, variantID)
+}
+
+func (s *ProductVariantService) findProduct(ctx context.Context, productID int) (*domain.Product, error) {
+	product, err := s.productRepo.FindOne(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	return product, nil
+}
+
+// findVariant returns a variant of the product, the variant of another
+// product is not found
+func (s *ProductVariantService) findVariant(ctx context.Context, productID, variantID int) (*domain.ProductVariant, error) {
+	variant, err := s.repo.FindOne(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if variant == nil || variant.ProductID != productID {
+		return nil, consts.ErrVariantNotFound
+	}
+
+	return variant, nil
+}
+
+// checkOptions matches the option values of a variant with the options of the
+// product, every option needs an allowed value and no other option may be
+// set. It returns the values keyed by the stored option names
+func (s *ProductVariantService) checkOptions(ctx context.Context, productID int, values map[string]string) (map[string]string, error) {
+	options, err := s.repo.FindOptions(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(options) {
+		return nil, consts.ErrInvalidVariantOptions
+	}
+
+	picked := make(map[string]string, len(values))
+	for name, value := range values {
+		picked[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+
+	checked := make(map[string]string, len(options))
+	for i := range options {
+		value, ok := picked[options[i].Name]
+		if !ok || !options[i].Allows(value) {
+			return nil, consts.ErrInvalidVariantOptions
+		}
+		checked[options[i].Name] = value
+	}
+
+	return checked, nil
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+	"github.com/aldotp/ecommerce-go-api/pkg/util"
+	"go.uber.org/zap"
+)
+
+// scheduleLease is how long a claimed schedule is hidden from other workers,
+// long enough to cover every retry of one run
+const scheduleLease = 5 * time.Minute
+
+type ScheduledTransferService struct {
+	repo       port.ScheduledTransferRepository
+	userRepo   port.UserRepository
+	balanceSvc port.BalanceService
+	mailer     port.EmailSender
+	rules      *config.Business
+	log        *zap.Logger
+}
+
+func NewScheduledTransferService(repo port.ScheduledTransferRepository, userRepo port.UserRepository, balanceSvc port.BalanceService, mailer port.EmailSender, rules *config.Business, log *zap.Logger) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		repo:       repo,
+		userRepo:   userRepo,
+		balanceSvc: balanceSvc,
+		mailer:     mailer,
+		rules:      rules,
+		log:        log,
+	}
+}
+
+// Create schedules a transfer, the amount is checked against the sender's
+// per transaction limit now and against the daily and monthly caps on each run.
+// Amounts that need a verification code are confirmed with a transfer inquiry
+// now, since runs happen without the sender
+func (s *ScheduledTransferService) Create(ctx context.Context, userID uint64, request dto.ScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+	if request.RecipientID == userID {
+		return nil, consts.ErrCannotSendBalanceSameAccount
+	}
+
+	schedule := &domain.ScheduledTransfer{
+		UserID:      userID,
+		RecipientID: request.RecipientID,
+		Amount:      request.Amount,
+		Frequency:   domain.ScheduledTransferFrequency(request.Frequency),
+		StartAt:     request.StartAt,
+		EndAt:       request.EndAt,
+	}
+
+	if err := s.validate(ctx, schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetUserByID(ctx, request.RecipientID); err != nil {
+		return nil, err
+	}
+
+	confirmation := dto.TransferRequest{InquiryID: request.InquiryID, OTP: request.OTP}
+	if err := s.verify(ctx, schedule, confirmation); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Store(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *ScheduledTransferService) List(ctx context.Context, userID uint64, request dto.ListScheduledTransferRequest) ([]domain.ScheduledTransfer, error) {
+	filter := map[string]interface{}{"user_id": userID}
+	if request.Status != "" {
+		filter["status"] = request.Status
+	}
+
+	return s.repo.Finds(ctx, filter)
+}
+
+// Get returns a schedule of the user together with its run history
+func (s *ScheduledTransferService) Get(ctx context.Context, userID uint64, id uint64) (*dto.ScheduledTransferDetailResponse, error) {
+	schedule, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := s.repo.FindRuns(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ScheduledTransferDetailResponse{ScheduledTransfer: *schedule, Runs: runs}, nil
+}
+
+func (s *ScheduledTransferService) Update(ctx context.Context, userID uint64, id uint64, request dto.UpdateScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+	schedule, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	amountChanged := request.Amount > 0 && request.Amount != schedule.Amount
+	if request.Amount > 0 {
+		schedule.Amount = request.Amount
+	}
+	if request.Frequency != "" {
+		schedule.Frequency = domain.ScheduledTransferFrequency(request.Frequency)
+	}
+	if request.EndAt != nil {
+		schedule.EndAt = request.EndAt
+	}
+	if request.Status != "" {
+		schedule.Status = domain.ScheduledTransferStatus(request.Status)
+	}
+
+	// a new start date or cadence restarts the schedule from its start date
+	if request.StartAt != nil || request.Frequency != "" {
+		if request.StartAt != nil {
+			schedule.StartAt = *request.StartAt
+		}
+		schedule.RunCount = 0
+		schedule.NextRunAt = schedule.StartAt
+
+		if err := s.validate(ctx, schedule, time.Now()); err != nil {
+			return nil, err
+		}
+	} else if err := s.validate(ctx, schedule, schedule.StartAt); err != nil {
+		return nil, err
+	}
+
+	if amountChanged {
+		confirmation := dto.TransferRequest{InquiryID: request.InquiryID, OTP: request.OTP}
+		if err := s.verify(ctx, schedule, confirmation); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// Cancel stops a schedule for good, runs already made are kept
+func (s *ScheduledTransferService) Cancel(ctx context.Context, userID uint64, id uint64) error {
+	schedule, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	schedule.Status = domain.ScheduledTransferCancelled
+
+	return s.repo.Update(ctx, schedule)
+}
+
+// RunDue executes every schedule that is due at now through BalanceService.TransferOnce.
+// Occurrences missed while the scheduler was down are skipped, not replayed
+func (s *ScheduledTransferService) RunDue(ctx context.Context, now time.Time) error {
+	schedules, err := s.repo.ClaimDue(ctx, now, uint64(s.rules.ScheduledTransferBatchSize), scheduleLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range schedules {
+		if err := s.run(ctx, &schedules[i], now); err != nil {
+			s.log.Error("Failed to record scheduled transfer run", zap.Uint64("scheduled_transfer_id", schedules[i].ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+func (s *ScheduledTransferService) run(ctx context.Context, schedule *domain.ScheduledTransfer, now time.Time) error {
+	err := s.transferWithRetry(ctx, schedule)
+	if errors.Is(err, consts.ErrBalanceLocked) {
+		// still contended after every retry, leave it due for the next tick
+		s.log.Warn("Scheduled transfer wallet is locked, retrying on next tick", zap.Uint64("scheduled_transfer_id", schedule.ID))
+		return s.repo.Release(ctx, schedule.ID)
+	}
+
+	run := &domain.ScheduledTransferRun{
+		Status:      domain.ScheduledTransferRunSucceeded,
+		Amount:      schedule.Amount,
+		ScheduledAt: schedule.NextRunAt,
+	}
+	schedule.LastError = ""
+	if err != nil {
+		run.Status = domain.ScheduledTransferRunFailed
+		run.Error = err.Error()
+		schedule.FailureCount++
+		schedule.LastError = err.Error()
+	}
+
+	// advance past this run and any occurrence already in the past
+	current := schedule.RunCount
+	for schedule.HasNextRun(current) && !schedule.RunAt(current+1).After(now) {
+		current++
+	}
+
+	hasNext := schedule.HasNextRun(current)
+	schedule.RunCount = current + 1
+	switch {
+	case hasNext:
+		schedule.NextRunAt = schedule.RunAt(schedule.RunCount)
+	case err != nil && schedule.Frequency == domain.ScheduledTransferOnce:
+		schedule.Status = domain.ScheduledTransferFailed
+	default:
+		schedule.Status = domain.ScheduledTransferCompleted
+	}
+
+	if err := s.repo.RecordRun(ctx, schedule, run); err != nil {
+		return err
+	}
+
+	if errors.Is(err, consts.ErrInsufficientBalance) {
+		s.notifyInsufficientBalance(ctx, schedule, run, hasNext)
+	}
+
+	return nil
+}
+
+// transferWithRetry retries while either wallet is locked by another
+// operation, waiting a little longer after each attempt. Each occurrence is
+// posted under its own key, so a run repeated after its outcome was lost does
+// not transfer again
+func (s *ScheduledTransferService) transferWithRetry(ctx context.Context, schedule *domain.ScheduledTransfer) error {
+	key := fmt.Sprintf("scheduled_transfer:%d:%d", schedule.ID, schedule.NextRunAt.Unix())
+
+	var err error
+	for attempt := 0; attempt <= s.rules.ScheduledTransferMaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.rules.ScheduledTransferRetryDelay * time.Duration(attempt)):
+			}
+		}
+
+		_, err = s.balanceSvc.TransferOnce(ctx, schedule.UserID, schedule.RecipientID, schedule.Amount, schedule.ID, key)
+		if errors.Is(err, consts.ErrAlreadyPosted) {
+			return nil
+		}
+		if !errors.Is(err, consts.ErrBalanceLocked) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (s *ScheduledTransferService) notifyInsufficientBalance(ctx context.Context, schedule *domain.ScheduledTransfer, run *domain.ScheduledTransferRun, hasNext bool) {
+	sender, err := s.userRepo.GetUserByID(ctx, schedule.UserID)
+	if err != nil {
+		s.log.Error("Failed to find scheduled transfer sender", zap.Uint64("user_id", schedule.UserID), zap.Error(err))
+		return
+	}
+
+	recipient, err := s.userRepo.GetUserByID(ctx, schedule.RecipientID)
+	if err != nil {
+		s.log.Error("Failed to find scheduled transfer recipient", zap.Uint64("user_id", schedule.RecipientID), zap.Error(err))
+		return
+	}
+
+	payload := map[string]interface{}{
+		"Name":          sender.Name,
+		"Amount":        strconv.FormatFloat(schedule.Amount, 'f', 2, 64),
+		"RecipientName": util.MaskName(recipient.Name),
+		"ScheduledAt":   run.ScheduledAt.Format(time.RFC1123),
+		"NextRunAt":     "",
+	}
+	if hasNext {
+		payload["NextRunAt"] = schedule.NextRunAt.Format(time.RFC1123)
+	}
+
+	err = s.mailer.SendEmail(payload, consts.TemplateScheduledTransferFailed, []string{sender.Email}, "Your scheduled transfer could not be sent")
+	if err != nil {
+		s.log.Error("Failed to send scheduled transfer failure email", zap.Uint64("scheduled_transfer_id", schedule.ID), zap.Error(err))
+	}
+}
+
+// validate checks the dates against notBefore and the amount against the
+// sender's transfer limit, amounts that need an admin review can't be scheduled
+func (s *ScheduledTransferService) validate(ctx context.Context, schedule *domain.ScheduledTransfer, notBefore time.Time) error {
+	if schedule.StartAt.Before(notBefore) {
+		return consts.ErrInvalidSchedule
+	}
+	if schedule.EndAt != nil && schedule.EndAt.Before(schedule.StartAt) {
+		return consts.ErrInvalidSchedule
+	}
+
+	if s.rules.TransferVerificationFor(schedule.Amount) == string(domain.TransferVerificationReview) {
+		return consts.ErrTransferNeedsReview
+	}
+
+	sender, err := s.userRepo.GetUserByID(ctx, schedule.UserID)
+	if err != nil {
+		return err
+	}
+
+	limit := s.rules.TransferLimitFor(string(sender.Role))
+	if limit.PerTransaction > 0 && schedule.Amount > limit.PerTransaction {
+		return consts.ErrTransferAmountLimitExceeded
+	}
+
+	return nil
+}
+
+// verify confirms a schedule whose amount needs a verification code against a
+// transfer inquiry of the sender
+func (s *ScheduledTransferService) verify(ctx context.Context, schedule *domain.ScheduledTransfer, confirmation dto.TransferRequest) error {
+	if s.rules.TransferVerificationFor(schedule.Amount) != string(domain.TransferVerificationOTP) {
+		return nil
+	}
+
+	if confirmation.InquiryID == "" {
+		return consts.ErrTransferNeedsOTP
+	}
+
+	return s.balanceSvc.VerifyTransfer(ctx, schedule.UserID, schedule.RecipientID, schedule.Amount, confirmation)
+}
+
+func (s *ScheduledTransferService) findOwned(ctx context.Context, userID uint64, id uint64) (*domain.ScheduledTransfer, error) {
+	schedule, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil || schedule.UserID != userID {
+		return nil, consts.ErrDataNotFound
+	}
+
+	return schedule, nil
+}
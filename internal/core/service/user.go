@@ -112,11 +112,20 @@ func (us *UserService) GetUser(ctx context.Context, id uint64) (*domain.User, er
 	return user, nil
 }
 
-// ListUsers lists all users
-func (us *UserService) ListUsers(ctx context.Context, page, limit uint64) ([]domain.User, error) {
-	var users []domain.User
+// ListUsers lists one page of users
+func (us *UserService) ListUsers(ctx context.Context, param dto.ListUserRequest) (*dto.PageResponse[dto.UserResponse], error) {
+	if param.PageSize == 0 {
+		param.PageSize = param.Limit
+	}
+
+	page, err := newPageRequest(param.PageRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	var users *dto.PageResponse[dto.UserResponse]
 
-	params := util.GenerateCacheKeyParams(page, limit)
+	params := util.GenerateCacheKeyParams(param.Page, page.Limit, param.Cursor, param.Sort)
 	cacheKey := util.GenerateCacheKey("users", params)
 
 	cachedUsers, err := us.cache.Get(ctx, cacheKey)
@@ -129,11 +138,18 @@ func (us *UserService) ListUsers(ctx context.Context, page, limit uint64) ([]dom
 		return users, nil
 	}
 
-	users, err = us.repo.ListUsers(ctx, page, limit)
+	found, err := us.repo.ListUsers(ctx, page)
 	if err != nil {
+		if err == consts.ErrInvalidSort || err == consts.ErrInvalidCursor {
+			return nil, err
+		}
 		return nil, consts.ErrInternal
 	}
 
+	users = newPageResponse(found, page, func(user domain.User) dto.UserResponse {
+		return dto.NewUserResponse(&user)
+	})
+
 	usersSerialized, err := util.Serialize(users)
 	if err != nil {
 		return nil, consts.ErrInternal
@@ -160,9 +176,11 @@ func (us *UserService) UpdateUser(ctx context.Context, user *domain.User) (*doma
 	emptyData := user.Name == "" &&
 		user.Email == "" &&
 		user.Password == "" &&
-		user.Role == ""
+		user.Role == "" &&
+		user.Phone == ""
 	sameData := existingUser.Name == user.Name &&
 		existingUser.Email == user.Email &&
+		existingUser.Phone == user.Phone &&
 		existingUser.Role == user.Role
 	if emptyData || sameData {
 		return nil, consts.ErrNoUpdatedData
@@ -263,6 +281,7 @@ func (us *UserService) GetProfile(ctx context.Context, id uint64) (*dto.GetProfi
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
+		Phone:     user.Phone,
 		Role:      string(user.Role),
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
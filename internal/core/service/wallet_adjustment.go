@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+type WalletAdjustmentService struct {
+	repo     port.WalletAdjustmentRepository
+	userRepo port.UserRepository
+	locker   port.Locker
+	rules    *config.Business
+}
+
+func NewWalletAdjustmentService(repo port.WalletAdjustmentRepository, userRepo port.UserRepository, locker port.Locker, rules *config.Business) *WalletAdjustmentService {
+	return &WalletAdjustmentService{
+		repo:     repo,
+		userRepo: userRepo,
+		locker:   locker,
+		rules:    rules,
+	}
+}
+
+// Create credits or debits the user's wallet right away, or leaves the
+// adjustment pending for a second admin when it reaches the approval threshold
+func (s *WalletAdjustmentService) Create(ctx context.Context, adminID uint64, request dto.WalletAdjustmentRequest) (*domain.WalletAdjustment, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+
+	adjustment := &domain.WalletAdjustment{
+		UserID:      request.UserID,
+		Direction:   domain.AdjustmentDirection(request.Direction),
+		Amount:      request.Amount,
+		ReasonCode:  request.ReasonCode,
+		Note:        request.Note,
+		Status:      domain.AdjustmentPending,
+		RequestedBy: adminID,
+	}
+
+	if s.rules.AdjustmentApprovalThreshold > 0 && request.Amount >= s.rules.AdjustmentApprovalThreshold {
+		if err := s.repo.Store(ctx, adjustment); err != nil {
+			return nil, err
+		}
+		return adjustment, nil
+	}
+
+	adjustment.Status = domain.AdjustmentApplied
+	unlock, err := lockWallets(ctx, s.locker, s.rules, adjustment.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := s.repo.Store(ctx, adjustment); err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+func (s *WalletAdjustmentService) List(ctx context.Context, request dto.ListWalletAdjustmentRequest) ([]domain.WalletAdjustment, error) {
+	filter := make(map[string]interface{})
+	if request.UserID != 0 {
+		filter["user_id"] = request.UserID
+	}
+	if request.RequestedBy != 0 {
+		filter["requested_by"] = request.RequestedBy
+	}
+	if request.Direction != "" {
+		filter["direction"] = request.Direction
+	}
+	if request.ReasonCode != "" {
+		filter["reason_code"] = request.ReasonCode
+	}
+	if request.Status != "" {
+		filter["status"] = request.Status
+	}
+
+	if request.From != "" {
+		from, err := time.Parse(time.DateOnly, request.From)
+		if err != nil {
+			return nil, err
+		}
+		filter["from"] = from
+	}
+
+	if request.To != "" {
+		to, err := time.Parse(time.DateOnly, request.To)
+		if err != nil {
+			return nil, err
+		}
+		// the end date is inclusive
+		filter["to"] = to.AddDate(0, 0, 1)
+	}
+
+	return s.repo.Finds(ctx, filter)
+}
+
+func (s *WalletAdjustmentService) Get(ctx context.Context, id uint64) (*domain.WalletAdjustment, error) {
+	adjustment, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	return adjustment, nil
+}
+
+// Approve applies a pending adjustment, it must be approved by an admin other
+// than the one who requested it
+func (s *WalletAdjustmentService) Approve(ctx context.Context, adminID uint64, id uint64) (*domain.WalletAdjustment, error) {
+	adjustment, err := s.findReviewable(ctx, adminID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockWallets(ctx, s.locker, s.rules, adjustment.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := s.repo.Approve(ctx, id, adminID); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, id)
+}
+
+// Reject closes a pending adjustment without touching the wallet, the
+// requester may withdraw their own adjustment this way
+func (s *WalletAdjustmentService) Reject(ctx context.Context, adminID uint64, id uint64, request dto.RejectWalletAdjustmentRequest) (*domain.WalletAdjustment, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Reject(ctx, id, adminID, request.Reason); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, id)
+}
+
+func (s *WalletAdjustmentService) findReviewable(ctx context.Context, adminID uint64, id uint64) (*domain.WalletAdjustment, error) {
+	adjustment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.Status != domain.AdjustmentPending {
+		return nil, consts.ErrAdjustmentClosed
+	}
+	if adjustment.RequestedBy == adminID {
+		return nil, consts.ErrAdjustmentSelfApproval
+	}
+
+	return adjustment, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// lockWallets obtains the balance locks of the users, lowest ID first so two
+// operations on the same wallets can't deadlock. A busy lock is retried with
+// backoff before failing with ErrBalanceLocked, and held locks renew
+// themselves until the returned func releases them
+func lockWallets(ctx context.Context, locker port.Locker, rules *config.Business, userIDs ...uint64) (func(), error) {
+	ids := slices.Clone(userIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	opts := port.LockOptions{
+		RetryCount:   rules.BalanceLockRetries,
+		RetryBackoff: rules.BalanceLockRetryBackoff,
+		MaxBackoff:   rules.BalanceLockMaxBackoff,
+		AutoRenew:    true,
+	}
+
+	locks := make([]port.Lock, 0, len(ids))
+	release := func() {
+		// releasing is best effort, a lock that can't be released expires on its own
+		for i := len(locks) - 1; i >= 0; i-- {
+			_ = locks[i].Release(context.WithoutCancel(ctx))
+		}
+	}
+
+	for _, id := range ids {
+		lock, err := locker.Obtain(ctx, fmt.Sprintf("balance_lock:%d", id), rules.BalanceLockTTL, opts)
+		if err != nil {
+			release()
+			if errors.Is(err, consts.ErrLockNotObtained) {
+				return nil, consts.ErrBalanceLocked
+			}
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+
+	return release, nil
+}
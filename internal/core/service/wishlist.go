@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// defaultWishlistName names the list created for save for later
+const defaultWishlistName = "Saved for later"
+
+type WishlistService struct {
+	repo        port.WishlistRepository
+	productRepo port.ProductRepository
+	cartSvc     port.CartService
+}
+
+func NewWishlistService(repo port.WishlistRepository, productRepo port.ProductRepository, cartSvc port.CartService) *WishlistService {
+	return &WishlistService{
+		repo:        repo,
+		productRepo: productRepo,
+		cartSvc:     cartSvc,
+	}
+}
+
+// List retrieves the lists of a user with their number of items
+func (s *WishlistService) List(ctx context.Context, userID int) ([]domain.Wishlist, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+// Create adds a named list, a user can't have two lists with the same name
+func (s *WishlistService) Create(ctx context.Context, userID int, request dto.WishlistRequest) (*domain.Wishlist, error) {
+	name := strings.TrimSpace(request.Name)
+	if err := s.checkName(ctx, userID, 0, name); err != nil {
+		return nil, err
+	}
+
+	wishlist := &domain.Wishlist{
+		UserID: userID,
+		Name:   name,
+	}
+
+	if err := s.repo.Store(ctx, wishlist); err != nil {
+		return nil, err
+	}
+
+	return wishlist, nil
+}
+
+// Get shows a list of the user with its products at their current price
+func (s *WishlistService) Get(ctx context.Context, userID, id int) (*dto.WishlistResponse, error) {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.FindItemsWithProducts(ctx, wishlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newWishlistResponse(wishlist, items)
+	response.ID = wishlist.ID
+	response.ShareToken = wishlist.ShareToken
+
+	return &response, nil
+}
+
+func (s *WishlistService) Rename(ctx context.Context, userID, id int, request dto.WishlistRequest) error {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if err := s.checkName(ctx, userID, wishlist.ID, name); err != nil {
+		return err
+	}
+
+	wishlist.Name = name
+
+	return s.repo.Update(ctx, wishlist.ID, *wishlist)
+}
+
+// Delete removes a list with its items, save for later creates a new
+// default list when the default one is gone
+func (s *WishlistService) Delete(ctx context.Context, userID, id int) error {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, wishlist.ID)
+}
+
+// AddItem saves a product in a list at its current price, saving it again
+// keeps the first price
+func (s *WishlistService) AddItem(ctx context.Context, userID, id int, productID int) error {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.addItem(ctx, wishlist, productID)
+}
+
+func (s *WishlistService) addItem(ctx context.Context, wishlist *domain.Wishlist, productID int) error {
+	product, err := s.productRepo.FindOne(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if product == nil {
+		return consts.ErrProductNotFound
+	}
+
+	return s.repo.AddItem(ctx, &domain.WishlistItem{
+		WishlistID: wishlist.ID,
+		ProductID:  product.ID,
+		Price:      product.Price,
+	})
+}
+
+func (s *WishlistService) RemoveItem(ctx context.Context, userID, id int, productID int) error {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	item, err := s.repo.FindItem(ctx, wishlist.ID, productID)
+	if err != nil {
+		return err
+	}
+
+	if item == nil {
+		return consts.ErrDataNotFound
+	}
+
+	return s.repo.RemoveItem(ctx, wishlist.ID, productID)
+}
+
+// MoveToCart adds a saved product to the user's cart with the cart's usual
+// checks and takes it out of the list once it is in the cart
+func (s *WishlistService) MoveToCart(ctx context.Context, userID, id int, productID, variantID int, quantity int) error {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	item, err := s.repo.FindItem(ctx, wishlist.ID, productID)
+	if err != nil {
+		return err
+	}
+
+	if item == nil {
+		return consts.ErrDataNotFound
+	}
+
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	_, err = s.cartSvc.AddToCart(ctx, domain.CartOwner{UserID: userID}, productID, variantID, quantity)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.RemoveItem(ctx, wishlist.ID, productID)
+}
+
+// SaveForLater moves a line of the user's cart into a list, the default list
+// unless the request names one. It returns the list the product went to
+func (s *WishlistService) SaveForLater(ctx context.Context, userID int, request dto.SaveForLaterRequest) (*domain.Wishlist, error) {
+	owner := domain.CartOwner{UserID: userID}
+
+	cart, err := s.cartSvc.GetCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	inCart := false
+	for _, line := range cart.Items {
+		if line.ProductID == request.ProductID && line.VariantID == request.VariantID {
+			inCart = true
+			break
+		}
+	}
+
+	if !inCart {
+		return nil, consts.ErrProductNotInCart
+	}
+
+	var wishlist *domain.Wishlist
+	if request.WishlistID != 0 {
+		wishlist, err = s.findOwned(ctx, userID, request.WishlistID)
+	} else {
+		wishlist, err = s.findOrCreateDefault(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addItem(ctx, wishlist, request.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := s.cartSvc.RemoveFromCart(ctx, owner, request.ProductID, request.VariantID); err != nil {
+		return nil, err
+	}
+
+	return wishlist, nil
+}
+
+// Share gives the list a share token, a list already shared keeps its token
+func (s *WishlistService) Share(ctx context.Context, userID, id int) (string, error) {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return "", err
+	}
+
+	if wishlist.ShareToken != "" {
+		return wishlist.ShareToken, nil
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	wishlist.ShareToken = base64.RawURLEncoding.EncodeToString(buf)
+	if err := s.repo.Update(ctx, wishlist.ID, *wishlist); err != nil {
+		return "", err
+	}
+
+	return wishlist.ShareToken, nil
+}
+
+// Unshare drops the share token, links shared before stop working
+func (s *WishlistService) Unshare(ctx context.Context, userID, id int) error {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	wishlist.ShareToken = ""
+
+	return s.repo.Update(ctx, wishlist.ID, *wishlist)
+}
+
+// GetShared shows a shared list read only, without its owner
+func (s *WishlistService) GetShared(ctx context.Context, token string) (*dto.WishlistResponse, error) {
+	wishlist, err := s.repo.FindByShareToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlist == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	items, err := s.repo.FindItemsWithProducts(ctx, wishlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newWishlistResponse(wishlist, items)
+
+	return &response, nil
+}
+
+// findOwned retrieves a list of the user, the list of another user is not
+// found either
+func (s *WishlistService) findOwned(ctx context.Context, userID, id int) (*domain.Wishlist, error) {
+	wishlist, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlist == nil || wishlist.UserID != userID {
+		return nil, consts.ErrDataNotFound
+	}
+
+	return wishlist, nil
+}
+
+func (s *WishlistService) findOrCreateDefault(ctx context.Context, userID int) (*domain.Wishlist, error) {
+	wishlist, err := s.repo.FindDefault(ctx, userID)
+	if err != nil || wishlist != nil {
+		return wishlist, err
+	}
+
+	name := defaultWishlistName
+	if err := s.checkName(ctx, userID, 0, name); err != nil {
+		// the user named a list like the default one
+		name = defaultWishlistName + " (" + time.Now().Format(time.DateOnly) + ")"
+	}
+
+	wishlist = &domain.Wishlist{
+		UserID:    userID,
+		Name:      name,
+		IsDefault: true,
+	}
+
+	if err := s.repo.Store(ctx, wishlist); err != nil {
+		return nil, err
+	}
+
+	return wishlist, nil
+}
+
+// checkName rejects a name used by another list of the user, exceptID is the
+// list being renamed
+func (s *WishlistService) checkName(ctx context.Context, userID, exceptID int, name string) error {
+	wishlists, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, wishlist := range wishlists {
+		if wishlist.ID != exceptID && strings.EqualFold(wishlist.Name, name) {
+			return consts.ErrConflictingData
+		}
+	}
+
+	return nil
+}
+
+func newWishlistResponse(wishlist *domain.Wishlist, items []domain.WishlistItemWithProduct) dto.WishlistResponse {
+	response := dto.WishlistResponse{
+		Name:      wishlist.Name,
+		Items:     []dto.WishlistItemResponse{},
+		UpdatedAt: wishlist.UpdatedAt,
+	}
+
+	for _, item := range items {
+		line := dto.WishlistItemResponse{
+			ProductID:  item.ProductID,
+			Price:      item.Price,
+			SavedPrice: item.Price,
+			SavedAt:    item.CreatedAt,
+		}
+
+		if item.Product != nil {
+			line.Name = item.Product.Name
+			line.Price = item.Product.Price
+			line.PriceDropped = item.Product.Price < item.Price
+			line.InStock = item.Product.Stock > 0
+		}
+
+		response.Items = append(response.Items, line)
+	}
+
+	response.TotalItems = len(response.Items)
+
+	return response
+}
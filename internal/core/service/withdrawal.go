@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/config"
+	"github.com/aldotp/ecommerce-go-api/internal/adapter/dto"
+	"github.com/aldotp/ecommerce-go-api/internal/core/domain"
+	"github.com/aldotp/ecommerce-go-api/internal/core/port"
+	"github.com/aldotp/ecommerce-go-api/pkg/consts"
+)
+
+// payoutClaimTimeout is how long a payout may stay processing before another
+// approval can claim it, the idempotency key keeps that retry from paying twice
+const payoutClaimTimeout = 10 * time.Minute
+
+type WithdrawalService struct {
+	repo            port.WithdrawalRepository
+	destinationRepo port.PayoutDestinationRepository
+	provider        port.PayoutProvider
+	locker          port.Locker
+	rules           *config.Business
+}
+
+func NewWithdrawalService(repo port.WithdrawalRepository, destinationRepo port.PayoutDestinationRepository, provider port.PayoutProvider, locker port.Locker, rules *config.Business) *WithdrawalService {
+	return &WithdrawalService{
+		repo:            repo,
+		destinationRepo: destinationRepo,
+		provider:        provider,
+		locker:          locker,
+		rules:           rules,
+	}
+}
+
+// AddDestination saves a bank account the user can withdraw to
+func (s *WithdrawalService) AddDestination(ctx context.Context, userID uint64, request dto.PayoutDestinationRequest) (*dto.PayoutDestinationResponse, error) {
+	destination := &domain.PayoutDestination{
+		UserID:        userID,
+		BankName:      request.BankName,
+		AccountNumber: request.AccountNumber,
+		AccountName:   request.AccountName,
+	}
+
+	if err := s.destinationRepo.Store(ctx, destination); err != nil {
+		return nil, err
+	}
+
+	response := dto.NewPayoutDestinationResponse(*destination)
+	return &response, nil
+}
+
+func (s *WithdrawalService) ListDestinations(ctx context.Context, userID uint64) ([]dto.PayoutDestinationResponse, error) {
+	destinations, err := s.destinationRepo.Finds(ctx, map[string]interface{}{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.PayoutDestinationResponse, 0, len(destinations))
+	for _, destination := range destinations {
+		response = append(response, dto.NewPayoutDestinationResponse(destination))
+	}
+
+	return response, nil
+}
+
+func (s *WithdrawalService) DeleteDestination(ctx context.Context, userID uint64, id uint64) error {
+	destination, err := s.destinationRepo.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+	if destination == nil || destination.UserID != userID {
+		return consts.ErrDataNotFound
+	}
+
+	return s.destinationRepo.Delete(ctx, id)
+}
+
+// Withdraw creates a withdrawal request to a saved destination and holds the amount until it is reviewed
+func (s *WithdrawalService) Withdraw(ctx context.Context, userID uint64, request dto.WithdrawRequest) (*dto.WithdrawalResponse, error) {
+	destination, err := s.destinationRepo.FindOne(ctx, request.DestinationID)
+	if err != nil {
+		return nil, err
+	}
+	if destination == nil || destination.UserID != userID {
+		return nil, consts.ErrDataNotFound
+	}
+
+	unlock, err := lockWallets(ctx, s.locker, s.rules, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	withdrawal := &domain.Withdrawal{
+		UserID:        userID,
+		DestinationID: destination.ID,
+		BankName:      destination.BankName,
+		AccountNumber: destination.AccountNumber,
+		AccountName:   destination.AccountName,
+		Amount:        request.Amount,
+	}
+
+	if err := s.repo.Store(ctx, withdrawal); err != nil {
+		return nil, err
+	}
+
+	response := dto.NewWithdrawalResponse(*withdrawal)
+	return &response, nil
+}
+
+func (s *WithdrawalService) ListWithdrawals(ctx context.Context, userID uint64, request dto.ListWithdrawalRequest) ([]dto.WithdrawalResponse, error) {
+	filter := map[string]interface{}{"user_id": userID}
+	if request.Status != "" {
+		filter["status"] = request.Status
+	}
+
+	return s.findWithdrawals(ctx, filter)
+}
+
+func (s *WithdrawalService) ListAllWithdrawals(ctx context.Context, request dto.ListWithdrawalRequest) ([]dto.WithdrawalResponse, error) {
+	filter := make(map[string]interface{})
+	if request.Status != "" {
+		filter["status"] = request.Status
+	}
+
+	return s.findWithdrawals(ctx, filter)
+}
+
+// Approve approves a requested withdrawal and pays it out through the provider,
+// an approved withdrawal whose payout failed can be approved again to retry.
+// The payout is claimed first so concurrent approvals cannot send it twice
+func (s *WithdrawalService) Approve(ctx context.Context, adminID uint64, id uint64) (*dto.WithdrawalResponse, error) {
+	withdrawal, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawal == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	switch withdrawal.Status {
+	case domain.WithdrawalRequested:
+		if err := s.repo.Approve(ctx, id, adminID); err != nil {
+			return nil, err
+		}
+	case domain.WithdrawalApproved, domain.WithdrawalProcessing:
+	default:
+		return nil, consts.ErrInvalidWithdrawalStatus
+	}
+
+	if err := s.repo.ClaimPayout(ctx, id, time.Now().Add(-payoutClaimTimeout)); err != nil {
+		return nil, err
+	}
+
+	reference, err := s.provider.Payout(ctx, *withdrawal, strconv.FormatUint(withdrawal.ID, 10))
+	if err != nil {
+		if err := s.repo.ReleasePayout(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, consts.ErrPayoutFailed
+	}
+
+	if err := s.repo.MarkPaid(ctx, id, reference); err != nil {
+		return nil, err
+	}
+
+	return s.findWithdrawal(ctx, id)
+}
+
+// Reject rejects a withdrawal that has not been paid out and releases the held
+// funds. An approved withdrawal whose payout failed can be rejected, one in
+// processing may already be on its way to the bank
+func (s *WithdrawalService) Reject(ctx context.Context, adminID uint64, id uint64, request dto.RejectWithdrawalRequest) (*dto.WithdrawalResponse, error) {
+	withdrawal, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawal == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	if err := s.repo.Reject(ctx, id, adminID, request.Reason); err != nil {
+		return nil, err
+	}
+
+	return s.findWithdrawal(ctx, id)
+}
+
+func (s *WithdrawalService) findWithdrawal(ctx context.Context, id uint64) (*dto.WithdrawalResponse, error) {
+	withdrawal, err := s.repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawal == nil {
+		return nil, consts.ErrDataNotFound
+	}
+
+	response := dto.NewWithdrawalResponse(*withdrawal)
+	return &response, nil
+}
+
+func (s *WithdrawalService) findWithdrawals(ctx context.Context, filter map[string]interface{}) ([]dto.WithdrawalResponse, error) {
+	withdrawals, err := s.repo.Finds(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.WithdrawalResponse, 0, len(withdrawals))
+	for _, withdrawal := range withdrawals {
+		response = append(response, dto.NewWithdrawalResponse(withdrawal))
+	}
+
+	return response, nil
+}
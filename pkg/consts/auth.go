@@ -2,4 +2,8 @@ package consts
 
 const (
 	AuthorizationKey = "user"
+
+	// guest carts are identified by a signed token sent in this header or cookie
+	CartTokenHeader = "X-Cart-Token"
+	CartTokenCookie = "cart_token"
 )
@@ -0,0 +1,8 @@
+package consts
+
+const (
+	// email templates
+	TemplateTransferOTP             = "templates/email/transfer_otp.html"
+	TemplateScheduledTransferFailed = "templates/email/scheduled_transfer_failed.html"
+	TemplateCartReminder            = "templates/email/cart_reminder.html"
+)
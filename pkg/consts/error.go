@@ -29,23 +29,131 @@ var (
 	ErrEmptyCart                    = errors.New("cart is empty")
 	ErrInsufficientBalance          = errors.New("insufficient balance")
 	ErrCannotSendBalanceSameAccount = errors.New("cannot send balance to the same account")
+	ErrCartItemLimitExceeded        = errors.New("cart has reached the maximum number of items")
+	ErrQuantityLimitExceeded        = errors.New("quantity exceeds the maximum allowed per item")
+	ErrOrderTotalLimitExceeded      = errors.New("order total exceeds the maximum allowed")
+	ErrInvalidCursor                = errors.New("invalid pagination cursor")
+	ErrInvalidSort                  = errors.New("listing cannot be sorted on this field")
+	ErrUnbalancedJournal            = errors.New("journal entry lines do not sum to zero")
+	ErrInvalidWithdrawalStatus      = errors.New("withdrawal cannot be changed in its current status")
+	ErrPayoutFailed                 = errors.New("payout provider failed to send the withdrawal")
+	ErrHoldNotFound                 = errors.New("no funds are held for this order")
+	ErrHoldReleased                 = errors.New("funds held for this order were released, place the order again")
+	ErrPaymentClosed                = errors.New("payment has expired, place the order again")
+	ErrOrderNotPaidByWallet         = errors.New("order was not paid from the wallet")
+	ErrRefundExceedsPayment         = errors.New("refund exceeds what the order paid less earlier refunds")
+	ErrAlreadyPosted                = errors.New("transaction was already posted")
+	ErrTransferNeedsOTP             = errors.New("transfer amount needs a verification code, send the inquiry_id and otp of a transfer inquiry")
+	ErrTransferInquiryMismatch      = errors.New("transfer inquiry is for another recipient or amount")
+	ErrTransferAmountLimitExceeded  = errors.New("transfer amount exceeds the per transaction limit")
+	ErrDailyTransferLimitExceeded   = errors.New("transfer exceeds the daily transfer limit")
+	ErrMonthlyTransferLimitExceeded = errors.New("transfer exceeds the monthly transfer limit")
+	ErrTransferInquiryNotFound      = errors.New("transfer confirmation not found or expired")
+	ErrInvalidOTP                   = errors.New("invalid verification code")
+	ErrTransferReviewClosed         = errors.New("transfer review is already closed")
+	ErrBalanceLocked                = errors.New("another transaction is in progress, try again later")
+	ErrTransferNeedsReview          = errors.New("transfer amount needs admin review, send it as a confirmed transfer")
+	ErrScheduledTransferClosed      = errors.New("scheduled transfer is already closed")
+	ErrInvalidSchedule              = errors.New("schedule must start in the future and end after it starts")
+	ErrMoneyRequestClosed           = errors.New("money request is no longer open")
+	ErrInvalidMoneyRequest          = errors.New("money request payers must be other users listed once")
+	ErrAdjustmentClosed             = errors.New("wallet adjustment is already closed")
+	ErrAdjustmentSelfApproval       = errors.New("wallet adjustment must be reviewed by another admin")
+	ErrLockNotObtained              = errors.New("lock is held by another process")
+	ErrLockNotHeld                  = errors.New("lock is no longer held")
+	ErrInvalidCartToken             = errors.New("cart token is invalid")
+	ErrCartVersionRequired          = errors.New("cart version is required, send the version of the cart you reviewed")
+	ErrCartChanged                  = errors.New("cart has changed since it was reviewed, review it again before checkout")
+	ErrCartItemUnavailable          = errors.New("cart has products that are no longer available")
+	ErrInvalidCartOperations        = errors.New("cart was not changed, some operations are invalid")
+	ErrInvalidCartOperation         = errors.New("operation must be upsert or remove")
+	ErrDuplicateCartOperation       = errors.New("product appears in more than one operation")
+	ErrClearCartWithBody            = errors.New("emptying the cart takes no body, remove a single product with DELETE /api/v1/carts/items")
+	ErrInvalidQuantity              = errors.New("quantity must be greater than zero")
+	ErrProductNotFound              = errors.New("product not found")
+	ErrProductNotInCart             = errors.New("product is not in the cart")
+	ErrInvalidUnsubscribeToken      = errors.New("unsubscribe link is invalid")
+	ErrVariantRequired              = errors.New("product has variants, choose one of them")
+	ErrVariantNotFound              = errors.New("product variant not found")
+	ErrInvalidVariantOptions        = errors.New("variant must pick an allowed value for every option of the product")
+	ErrOptionInUse                  = errors.New("option is used by variants of the product")
+	ErrUnsupportedImageType         = errors.New("image must be a JPEG, PNG or WebP file")
+	ErrImageTooLarge                = errors.New("image is larger than the upload limit")
+	ErrImageNotUploaded             = errors.New("image has not been uploaded")
+	ErrInvalidImageOrder            = errors.New("image order must list every image of the product once")
+	ErrStorageNotConfigured         = errors.New("file storage is not configured")
+	ErrInvalidUploadURL             = errors.New("upload URL is invalid or has expired")
+	ErrInvalidImage                 = errors.New("image could not be decoded")
 )
 
 var ErrorToHTTPStatusCode = map[error]int{
-	ErrInternal:                   http.StatusInternalServerError,
-	ErrDataNotFound:               http.StatusNotFound,
-	ErrConflictingData:            http.StatusConflict,
-	ErrInvalidCredentials:         http.StatusUnauthorized,
-	ErrUnauthorized:               http.StatusUnauthorized,
-	ErrEmptyAuthorizationHeader:   http.StatusUnauthorized,
-	ErrInvalidAuthorizationHeader: http.StatusUnauthorized,
-	ErrInvalidAuthorizationType:   http.StatusUnauthorized,
-	ErrInvalidToken:               http.StatusUnauthorized,
-	ErrExpiredToken:               http.StatusUnauthorized,
-	ErrForbidden:                  http.StatusForbidden,
-	ErrNoUpdatedData:              http.StatusBadRequest,
-	ErrInsufficientStock:          http.StatusBadRequest,
-	ErrInsufficientPayment:        http.StatusBadRequest,
-	ErrTokenCreation:              http.StatusInternalServerError,
-	ErrTokenDuration:              http.StatusInternalServerError,
+	ErrInternal:                     http.StatusInternalServerError,
+	ErrDataNotFound:                 http.StatusNotFound,
+	ErrConflictingData:              http.StatusConflict,
+	ErrInvalidCredentials:           http.StatusUnauthorized,
+	ErrUnauthorized:                 http.StatusUnauthorized,
+	ErrEmptyAuthorizationHeader:     http.StatusUnauthorized,
+	ErrInvalidAuthorizationHeader:   http.StatusUnauthorized,
+	ErrInvalidAuthorizationType:     http.StatusUnauthorized,
+	ErrInvalidToken:                 http.StatusUnauthorized,
+	ErrExpiredToken:                 http.StatusUnauthorized,
+	ErrForbidden:                    http.StatusForbidden,
+	ErrNoUpdatedData:                http.StatusBadRequest,
+	ErrInsufficientStock:            http.StatusBadRequest,
+	ErrInsufficientPayment:          http.StatusBadRequest,
+	ErrCartItemLimitExceeded:        http.StatusBadRequest,
+	ErrQuantityLimitExceeded:        http.StatusBadRequest,
+	ErrOrderTotalLimitExceeded:      http.StatusBadRequest,
+	ErrInvalidCursor:                http.StatusBadRequest,
+	ErrInvalidSort:                  http.StatusBadRequest,
+	ErrUnbalancedJournal:            http.StatusInternalServerError,
+	ErrInvalidWithdrawalStatus:      http.StatusConflict,
+	ErrPayoutFailed:                 http.StatusBadGateway,
+	ErrHoldNotFound:                 http.StatusNotFound,
+	ErrHoldReleased:                 http.StatusConflict,
+	ErrPaymentClosed:                http.StatusConflict,
+	ErrOrderNotPaidByWallet:         http.StatusBadRequest,
+	ErrRefundExceedsPayment:         http.StatusConflict,
+	ErrAlreadyPosted:                http.StatusConflict,
+	ErrTransferNeedsOTP:             http.StatusBadRequest,
+	ErrTransferInquiryMismatch:      http.StatusBadRequest,
+	ErrTransferAmountLimitExceeded:  http.StatusBadRequest,
+	ErrDailyTransferLimitExceeded:   http.StatusBadRequest,
+	ErrMonthlyTransferLimitExceeded: http.StatusBadRequest,
+	ErrTransferInquiryNotFound:      http.StatusNotFound,
+	ErrInvalidOTP:                   http.StatusBadRequest,
+	ErrTransferReviewClosed:         http.StatusConflict,
+	ErrBalanceLocked:                http.StatusConflict,
+	ErrTransferNeedsReview:          http.StatusBadRequest,
+	ErrScheduledTransferClosed:      http.StatusConflict,
+	ErrInvalidSchedule:              http.StatusBadRequest,
+	ErrMoneyRequestClosed:           http.StatusConflict,
+	ErrInvalidMoneyRequest:          http.StatusBadRequest,
+	ErrAdjustmentClosed:             http.StatusConflict,
+	ErrAdjustmentSelfApproval:       http.StatusForbidden,
+	ErrInvalidCartToken:             http.StatusBadRequest,
+	ErrCartVersionRequired:          http.StatusPreconditionRequired,
+	ErrCartChanged:                  http.StatusPreconditionFailed,
+	ErrCartItemUnavailable:          http.StatusConflict,
+	ErrInvalidCartOperations:        http.StatusUnprocessableEntity,
+	ErrInvalidCartOperation:         http.StatusBadRequest,
+	ErrClearCartWithBody:            http.StatusBadRequest,
+	ErrDuplicateCartOperation:       http.StatusBadRequest,
+	ErrInvalidQuantity:              http.StatusBadRequest,
+	ErrProductNotFound:              http.StatusNotFound,
+	ErrProductNotInCart:             http.StatusNotFound,
+	ErrInvalidUnsubscribeToken:      http.StatusBadRequest,
+	ErrVariantRequired:              http.StatusBadRequest,
+	ErrVariantNotFound:              http.StatusNotFound,
+	ErrInvalidVariantOptions:        http.StatusBadRequest,
+	ErrOptionInUse:                  http.StatusConflict,
+	ErrUnsupportedImageType:         http.StatusUnsupportedMediaType,
+	ErrImageTooLarge:                http.StatusRequestEntityTooLarge,
+	ErrImageNotUploaded:             http.StatusBadRequest,
+	ErrInvalidImageOrder:            http.StatusBadRequest,
+	ErrStorageNotConfigured:         http.StatusServiceUnavailable,
+	ErrInvalidUploadURL:             http.StatusForbidden,
+	ErrInvalidImage:                 http.StatusUnprocessableEntity,
+	ErrTokenCreation:                http.StatusInternalServerError,
+	ErrTokenDuration:                http.StatusInternalServerError,
 }
@@ -7,4 +7,7 @@ const (
 
 	// queue
 	QueueUpdateStock = "queue_update_stock"
+	QueuePriceDrop   = "queue_price_drop"
+
+	QueueProductImageDerivatives = "queue_product_image_derivatives"
 )
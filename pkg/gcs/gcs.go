@@ -3,6 +3,7 @@ package gcs
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -48,6 +49,10 @@ func (g *GCS) Upload(ctx context.Context, object *FileUploadObject) error {
 		wc.ACL = []gcstorage.ACLRule{{Entity: gcstorage.AllUsers, Role: gcstorage.RoleReader}}
 	}
 
+	if object.ContentType != "" {
+		wc.ContentType = object.ContentType
+	}
+
 	if _, err := io.Copy(wc, object.File); err != nil {
 		return fmt.Errorf("io.Copy: %w", err)
 	}
@@ -64,6 +69,9 @@ func (g *GCS) Download(ctx context.Context, fileName string) (*bytes.Buffer, err
 	defer cancel()
 
 	rc, err := g.client.Bucket(g.bucket).Object(fileName).NewReader(ctx)
+	if errors.Is(err, gcstorage.ErrObjectNotExist) {
+		return nil, ErrObjectNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("Object(%q).NewReader: %w", fileName, err)
 	}
@@ -75,6 +83,21 @@ func (g *GCS) Download(ctx context.Context, fileName string) (*bytes.Buffer, err
 	return buff, nil
 }
 
+func (g *GCS) Size(ctx context.Context, fileName string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*15)
+	defer cancel()
+
+	attrs, err := g.client.Bucket(g.bucket).Object(fileName).Attrs(ctx)
+	if errors.Is(err, gcstorage.ErrObjectNotExist) {
+		return 0, ErrObjectNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Object(%q).Attrs: %w", fileName, err)
+	}
+
+	return attrs.Size, nil
+}
+
 func (g *GCS) Delete(ctx context.Context, fileName string) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*15)
 	defer cancel()
@@ -0,0 +1,254 @@
+// Package gcstest holds the contract suite every gcs.StorageInterface backend
+// has to pass
+package gcstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+)
+
+// png is the signature of a PNG file, enough for content type detection
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+// File is an in-memory multipart.File for FileUploadObject
+type File struct {
+	*bytes.Reader
+	Closed bool
+}
+
+func NewFile(data []byte) *File {
+	return &File{Reader: bytes.NewReader(data)}
+}
+
+func (f *File) Close() error {
+	f.Closed = true
+	return nil
+}
+
+var _ multipart.File = &File{}
+
+// StorageContract runs the storage of a backend through uploads, downloads,
+// sizes, deletes, public URLs and presigned uploads. The URLs of the storage have to
+// be reachable from the test, newStorage is called for every subtest
+func StorageContract(t *testing.T, newStorage func(t *testing.T) gcs.StorageInterface) {
+	ctx := context.Background()
+
+	t.Run("upload and download", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".png")
+
+		file := NewFile(png)
+		upload(t, storage, key, file, "image/png")
+
+		if !file.Closed {
+			t.Error("Upload did not close the file")
+		}
+
+		got, err := storage.Download(ctx, key)
+		if err != nil {
+			t.Fatalf("Download: %v", err)
+		}
+		if !bytes.Equal(got.Bytes(), png) {
+			t.Errorf("Download = %q, want %q", got.Bytes(), png)
+		}
+	})
+
+	t.Run("upload replaces", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".txt")
+
+		upload(t, storage, key, NewFile([]byte("first")), "text/plain")
+		upload(t, storage, key, NewFile([]byte("second")), "text/plain")
+
+		got, err := storage.Download(ctx, key)
+		if err != nil {
+			t.Fatalf("Download: %v", err)
+		}
+		if got.String() != "second" {
+			t.Errorf("Download = %q, want %q", got.String(), "second")
+		}
+	})
+
+	t.Run("download missing", func(t *testing.T) {
+		storage := newStorage(t)
+
+		_, err := storage.Download(ctx, newKey(t, ".png"))
+		if !errors.Is(err, gcs.ErrObjectNotFound) {
+			t.Errorf("Download = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("size", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".png")
+
+		upload(t, storage, key, NewFile(png), "image/png")
+
+		size, err := storage.Size(ctx, key)
+		if err != nil {
+			t.Fatalf("Size: %v", err)
+		}
+		if size != int64(len(png)) {
+			t.Errorf("Size = %d, want %d", size, len(png))
+		}
+
+		if _, err := storage.Size(ctx, newKey(t, ".png")); !errors.Is(err, gcs.ErrObjectNotFound) {
+			t.Errorf("Size of a missing file = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".png")
+
+		upload(t, storage, key, NewFile(png), "image/png")
+
+		if err := storage.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		_, err := storage.Download(ctx, key)
+		if !errors.Is(err, gcs.ErrObjectNotFound) {
+			t.Errorf("Download after Delete = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("public url", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".png")
+
+		upload(t, storage, key, NewFile(png), "image/png")
+
+		response, err := http.Get(storage.GenerateUrl(key))
+		if err != nil {
+			t.Fatalf("GET %s: %v", storage.GenerateUrl(key), err)
+		}
+		defer response.Body.Close()
+
+		body, _ := io.ReadAll(response.Body)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("GET status = %d, want 200: %s", response.StatusCode, body)
+		}
+		if !bytes.Equal(body, png) {
+			t.Errorf("GET body = %q, want %q", body, png)
+		}
+		if got := response.Header.Get("Content-Type"); got != "image/png" {
+			t.Errorf("GET Content-Type = %q, want image/png", got)
+		}
+	})
+
+	t.Run("presigned upload", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".png")
+
+		signed, err := storage.GeneratePresignedUrl(key, time.Minute)
+		if err != nil {
+			t.Fatalf("GeneratePresignedUrl: %v", err)
+		}
+
+		if status := put(t, signed, png); status != http.StatusOK {
+			t.Fatalf("PUT status = %d, want 200", status)
+		}
+
+		got, err := storage.Download(ctx, key)
+		if err != nil {
+			t.Fatalf("Download: %v", err)
+		}
+		if !bytes.Equal(got.Bytes(), png) {
+			t.Errorf("Download = %q, want %q", got.Bytes(), png)
+		}
+	})
+
+	t.Run("presigned url is bound to its key", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".png")
+		other := newKey(t, ".png")
+
+		signed, err := storage.GeneratePresignedUrl(key, time.Minute)
+		if err != nil {
+			t.Fatalf("GeneratePresignedUrl: %v", err)
+		}
+
+		// the signature of key sent to the path of other
+		tampered := storage.GenerateUrl(other) + "?" + mustParse(t, signed).RawQuery
+		if status := put(t, tampered, png); status != http.StatusForbidden {
+			t.Errorf("PUT to another key status = %d, want 403", status)
+		}
+
+		if _, err := storage.Download(ctx, other); !errors.Is(err, gcs.ErrObjectNotFound) {
+			t.Errorf("Download of another key = %v, want ErrObjectNotFound", err)
+		}
+	})
+
+	t.Run("presigned url expires", func(t *testing.T) {
+		storage := newStorage(t)
+		key := newKey(t, ".png")
+
+		signed, err := storage.GeneratePresignedUrl(key, time.Second)
+		if err != nil {
+			t.Fatalf("GeneratePresignedUrl: %v", err)
+		}
+
+		time.Sleep(2 * time.Second)
+
+		if status := put(t, signed, png); status != http.StatusForbidden {
+			t.Errorf("PUT after expiry status = %d, want 403", status)
+		}
+	})
+}
+
+func upload(t *testing.T, storage gcs.StorageInterface, key string, file *File, contentType string) {
+	t.Helper()
+
+	err := storage.Upload(context.Background(), &gcs.FileUploadObject{File: file, FileName: key, ContentType: contentType})
+	if err != nil {
+		t.Fatalf("Upload(%s): %v", key, err)
+	}
+}
+
+func put(t *testing.T, rawURL string, body []byte) int {
+	t.Helper()
+
+	request, err := http.NewRequest(http.MethodPut, rawURL, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("building PUT %s: %v", rawURL, err)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("PUT %s: %v", rawURL, err)
+	}
+	defer response.Body.Close()
+
+	io.Copy(io.Discard, response.Body)
+
+	return response.StatusCode
+}
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", rawURL, err)
+	}
+
+	return parsed
+}
+
+// newKey names an object no other subtest or run uses
+func newKey(t *testing.T, ext string) string {
+	name := strings.NewReplacer("/", "-", " ", "-").Replace(t.Name())
+	return fmt.Sprintf("contract/%d/%s%s", time.Now().UnixNano(), name, ext)
+}
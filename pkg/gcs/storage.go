@@ -3,10 +3,20 @@ package gcs
 import (
 	"bytes"
 	"context"
+	"errors"
+	"time"
 )
 
+// ErrObjectNotFound is returned by Download and Size for a file that is not
+// in the storage
+var ErrObjectNotFound = errors.New("object not found")
+
 type StorageInterface interface {
 	Upload(ctx context.Context, file *FileUploadObject) error
 	Download(ctx context.Context, fileName string) (*bytes.Buffer, error)
+	// Size is the size in bytes of a stored file, read without downloading it
+	Size(ctx context.Context, fileName string) (int64, error)
 	Delete(ctx context.Context, fileName string) error
+	GenerateUrl(fileName string) string
+	GeneratePresignedUrl(fileName string, expiration time.Duration) (string, error)
 }
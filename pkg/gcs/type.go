@@ -1,8 +1,28 @@
 package gcs
 
-import "mime/multipart"
+import (
+	"bytes"
+	"mime/multipart"
+)
 
+// FileUploadObject is a file to upload, the storage detects the content type
+// when ContentType is empty
 type FileUploadObject struct {
-	File     multipart.File `json:"file"`
-	FileName string         `json:"file_name"`
+	File        multipart.File `json:"file"`
+	FileName    string         `json:"file_name"`
+	ContentType string         `json:"content_type"`
+}
+
+// bytesFile is a multipart.File over content held in memory
+type bytesFile struct {
+	*bytes.Reader
+}
+
+func (bytesFile) Close() error {
+	return nil
+}
+
+// NewBytesFile wraps generated content to upload it as a FileUploadObject
+func NewBytesFile(content []byte) multipart.File {
+	return bytesFile{Reader: bytes.NewReader(content)}
 }
@@ -0,0 +1,208 @@
+// Package localstorage keeps files on the local disk behind the API, for
+// development and CI where no bucket is available
+package localstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+)
+
+var (
+	// ErrInvalidFileName is returned for a file name that leaves the storage
+	// directory
+	ErrInvalidFileName = errors.New("invalid file name")
+	// ErrInvalidSignature is returned by Verify for a presigned URL that was
+	// not signed for the request or has expired
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// LocalStorage stores files under a directory. Files are served from baseURL
+// and presigned uploads are PUT to it with an HMAC signature, both through
+// the routes of the file handler
+type LocalStorage struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalStorage(dir, baseURL, secret string) (*LocalStorage, error) {
+	if secret == "" {
+		return nil, errors.New("local storage needs a secret to sign upload URLs")
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("MkdirAll(%s): %w", dir, err)
+	}
+
+	return &LocalStorage{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+	}, nil
+}
+
+var _ gcs.StorageInterface = &LocalStorage{}
+
+// Upload stores the file, the content type is taken from the file name when
+// it is served
+func (l *LocalStorage) Upload(ctx context.Context, object *gcs.FileUploadObject) error {
+	defer object.File.Close()
+
+	return l.Write(object.FileName, object.File)
+}
+
+// Write stores the content of r under fileName. The file is written aside and
+// moved in place, so a failed write leaves the previous file untouched
+func (l *LocalStorage) Write(fileName string, r io.Reader) error {
+	path, err := l.Path(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("CreateTemp: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("io.Copy: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("File.Close: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("Rename: %w", err)
+	}
+
+	return nil
+}
+
+func (l *LocalStorage) Download(ctx context.Context, fileName string) (*bytes.Buffer, error) {
+	path, err := l.Path(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, gcs.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile(%q): %w", fileName, err)
+	}
+
+	return bytes.NewBuffer(content), nil
+}
+
+func (l *LocalStorage) Size(ctx context.Context, fileName string) (int64, error) {
+	path, err := l.Path(fileName)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, gcs.ErrObjectNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Stat(%q): %w", fileName, err)
+	}
+
+	return info.Size(), nil
+}
+
+// Delete removes a file, a file that is not stored is not an error
+func (l *LocalStorage) Delete(ctx context.Context, fileName string) error {
+	path, err := l.Path(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Remove(%q): %w", fileName, err)
+	}
+
+	return nil
+}
+
+func (l *LocalStorage) GenerateUrl(fileName string) string {
+	segments := strings.Split(fileName, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+
+	return l.baseURL + "/" + strings.Join(segments, "/")
+}
+
+// GeneratePresignedUrl returns a URL to PUT the file to until expiration has
+// passed
+func (l *LocalStorage) GeneratePresignedUrl(fileName string, expiration time.Duration) (string, error) {
+	expires := time.Now().Add(expiration).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", l.sign("PUT", fileName, expires))
+
+	return l.GenerateUrl(fileName) + "?" + query.Encode(), nil
+}
+
+// Verify checks the signature of a presigned URL request for a file
+func (l *LocalStorage) Verify(method, fileName string, query url.Values) error {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrInvalidSignature
+	}
+
+	want := l.sign(method, fileName, expires)
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(want)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Path is where a file is kept on disk, names that resolve outside the
+// storage directory are ErrInvalidFileName
+func (l *LocalStorage) Path(fileName string) (string, error) {
+	path := filepath.Join(l.dir, filepath.FromSlash(fileName))
+	if !strings.HasPrefix(path, l.dir+string(filepath.Separator)) {
+		return "", ErrInvalidFileName
+	}
+
+	return path, nil
+}
+
+func (l *LocalStorage) sign(method, fileName string, expires int64) string {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, fileName, expires)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
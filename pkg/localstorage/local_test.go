@@ -0,0 +1,53 @@
+package localstorage_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	handler "github.com/aldotp/ecommerce-go-api/internal/adapter/handler/http"
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs/gcstest"
+	"github.com/aldotp/ecommerce-go-api/pkg/localstorage"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TestLocalStorageContract serves the storage through the file handler routes
+// the way the API does with STORAGE_BACKEND=local
+func TestLocalStorageContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	gcstest.StorageContract(t, func(t *testing.T) gcs.StorageInterface {
+		router := gin.New()
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+
+		storage, err := localstorage.NewLocalStorage(t.TempDir(), server.URL+"/files", "contract-secret")
+		if err != nil {
+			t.Fatalf("NewLocalStorage: %v", err)
+		}
+
+		files := handler.NewFileHandler(storage, 1<<20, zap.NewNop())
+		router.GET("/files/*key", files.ServeFile)
+		router.PUT("/files/*key", files.UploadFile)
+
+		return storage
+	})
+}
+
+func TestLocalStoragePath(t *testing.T) {
+	storage, err := localstorage.NewLocalStorage(t.TempDir(), "http://localhost/files", "secret")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	for _, name := range []string{"", ".", "../outside.png", "products/../../outside.png"} {
+		if _, err := storage.Path(name); err != localstorage.ErrInvalidFileName {
+			t.Errorf("Path(%q) = %v, want ErrInvalidFileName", name, err)
+		}
+	}
+
+	if _, err := storage.Path("products/1/image.png"); err != nil {
+		t.Errorf("Path(products/1/image.png) = %v", err)
+	}
+}
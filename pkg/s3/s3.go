@@ -0,0 +1,233 @@
+// Package s3 stores files in an S3 compatible bucket such as AWS S3 or MinIO,
+// signing its requests with AWS Signature Version 4
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+)
+
+// maxPresignExpiry is the longest a presigned URL may stay valid in S3
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// S3 stores files in a bucket addressed path-style, as endpoint/bucket/key,
+// which every S3 compatible server accepts
+type S3 struct {
+	endpoint *url.URL
+	bucket   string
+	signer   signer
+	client   *http.Client
+}
+
+func NewS3(endpoint, region, bucket, accessKey, secretKey string) (*S3, error) {
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing S3 endpoint: %w", err)
+	}
+
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("S3 endpoint %q needs a scheme and a host", endpoint)
+	}
+
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is not set")
+	}
+
+	return &S3{
+		endpoint: parsed,
+		bucket:   bucket,
+		signer: signer{
+			accessKey: accessKey,
+			secretKey: secretKey,
+			region:    region,
+			service:   "s3",
+		},
+		client: &http.Client{},
+	}, nil
+}
+
+var _ gcs.StorageInterface = &S3{}
+
+// Upload stores the file, without a content type one is guessed from the file
+// name
+func (s *S3) Upload(ctx context.Context, object *gcs.FileUploadObject) error {
+	defer object.File.Close()
+
+	// the payload is hashed into the signature, so the file is read twice
+	hash := sha256.New()
+	size, err := io.Copy(hash, object.File)
+	if err != nil {
+		return fmt.Errorf("io.Copy: %w", err)
+	}
+
+	if _, err := object.File.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("File.Seek: %w", err)
+	}
+
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(object.FileName))
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	response, err := s.do(ctx, http.MethodPut, object.FileName, header, io.NopCloser(object.File), size, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return checkResponse(response, http.MethodPut, object.FileName)
+}
+
+func (s *S3) Download(ctx context.Context, fileName string) (*bytes.Buffer, error) {
+	response, err := s.do(ctx, http.MethodGet, fileName, http.Header{}, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, gcs.ErrObjectNotFound
+	}
+
+	if err := checkResponse(response, http.MethodGet, fileName); err != nil {
+		return nil, err
+	}
+
+	buff := new(bytes.Buffer)
+	if _, err := buff.ReadFrom(response.Body); err != nil {
+		return nil, fmt.Errorf("reading %q: %w", fileName, err)
+	}
+
+	return buff, nil
+}
+
+// Size reads the size of a file with a HEAD request
+func (s *S3) Size(ctx context.Context, fileName string) (int64, error) {
+	response, err := s.do(ctx, http.MethodHead, fileName, http.Header{}, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return 0, gcs.ErrObjectNotFound
+	}
+
+	if err := checkResponse(response, http.MethodHead, fileName); err != nil {
+		return 0, err
+	}
+
+	if response.ContentLength < 0 {
+		return 0, fmt.Errorf("S3 %s %q: no Content-Length", http.MethodHead, fileName)
+	}
+
+	return response.ContentLength, nil
+}
+
+// Delete removes a file, S3 reports no error for a file that is not stored
+func (s *S3) Delete(ctx context.Context, fileName string) error {
+	response, err := s.do(ctx, http.MethodDelete, fileName, http.Header{}, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return checkResponse(response, http.MethodDelete, fileName)
+}
+
+// GenerateUrl is the public URL of a file, the bucket has to allow anonymous
+// reads for it to be served
+func (s *S3) GenerateUrl(fileName string) string {
+	object := s.objectURL(fileName)
+
+	return object.Scheme + "://" + object.Host + uriEncode(object.Path, false)
+}
+
+// GeneratePresignedUrl returns a URL to PUT the file to until expiration has
+// passed, S3 allows at most seven days
+func (s *S3) GeneratePresignedUrl(fileName string, expiration time.Duration) (string, error) {
+	if expiration > maxPresignExpiry {
+		return "", fmt.Errorf("presigned URL expiry %s is longer than %s", expiration, maxPresignExpiry)
+	}
+
+	if expiration < time.Second {
+		expiration = time.Second
+	}
+
+	return s.signer.presign(http.MethodPut, s.objectURL(fileName), expiration, time.Now()), nil
+}
+
+func (s *S3) objectURL(fileName string) *url.URL {
+	object := *s.endpoint
+	object.Path = path.Join("/", s.endpoint.Path, s.bucket, fileName)
+	object.RawPath = ""
+	object.RawQuery = ""
+
+	return &object
+}
+
+// do sends a signed request for a file of the bucket
+func (s *S3) do(ctx context.Context, method, fileName string, header http.Header, body io.ReadCloser, size int64, payloadHash string) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*15)
+
+	object := s.objectURL(fileName)
+	object.RawPath = uriEncode(object.Path, false)
+
+	request, err := http.NewRequestWithContext(ctx, method, object.String(), body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	request.Header = header
+	request.ContentLength = size
+	s.signer.sign(request, payloadHash, time.Now())
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("S3 %s %q: %w", method, fileName, err)
+	}
+
+	response.Body = &cancelBody{ReadCloser: response.Body, cancel: cancel}
+
+	return response, nil
+}
+
+// cancelBody releases the timeout of a request once its response is read
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func checkResponse(response *http.Response, method, fileName string) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+	return fmt.Errorf("S3 %s %q: %s: %s", method, fileName, response.Status, bytes.TrimSpace(body))
+}
@@ -0,0 +1,188 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs"
+	"github.com/aldotp/ecommerce-go-api/pkg/gcs/gcstest"
+)
+
+// TestS3Contract runs the contract against an in-memory stand-in for MinIO,
+// and against the S3 compatible server in TEST_S3_* when TEST_S3_ENDPOINT is
+// set. That bucket has to allow anonymous reads
+func TestS3Contract(t *testing.T) {
+	t.Run("stand-in", func(t *testing.T) {
+		fake := newFakeS3("contract", "fake-access-key", "fake-secret-key")
+		server := httptest.NewServer(fake)
+		t.Cleanup(server.Close)
+
+		gcstest.StorageContract(t, func(t *testing.T) gcs.StorageInterface {
+			storage, err := NewS3(server.URL, fake.signer.region, fake.bucket, fake.signer.accessKey, fake.signer.secretKey)
+			if err != nil {
+				t.Fatalf("NewS3: %v", err)
+			}
+			return storage
+		})
+	})
+
+	t.Run("endpoint", func(t *testing.T) {
+		endpoint := os.Getenv("TEST_S3_ENDPOINT")
+		if endpoint == "" {
+			t.Skip("TEST_S3_ENDPOINT is not set")
+		}
+
+		region := os.Getenv("TEST_S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		gcstest.StorageContract(t, func(t *testing.T) gcs.StorageInterface {
+			storage, err := NewS3(endpoint, region, os.Getenv("TEST_S3_BUCKET"), os.Getenv("TEST_S3_ACCESS_KEY"), os.Getenv("TEST_S3_SECRET_KEY"))
+			if err != nil {
+				t.Fatalf("NewS3: %v", err)
+			}
+			return storage
+		})
+	})
+}
+
+// fakeS3 serves one bucket path-style like MinIO. Writes need a valid
+// signature in the headers or the query, reads are anonymous like a public
+// bucket
+type fakeS3 struct {
+	bucket string
+	signer signer
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+}
+
+func newFakeS3(bucket, accessKey, secretKey string) *fakeS3 {
+	return &fakeS3{
+		bucket: bucket,
+		signer: signer{
+			accessKey: accessKey,
+			secretKey: secretKey,
+			region:    "us-east-1",
+			service:   "s3",
+		},
+		objects: make(map[string]fakeObject),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		object, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		if object.contentType != "" {
+			w.Header().Set("Content-Type", object.contentType)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.body)))
+		w.Write(object.body)
+		return
+	}
+
+	if !f.authorized(r, body) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{body: body, contentType: r.Header.Get("Content-Type")}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authorized recomputes the signature of a request the way S3 does
+func (f *fakeS3) authorized(r *http.Request, body []byte) bool {
+	query := r.URL.Query()
+	if signature := query.Get("X-Amz-Signature"); signature != "" {
+		query.Del("X-Amz-Signature")
+
+		date, err := time.Parse(amzDateFormat, query.Get("X-Amz-Date"))
+		if err != nil {
+			return false
+		}
+
+		expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || time.Now().After(date.Add(time.Duration(expires)*time.Second)) {
+			return false
+		}
+
+		canonical := canonicalRequest(r.Method, r.URL.Path, query, map[string]string{"host": r.Host}, []string{"host"}, unsignedPayload)
+
+		return query.Get("X-Amz-Credential") == f.signer.accessKey+"/"+f.signer.scope(date) &&
+			hmac.Equal([]byte(signature), []byte(f.signer.signature(canonical, date)))
+	}
+
+	fields := map[string]string{}
+	for _, field := range strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), algorithm+" "), ", ") {
+		name, value, _ := strings.Cut(field, "=")
+		fields[name] = value
+	}
+
+	date, err := time.Parse(amzDateFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if digest := sha256.Sum256(body); payloadHash != hex.EncodeToString(digest[:]) {
+		return false
+	}
+
+	names := strings.Split(fields["SignedHeaders"], ";")
+	headers := make(map[string]string, len(names))
+	for _, name := range names {
+		if name == "host" {
+			headers[name] = r.Host
+			continue
+		}
+		headers[name] = r.Header.Get(name)
+	}
+
+	canonical := canonicalRequest(r.Method, r.URL.Path, query, headers, names, payloadHash)
+
+	return fields["Credential"] == f.signer.accessKey+"/"+f.signer.scope(date) &&
+		hmac.Equal([]byte(fields["Signature"]), []byte(f.signer.signature(canonical, date)))
+}
@@ -0,0 +1,172 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm     = "AWS4-HMAC-SHA256"
+	amzDateFormat = "20060102T150405Z"
+	dateFormat    = "20060102"
+
+	// unsignedPayload stands in for the payload hash of presigned URLs, whose
+	// body is not known when they are signed
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body
+var emptyPayloadHash = hex.EncodeToString(sha256.New().Sum(nil))
+
+// signer signs requests with AWS Signature Version 4
+type signer struct {
+	accessKey string
+	secretKey string
+	region    string
+	service   string
+}
+
+// sign adds the date, payload hash and Authorization headers to a request.
+// The host and every header already set on the request are signed
+func (s signer) sign(request *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	request.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	names := signedHeaders(headers)
+	canonical := canonicalRequest(request.Method, request.URL.Path, request.URL.Query(), headers, names, payloadHash)
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.accessKey, s.scope(now), strings.Join(names, ";"), s.signature(canonical, now)))
+}
+
+// presign returns the URL of a request signed in its query, valid for
+// expiration from now. Only the host header is signed
+func (s signer) presign(method string, target *url.URL, expiration time.Duration, now time.Time) string {
+	now = now.UTC()
+
+	query := target.Query()
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiration/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	headers := map[string]string{"host": target.Host}
+	canonical := canonicalRequest(method, target.Path, query, headers, []string{"host"}, unsignedPayload)
+
+	return target.Scheme + "://" + target.Host + uriEncode(target.Path, false) + "?" +
+		canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(canonical, now)
+}
+
+func (s signer) scope(now time.Time) string {
+	return strings.Join([]string{now.Format(dateFormat), s.region, s.service, "aws4_request"}, "/")
+}
+
+// signature signs a canonical request with the key derived for the day
+func (s signer) signature(canonical string, now time.Time) string {
+	digest := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{
+		algorithm,
+		now.Format(amzDateFormat),
+		s.scope(now),
+		hex.EncodeToString(digest[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format(dateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func canonicalRequest(method, path string, query url.Values, headers map[string]string, names []string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join([]string{
+		method,
+		uriEncode(path, false),
+		canonicalQuery(query),
+		canonicalHeaders.String(),
+		strings.Join(names, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// canonicalQuery encodes a query sorted by name and value
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func signedHeaders(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// uriEncode percent-encodes every byte but the unreserved characters, which
+// is how AWS expects paths and query strings. Slashes are kept in paths
+func uriEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,82 @@
+// Package thumbnail makes resized JPEG copies of JPEG, PNG and WebP images
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the images Decode accepts, a small file can declare a huge
+// image that would exhaust memory once decoded
+const MaxPixels = 50_000_000
+
+// Quality is the JPEG quality of the copies
+const Quality = 85
+
+// ErrInvalidImage is returned by Decode for content that is not a JPEG, PNG
+// or WebP image it can read
+var ErrInvalidImage = errors.New("invalid image")
+
+// Decode reads a JPEG, PNG or WebP image. Corrupt, unsupported and oversized
+// images are ErrInvalidImage
+func Decode(content []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrInvalidImage, config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	return src, nil
+}
+
+// Fit scales an image down to fit a maxSide square, keeping its aspect ratio.
+// Images that already fit are not enlarged
+func Fit(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= maxSide && height <= maxSide {
+		return src
+	}
+
+	if width >= height {
+		height = max(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = max(1, width*maxSide/height)
+		height = maxSide
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	return dst
+}
+
+// EncodeJPEG writes an image as a JPEG, transparent areas turn white since
+// JPEG has no alpha channel
+func EncodeJPEG(w io.Writer, src image.Image) error {
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	return jpeg.Encode(w, flat, &jpeg.Options{Quality: Quality})
+}
@@ -0,0 +1,92 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	src := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		src.Set(x, 0, color.NRGBA{R: 255, A: 128})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxSide       int
+		wantW, wantH  int
+	}{
+		{"landscape", 1200, 600, 300, 300, 150},
+		{"portrait", 600, 1200, 300, 150, 300},
+		{"already fits", 200, 100, 300, 200, 100},
+		{"thin", 3000, 2, 300, 300, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := Decode(encodePNG(t, tt.width, tt.height))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			bounds := Fit(src, tt.maxSide).Bounds()
+			if bounds.Dx() != tt.wantW || bounds.Dy() != tt.wantH {
+				t.Errorf("Fit = %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestEncodeJPEG(t *testing.T) {
+	src, err := Decode(encodePNG(t, 40, 20))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeJPEG(&buf, Fit(src, 10)); err != nil {
+		t.Fatalf("EncodeJPEG: %v", err)
+	}
+
+	config, format, err := image.DecodeConfig(&buf)
+	if err != nil {
+		t.Fatalf("decoding the JPEG: %v", err)
+	}
+	if format != "jpeg" || config.Width != 10 || config.Height != 5 {
+		t.Errorf("EncodeJPEG wrote a %dx%d %s, want a 10x5 jpeg", config.Width, config.Height, format)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid := encodePNG(t, 10, 10)
+
+	tests := map[string][]byte{
+		"empty":     nil,
+		"text":      []byte("not an image"),
+		"truncated": valid[:len(valid)/2],
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Decode(content); !errors.Is(err, ErrInvalidImage) {
+				t.Errorf("Decode = %v, want ErrInvalidImage", err)
+			}
+		})
+	}
+}
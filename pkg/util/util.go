@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/aldotp/ecommerce-go-api/pkg/consts"
@@ -49,3 +50,33 @@ func CatchInternalServerError(errMessage error) error {
 
 	return consts.ErrInternal
 }
+
+// MaskString hides all but the last visible characters of value
+func MaskString(value string, visible int) string {
+	runes := []rune(value)
+	if len(runes) <= visible {
+		return value
+	}
+
+	masked := make([]rune, len(runes))
+	for i, r := range runes {
+		if i < len(runes)-visible {
+			masked[i] = '*'
+		} else {
+			masked[i] = r
+		}
+	}
+
+	return string(masked)
+}
+
+// MaskName keeps the first letter of every word, e.g. "John Doe" becomes "J*** D**"
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+
+	return strings.Join(words, " ")
+}